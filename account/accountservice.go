@@ -2,25 +2,21 @@ package account
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/ecdsa"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/binary"
 	"os"
 	"path"
 	"sync"
+	"time"
 
 	"github.com/aergoio/aergo-actor/actor"
 
-	"github.com/aergoio/aergo-lib/db"
 	"github.com/aergoio/aergo-lib/log"
 	cfg "github.com/aergoio/aergo/config"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/mr-tron/base58/base58"
 )
 
 type aergokey = btcec.PrivateKey
@@ -30,18 +26,23 @@ type AccountService struct {
 	cfg         *cfg.Config
 	accountLock sync.RWMutex
 	accounts    []*types.Account
-	unlocked    map[string]*aergokey
-	storage     db.DB
+	keystoreDir string
 	addrs       *Addresses
 	testConfig  bool
+
+	// unlockLock guards unlocked keys and the timers to lock them again
+	unlockLock   sync.Mutex
+	unlocked     map[string]*aergokey
+	unlockTimers map[string]*time.Timer
 }
 
 //NewAccountService create account service
 func NewAccountService(cfg *cfg.Config) *AccountService {
 	actor := &AccountService{
-		cfg:      cfg,
-		accounts: []*types.Account{},
-		unlocked: map[string]*aergokey{},
+		cfg:          cfg,
+		accounts:     []*types.Account{},
+		unlocked:     map[string]*aergokey{},
+		unlockTimers: map[string]*time.Timer{},
 	}
 	actor.BaseComponent = component.NewBaseComponent(message.AccountsSvc, actor, log.NewLogger("account"))
 
@@ -49,15 +50,14 @@ func NewAccountService(cfg *cfg.Config) *AccountService {
 }
 
 func (as *AccountService) BeforeStart() {
-	const dbName = "account"
+	const keystoreDir = "keystore"
 	const addressFile = "addresses"
 
-	//TODO: fix it store secure storage
-	dbPath := path.Join(as.cfg.DataDir, dbName)
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		_ = os.MkdirAll(dbPath, 0711)
+	// keys are kept in encrypted keystore files
+	as.keystoreDir = path.Join(as.cfg.DataDir, keystoreDir)
+	if _, err := os.Stat(as.keystoreDir); os.IsNotExist(err) {
+		_ = os.MkdirAll(as.keystoreDir, 0700)
 	}
-	as.storage = db.NewDB(db.BadgerImpl, dbPath)
 
 	addrPath := path.Join(as.cfg.DataDir, addressFile)
 	as.addrs = NewAddresses(as.Logger, addrPath)
@@ -66,10 +66,13 @@ func (as *AccountService) BeforeStart() {
 
 func (as *AccountService) BeforeStop() {
 	as.accounts = nil
-	as.unlocked = nil
-	if as.storage != nil {
-		as.storage.Close()
+	as.unlockLock.Lock()
+	for _, timer := range as.unlockTimers {
+		timer.Stop()
 	}
+	as.unlocked = nil
+	as.unlockTimers = nil
+	as.unlockLock.Unlock()
 	as.addrs = nil
 }
 
@@ -87,16 +90,19 @@ func (as *AccountService) Receive(context actor.Context) {
 		account, _ := as.createAccount(msg.Passphrase)
 		context.Respond(&message.CreateAccountRsp{Account: account})
 	case *message.LockAccount:
-		account, err := as.lockAccount(msg.Account.Address, msg.Passphrase)
+		account, err := as.lockAccount(msg.Account.Address)
 		context.Respond(&message.AccountRsp{Account: account, Err: err})
 	case *message.UnlockAccount:
-		account, err := as.unlockAccount(msg.Account.Address, msg.Passphrase)
+		account, err := as.unlockAccount(msg.Account.Address, msg.Passphrase, msg.Duration)
 		context.Respond(&message.AccountRsp{Account: account, Err: err})
 	case *message.SignTx:
 		err := as.signTx(context, msg.Tx)
 		if err != nil {
 			context.Respond(&message.SignTxRsp{Tx: nil, Err: err})
 		}
+	case *message.SignTxFrom:
+		tx, err := as.signTxFrom(msg.Tx, msg.From)
+		context.Respond(&message.SignTxRsp{Tx: tx, Err: err})
 	case *message.VerifyTx:
 		err := as.verifyTx(msg.Tx)
 		if err != nil {
@@ -131,16 +137,14 @@ func (as *AccountService) createAccount(passphrase string) (*types.Account, erro
 		return nil, err
 	}
 	//gen new address
-	address := generateAddress(&privkey.PublicKey)
+	address := types.AddressFromPubKey(&privkey.PublicKey)
 
-	//save pass/address/key
-	encryptkey := hashBytes(address, []byte(passphrase))
-	encrypted, err := encrypt(address, encryptkey, privkey.Serialize())
+	//save key encrypted by passphrase
+	err = SaveKeystore(as.keystorePath(address), privkey.Serialize(), passphrase)
 	if err != nil {
-		as.Error().Err(err).Msg("could not encrypt key")
+		as.Error().Err(err).Msg("could not save key")
 		return nil, err
 	}
-	as.storage.Set(hashBytes(address, encryptkey), encrypted)
 	account := types.NewAccount(address)
 
 	//append list
@@ -152,73 +156,80 @@ func (as *AccountService) createAccount(passphrase string) (*types.Account, erro
 	return account, nil
 }
 
-func generateAddress(pubkey *ecdsa.PublicKey) []byte {
-	addr := new(bytes.Buffer)
-	binary.Write(addr, binary.LittleEndian, pubkey.X.Bytes())
-	binary.Write(addr, binary.LittleEndian, pubkey.Y.Bytes())
-	return addr.Bytes()[:20] //TODO: ADDRESSLENGTH ?
+func (as *AccountService) keystorePath(address []byte) string {
+	return path.Join(as.keystoreDir, base58.Encode(address)+".json")
 }
 
 func (as *AccountService) getKey(address []byte, passphrase string) ([]byte, error) {
-	encryptkey := hashBytes(address, []byte(passphrase))
-	key := as.storage.Get(hashBytes(address, encryptkey))
-	if cap(key) == 0 {
+	key, err := LoadKeystore(as.keystorePath(address), passphrase)
+	if os.IsNotExist(err) || err == ErrWrongPassphrase {
 		return nil, message.ErrWrongAddressOrPassWord
 	}
-	return decrypt(address, encryptkey, key)
+	return key, err
 }
 
-func (as *AccountService) unlockAccount(address []byte, passphrase string) (*types.Account, error) {
+// unlockAccount keeps the key of account in memory to sign txs. The account is locked again after
+// duration, or kept unlocked until lockAccount is called if duration is 0.
+func (as *AccountService) unlockAccount(address []byte, passphrase string, duration time.Duration) (*types.Account, error) {
 
 	key, err := as.getKey(address, passphrase)
 	if key == nil {
 		as.Error().Err(err).Msg("could not find the key")
 		return nil, err
 	}
-	as.unlocked[EncodeB64(address)], _ = btcec.PrivKeyFromBytes(btcec.S256(), key)
+	b64addr := EncodeB64(address)
+	as.unlockLock.Lock()
+	defer as.unlockLock.Unlock()
+	as.unlocked[b64addr], _ = btcec.PrivKeyFromBytes(btcec.S256(), key)
+	if timer, exists := as.unlockTimers[b64addr]; exists {
+		timer.Stop()
+		delete(as.unlockTimers, b64addr)
+	}
+	if duration > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(duration, func() {
+			as.unlockLock.Lock()
+			defer as.unlockLock.Unlock()
+			// the account may be unlocked again with new timer
+			if as.unlockTimers[b64addr] == timer {
+				delete(as.unlockTimers, b64addr)
+				delete(as.unlocked, b64addr)
+			}
+		})
+		as.unlockTimers[b64addr] = timer
+	}
 	return &types.Account{Address: address}, nil
 }
 
-func (as *AccountService) lockAccount(address []byte, passphrase string) (*types.Account, error) {
-	key, err := as.getKey(address, passphrase)
-	if key == nil {
-		as.Error().Err(err).Msg("could not load the key")
-		return nil, err
-	}
+func (as *AccountService) lockAccount(address []byte) (*types.Account, error) {
 	//TODO: zeroing key
 	b64addr := EncodeB64(address)
-	//TODO: lock
-	as.unlocked[b64addr] = nil
+	as.unlockLock.Lock()
+	defer as.unlockLock.Unlock()
+	if timer, exists := as.unlockTimers[b64addr]; exists {
+		timer.Stop()
+		delete(as.unlockTimers, b64addr)
+	}
 	delete(as.unlocked, b64addr)
 
 	return &types.Account{Address: address}, nil
 }
 
-func hashBytes(b1 []byte, b2 []byte) []byte {
-	h := sha256.New()
-	h.Write(b1)
-	h.Write(b2)
-	return h.Sum(nil)
-}
-
-func hashWithoutSign(txBody *types.TxBody) []byte {
-	h := sha256.New()
-	binary.Write(h, binary.LittleEndian, txBody.Nonce)
-	h.Write(txBody.Account)
-	h.Write(txBody.Recipient)
-	binary.Write(h, binary.LittleEndian, txBody.Amount)
-	binary.Write(h, binary.LittleEndian, txBody.Limit)
-	binary.Write(h, binary.LittleEndian, txBody.Price)
-	h.Write(txBody.Payload)
-	binary.Write(h, binary.LittleEndian, txBody.Type)
-	return h.Sum(nil)
+func (as *AccountService) getUnlockedKey(address []byte) (*aergokey, bool) {
+	as.unlockLock.Lock()
+	defer as.unlockLock.Unlock()
+	key, exist := as.unlocked[EncodeB64(address)]
+	return key, exist
 }
 
 func (as *AccountService) signTx(c actor.Context, tx *types.Tx) error {
+	if tx == nil || tx.Body == nil {
+		return message.ErrTxFormatInvalid
+	}
 	//hash tx
 	txbody := tx.Body
 	//get key
-	key, exist := as.unlocked[EncodeB64(txbody.Account)]
+	key, exist := as.getUnlockedKey(txbody.Account)
 	if !exist {
 		return message.ErrShouldUnlockAccount
 	}
@@ -229,67 +240,46 @@ func (as *AccountService) signTx(c actor.Context, tx *types.Tx) error {
 	return nil
 }
 
-func (as *AccountService) verifyTx(tx *types.Tx) error {
-	txbody := tx.Body
-	hash := hashWithoutSign(txbody)
-
-	pubkey, _, err := btcec.RecoverCompact(btcec.S256(), txbody.Sign, hash)
-	if err != nil {
-		as.Error().Err(err).Msg("could not recover sign")
-		return err
-	}
-	address := generateAddress(pubkey.ToECDSA())
-	if !bytes.Equal(address, txbody.Account) {
-		return message.ErrSignNotMatch
+func (as *AccountService) hasAccount(address []byte) bool {
+	for _, account := range as.getAccounts() {
+		if bytes.Equal(account.Address, address) {
+			return true
+		}
 	}
-	return nil
+	return false
 }
 
-func encrypt(address, key, data []byte) ([]byte, error) {
-	// Load your secret key from a safe place and reuse it across multiple
-	// Seal/Open calls. (Obviously don't use this example key for anything
-	// real.) If you want to convert a passphrase to a key, use a suitable
-	// package like bcrypt or scrypt.
-	// When decoded the key should be 16 bytes (AES-128) or 32 (AES-256).
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
+// signTxFrom signs tx with the key of account from, which must be unlocked in this node.
+func (as *AccountService) signTxFrom(tx *types.Tx, from []byte) (*types.Tx, error) {
+	if tx == nil || tx.Body == nil {
+		return nil, message.ErrTxFormatInvalid
 	}
-
-	// Never use more than 2^32 random nonces with a given key because of the risk of a repeat.
-	nonce := address[:12]
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+	if !as.hasAccount(from) {
+		return nil, message.ErrUnknownAccount
 	}
-
-	cipherbytes := aesgcm.Seal(nil, nonce, data, nil)
-	return cipherbytes, nil
-}
-
-func decrypt(address, key, data []byte) ([]byte, error) {
-	// Load your secret key from a safe place and reuse it across multiple
-	// Seal/Open calls. (Obviously don't use this example key for anything
-	// real.) If you want to convert a passphrase to a key, use a suitable
-	// package like bcrypt or scrypt.
-	// When decoded the key should be 16 bytes (AES-128) or 32 (AES-256).
-	nonce := address[:12]
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
+	key, exist := as.getUnlockedKey(from)
+	if !exist {
+		return nil, message.ErrShouldUnlockAccount
 	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
+	if len(tx.Body.Account) == 0 {
+		tx.Body.Account = from
+	} else if !bytes.Equal(tx.Body.Account, from) {
+		return nil, message.ErrAccountNotMatch
+	}
+	if err := NewSigner(as.Logger, key).SignTx(tx); err != nil {
 		return nil, err
 	}
+	return tx, nil
+}
 
-	plainbytes, err := aesgcm.Open(nil, nonce, data, nil)
-
+func (as *AccountService) verifyTx(tx *types.Tx) error {
+	valid, err := tx.VerifySign()
 	if err != nil {
-		return nil, err
+		as.Error().Err(err).Msg("could not recover sign")
+		return err
 	}
-	return plainbytes, nil
+	if !valid {
+		return message.ErrSignNotMatch
+	}
+	return nil
 }
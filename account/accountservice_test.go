@@ -3,7 +3,9 @@ package account
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/aergoio/aergo/config"
 	"github.com/aergoio/aergo/message"
@@ -59,6 +61,11 @@ func TestNewAccountAndGet(t *testing.T) {
 	if len(resultlist) != len(testaccounts) {
 		t.Error("failed to get account")
 	}
+	for _, a := range testaccounts {
+		if _, err := os.Stat(as.keystorePath(a.Address)); err != nil {
+			t.Errorf("keystore of account is not saved:%s", err)
+		}
+	}
 }
 
 const AddressLength = 20
@@ -84,7 +91,7 @@ func TestNewAccountAndUnlockLock(t *testing.T) {
 	}
 	for i := 0; i < testsize; i++ {
 		passphrase := fmt.Sprintf("test%d", i)
-		account, err := as.unlockAccount(testaccounts[i].Address, passphrase)
+		account, err := as.unlockAccount(testaccounts[i].Address, passphrase, 0)
 		if err != nil || account == nil {
 			t.Errorf("failed to unlock account[%d]:%s", i, err)
 		}
@@ -93,8 +100,7 @@ func TestNewAccountAndUnlockLock(t *testing.T) {
 		t.Error("failed to unlock account")
 	}
 	for i := 0; i < testsize; i++ {
-		passphrase := fmt.Sprintf("test%d", i)
-		account, err := as.lockAccount(testaccounts[i].Address, passphrase)
+		account, err := as.lockAccount(testaccounts[i].Address)
 		if err != nil || account == nil {
 			t.Errorf("failed to lock account[%d]:%s", i, err)
 		}
@@ -125,7 +131,7 @@ func TestNewAccountAndUnlockFail(t *testing.T) {
 	}
 	for i := 0; i < testsize; i++ {
 		passphrase := fmt.Sprintf("test_Error%d", i)
-		account, err := as.unlockAccount(testaccounts[i].Address, passphrase)
+		account, err := as.unlockAccount(testaccounts[i].Address, passphrase, 0)
 		if err == nil || account != nil {
 			t.Errorf("should not unlock the account[%d]:%s", i, err)
 		}
@@ -137,6 +143,46 @@ func TestNewAccountAndUnlockFail(t *testing.T) {
 		t.Error("unlock account with wrong pass")
 	}
 }
+
+func TestUnlockUnknownAccount(t *testing.T) {
+	initTest()
+	defer deinitTest()
+	account, err := as.unlockAccount([]byte("01234567890123456789"), "test", 0)
+	if err != message.ErrWrongAddressOrPassWord || account != nil {
+		t.Errorf("should not unlock unknown account:%s", err)
+	}
+}
+
+func TestUnlockAccountDuration(t *testing.T) {
+	initTest()
+	defer deinitTest()
+	passphrase := "test"
+	account, err := as.createAccount(passphrase)
+	if err != nil {
+		t.Fatalf("failed to create account:%s", err)
+	}
+	_, err = as.unlockAccount(account.Address, passphrase, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to unlock account:%s", err)
+	}
+	if _, unlocked := as.getUnlockedKey(account.Address); !unlocked {
+		t.Fatal("account is not unlocked")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if _, unlocked := as.getUnlockedKey(account.Address); unlocked {
+		t.Error("account is not locked after duration")
+	}
+
+	// lock before duration stops auto lock
+	as.unlockAccount(account.Address, passphrase, 100*time.Millisecond)
+	as.lockAccount(account.Address)
+	if _, unlocked := as.getUnlockedKey(account.Address); unlocked {
+		t.Error("failed to lock account")
+	}
+	if len(as.unlockTimers) != 0 {
+		t.Error("timer remains after lock")
+	}
+}
 func TestNewAccountUnlockSignVerfiy(t *testing.T) {
 	initTest()
 	defer deinitTest()
@@ -145,13 +191,14 @@ func TestNewAccountUnlockSignVerfiy(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to create account:%s", err)
 	}
-	unlockedAccount, err := as.unlockAccount(account.Address, passphrase)
+	unlockedAccount, err := as.unlockAccount(account.Address, passphrase, 0)
 	if err != nil || unlockedAccount == nil {
 		t.Errorf("failed to unlock account:%s", err)
 		t.FailNow()
 	}
 	tx := types.Tx{Body: &types.TxBody{Account: account.Address}}
-	signer := NewSigner(as.Logger, as.unlocked[EncodeB64(account.Address)])
+	key, _ := as.getUnlockedKey(account.Address)
+	signer := NewSigner(as.Logger, key)
 	err = signer.SignTx(&tx)
 	if err != nil {
 		t.Fatalf("failed to sign: %s", err)
@@ -173,13 +220,14 @@ func TestVerfiyFail(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to create account:%s", err)
 	}
-	unlockedAccount, err := as.unlockAccount(account.Address, passphrase)
+	unlockedAccount, err := as.unlockAccount(account.Address, passphrase, 0)
 	if err != nil || unlockedAccount == nil {
 		t.Errorf("failed to unlock account:%s", err)
 		t.FailNow()
 	}
 	tx := types.Tx{Body: &types.TxBody{Account: account.Address}}
-	signer := NewSigner(as.Logger, as.unlocked[EncodeB64(account.Address)])
+	key, _ := as.getUnlockedKey(account.Address)
+	signer := NewSigner(as.Logger, key)
 	err = signer.SignTx(&tx)
 	if err != nil {
 		t.Fatalf("failed to sign: %s", err)
@@ -197,3 +245,73 @@ func TestVerfiyFail(t *testing.T) {
 		t.Fatal("should not success to verify")
 	}
 }
+
+func TestSignTxFrom(t *testing.T) {
+	initTest()
+	defer deinitTest()
+	passphrase := "test"
+	account, err := as.createAccount(passphrase)
+	if err != nil {
+		t.Fatalf("failed to create account:%s", err)
+	}
+	_, err = as.unlockAccount(account.Address, passphrase, 0)
+	if err != nil {
+		t.Fatalf("failed to unlock account:%s", err)
+	}
+	tx := &types.Tx{Body: &types.TxBody{Nonce: 1, Amount: 10}}
+	signed, err := as.signTxFrom(tx, account.Address)
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if signed.Body.Sign == nil || signed.Hash == nil {
+		t.Fatal("tx is not signed")
+	}
+	if err = as.verifyTx(signed); err != nil {
+		t.Errorf("failed to verify: %s", err)
+	}
+
+	other, _ := as.createAccount(passphrase)
+	tx = &types.Tx{Body: &types.TxBody{Account: other.Address}}
+	if _, err = as.signTxFrom(tx, account.Address); err != message.ErrAccountNotMatch {
+		t.Errorf("should return :%s", message.ErrAccountNotMatch)
+	}
+}
+
+func TestSignTxFromLocked(t *testing.T) {
+	initTest()
+	defer deinitTest()
+	passphrase := "test"
+	account, err := as.createAccount(passphrase)
+	if err != nil {
+		t.Fatalf("failed to create account:%s", err)
+	}
+	tx := &types.Tx{Body: &types.TxBody{Account: account.Address}}
+	if _, err = as.signTxFrom(tx, account.Address); err != message.ErrShouldUnlockAccount {
+		t.Errorf("should return :%s", message.ErrShouldUnlockAccount)
+	}
+	if tx.Body.Sign != nil {
+		t.Error("tx is signed by locked account")
+	}
+	if _, err = as.signTxFrom(tx, []byte("01234567890123456789")); err != message.ErrUnknownAccount {
+		t.Errorf("should return :%s", message.ErrUnknownAccount)
+	}
+}
+
+func TestSignTxFromMalformed(t *testing.T) {
+	initTest()
+	defer deinitTest()
+	passphrase := "test"
+	account, err := as.createAccount(passphrase)
+	if err != nil {
+		t.Fatalf("failed to create account:%s", err)
+	}
+	_, err = as.unlockAccount(account.Address, passphrase, 0)
+	if err != nil {
+		t.Fatalf("failed to unlock account:%s", err)
+	}
+	for _, tx := range []*types.Tx{nil, {}} {
+		if _, err = as.signTxFrom(tx, account.Address); err != message.ErrTxFormatInvalid {
+			t.Errorf("should return :%s", message.ErrTxFormatInvalid)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package account
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// KeystorePassphraseEnv is the environment variable to give the passphrase of keystore
+// without prompting.
+const KeystorePassphraseEnv = "AERGO_KEYSTORE_PASSPHRASE"
+
+const (
+	keystoreVersion = 1
+	keystoreKDF     = "scrypt"
+	keystoreCipher  = "aes-256-gcm"
+
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 32
+)
+
+var (
+	// ErrWrongPassphrase is returned when keystore cannot be decrypted by the passphrase
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	// ErrInvalidKeystore is returned when keystore is malformed or of unsupported format
+	ErrInvalidKeystore = errors.New("invalid keystore")
+)
+
+// keystore is the json format of an encrypted key. KDF parameters are kept in the file, so that
+// they can be changed without breaking old files.
+type keystore struct {
+	Version    int          `json:"version"`
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+}
+
+type scryptParams struct {
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	KeyLen int    `json:"keylen"`
+	Salt   string `json:"salt"`
+}
+
+// EncryptKey encrypts a private key by passphrase and returns it in keystore json format.
+func EncryptKey(key []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	params := scryptParams{N: scryptN, R: scryptR, P: scryptP, KeyLen: scryptKeyLen, Salt: hex.EncodeToString(salt)}
+	aesgcm, err := newKeystoreCipher(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aesgcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	ks := keystore{
+		Version:    keystoreVersion,
+		Cipher:     keystoreCipher,
+		CipherText: hex.EncodeToString(aesgcm.Seal(nil, nonce, key, nil)),
+		Nonce:      hex.EncodeToString(nonce),
+		KDF:        keystoreKDF,
+		KDFParams:  params,
+	}
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// DecryptKey decrypts the private key in keystore json format by passphrase.
+func DecryptKey(keystoreJSON []byte, passphrase string) ([]byte, error) {
+	var ks keystore
+	if err := json.Unmarshal(keystoreJSON, &ks); err != nil {
+		return nil, ErrInvalidKeystore
+	}
+	if ks.Version != keystoreVersion || ks.Cipher != keystoreCipher || ks.KDF != keystoreKDF {
+		return nil, ErrInvalidKeystore
+	}
+	cipherText, err := hex.DecodeString(ks.CipherText)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+	aesgcm, err := newKeystoreCipher(passphrase, ks.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aesgcm.NonceSize() {
+		return nil, ErrInvalidKeystore
+	}
+	key, err := aesgcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		// gcm authentication fails if the key derived from passphrase is different
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+func newKeystoreCipher(passphrase string, params scryptParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SaveKeystore encrypts a private key by passphrase and writes it to the file of path.
+func SaveKeystore(path string, key []byte, passphrase string) error {
+	data, err := EncryptKey(key, passphrase)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// LoadKeystore reads the keystore file of path and decrypts the private key in it.
+func LoadKeystore(path string, passphrase string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, passphrase)
+}
+
+// CreateKeystore generates a new secp256k1 private key, and saves it to the keystore file of path.
+func CreateKeystore(path string, passphrase string) (*btcec.PrivateKey, error) {
+	privkey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	if err = SaveKeystore(path, privkey.Serialize(), passphrase); err != nil {
+		return nil, err
+	}
+	return privkey, nil
+}
+
+// ReadPassphrase gets the passphrase of keystore from the environment variable, or prompts
+// for it if stdin is a terminal.
+func ReadPassphrase(prompt string) (string, error) {
+	if passphrase, exists := os.LookupEnv(KeystorePassphraseEnv); exists {
+		return passphrase, nil
+	}
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return "", fmt.Errorf("no passphrase: set %s or run in terminal", KeystorePassphraseEnv)
+	}
+	fmt.Print(prompt)
+	passphrase, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(passphrase), nil
+}
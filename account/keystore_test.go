@@ -0,0 +1,71 @@
+package account
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestKeystoreEncryptDecrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := EncryptKey(key, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to encrypt key:%s", err)
+	}
+	if bytes.Contains(encrypted, key) {
+		t.Error("keystore contains plain key")
+	}
+	decrypted, err := DecryptKey(encrypted, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to decrypt key:%s", err)
+	}
+	if !bytes.Equal(key, decrypted) {
+		t.Errorf("decrypted key is different:%x", decrypted)
+	}
+
+	// same key is encrypted differently each time by random salt and nonce
+	another, _ := EncryptKey(key, "passphrase")
+	if bytes.Equal(encrypted, another) {
+		t.Error("keystore is not randomized")
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	encrypted, err := EncryptKey([]byte("key"), "passphrase")
+	if err != nil {
+		t.Fatalf("failed to encrypt key:%s", err)
+	}
+	decrypted, err := DecryptKey(encrypted, "wrong")
+	if err != ErrWrongPassphrase {
+		t.Errorf("wrong passphrase must be rejected:%v", err)
+	}
+	if decrypted != nil {
+		t.Error("key is returned by wrong passphrase")
+	}
+	if _, err = DecryptKey([]byte("{}"), "passphrase"); err != ErrInvalidKeystore {
+		t.Errorf("invalid keystore must be rejected:%v", err)
+	}
+}
+
+func TestCreateAndLoadKeystore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "keystore")
+	defer os.RemoveAll(dir)
+	ksPath := path.Join(dir, "key.json")
+
+	privkey, err := CreateKeystore(ksPath, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to create keystore:%s", err)
+	}
+	loaded, err := LoadKeystore(ksPath, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to load keystore:%s", err)
+	}
+	if !bytes.Equal(privkey.Serialize(), loaded) {
+		t.Error("loaded key is different")
+	}
+	if _, err = LoadKeystore(ksPath, "wrong"); err != ErrWrongPassphrase {
+		t.Errorf("wrong passphrase must be rejected:%v", err)
+	}
+}
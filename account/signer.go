@@ -5,7 +5,6 @@ import (
 	"github.com/aergoio/aergo-lib/log"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
-	"github.com/btcsuite/btcd/btcec"
 )
 
 //Signer is submodule of account for signing the transaction
@@ -39,17 +38,9 @@ func (s *Signer) Receive(context actor.Context) {
 
 //SignTx sign transaction with key
 func (s *Signer) SignTx(tx *types.Tx) error {
-	//hash tx
-	txbody := tx.Body
-	hash := hashWithoutSign(txbody)
-	//sign tx
-	sign, err := btcec.SignCompact(btcec.S256(), s.key, hash, true)
-	if err != nil {
+	if err := tx.Sign(s.key); err != nil {
 		s.log.Warn().Err(err).Msg("could not sign")
 		return err
 	}
-	txbody.Sign = sign
-	//txbody.Sign = sign
-	tx.Hash = tx.CalculateTxHash()
 	return nil
 }
@@ -27,6 +27,8 @@ var (
 	ErrNoChainDB = fmt.Errorf("chaindb not prepared")
 
 	latestKey = []byte(chainDBName + ".latest")
+	// bloomPrefix is the prefix of the keys of the bloom filters of events, followed by block hash.
+	bloomPrefix = []byte(chainDBName + ".bloom.")
 )
 
 // ErrNoBlock reports there is no such a block with id (hash or block number).
@@ -153,9 +155,11 @@ func (cdb *ChainDB) loadData(key []byte, pb proto.Message) error {
 	//logger.Debug("  loaded: ", ToJSON(pb))
 	return nil
 }
-func (cdb *ChainDB) generateGenesisBlock(seed int64) (*types.Block, error) {
-	genesisBlock := types.NewBlock(nil, nil, 0)
-	genesisBlock.Header.Timestamp = seed
+func (cdb *ChainDB) generateGenesisBlock(genesis *types.Genesis) (*types.Block, error) {
+	genesisBlock, err := genesis.Block()
+	if err != nil {
+		return nil, err
+	}
 	tx := cdb.store.NewTx(true)
 	if err := cdb.addBlock(&tx, genesisBlock, true); err != nil {
 		return nil, err
@@ -215,6 +219,23 @@ func (cdb *ChainDB) deleteTx(dbtx *db.Transaction, tx *types.Tx) {
 	(*dbtx).Delete(tx.Hash)
 }
 
+func bloomKey(blockHash []byte) []byte {
+	return append(append([]byte{}, bloomPrefix...), blockHash...)
+}
+
+// addBloom stores the bloom filter of the events emitted in block. The filter of block without events is not stored.
+func (cdb *ChainDB) addBloom(dbtx *db.Transaction, blockHash []byte, bloom *types.LogsBloom) {
+	if bloom.IsEmpty() {
+		return
+	}
+	(*dbtx).Set(bloomKey(blockHash), bloom.Bytes())
+}
+
+// getBloom returns the bloom filter of the events emitted in block, or nil if the block has no event.
+func (cdb *ChainDB) getBloom(blockHash []byte) *types.LogsBloom {
+	return types.LogsBloomFromBytes(cdb.store.Get(bloomKey(blockHash)))
+}
+
 // store block info to DB
 func (cdb *ChainDB) addBlock(dbtx *db.Transaction, block *types.Block, isMainChain bool) error {
 	blockNo := block.GetHeader().GetBlockNo()
@@ -9,9 +9,11 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"fmt"
+	"math"
 	"strconv"
 
 	"github.com/aergoio/aergo-lib/db"
+	"github.com/aergoio/aergo/consensus"
 	"github.com/aergoio/aergo/contract"
 	"github.com/aergoio/aergo/internal/enc"
 	"github.com/aergoio/aergo/message"
@@ -43,10 +45,74 @@ func (cs *ChainService) getHashByNo(blockNo types.BlockNo) ([]byte, error) {
 	return cs.cdb.getHashByNo(blockNo)
 }
 
+// getAccountState returns the state of account as of the block, which is confirmations deep
+// from the best block.
+func (cs *ChainService) getAccountState(account []byte, confirmations uint64) (*types.State, error) {
+	id := types.ToAccountID(account)
+	if confirmations == 0 {
+		return cs.sdb.GetAccountStateClone(id)
+	}
+	bestNo := cs.getBestBlockNo()
+	if confirmations > bestNo {
+		return nil, fmt.Errorf("not enough blocks for confirmations: best=%d, confirmations=%d",
+			bestNo, confirmations)
+	}
+	return cs.sdb.GetAccountStateAt(id, bestNo-confirmations)
+}
+
 func (cs *ChainService) getTx(txHash []byte) (*types.Tx, *types.TxIdx, error) {
 	return cs.cdb.getTx(txHash)
 }
 
+// maxLogsBlockRange is the maximum number of blocks which a query of events scans.
+const maxLogsBlockRange = 10000
+
+// getLogs returns the events selected by filter in the main chain. The blocks whose bloom filter doesn't match
+// are skipped without reading their txs. Blockto 0 means the best block.
+func (cs *ChainService) getLogs(filter *types.FilterInfo) ([]*types.Event, error) {
+	if len(filter.GetContractAddress()) == 0 {
+		return nil, fmt.Errorf("contract address is required to get logs")
+	}
+	from, to := filter.GetBlockfrom(), filter.GetBlockto()
+	if best := cs.getBestBlockNo(); to == 0 || to > best {
+		to = best
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: from=%d, to=%d", from, to)
+	}
+	if to-from >= maxLogsBlockRange {
+		return nil, fmt.Errorf("too many blocks to get logs: from=%d, to=%d, max=%d", from, to, maxLogsBlockRange)
+	}
+
+	events := []*types.Event{}
+	for blockNo := from; blockNo <= to; blockNo++ {
+		blockHash, err := cs.getHashByNo(blockNo)
+		if err != nil {
+			return nil, err
+		}
+		if bloom := cs.cdb.getBloom(blockHash); bloom == nil || !bloom.MayMatch(filter) {
+			continue
+		}
+		block, err := cs.getBlock(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		for idx, tx := range block.GetBody().GetTxs() {
+			for _, event := range contract.GetEvents(tx.GetHash()) {
+				if !filter.Matches(event) {
+					continue
+				}
+				event.TxHash = tx.GetHash()
+				event.TxIndex = int32(idx)
+				event.BlockHash = blockHash
+				event.BlockNo = blockNo
+				events = append(events, event)
+			}
+		}
+	}
+	return events, nil
+}
+
 func (cs *ChainService) addBlock(nblock *types.Block, peerID peer.ID) error {
 	logger.Debug().Str("hash", nblock.ID()).Msg("add block")
 
@@ -73,14 +139,22 @@ func (cs *ChainService) addBlock(nblock *types.Block, peerID peer.ID) error {
 	var lastBlock *types.Block
 
 	for tblock != nil {
-		dbtx := cs.cdb.store.NewTx(true)
+		if err := cs.checkBlockOrder(tblock); err != nil {
+			return err
+		}
 
 		var isMainChain bool
 		var err error
 		if isMainChain, err = cs.cdb.isMainChain(tblock); err != nil {
 			return err
 		}
+		if isMainChain {
+			if err := cs.catchUpState(tblock); err != nil {
+				return err
+			}
+		}
 
+		dbtx := cs.cdb.store.NewTx(true)
 		if isMainChain {
 			if err := cs.processTxsAndState(&dbtx, tblock); err != nil {
 				return err
@@ -146,29 +220,226 @@ func (cs *ChainService) processTxsAndState(dbtx *db.Transaction, block *types.Bl
 
 	logger.Debug().Uint64("blockNo", block.GetHeader().GetBlockNo()).Str("hash", block.ID()).Msg("process txs and update state")
 
-	for i, tx := range txs {
-		err := cs.processTx(dbtx, bstate, tx, block, i)
-		if err != nil {
-			logger.Error().Err(err).Str("hash", block.ID()).Int("txidx", i).Msg("failed to process tx")
-			return err
+	// the contract writes and receipts of the txs are kept apart until the block is validated by its state root
+	bloom := types.NewLogsBloom()
+	commitContracts, err := contract.Stage(func() error {
+		for i, tx := range txs {
+			if err := cs.processTx(dbtx, bstate, tx, block, i); err != nil {
+				logger.Error().Err(err).Str("hash", block.ID()).Int("txidx", i).Msg("failed to process tx")
+				return err
+			}
+			if tx.GetBody().GetPayload() != nil {
+				for _, event := range contract.GetEvents(tx.GetHash()) {
+					bloom.AddEvent(event)
+				}
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	err := cs.sdb.Apply(bstate)
+	cs.cdb.addBloom(dbtx, block.BlockHash(), bloom)
+	root, err := cs.sdb.ComputeRoot(bstate)
+	if err != nil {
+		return err
+	}
+	if err := checkStateRoot(block, root); err != nil {
+		logger.Error().Err(err).Str("hash", block.ID()).Msg("invalid state root")
+		return err
+	}
+	err = cs.sdb.Apply(bstate)
 	if err != nil {
 		// FIXME: is that enough?
 		logger.Error().Err(err).Str("hash", block.ID()).Msg("failed to apply state")
 		return err
 	}
+	commitContracts()
+
+	return nil
+}
+
+// catchUpState checks that block of the main chain can be applied next to the latest state, before its txs are
+// executed. If the state is behind the chain, such as when the buffered states are lost, it catches up with the
+// chain first. The block which is not on the latest state is rejected.
+func (cs *ChainService) catchUpState(block *types.Block) error {
+	blockNo := block.GetHeader().GetBlockNo()
+	prevHash := types.ToBlockID(block.GetHeader().GetPrevBlockHash())
+	err := cs.sdb.CheckNext(blockNo, prevHash)
+	if _, behind := err.(*state.ErrBlockGap); behind {
+		logger.Warn().Err(err).Str("hash", block.ID()).Msg("state is behind chain, recovering it")
+		if err = cs.recoverState(); err != nil {
+			return err
+		}
+		err = cs.sdb.CheckNext(blockNo, prevHash)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("hash", block.ID()).Msg("block is not on the latest state")
+	}
+	return err
+}
+
+// computeStateRoot returns the state root which would be after the txs of block are applied. The contract calls
+// are executed to charge the fees of the gas used by them. Neither state nor any other db is changed.
+func (cs *ChainService) computeStateRoot(block *types.Block) ([]byte, error) {
+	latest, err := cs.getBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(latest.BlockHash(), block.GetHeader().GetPrevBlockHash()) {
+		return nil, fmt.Errorf("state root can be computed only on best block: best=%v, prev=%v",
+			latest.ID(), block.PrevID())
+	}
+	blockHash := types.ToBlockID(block.BlockHash())
+	prevHash := types.ToBlockID(block.GetHeader().GetPrevBlockHash())
+	bstate := state.NewBlockState(block.Header.BlockNo, blockHash, prevHash)
+	err = contract.DryRun(func() error {
+		for _, tx := range block.GetBody().GetTxs() {
+			if err := cs.executeTx(bstate, tx, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cs.sdb.ComputeRoot(bstate)
+}
+
+// recoverState re-applies the account states of the blocks in chain db which the state db doesn't have. They are
+// lost if the node stops before the buffered states are written. Contract states are written on executing txs, so
+// contracts are not executed again, and their fees are charged by the gas used in the receipts.
+func (cs *ChainService) recoverState() error {
+	from := cs.sdb.GetLatestBlockNo() + 1
+	best := cs.getBestBlockNo()
+	if from > best {
+		return nil
+	}
+	for blockNo := from; blockNo <= best; blockNo++ {
+		block, err := cs.getBlockByNo(blockNo)
+		if err != nil {
+			return err
+		}
+		blockHash := types.ToBlockID(block.BlockHash())
+		prevHash := types.ToBlockID(block.GetHeader().GetPrevBlockHash())
+		bstate := state.NewBlockState(blockNo, blockHash, prevHash)
+		for _, tx := range block.GetBody().GetTxs() {
+			_, createContract, err := cs.applyTxState(bstate, tx)
+			if err != nil {
+				return err
+			}
+			if tx.GetBody().GetPayload() == nil || createContract {
+				continue
+			}
+			receipt := contract.GetReceipt(tx.GetHash())
+			if receipt == nil {
+				return fmt.Errorf("receipt of tx %s is not found", enc.ToString(tx.GetHash()))
+			}
+			if err := cs.chargeFee(bstate, tx, receipt.GasUsed); err != nil {
+				return err
+			}
+		}
+		root, err := cs.sdb.ComputeRoot(bstate)
+		if err != nil {
+			return err
+		}
+		if err := checkStateRoot(block, root); err != nil {
+			return err
+		}
+		if err := cs.sdb.Apply(bstate); err != nil {
+			return err
+		}
+	}
+	logger.Info().Uint64("from", from).Uint64("to", best).Msg("state recovered")
+	return cs.sdb.Flush()
+}
 
+// checkStateRoot checks that the state root declared in block header is same as the computed one.
+func checkStateRoot(block *types.Block, root []byte) error {
+	if !bytes.Equal(block.GetHeader().GetStateRootHash(), root) {
+		return fmt.Errorf("state root mismatch: block=%v, computed=%v",
+			enc.ToString(block.GetHeader().GetStateRootHash()), enc.ToString(root))
+	}
 	return nil
 }
 
 func (cs *ChainService) processTx(dbtx *db.Transaction, bs *state.BlockState, tx *types.Tx, block *types.Block, idx int) error {
+	if err := cs.executeTx(bs, tx, block); err != nil {
+		return err
+	}
+	return cs.cdb.addTx(dbtx, tx, block.BlockHash(), idx)
+}
+
+// executeTx puts the account changes made by tx into block state by the tx executor of chain.
+func (cs *ChainService) executeTx(bs *state.BlockState, tx *types.Tx, block *types.Block) error {
+	_, err := cs.executor.Execute(tx, bs, block)
+	return err
+}
+
+// chargeFee deducts the fee of gasUsed at the gas price of tx from the balance of sender. The tx whose sender
+// can't afford it is rejected.
+func (cs *ChainService) chargeFee(bs *state.BlockState, tx *types.Tx, gasUsed uint64) error {
+	fee := gasFee(gasUsed, tx.GetBody().GetPrice())
+	if fee == 0 {
+		return nil
+	}
+	senderID := types.ToAccountID(tx.GetBody().GetAccount())
+	senderState, err := cs.sdb.GetBlockAccountClone(bs, senderID)
+	if err != nil {
+		return err
+	}
+	if senderState.Balance < fee {
+		return ErrInsufficientFee
+	}
+	senderChange := types.Clone(*senderState).(types.State)
+	senderChange.Balance = senderState.Balance - fee
+	bs.PutAccount(senderID, senderState, &senderChange)
+	return nil
+}
+
+// checkMaxFee checks that the sender of tx can afford the fee of its gas limit, so that the fee is paid whatever
+// the contract call does.
+func (cs *ChainService) checkMaxFee(bs *state.BlockState, tx *types.Tx) error {
+	senderState, err := cs.sdb.GetBlockAccountClone(bs, types.ToAccountID(tx.GetBody().GetAccount()))
+	if err != nil {
+		return err
+	}
+	if senderState.Balance < MaxFee(tx) {
+		return ErrInsufficientFee
+	}
+	return nil
+}
+
+// MaxFee returns the fee of the gas limit of tx at its gas price, which is charged at most for its contract call.
+func MaxFee(tx *types.Tx) uint64 {
+	body := tx.GetBody()
+	if body.GetPayload() == nil || len(body.GetRecipient()) == 0 {
+		return 0
+	}
+	return gasFee(contract.GasLimit(body.GetLimit()), body.GetPrice())
+}
+
+// gasFee returns the fee of gas at price, which is math.MaxUint64 on overflow.
+func gasFee(gas, price uint64) uint64 {
+	if gas == 0 || price == 0 {
+		return 0
+	}
+	fee := gas * price
+	if fee/price != gas {
+		return math.MaxUint64
+	}
+	return fee
+}
+
+// applyTxState puts the account changes made by tx into block state. It returns recipient address,
+// and whether a contract is created by the tx.
+func (cs *ChainService) applyTxState(bs *state.BlockState, tx *types.Tx) ([]byte, bool, error) {
 	txBody := tx.GetBody()
 	senderID := types.ToAccountID(txBody.Account)
 	senderState, err := cs.sdb.GetBlockAccountClone(bs, senderID)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 	recipient := txBody.Recipient
 	var receiverID types.AccountID
@@ -185,7 +456,7 @@ func (cs *ChainService) processTx(dbtx *db.Transaction, bs *state.BlockState, tx
 	}
 	receiverState, err := cs.sdb.GetBlockAccountClone(bs, receiverID)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 
 	senderChange := types.Clone(*senderState).(types.State)
@@ -199,37 +470,6 @@ func (cs *ChainService) processTx(dbtx *db.Transaction, bs *state.BlockState, tx
 		receiverChange.Balance = receiverChange.Balance + txBody.Amount
 		bs.PutAccount(receiverID, receiverState, &receiverChange)
 	}
-	if txBody.Payload != nil {
-		if createContract {
-			err = contract.Create(txBody.Payload, recipient, tx.Hash)
-		} else {
-			bcCtx := contract.NewContext(txBody.GetAccount(), block.BlockHash(), tx.GetHash(),
-				block.GetHeader().GetBlockNo(), block.GetHeader().GetTimestamp(), "", false, recipient)
-
-			err = contract.Call(txBody.Payload, recipient, tx.Hash, bcCtx)
-		}
-		if err != nil {
-			return err
-		}
-
-		/*
-			// open state for contract
-			senderContract, err := cs.sdb.OpenContractState(&senderChange)
-			if err != nil {
-				return err
-			}
-
-			// set contract code
-			senderContract.SetCode(txBody.Payload)
-
-			// execute contract and set data as key-value pair
-			// - ex: err := senderContract.SetData([]byte("key"), []byte("value"))
-			// - ex: val, err := senderContract.GetData([]byte("key"))
-
-			// commit state for contract
-			err = cs.sdb.CommitContractState(senderContract)
-		*/
-	}
 	senderChange.Nonce = txBody.Nonce
 	bs.PutAccount(senderID, senderState, &senderChange)
 
@@ -237,8 +477,7 @@ func (cs *ChainService) processTx(dbtx *db.Transaction, bs *state.BlockState, tx
 	// 	txBody.Amount, senderID, senderState.ToString(),
 	// 	receiverID, receiverState.ToString())
 
-	err = cs.cdb.addTx(dbtx, tx, block.BlockHash(), idx)
-	return err
+	return recipient, createContract, nil
 }
 
 // find an orphan block which is the child of the added block
@@ -267,6 +506,19 @@ func (cs *ChainService) connectOrphan(block *types.Block) (*types.Block, error)
 	return orphanBlock, nil
 }
 
+// checkBlockOrder validates block against its parent, if the consensus does.
+func (cs *ChainService) checkBlockOrder(block *types.Block) error {
+	validator, ok := cs.ChainConsensus.(consensus.BlockOrderValidator)
+	if !ok {
+		return nil
+	}
+	parent, err := cs.getBlock(block.GetHeader().GetPrevBlockHash())
+	if err != nil {
+		return err
+	}
+	return validator.IsBlockOrderValid(block, parent)
+}
+
 func (cs *ChainService) isOrphan(block *types.Block) bool {
 	prevhash := block.Header.PrevBlockHash
 	_, err := cs.getBlock(prevhash)
@@ -0,0 +1,249 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+
+	"github.com/aergoio/aergo/contract"
+	"github.com/aergoio/aergo/state"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testEventContract = `
+function transfer(to, amount)
+	system.event("transfer", to, amount)
+end
+
+function approve(to)
+	system.event("approve", to)
+end
+
+abi = {}
+function abi.call(name, ...)
+	return _G[name](...)
+end
+`
+
+// connectTestBlock executes txs in a new block on top of the best block, and adds it to the main chain.
+func connectTestBlock(t *testing.T, cs *ChainService, txs ...*types.Tx) *types.Block {
+	best, err := cs.getBestBlock()
+	assert.Nil(t, err)
+	block := types.NewBlock(best, txs, best.GetHeader().GetTimestamp()+1)
+	root, err := cs.computeStateRoot(block)
+	assert.Nil(t, err)
+	block.SetStateRoot(root)
+
+	dbtx := cs.cdb.store.NewTx(true)
+	assert.Nil(t, cs.processTxsAndState(&dbtx, block))
+	assert.Nil(t, cs.cdb.addBlock(&dbtx, block, true))
+	dbtx.Commit()
+	return block
+}
+
+func TestGetLogs(t *testing.T) {
+	cs, closeChain := NewTestChain(t, nil)
+	defer closeChain()
+
+	sender := []byte("alice")
+	nonce := uint64(0)
+	newTx := func(recipient []byte, payload string) *types.Tx {
+		nonce++
+		tx := &types.Tx{Body: &types.TxBody{Account: sender, Nonce: nonce, Recipient: recipient, Payload: []byte(payload)}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	deploy := func() []byte {
+		tx := newTx(nil, testEventContract)
+		connectTestBlock(t, cs, tx)
+		h := sha256.New()
+		h.Write(sender)
+		h.Write([]byte(strconv.FormatUint(tx.Body.Nonce, 10)))
+		return h.Sum(nil)[:20]
+	}
+	token, other := deploy(), deploy()
+
+	// block 3 has events of both contracts, block 4 has none, block 5 has only approve of token
+	transfer := newTx(token, `{"Name":"transfer","Args":["bob","10"]}`)
+	block3 := connectTestBlock(t, cs, newTx(other, `{"Name":"transfer","Args":["carol","1"]}`), transfer)
+	connectTestBlock(t, cs, newTx(nil, testEventContract))
+	approve := newTx(token, `{"Name":"approve","Args":["bob"]}`)
+	block5 := connectTestBlock(t, cs, approve)
+
+	assert.Nil(t, cs.cdb.getBloom(block3.GetHeader().GetPrevBlockHash()), "block without events has no bloom")
+	for _, blockNo := range []types.BlockNo{3, 5} {
+		blockHash, err := cs.getHashByNo(blockNo)
+		assert.Nil(t, err)
+		assert.True(t, cs.cdb.getBloom(blockHash).MayMatch(&types.FilterInfo{ContractAddress: token}))
+	}
+
+	events, err := cs.getLogs(&types.FilterInfo{ContractAddress: token})
+	assert.Nil(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "transfer", events[0].EventName)
+		assert.Equal(t, `["bob","10"]`, events[0].JsonArgs)
+		assert.Equal(t, token, events[0].ContractAddress)
+		assert.Equal(t, transfer.Hash, events[0].TxHash)
+		assert.Equal(t, int32(1), events[0].TxIndex)
+		assert.Equal(t, block3.BlockHash(), events[0].BlockHash)
+		assert.Equal(t, types.BlockNo(3), events[0].BlockNo)
+		assert.Equal(t, "approve", events[1].EventName)
+		assert.Equal(t, approve.Hash, events[1].TxHash)
+		assert.Equal(t, block5.BlockHash(), events[1].BlockHash)
+	}
+
+	events, err = cs.getLogs(&types.FilterInfo{ContractAddress: token, EventName: "transfer", Blockfrom: 4})
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+
+	events, err = cs.getLogs(&types.FilterInfo{ContractAddress: other, EventName: "transfer", Blockto: 3})
+	assert.Nil(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, `["carol","1"]`, events[0].JsonArgs)
+		assert.Equal(t, int32(0), events[0].TxIndex)
+	}
+
+	_, err = cs.getLogs(&types.FilterInfo{})
+	assert.NotNil(t, err)
+	_, err = cs.getLogs(&types.FilterInfo{ContractAddress: token, Blockfrom: 4, Blockto: 3})
+	assert.NotNil(t, err)
+}
+
+const testFeeContract = `
+function sum(n)
+	local s = 0
+	for i = 1, tonumber(n) do
+		s = s + i
+	end
+	return s
+end
+
+function loop()
+	while true do
+	end
+end
+
+abi = {}
+function abi.call(name, ...)
+	return _G[name](...)
+end
+`
+
+func TestContractFee(t *testing.T) {
+	sender := []byte("alice")
+	const balance = uint64(1000000000)
+	genesis := types.NewTestGenesis(types.TestGenesisOptions{Balances: map[string]uint64{string(sender): balance}})
+	cs, closeChain := NewTestChain(t, genesis)
+	defer closeChain()
+
+	nonce := uint64(0)
+	newTx := func(recipient []byte, payload string, limit, price uint64) *types.Tx {
+		nonce++
+		tx := &types.Tx{Body: &types.TxBody{Account: sender, Nonce: nonce, Recipient: recipient,
+			Payload: []byte(payload), Limit: limit, Price: price}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	getBalance := func() uint64 {
+		st, err := cs.sdb.GetAccountStateClone(types.ToAccountID(sender))
+		assert.Nil(t, err)
+		return st.Balance
+	}
+
+	// deploying is not charged
+	deploy := newTx(nil, testFeeContract, 0, 1)
+	connectTestBlock(t, cs, deploy)
+	assert.Equal(t, balance, getBalance())
+	h := sha256.New()
+	h.Write(sender)
+	h.Write([]byte(strconv.FormatUint(deploy.Body.Nonce, 10)))
+	address := h.Sum(nil)[:20]
+
+	// the state root computed before executing the block must count the fees
+	sum := newTx(address, `{"Name":"sum","Args":["1000"]}`, 0, 3)
+	loop := newTx(address, `{"Name":"loop","Args":[]}`, 500, 2)
+	connectTestBlock(t, cs, sum, loop)
+
+	sumReceipt := contract.GetReceipt(sum.Hash)
+	assert.Equal(t, types.ReceiptSuccess, sumReceipt.Status)
+	assert.True(t, sumReceipt.GasUsed > 0)
+	loopReceipt := contract.GetReceipt(loop.Hash)
+	assert.Equal(t, types.ReceiptOutOfGas, loopReceipt.Status)
+	assert.Equal(t, uint64(500), loopReceipt.GasUsed)
+	assert.Equal(t, balance-sumReceipt.GasUsed*3-500*2, getBalance())
+
+	// the tx whose sender can't afford the fee of its gas limit is rejected without executing the call
+	remaining := getBalance()
+	best, err := cs.getBestBlock()
+	assert.Nil(t, err)
+	unaffordable := newTx(address, `{"Name":"sum","Args":["10"]}`, 10, remaining/10+1)
+	_, err = cs.computeStateRoot(types.NewBlock(best, []*types.Tx{unaffordable}, best.GetHeader().GetTimestamp()+1))
+	assert.Equal(t, ErrInsufficientFee, err)
+	assert.Nil(t, contract.GetReceipt(unaffordable.Hash))
+	assert.Equal(t, remaining, getBalance())
+}
+
+func TestContractCallFailure(t *testing.T) {
+	cs, closeChain := NewTestChain(t, nil)
+	defer closeChain()
+
+	sender := []byte("alice")
+	nonce := uint64(0)
+	newTx := func(recipient []byte, payload string) *types.Tx {
+		nonce++
+		tx := &types.Tx{Body: &types.TxBody{Account: sender, Nonce: nonce, Recipient: recipient, Payload: []byte(payload)}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	deploy := newTx(nil, testFeeContract)
+	connectTestBlock(t, cs, deploy)
+	h := sha256.New()
+	h.Write(sender)
+	h.Write([]byte(strconv.FormatUint(deploy.Body.Nonce, 10)))
+	address := h.Sum(nil)[:20]
+
+	// the calls which can't be executed are still valid txs, so the block is connected
+	missing := newTx([]byte("98765432109876543210"), `{"Name":"sum","Args":["1"]}`)
+	invalidABI := newTx(address, `{"Name":"sum","Args":"1"}`)
+	block := connectTestBlock(t, cs, missing, invalidABI)
+	best, err := cs.getBestBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, block.BlockHash(), best.BlockHash())
+
+	for _, tx := range []*types.Tx{missing, invalidABI} {
+		receipt := contract.GetReceipt(tx.Hash)
+		if assert.NotNil(t, receipt) {
+			assert.Equal(t, types.ReceiptError, receipt.Status)
+		}
+	}
+	st, err := cs.sdb.GetAccountStateClone(types.ToAccountID(sender))
+	assert.Nil(t, err)
+	assert.Equal(t, invalidABI.Body.Nonce, st.Nonce)
+}
+
+func TestCatchUpState(t *testing.T) {
+	cs, closeChain := NewTestChain(t, nil)
+	defer closeChain()
+
+	connectTestBlock(t, cs)
+	block2 := connectTestBlock(t, cs)
+	block3 := connectTestBlock(t, cs)
+	next := types.NewBlock(block3, nil, block3.GetHeader().GetTimestamp()+1)
+
+	// the state left behind the chain catches up with it before the next block
+	assert.Nil(t, cs.sdb.Rollback(1))
+	assert.Nil(t, cs.catchUpState(next))
+	assert.Equal(t, types.BlockNo(3), cs.sdb.GetLatestBlockNo())
+
+	// the block of another branch is rejected
+	fork3 := types.NewBlock(block2, nil, block2.GetHeader().GetTimestamp()+2)
+	fork4 := types.NewBlock(fork3, nil, fork3.GetHeader().GetTimestamp()+1)
+	assert.IsType(t, &state.ErrPrevHashMismatch{}, cs.catchUpState(fork4))
+	assert.Equal(t, types.BlockNo(3), cs.sdb.GetLatestBlockNo())
+}
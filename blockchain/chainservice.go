@@ -6,6 +6,10 @@
 package blockchain
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
 	"github.com/aergoio/aergo-actor/actor"
 	"github.com/aergoio/aergo-lib/db"
 	"github.com/aergoio/aergo-lib/log"
@@ -20,6 +24,8 @@ import (
 	"github.com/libp2p/go-libp2p-peer"
 	"os"
 	"path"
+	"reflect"
+	"time"
 )
 
 type ChainService struct {
@@ -30,6 +36,10 @@ type ChainService struct {
 	cdb *ChainDB
 	sdb *state.ChainStateDB
 	op  *OrphanPool
+	// executor executes the txs of blocks
+	executor TxExecutor
+
+	contractGC *state.DBGC
 
 	cc chan consensus.ChainConsensus
 }
@@ -38,6 +48,8 @@ var (
 	logger = log.NewLogger("chain")
 )
 
+const syncStateTimeout = time.Second
+
 func NewChainService(cfg *cfg.Config) *ChainService {
 	actor := &ChainService{
 		cfg: cfg,
@@ -47,10 +59,17 @@ func NewChainService(cfg *cfg.Config) *ChainService {
 		op:  NewOrphanPool(),
 	}
 	actor.BaseComponent = component.NewBaseComponent(message.ChainSvc, actor, logger)
+	actor.executor = &luaTxExecutor{cs: actor}
 
 	return actor
 }
 
+// SetTxExecutor replaces the executor of the txs of blocks, such as to run another VM. It must be set before the
+// service starts.
+func (cs *ChainService) SetTxExecutor(executor TxExecutor) {
+	cs.executor = executor
+}
+
 func (cs *ChainService) receiveChainInfo() {
 	// Get a Validation interface from the consensus service
 	cs.ChainConsensus = <-cs.cc
@@ -71,8 +90,46 @@ func (cs *ChainService) initDB(dataDir string) error {
 		logger.Fatal().Err(err).Msg("failed to initialize statedb")
 		return err
 	}
+	cs.sdb.StartGC(cs.dbGCInterval(), cs.cfg.Blockchain.DBGCRatio)
+	if err := cs.sdb.SetBufferSize(cs.stateBufferSize()); err != nil {
+		return err
+	}
+	if err := cs.sdb.SetBlockStateCacheSize(cs.stateCacheSize()); err != nil {
+		return err
+	}
+	if err := cs.sdb.SetAccountCacheSize(cs.accountCacheSize()); err != nil {
+		return err
+	}
 	return nil
 }
+
+func (cs *ChainService) stateBufferSize() int {
+	if cs.cfg.Blockchain == nil {
+		return 0
+	}
+	return cs.cfg.Blockchain.StateBuffer
+}
+
+func (cs *ChainService) stateCacheSize() int {
+	if cs.cfg.Blockchain == nil {
+		return 0
+	}
+	return cs.cfg.Blockchain.StateCache
+}
+
+func (cs *ChainService) accountCacheSize() int {
+	if cs.cfg.Blockchain == nil {
+		return 0
+	}
+	return cs.cfg.Blockchain.AccountCache
+}
+
+func (cs *ChainService) dbGCInterval() time.Duration {
+	if cs.cfg.Blockchain == nil {
+		return 0
+	}
+	return time.Duration(cs.cfg.Blockchain.DBGCInterval) * time.Second
+}
 func (cs *ChainService) BeforeStart() {
 
 	if err := cs.initDB(cs.cfg.DataDir); err != nil {
@@ -80,45 +137,114 @@ func (cs *ChainService) BeforeStart() {
 	}
 
 	// init genesis block
-	if err := cs.initGenesis(cs.cfg.GenesisSeed); err != nil {
+	genesis, err := LoadGenesis(&cs.cfg.BaseConfig)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to load genesis")
+	}
+	if err := cs.initGenesis(genesis); err != nil {
 		logger.Fatal().Err(err).Msg("failed to genesis block")
 	}
 }
 
+// LoadGenesis returns the genesis of the chain to join. It is read from the genesis file at GenesisPath if it is
+// given, or made of GenesisSeed and the chain id of the preset network otherwise.
+func LoadGenesis(conf *cfg.BaseConfig) (*types.Genesis, error) {
+	if conf.GenesisPath == "" {
+		preset, err := cfg.GetNetworkPreset(conf.Network)
+		if err != nil {
+			return nil, err
+		}
+		genesis := &types.Genesis{Timestamp: conf.GenesisSeed}
+		if preset != nil {
+			genesis.ChainID = preset.ChainID
+		}
+		return genesis, nil
+	}
+	file, err := os.Open(conf.GenesisPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	genesis := new(types.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %s", conf.GenesisPath, err.Error())
+	}
+	return genesis, nil
+}
+
 func (cs *ChainService) InitGenesisBlock(gb *types.Genesis, dataDir string) error {
 
 	if err := cs.initDB(dataDir); err != nil {
 		logger.Fatal().Err(err).Msg("failed to initialize DB")
 		return err
 	}
-	return cs.initGenesis(gb.Timestamp)
+	return cs.initGenesis(gb)
 }
-func (cs *ChainService) initGenesis(seed int64) error {
+func (cs *ChainService) initGenesis(genesis *types.Genesis) error {
 	gh, _ := cs.cdb.getHashByNo(0)
 	if gh == nil || len(gh) == 0 {
 		if cs.cdb.latest == 0 {
-			genesisBlock, err := cs.cdb.generateGenesisBlock(seed)
+			states, err := genesis.AccountStates()
+			if err != nil {
+				return err
+			}
+			genesisBlock, err := cs.cdb.generateGenesisBlock(genesis)
 			if err != nil {
 				return err
 			}
-			err = cs.sdb.SetGenesis(genesisBlock)
+			err = cs.sdb.SetGenesisWithStates(genesisBlock, states)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	gb, _ := cs.cdb.getBlockByNo(0)
-	logger.Info().Int64("seed", gb.Header.Timestamp).Str("genesis", enc.ToString(gb.Hash)).Msg("chain initialized")
+	gb, err := cs.cdb.getBlockByNo(0)
+	if err != nil {
+		return err
+	}
+	if err := checkGenesis(gb, genesis); err != nil {
+		return err
+	}
 
+	// the receipts of contract calls are read to recover state
 	dbPath := path.Join(cs.cfg.DataDir, contract.DbName)
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 		_ = os.MkdirAll(dbPath, 0711)
 	}
 	contract.DB = db.NewDB(db.BadgerImpl, dbPath)
+	cs.contractGC = state.StartDBGC(contract.DbName, dbPath, contract.DB, cs.dbGCInterval(), cs.cfg.Blockchain.DBGCRatio)
+
+	if err := cs.recoverState(); err != nil {
+		logger.Error().Err(err).Msg("failed to recover state")
+		return err
+	}
+	logger.Info().Int64("seed", gb.Header.Timestamp).Str("genesis", enc.ToString(gb.Hash)).Msg("chain initialized")
 
 	return nil
 }
 
+// checkGenesis checks that the genesis block, which may be generated before, is the one of genesis. Slots of block
+// producers are calculated from the genesis timestamp, and the initial block producers and account states are
+// committed to the genesis block, so mismatch between them must not be allowed.
+func checkGenesis(block *types.Block, genesis *types.Genesis) error {
+	if block == nil || block.GetHeader() == nil {
+		return fmt.Errorf("genesis block not found")
+	}
+	if block.GetHeader().GetTimestamp() != genesis.Timestamp {
+		return fmt.Errorf("genesis timestamp mismatch: genesis=%d, seed=%d",
+			block.GetHeader().GetTimestamp(), genesis.Timestamp)
+	}
+	digest, err := genesis.Digest()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(block.GetHeader().GetTxsRootHash(), digest) {
+		return fmt.Errorf("genesis block producers or alloc mismatch: block=%s, genesis=%s",
+			enc.ToString(block.GetHeader().GetTxsRootHash()), enc.ToString(digest))
+	}
+	return nil
+}
+
 // Sync with peer
 func (cs *ChainService) ChainSync(peerID peer.ID) {
 	// handlt it like normal block (orphan)
@@ -138,6 +264,10 @@ func (cs *ChainService) SendChainInfo(ca consensus.ChainConsensus) {
 }
 
 func (cs *ChainService) BeforeStop() {
+	cs.contractGC.Stop()
+	if contract.DB != nil {
+		contract.DB.Close()
+	}
 	if cs.sdb != nil {
 		cs.sdb.Close()
 	}
@@ -217,14 +347,22 @@ func (cs *ChainService) Receive(context actor.Context) {
 				Err:       err,
 			})
 		}
+	case *message.ComputeStateRoot:
+		root, err := cs.computeStateRoot(msg.Block)
+		if err != nil {
+			logger.Error().Err(err).Str("hash", msg.Block.ID()).Msg("failed to compute state root")
+		}
+		context.Respond(message.ComputeStateRootRsp{
+			Root: root,
+			Err:  err,
+		})
 	case *message.MemPoolDelRsp:
 		err := msg.Err
 		if err != nil {
 			logger.Error().Err(err).Msg("failed to remove txs from mempool")
 		}
 	case *message.GetState:
-		id := types.ToAccountID(msg.Account)
-		state, err := cs.sdb.GetAccountStateClone(id)
+		state, err := cs.getAccountState(msg.Account, msg.Confirmations)
 		if err != nil {
 			logger.Error().Str("hash", enc.ToString(msg.Account)).Err(err).Msg("failed to get state for account")
 		}
@@ -254,6 +392,37 @@ func (cs *ChainService) Receive(context actor.Context) {
 		})
 	case *message.SyncBlockState:
 		cs.checkBlockHandshake(msg.PeerID, msg.BlockNo, msg.BlockHash)
+	case *message.GetSyncState:
+		cs.respondSyncState(context.Sender())
+	case *message.GetBpAssignment:
+		assignment, err := cs.getBpAssignment(msg.BlockNo, msg.Timestamp)
+		if err != nil {
+			logger.Debug().Err(err).Uint64("blockNo", msg.BlockNo).Int64("timestamp", msg.Timestamp).
+				Msg("failed to get bp assignment")
+		}
+		context.Respond(message.GetBpAssignmentRsp{
+			Assignment: assignment,
+			Err:        err,
+		})
+	case *message.GetBpSchedule:
+		current, nextSlot, err := cs.getBpSchedule(msg.BpID)
+		if err != nil {
+			logger.Debug().Err(err).Str("bpID", msg.BpID).Msg("failed to get bp schedule")
+		}
+		context.Respond(message.GetBpScheduleRsp{
+			Current:  current,
+			NextSlot: nextSlot,
+			Err:      err,
+		})
+	case *message.GetLogs:
+		events, err := cs.getLogs(msg.Filter)
+		if err != nil {
+			logger.Debug().Err(err).Msg("failed to get logs")
+		}
+		context.Respond(message.GetLogsRsp{
+			Events: events,
+			Err:    err,
+		})
 	case actor.SystemMessage,
 		actor.AutoReceiveMessage,
 		actor.NotInfluenceReceiveTimeout:
@@ -265,11 +434,119 @@ func (cs *ChainService) Receive(context actor.Context) {
 }
 
 func (cs *ChainService) Statics() *map[string]interface{} {
-	return &map[string]interface{}{
+	statics := map[string]interface{}{
 		"orphan": cs.op.curCnt,
 	}
+	if sp, ok := cs.ChainConsensus.(consensus.StatisticsProvider); ok {
+		statics["consensus"] = sp.Statistics()
+	}
+	return &statics
 }
 
 func (cs *ChainService) GetChainTree() ([]byte, error) {
 	return cs.cdb.GetChainTree()
 }
+
+// GetSyncState compares the best block number with the heights reported by peers. It waits for the p2p service,
+// so it must not be called by the chain service actor itself.
+func (cs *ChainService) GetSyncState() (types.SyncState, error) {
+	return syncState(cs.getBestBlockNo(), cs.RequestToFuture(message.P2PSvc, &message.GetPeerHeights{}, syncStateTimeout))
+}
+
+// respondSyncState responds the sync state to sender. The heights of peers are waited for in another goroutine, so
+// that the chain service is not blocked by the p2p service.
+func (cs *ChainService) respondSyncState(sender *actor.PID) {
+	bestNo := cs.getBestBlockNo()
+	future := cs.RequestToFuture(message.P2PSvc, &message.GetPeerHeights{}, syncStateTimeout)
+	go func() {
+		state, err := syncState(bestNo, future)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to get sync state")
+		}
+		sender.Tell(message.GetSyncStateRsp{State: state, Err: err})
+	}()
+}
+
+// syncState compares bestNo with the heights of peers, which are the result of future.
+func syncState(bestNo types.BlockNo, future *actor.Future) (types.SyncState, error) {
+	result, err := future.Result()
+	if err != nil {
+		return types.SyncState{}, err
+	}
+	rsp, ok := result.(*message.GetPeerHeightsRsp)
+	if !ok {
+		return types.SyncState{}, fmt.Errorf("invalid response type of peer heights: %v", reflect.TypeOf(result))
+	}
+	heights := make([]types.BlockNo, 0, len(rsp.Heights))
+	for _, height := range rsp.Heights {
+		heights = append(heights, height)
+	}
+	return types.NewSyncState(bestNo, heights), nil
+}
+
+// getBpAssignment returns the block producer scheduled for the slot of block blockNo and the one which actually
+// produced it. If ts (UNIX time in ns) is not zero, the slot including ts is looked up instead.
+func (cs *ChainService) getBpAssignment(blockNo types.BlockNo, ts int64) (*types.BpAssignment, error) {
+	sp, ok := cs.ChainConsensus.(consensus.BpScheduleProvider)
+	if !ok {
+		return nil, fmt.Errorf("consensus has no block producer schedule")
+	}
+
+	var (
+		block *types.Block
+		err   error
+	)
+	if ts != 0 {
+		block, err = cs.findBlockByTime(ts)
+	} else {
+		if blockNo == 0 {
+			return nil, fmt.Errorf("genesis block has no block producer")
+		}
+		block, err = cs.getBlockByNo(blockNo)
+		ts = block.GetHeader().GetTimestamp()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sp.BpAssignment(ts, block)
+}
+
+// getBpSchedule returns the block producer of the current slot, and the beginning of the next slot assigned to
+// bpID. The next slot is not looked up if bpID is empty.
+func (cs *ChainService) getBpSchedule(bpID string) (string, time.Time, error) {
+	sp, ok := cs.ChainConsensus.(consensus.BpScheduleProvider)
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("consensus has no block producer schedule")
+	}
+
+	current := sp.CurrentProducer(time.Now())
+	if bpID == "" {
+		return current, time.Time{}, nil
+	}
+	nextSlot := sp.NextSlotFor(bpID)
+	if nextSlot.IsZero() {
+		return current, nextSlot, fmt.Errorf("%s is not a scheduled block producer", bpID)
+	}
+	return current, nextSlot, nil
+}
+
+// findBlockByTime returns the last block of the main chain produced at or before ts. It returns nil if there is no
+// such block other than the genesis block.
+func (cs *ChainService) findBlockByTime(ts int64) (*types.Block, error) {
+	var found *types.Block
+	lo, hi := types.BlockNo(1), cs.getBestBlockNo()
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		block, err := cs.getBlockByNo(mid)
+		if err != nil {
+			return nil, err
+		}
+		if block.GetHeader().GetTimestamp() <= ts {
+			found = block
+			lo = mid + 1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return found, nil
+}
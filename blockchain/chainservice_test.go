@@ -0,0 +1,146 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	cfg "github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/internal/enc"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStateRoot(t *testing.T) {
+	root := []byte("0123456789abcdef0123456789abcdef")
+	block := types.NewBlock(nil, nil, 0)
+	block.Header.StateRootHash = root
+	assert.Nil(t, checkStateRoot(block, root))
+
+	mismatched := []byte("fedcba9876543210fedcba9876543210")
+	assert.NotNil(t, checkStateRoot(block, mismatched))
+
+	block.Header.StateRootHash = nil
+	assert.NotNil(t, checkStateRoot(block, root))
+}
+
+func TestCheckGenesis(t *testing.T) {
+	const seed int64 = 1530838800
+	alloc := &types.Genesis{Timestamp: seed, Balance: map[string]*types.State{
+		enc.ToString([]byte("alice")): {Balance: 1000},
+	}}
+	newGenesis := func(genesis *types.Genesis) *types.Block {
+		block, err := genesis.Block()
+		assert.Nil(t, err)
+		return block
+	}
+	tests := []struct {
+		name    string
+		block   *types.Block
+		genesis *types.Genesis
+		wantErr bool
+	}{
+		{"TConsistent", newGenesis(&types.Genesis{Timestamp: seed}), &types.Genesis{Timestamp: seed}, false},
+		{"TInconsistent", newGenesis(&types.Genesis{Timestamp: seed + 1}), &types.Genesis{Timestamp: seed}, true},
+		{"TAlloc", newGenesis(alloc), alloc, false},
+		{"TAllocMissing", newGenesis(&types.Genesis{Timestamp: seed}), alloc, true},
+		{"TAllocAdded", newGenesis(alloc), &types.Genesis{Timestamp: seed}, true},
+		{"TNoGenesis", nil, &types.Genesis{Timestamp: seed}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkGenesis(tt.block, tt.genesis)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestLoadGenesisNetwork(t *testing.T) {
+	conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+	conf.Network = cfg.NetworkTestnet
+	assert.Nil(t, conf.ApplyNetwork())
+	genesis, err := LoadGenesis(&conf.BaseConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, "aergo.io/testnet", genesis.ChainID)
+
+	cs, closeChain := NewTestChain(t, genesis)
+	defer closeChain()
+	gb, err := cs.getBlockByNo(0)
+	assert.Nil(t, err)
+	testnet, err := (&types.Genesis{ChainID: "aergo.io/testnet", Timestamp: 1538352000}).Block()
+	assert.Nil(t, err)
+	assert.Equal(t, testnet.BlockHash(), gb.BlockHash())
+
+	// the chain id is committed to the genesis block, so the presets are distinct even at the same timestamp
+	other, err := (&types.Genesis{ChainID: "aergo.io/mainnet", Timestamp: 1538352000}).Block()
+	assert.Nil(t, err)
+	assert.NotEqual(t, other.BlockHash(), gb.BlockHash())
+
+	// genesis is loaded only from the explicit path
+	conf.GenesisPath = filepath.Join(os.TempDir(), "no-such-genesis.json")
+	_, err = LoadGenesis(&conf.BaseConfig)
+	assert.NotNil(t, err)
+}
+
+func TestChainServiceRecoverState(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "recoverstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+	sender := []byte("alice")
+	genesis := types.NewTestGenesis(types.TestGenesisOptions{Balances: map[string]uint64{string(sender): 1000000}})
+	conf := &cfg.Config{
+		BaseConfig: cfg.BaseConfig{DataDir: dataDir, GenesisSeed: genesis.Timestamp},
+		Blockchain: &cfg.BlockchainConfig{},
+	}
+	cs := NewChainService(conf)
+	if !assert.Nil(t, cs.InitGenesisBlock(genesis, dataDir)) {
+		return
+	}
+
+	nonce := uint64(0)
+	newTx := func(recipient []byte, payload string) *types.Tx {
+		nonce++
+		tx := &types.Tx{Body: &types.TxBody{Account: sender, Nonce: nonce, Recipient: recipient,
+			Payload: []byte(payload), Price: 1}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	deploy := newTx(nil, testFeeContract)
+	connectTestBlock(t, cs, deploy)
+	h := sha256.New()
+	h.Write(sender)
+	h.Write([]byte(strconv.FormatUint(deploy.Body.Nonce, 10)))
+	address := h.Sum(nil)[:20]
+	connectTestBlock(t, cs, newTx(address, `{"Name":"sum","Args":["100"]}`))
+	connectTestBlock(t, cs, newTx(address, `{"Name":"sum","Args":["10"]}`))
+	want, err := cs.sdb.GetAccountStateClone(types.ToAccountID(sender))
+	assert.Nil(t, err)
+	wantRoot := cs.sdb.GetHash()
+
+	// the state is left behind the chain, as if the node crashed before writing it
+	assert.Nil(t, cs.sdb.Rollback(1))
+	cs.BeforeStop()
+
+	// restarting replays the contract calls with their receipts
+	restarted := NewChainService(conf)
+	if !assert.Nil(t, restarted.InitGenesisBlock(genesis, dataDir)) {
+		return
+	}
+	defer restarted.BeforeStop()
+	assert.Equal(t, types.BlockNo(3), restarted.sdb.GetLatestBlockNo())
+	assert.Equal(t, wantRoot, restarted.sdb.GetHash())
+	got, err := restarted.sdb.GetAccountStateClone(types.ToAccountID(sender))
+	assert.Nil(t, err)
+	assert.Equal(t, want.Balance, got.Balance)
+	assert.Equal(t, want.Nonce, got.Nonce)
+}
@@ -0,0 +1,80 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/aergoio/aergo-lib/db"
+	"github.com/aergoio/aergo/contract"
+	"github.com/aergoio/aergo/state"
+	"github.com/aergoio/aergo/types"
+)
+
+// CheckDB opens and closes the chain, state and contract databases in dataDir. It is used to check the environment
+// of a node without starting it, so it fails if the databases are locked by a running node.
+func CheckDB(dataDir string) (err error) {
+	// db implementations panic when they fail to open
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to open db: %v", r)
+		}
+	}()
+
+	cdb := NewChainDB()
+	defer cdb.Close()
+	if err := cdb.Init(dataDir); err != nil {
+		return err
+	}
+
+	sdb := state.NewStateDB()
+	defer func() {
+		if closeErr := sdb.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if err := sdb.Init(dataDir); err != nil {
+		return err
+	}
+
+	dbPath := path.Join(dataDir, contract.DbName)
+	if err := os.MkdirAll(dbPath, 0711); err != nil {
+		return err
+	}
+	db.NewDB(db.BadgerImpl, dbPath).Close()
+
+	return nil
+}
+
+// CheckGenesis checks that genesis is valid, and the genesis block in the chain database of dataDir, if any, is the
+// one of genesis. It passes if the genesis block is not generated yet.
+func CheckGenesis(dataDir string, genesis *types.Genesis) (err error) {
+	if _, err := genesis.Block(); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to open db: %v", r)
+		}
+	}()
+
+	cdb := NewChainDB()
+	if err := cdb.Init(dataDir); err != nil {
+		return err
+	}
+	defer cdb.Close()
+
+	if gh, _ := cdb.getHashByNo(0); len(gh) == 0 {
+		return nil
+	}
+	gb, err := cdb.getBlockByNo(0)
+	if err != nil {
+		return err
+	}
+	return checkGenesis(gb, genesis)
+}
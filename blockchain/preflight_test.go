@@ -0,0 +1,24 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDB(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "aergo-preflight")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+
+	// the databases are created in the fresh dir, and closed so that they can be opened again
+	assert.Nil(t, CheckDB(dataDir))
+	assert.Nil(t, CheckDB(dataDir))
+}
@@ -238,6 +238,7 @@ func (reorg *reorganizer) rollforwardChain() error {
 	cs := reorg.cs
 	cdb := cs.cdb
 
+	confirmed := make([][]byte, 0)
 	for i := len(reorg.rfBlocks) - 1; i >= 0; i-- {
 		rfBlock := reorg.rfBlocks[i]
 
@@ -256,7 +257,20 @@ func (reorg *reorganizer) rollforwardChain() error {
 		if err := reorg.rollforwardBlock(targetBlock); err != nil {
 			return err
 		}
+		for _, tx := range targetBlock.GetBody().GetTxs() {
+			confirmed = append(confirmed, tx.GetHash())
+		}
+	}
+
+	//let p2p handle notices of rollbacked Tx again
+	reverted := make([][]byte, 0, len(reorg.rbTxs))
+	for _, tx := range reorg.rbTxs {
+		reverted = append(reverted, tx.GetHash())
 	}
+	cs.RequestTo(message.P2PSvc, &message.NotifyTxsReorged{
+		Confirmed: confirmed,
+		Reverted:  reverted,
+	})
 
 	//add rollbacked Tx to mempool (except played tx in roll forward)
 	cntRbTxs := len(reorg.rbTxs)
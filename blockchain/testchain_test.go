@@ -0,0 +1,77 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	cfg "github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// NewTestChain makes a chain service on a temporary directory, which is initialized with genesis. A trivial
+// genesis made by types.NewTestGenesis is used if genesis is nil. The returned function closes the chain and
+// removes the directory. It fails t on any error.
+func NewTestChain(t testing.TB, genesis *types.Genesis) (*ChainService, func()) {
+	dataDir, err := ioutil.TempDir("", "testchain")
+	if err != nil {
+		t.Fatalf("failed to make data dir of test chain: %s", err.Error())
+	}
+	if genesis == nil {
+		genesis = types.NewTestGenesis(types.TestGenesisOptions{})
+	}
+	conf := &cfg.Config{
+		BaseConfig: cfg.BaseConfig{DataDir: dataDir, GenesisSeed: genesis.Timestamp},
+		Blockchain: &cfg.BlockchainConfig{},
+	}
+	cs := NewChainService(conf)
+	if err := cs.InitGenesisBlock(genesis, dataDir); err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("failed to init test chain: %s", err.Error())
+	}
+	return cs, func() {
+		cs.BeforeStop()
+		os.RemoveAll(dataDir)
+	}
+}
+
+func TestNewTestChain(t *testing.T) {
+	genesis := types.NewTestGenesis(types.TestGenesisOptions{
+		Balances: map[string]uint64{"alice": 1000, "bob": 20},
+	})
+	cs, closeChain := NewTestChain(t, genesis)
+	defer closeChain()
+
+	best, err := cs.getBestBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, types.BlockNo(0), best.GetHeader().GetBlockNo())
+	assert.Equal(t, genesis.Timestamp, best.GetHeader().GetTimestamp())
+	// the funded balances are committed to the genesis block
+	digest, err := genesis.Digest()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, digest)
+	assert.Equal(t, digest, best.GetHeader().GetTxsRootHash())
+
+	for addr, balance := range map[string]uint64{"alice": 1000, "bob": 20, "carol": 0} {
+		state, err := cs.getAccountState([]byte(addr), 0)
+		assert.Nil(t, err)
+		assert.Equal(t, balance, state.Balance, addr)
+	}
+	// funded accounts are in the state trie
+	assert.NotEmpty(t, cs.sdb.GetHash())
+}
+
+func TestNewTestChainNoGenesis(t *testing.T) {
+	cs, closeChain := NewTestChain(t, nil)
+	defer closeChain()
+
+	state, err := cs.getAccountState([]byte("alice"), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), state.Balance)
+}
@@ -0,0 +1,63 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/aergoio/aergo/contract"
+	"github.com/aergoio/aergo/state"
+	"github.com/aergoio/aergo/types"
+)
+
+// ErrInsufficientFee is returned for the tx whose sender can't afford the fee of its gas limit.
+var ErrInsufficientFee = errors.New("insufficient balance to pay the fee of the gas limit")
+
+// TxExecutor executes the txs of blocks, both to compute the state root of a block being generated and to
+// validate a block being connected. Execute puts the account changes made by tx of block into bs, and returns the
+// receipt of the contract deployed or called by tx, which is nil for a plain transfer. An error makes the block
+// invalid.
+type TxExecutor interface {
+	Execute(tx *types.Tx, bs *state.BlockState, block *types.Block) (*types.Receipt, error)
+}
+
+// luaTxExecutor is the default TxExecutor, which transfers the amount of tx and runs Lua contracts.
+type luaTxExecutor struct {
+	cs *ChainService
+}
+
+// Execute runs the contract call of tx within the gas limit of tx, and charges the fee of the gas used by it to
+// the sender. The call is not executed unless the sender can afford the fee of the whole gas limit.
+func (e *luaTxExecutor) Execute(tx *types.Tx, bs *state.BlockState, block *types.Block) (*types.Receipt, error) {
+	txBody := tx.GetBody()
+	recipient, createContract, err := e.cs.applyTxState(bs, tx)
+	if err != nil {
+		return nil, err
+	}
+	if txBody.Payload == nil {
+		return nil, nil
+	}
+	if createContract {
+		if err := contract.Create(txBody.Payload, recipient, tx.Hash); err != nil {
+			return nil, err
+		}
+		return contract.GetReceipt(tx.Hash), nil
+	}
+	if err := e.cs.checkMaxFee(bs, tx); err != nil {
+		return nil, err
+	}
+	bcCtx := contract.NewContext(txBody.GetAccount(), block.BlockHash(), tx.GetHash(),
+		block.GetHeader().GetBlockNo(), block.GetHeader().GetTimestamp(), "", false, recipient)
+
+	gasUsed, err := contract.Call(txBody.Payload, recipient, tx.Hash, bcCtx, txBody.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	if err := e.cs.chargeFee(bs, tx, gasUsed); err != nil {
+		return nil, err
+	}
+	return contract.GetReceipt(tx.Hash), nil
+}
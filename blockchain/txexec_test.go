@@ -0,0 +1,60 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/aergoio/aergo/state"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTxExecutor records the txs executed, and sets the nonce of each sender without any VM.
+type testTxExecutor struct {
+	executed [][]byte
+}
+
+func (e *testTxExecutor) Execute(tx *types.Tx, bs *state.BlockState, block *types.Block) (*types.Receipt, error) {
+	e.executed = append(e.executed, tx.GetHash())
+	bs.PutAccount(types.ToAccountID(tx.GetBody().GetAccount()), types.NewState(),
+		&types.State{Nonce: tx.GetBody().GetNonce()})
+	return nil, nil
+}
+
+func TestTxExecutor(t *testing.T) {
+	cs, closeChain := NewTestChain(t, nil)
+	defer closeChain()
+	executor := &testTxExecutor{}
+	cs.SetTxExecutor(executor)
+
+	var txs []*types.Tx
+	var hashes [][]byte
+	for i, sender := range []string{"alice", "bob", "carol"} {
+		tx := &types.Tx{Body: &types.TxBody{Account: []byte(sender), Nonce: uint64(i + 1), Payload: []byte("{}")}}
+		tx.Hash = tx.CalculateTxHash()
+		txs = append(txs, tx)
+		hashes = append(hashes, tx.Hash)
+	}
+	best, err := cs.getBestBlock()
+	assert.Nil(t, err)
+	block := types.NewBlock(best, txs, best.GetHeader().GetTimestamp()+1)
+
+	// block generation executes each tx by the executor
+	root, err := cs.computeStateRoot(block)
+	assert.Nil(t, err)
+	assert.Equal(t, hashes, executor.executed)
+	assert.NotEqual(t, best.GetHeader().GetStateRootHash(), root)
+
+	// and so does block validation, which gets the same state root
+	executor.executed = nil
+	block.SetStateRoot(root)
+	dbtx := cs.cdb.store.NewTx(true)
+	assert.Nil(t, cs.processTxsAndState(&dbtx, block))
+	dbtx.Commit()
+	assert.Equal(t, hashes, executor.executed)
+	assert.Equal(t, root, cs.sdb.GetHash())
+}
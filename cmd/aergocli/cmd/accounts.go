@@ -19,8 +19,11 @@ func init() {
 	rootCmd.AddCommand(getAccountsCmd)
 	rootCmd.AddCommand(lockAccountsCmd)
 	rootCmd.AddCommand(unlockAccountsCmd)
+	unlockAccountsCmd.Flags().Uint64Var(&unlockDuration, "duration", 0, "seconds to keep the account unlocked (0 means until locked)")
 }
 
+var unlockDuration uint64
+
 var newAccountCmd = &cobra.Command{
 	Use:   "newaccount",
 	Short: "Create new account in the node",
@@ -97,10 +100,16 @@ var lockAccountsCmd = &cobra.Command{
 			panic("Internal error. wrong RPC client type")
 		}
 		defer client.Close()
-		param, err := parsePersonalParam(args)
+		if len(args) < 1 {
+			fmt.Println("Failed: no address")
+			return
+		}
+		address, err := base58.Decode(args[0])
 		if err != nil {
+			fmt.Printf("Failed: %s\n", err.Error())
 			return
 		}
+		param := &types.Personal{Account: &types.Account{Address: address}}
 		msg, err := client.LockAccount(context.Background(), param)
 		if err == nil {
 			fmt.Println(base58.Encode(msg.GetAddress()))
@@ -127,6 +136,7 @@ var unlockAccountsCmd = &cobra.Command{
 		if err != nil {
 			return
 		}
+		param.Duration = unlockDuration
 		msg, err := client.UnlockAccount(context.Background(), param)
 		if nil == err {
 			fmt.Println(base58.Encode(msg.GetAddress()))
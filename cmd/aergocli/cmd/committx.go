@@ -72,7 +72,7 @@ func execCommitTX(cmd *cobra.Command, args []string) {
 		}
 	}
 	for i, r := range msg.Results {
-		fmt.Println(i+1, ":", util.EncodeB64(r.Hash), r.Error)
+		fmt.Println(i+1, ":", util.EncodeB64(r.Hash), r.Error, r.Detail)
 	}
 }
 
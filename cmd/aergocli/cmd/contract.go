@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strings"
 
 	"github.com/aergoio/aergo/cmd/aergocli/util"
 	"github.com/aergoio/aergo/types"
@@ -35,7 +36,7 @@ func init() {
 				if err != nil {
 					log.Fatal(err)
 				}
-				state, err := client.GetState(context.Background(), &types.SingleBytes{Value: creator})
+				state, err := client.GetState(context.Background(), &types.StateQuery{Account: creator})
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -74,7 +75,7 @@ func init() {
 				if err != nil {
 					log.Fatal(err)
 				}
-				state, err := client.GetState(context.Background(), &types.SingleBytes{Value: caller})
+				state, err := client.GetState(context.Background(), &types.StateQuery{Account: caller})
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -85,7 +86,10 @@ func init() {
 				var abi types.ABI
 				abi.Name = args[2]
 				if len(args) > 3 {
-					err = json.Unmarshal([]byte(args[3]), &abi.Args)
+					// numbers are kept as is, so that large integers aren't rounded on the way
+					dec := json.NewDecoder(strings.NewReader(args[3]))
+					dec.UseNumber()
+					err = dec.Decode(&abi.Args)
 					if err != nil {
 						log.Fatal(err)
 					}
@@ -0,0 +1,44 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aergoio/aergo/cmd/aergocli/util"
+	"github.com/aergoio/aergo/types"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var generateBlockCmd = &cobra.Command{
+	Use:   "generateblock",
+	Short: "Produce a block immediately (only for development consensus)",
+	Args:  cobra.MinimumNArgs(0),
+	Run:   execGenerateBlock,
+}
+
+func init() {
+	rootCmd.AddCommand(generateBlockCmd)
+}
+
+func execGenerateBlock(cmd *cobra.Command, args []string) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	var client *util.ConnClient
+	var ok bool
+	if client, ok = util.GetClient(GetServerAddress(), opts).(*util.ConnClient); !ok {
+		panic("Internal error. wrong RPC client type")
+	}
+	defer client.Close()
+
+	msg, err := client.GenerateBlock(context.Background(), &types.Empty{})
+	if err != nil {
+		fmt.Printf("Failed: %s\n", err.Error())
+		return
+	}
+	fmt.Println(util.EncodeB64(msg.Value))
+}
@@ -0,0 +1,65 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aergoio/aergo/cmd/aergocli/util"
+	"github.com/aergoio/aergo/types"
+	"github.com/mr-tron/base58/base58"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var getLogsCmd = &cobra.Command{
+	Use:   "getlogs [flags] contract_address",
+	Short: "Get events emitted by contract",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   execGetLogs,
+}
+
+var (
+	logsEventName string
+	logsFrom      uint64
+	logsTo        uint64
+)
+
+func init() {
+	rootCmd.AddCommand(getLogsCmd)
+	getLogsCmd.Flags().StringVar(&logsEventName, "event", "", "Event name (all events if empty)")
+	getLogsCmd.Flags().Uint64Var(&logsFrom, "from", 0, "First block height to search")
+	getLogsCmd.Flags().Uint64Var(&logsTo, "to", 0, "Last block height to search (best block if 0)")
+}
+
+func execGetLogs(cmd *cobra.Command, args []string) {
+	contractAddress, err := base58.Decode(args[0])
+	if err != nil {
+		fmt.Printf("decode error: %s\n", err.Error())
+		return
+	}
+
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	var client *util.ConnClient
+	var ok bool
+	if client, ok = util.GetClient(GetServerAddress(), opts).(*util.ConnClient); !ok {
+		panic("Internal error. wrong RPC client type")
+	}
+	defer client.Close()
+
+	msg, err := client.GetLogs(context.Background(), &types.FilterInfo{
+		ContractAddress: contractAddress,
+		EventName:       logsEventName,
+		Blockfrom:       logsFrom,
+		Blockto:         logsTo,
+	})
+	if err != nil {
+		fmt.Printf("Failed: %s\n", err.Error())
+		return
+	}
+	fmt.Println(util.JSON(msg))
+}
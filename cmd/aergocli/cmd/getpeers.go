@@ -51,6 +51,9 @@ func execGetPeers(cmd *cobra.Command, args []string) {
 		peerData := make(map[string]string)
 		peerState := types.PeerState(msg2.States[i]).String()
 		peerData["Address"] = net.IP(peer.Address).String()
+		if len(peer.Host) > 0 {
+			peerData["Address"] = peer.Host
+		}
 		peerData["Port"] = strconv.Itoa(int(peer.Port))
 		peerData["PeerID"] = base58.Encode(peer.PeerID)
 		peerData["State"] = peerState
@@ -24,10 +24,12 @@ var getstateCmd = &cobra.Command{
 }
 
 var address string
+var confirmations uint64
 
 func init() {
 	rootCmd.AddCommand(getstateCmd)
 	getstateCmd.Flags().StringVar(&address, "address", "", "Get state from the address")
+	getstateCmd.Flags().Uint64Var(&confirmations, "confirmations", 0, "Get state confirmed by the number of blocks")
 }
 
 func execGetState(cmd *cobra.Command, args []string) {
@@ -48,7 +50,7 @@ func execGetState(cmd *cobra.Command, args []string) {
 		fmt.Printf("Failed: %s\n", err.Error())
 	}
 	msg, err := client.GetState(context.Background(),
-		&types.SingleBytes{Value: param})
+		&types.StateQuery{Account: param, Confirmations: confirmations})
 	if nil == err {
 		fmt.Printf("{account:%s, nonce:%d, balance:%d}\n",
 			address, msg.GetNonce(), msg.GetBalance())
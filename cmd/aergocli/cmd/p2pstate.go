@@ -0,0 +1,44 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aergoio/aergo/cmd/aergocli/util"
+	"github.com/aergoio/aergo/types"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var p2pStateCmd = &cobra.Command{
+	Use:   "p2pstate",
+	Short: "Dump state of peers and connections",
+	Args:  cobra.MinimumNArgs(0),
+	Run:   execP2PState,
+}
+
+func init() {
+	rootCmd.AddCommand(p2pStateCmd)
+}
+
+func execP2PState(cmd *cobra.Command, args []string) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	var client *util.ConnClient
+	var ok bool
+	if client, ok = util.GetClient(GetServerAddress(), opts).(*util.ConnClient); !ok {
+		panic("Internal error. wrong RPC client type")
+	}
+	defer client.Close()
+
+	msg, err := client.DumpP2PState(context.Background(), &types.Empty{})
+	if err != nil {
+		fmt.Printf("Failed: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("%s\n", string(msg.Value))
+}
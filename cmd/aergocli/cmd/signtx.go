@@ -6,13 +6,17 @@ import (
 
 	"github.com/aergoio/aergo/cmd/aergocli/util"
 	"github.com/aergoio/aergo/types"
+	"github.com/mr-tron/base58/base58"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 )
 
+var signFrom string
+
 func init() {
 	rootCmd.AddCommand(signCmd)
 	signCmd.Flags().StringVar(&jsonTx, "jsontx", "", "transaction json to sign")
+	signCmd.Flags().StringVar(&signFrom, "from", "", "unlocked account in the node to sign with")
 
 	rootCmd.AddCommand(verifyCmd)
 	verifyCmd.Flags().StringVar(&jsonTx, "jsontx", "", "transaction list json to verify")
@@ -43,7 +47,19 @@ var signCmd = &cobra.Command{
 			fmt.Printf("Failed: %s\n", err.Error())
 			return
 		}
-		msg, err := client.SignTX(context.Background(), &types.Tx{Body: param})
+		var msg *types.Tx
+		if signFrom != "" {
+			var from []byte
+			from, err = base58.Decode(signFrom)
+			if err != nil {
+				fmt.Printf("Failed: %s\n", err.Error())
+				return
+			}
+			msg, err = client.SignTransaction(context.Background(),
+				&types.SignTxRequest{Tx: &types.Tx{Body: param}, From: from})
+		} else {
+			msg, err = client.SignTX(context.Background(), &types.Tx{Body: param})
+		}
 		if nil == err && msg != nil {
 			fmt.Println(util.ConvBase58Addr(msg))
 		} else {
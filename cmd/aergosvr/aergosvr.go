@@ -23,6 +23,7 @@ import (
 	"github.com/aergoio/aergo/pkg/component"
 	rest "github.com/aergoio/aergo/rest"
 	"github.com/aergoio/aergo/rpc"
+	"github.com/aergoio/aergo/types"
 	"github.com/spf13/cobra"
 )
 
@@ -61,12 +62,21 @@ func initConfig() {
 		fmt.Printf("Fail to load configuration file %v: %v", serverCtx.Vc.ConfigFileUsed(), err.Error())
 		os.Exit(1)
 	}
+	if err := cfg.ApplyNetwork(); err != nil {
+		fmt.Printf("Fail to apply network: %v\n", err.Error())
+		os.Exit(1)
+	}
+	if err := cfg.Blockchain.CheckSyncMode(); err != nil {
+		fmt.Printf("Fail to check syncmode: %v\n", err.Error())
+		os.Exit(1)
+	}
 }
 
 func rootRun(cmd *cobra.Command, args []string) {
 
 	svrlog = log.NewLogger("asvr")
 	svrlog.Info().Msg("AERGO SVR STARTED")
+	svrlog.Info().Str("network", cfg.Network).Str("chainid", cfg.ChainID()).Msg("Joining network")
 
 	if cfg.EnableProfile {
 		svrlog.Info().Msgf("Enable Profiling on localhost:", cfg.ProfilePort)
@@ -76,6 +86,8 @@ func rootRun(cmd *cobra.Command, args []string) {
 		}()
 	}
 
+	// components are stopped in the reverse order of registration, so each one is registered after the ones it
+	// depends on
 	compMng := component.NewComponentHub()
 	chainsvc := blockchain.NewChainService(cfg)
 	compMng.Register(chainsvc)
@@ -108,14 +120,66 @@ func rootRun(cmd *cobra.Command, args []string) {
 		consensus.Start(c)
 	}
 	chainsvc.SendChainInfo(c)
+	rpcsvc.SetConsensus(c)
+
+	run := newServerRun(func() types.BlockNo {
+		block, err := chainsvc.GetBestBlock()
+		if err != nil {
+			return 0
+		}
+		return block.GetHeader().GetBlockNo()
+	})
+	os.Exit(waitShutdown(common.NotifyKillSig(), func() error {
+		// the components are stopped even if the consensus fails to stop
+		consensusErr := consensus.Stop(c)
+		if consensusErr != nil {
+			svrlog.Error().Err(consensusErr).Msg("failed to stop consensus")
+		}
+		if err := compMng.Stop(); err != nil {
+			return err
+		}
+		return consensusErr
+	}, run))
+}
 
-	common.HandleKillSig(func() {
-		consensus.Stop(c)
-		compMng.Stop()
-	}, svrlog)
+// serverRun keeps the summary of a server run, which is logged on shutdown.
+type serverRun struct {
+	startedAt   time.Time
+	startBlock  types.BlockNo
+	bestBlockNo func() types.BlockNo
+}
 
-	// wait... TODO need to break out when system finished.
-	for {
-		time.Sleep(time.Minute)
+func newServerRun(bestBlockNo func() types.BlockNo) *serverRun {
+	return &serverRun{
+		startedAt:   time.Now(),
+		startBlock:  bestBlockNo(),
+		bestBlockNo: bestBlockNo,
+	}
+}
+
+// waitShutdown blocks until a signal is received from sigs, and then shuts the server down by stop. It returns the
+// exit code of the server, which is 0 if stop succeeds, or 1 if it fails or panics.
+func waitShutdown(sigs <-chan os.Signal, stop func() error, run *serverRun) (code int) {
+	sig := <-sigs
+	svrlog.Info().Str("signal", sig.String()).Msg("Shutting down")
+
+	// the best block is read before the chain db is closed by stop
+	bestBlock := run.bestBlockNo()
+	var processed types.BlockNo
+	if bestBlock > run.startBlock {
+		processed = bestBlock - run.startBlock
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			svrlog.Error().Interface("panic", r).Msg("failed to shut down")
+			code = 1
+		}
+		svrlog.Info().Str("uptime", time.Since(run.startedAt).String()).Uint64("bestBlock", bestBlock).
+			Uint64("blocksProcessed", processed).Int("exitCode", code).Msg("AERGO SVR STOPPED")
+	}()
+	if err := stop(); err != nil {
+		svrlog.Error().Err(err).Msg("failed to shut down")
+		return 1
 	}
+	return 0
 }
@@ -0,0 +1,52 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitShutdown(t *testing.T) {
+	svrlog = log.NewLogger("asvr")
+
+	bestBlock := types.BlockNo(10)
+	run := newServerRun(func() types.BlockNo { return bestBlock })
+	assert.Equal(t, types.BlockNo(10), run.startBlock)
+
+	tests := []struct {
+		name string
+		stop func() error
+		code int
+	}{
+		{"clean", func() error { return nil }, 0},
+		{"error", func() error { return fmt.Errorf("failed to stop") }, 1},
+		{"panic", func() error { panic("failed to stop") }, 1},
+	}
+	for _, tt := range tests {
+		sigs := make(chan os.Signal, 1)
+		stopped := make(chan bool, 1)
+		code := make(chan int, 1)
+		go func() {
+			code <- waitShutdown(sigs, func() error {
+				stopped <- true
+				return tt.stop()
+			}, run)
+		}()
+
+		// nothing is stopped until signaled
+		assert.Empty(t, stopped, tt.name)
+		bestBlock += 5
+		sigs <- syscall.SIGTERM
+		assert.Equal(t, tt.code, <-code, tt.name)
+		assert.Len(t, stopped, 1, tt.name)
+	}
+}
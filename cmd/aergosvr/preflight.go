@@ -0,0 +1,205 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/aergoio/aergo/blockchain"
+	"github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/p2p"
+	"github.com/aergoio/aergo/types"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+}
+
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check the environment of node without starting it",
+	Long:  "Check configuration, key, databases, genesis block and ports of node, and exit with non-zero status if any check fails",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !runPreflight(cfg, os.Stdout) {
+			os.Exit(1)
+		}
+	},
+}
+
+// preflightCheck is a check of the environment, which must pass for node to start.
+type preflightCheck struct {
+	name  string
+	check func(cfg *config.Config) error
+}
+
+var preflightChecks = []preflightCheck{
+	{"config", checkConfig},
+	{"key", checkKey},
+	{"database", checkDatabase},
+	{"genesis", checkGenesis},
+	{"ports", checkPorts},
+}
+
+// runPreflight runs all the checks against cfg and reports the result of each to w. It returns true if all of
+// them pass.
+func runPreflight(cfg *config.Config, w io.Writer) bool {
+	passed := true
+	for _, c := range preflightChecks {
+		if err := c.check(cfg); err != nil {
+			fmt.Fprintf(w, "[FAIL] %s: %s\n", c.name, err.Error())
+			passed = false
+		} else {
+			fmt.Fprintf(w, "[PASS] %s\n", c.name)
+		}
+	}
+	return passed
+}
+
+func checkPort(name string, port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid %s port %d", name, port)
+	}
+	return nil
+}
+
+func checkFileExists(name string, path string) error {
+	if path == "" {
+		return fmt.Errorf("%s is not set", name)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%s %s is not accessible: %s", name, path, err.Error())
+	}
+	return nil
+}
+
+func checkConfig(cfg *config.Config) error {
+	if _, err := config.GetNetworkPreset(cfg.Network); err != nil {
+		return err
+	}
+	if err := cfg.Blockchain.CheckSyncMode(); err != nil {
+		return err
+	}
+	if err := checkPort("rpc", cfg.RPC.NetServicePort); err != nil {
+		return err
+	}
+	if err := checkPort("p2p", cfg.P2P.NetProtocolPort); err != nil {
+		return err
+	}
+	if cfg.EnableRest {
+		if err := checkPort("rest", cfg.REST.RestPort); err != nil {
+			return err
+		}
+	}
+	if cfg.EnableProfile {
+		if err := checkPort("profile", cfg.ProfilePort); err != nil {
+			return err
+		}
+	}
+	if net.ParseIP(cfg.P2P.NetProtocolAddr) == nil {
+		return fmt.Errorf("invalid netprotocoladdr %s", cfg.P2P.NetProtocolAddr)
+	}
+	if cfg.P2P.NPEnableTLS {
+		if err := checkFileExists("npcert", cfg.P2P.NPCert); err != nil {
+			return err
+		}
+		if err := checkFileExists("npcertkey", cfg.P2P.NPCertKey); err != nil {
+			return err
+		}
+	}
+	if cfg.RPC.NSEnableTLS {
+		if err := checkFileExists("nscert", cfg.RPC.NSCert); err != nil {
+			return err
+		}
+		if err := checkFileExists("nskey", cfg.RPC.NSKey); err != nil {
+			return err
+		}
+	}
+	if cfg.Consensus.BlockInterval <= 0 {
+		return fmt.Errorf("invalid blockinterval %d", cfg.Consensus.BlockInterval)
+	}
+	if cfg.Consensus.EnableDpos {
+		if _, err := types.DecodePeerIDs("bpids", cfg.Consensus.BpIds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadKeyFile(path string) error {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := crypto.UnmarshalPrivateKey(dat); err != nil {
+		return fmt.Errorf("invalid private key in %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+func checkKey(cfg *config.Config) error {
+	switch {
+	case cfg.P2P.NPKeystore != "":
+		// decrypting keystore needs the passphrase, which is asked on starting node
+		return checkFileExists("npkeystore", cfg.P2P.NPKeystore)
+	case cfg.P2P.NPKey != "":
+		return loadKeyFile(cfg.P2P.NPKey)
+	case cfg.DataDir != "":
+		// the default key file is generated on starting node if it does not exist yet
+		path := filepath.Join(cfg.DataDir, p2p.DefaultPeerKeyFile)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+		return loadKeyFile(path)
+	}
+	return nil
+}
+
+func checkDatabase(cfg *config.Config) error {
+	return blockchain.CheckDB(cfg.DataDir)
+}
+
+func checkGenesis(cfg *config.Config) error {
+	genesis, err := blockchain.LoadGenesis(&cfg.BaseConfig)
+	if err != nil {
+		return err
+	}
+	return blockchain.CheckGenesis(cfg.DataDir, genesis)
+}
+
+func checkBindable(addr string, port int) error {
+	l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return l.Close()
+}
+
+func checkPorts(cfg *config.Config) error {
+	if err := checkBindable(cfg.RPC.NetServiceAddr, cfg.RPC.NetServicePort); err != nil {
+		return err
+	}
+	if err := checkBindable(cfg.P2P.NetProtocolAddr, cfg.P2P.NetProtocolPort); err != nil {
+		return err
+	}
+	if cfg.EnableRest {
+		if err := checkBindable("", cfg.REST.RestPort); err != nil {
+			return err
+		}
+	}
+	if cfg.EnableProfile {
+		if err := checkBindable("0.0.0.0", cfg.ProfilePort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
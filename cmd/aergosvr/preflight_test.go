@@ -0,0 +1,85 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aergoio/aergo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func newPreflightConfig(t *testing.T) (*config.Config, func()) {
+	dataDir, err := ioutil.TempDir("", "preflight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := config.NewServerContext("", "").GetDefaultConfig().(*config.Config)
+	conf.DataDir = dataDir
+	conf.RPC.NetServiceAddr = "127.0.0.1"
+	conf.RPC.NetServicePort = freePort(t)
+	conf.P2P.NetProtocolAddr = "127.0.0.1"
+	conf.P2P.NetProtocolPort = freePort(t)
+	return conf, func() { os.RemoveAll(dataDir) }
+}
+
+func TestRunPreflight(t *testing.T) {
+	conf, cleanup := newPreflightConfig(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	assert.True(t, runPreflight(conf, &out), out.String())
+	for _, c := range preflightChecks {
+		assert.Contains(t, out.String(), "[PASS] "+c.name)
+	}
+}
+
+func TestRunPreflight_portInUse(t *testing.T) {
+	conf, cleanup := newPreflightConfig(t)
+	defer cleanup()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conf.P2P.NetProtocolPort = l.Addr().(*net.TCPAddr).Port
+
+	var out bytes.Buffer
+	assert.False(t, runPreflight(conf, &out))
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.Contains(line, "ports") {
+			assert.True(t, strings.HasPrefix(line, "[FAIL] ports"), line)
+		} else {
+			assert.True(t, strings.HasPrefix(line, "[PASS]"), line)
+		}
+	}
+}
+
+func TestRunPreflight_fastSync(t *testing.T) {
+	conf, cleanup := newPreflightConfig(t)
+	defer cleanup()
+
+	// fast sync is refused until state snapshots can be imported
+	conf.Blockchain.SyncMode = config.SyncModeFast
+	var out bytes.Buffer
+	assert.False(t, runPreflight(conf, &out))
+	assert.Contains(t, out.String(), "[FAIL] config: syncmode fast")
+	assert.Contains(t, out.String(), "snapshot import")
+}
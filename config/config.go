@@ -48,7 +48,7 @@ func (ctx *ServerContext) GetDefaultConfig() interface{} {
 func (ctx *ServerContext) GetDefaultBaseConfig() BaseConfig {
 	return BaseConfig{
 		DataDir:       ctx.ExpandPathEnv("$HOME/data"),
-		GenesisPath:   ctx.ExpandPathEnv("$HOME/data/genesis.block"),
+		Network:       NetworkPrivate,
 		GenesisSeed:   1530838800, // time.Parse(time.RFC3339, "2018-07-06T10:00:00+09:00")
 		EnableProfile: false,
 		ProfilePort:   6060,
@@ -76,15 +76,44 @@ func (ctx *ServerContext) GetDefaultP2PConfig() *P2PConfig {
 		NetProtocolPort: 7846,
 		NPEnableTLS:     false,
 		NPCert:          "",
+		NPCertKey:       "",
 		NPKey:           "",
+		NPKeystore:      "",
 		NPAddPeers:      []string{},
 		NPMaxPeers:      100,
 		NPPeerPool:      100,
+		PeerAddrTTL:     600,
+		RelayAddrs:      []string{},
+		EnableRelay:     false,
+
+		MaxStreamsPerPeer:       8,
+		MaxConcurrentHandshakes: 16,
+		ConfirmedTxCacheSize:    10000,
+		NPVerifyMessages:        true,
+		DesignatedRetry:         -1,
+		DiscoveredRetry:         3,
+		NPMinPeerScore:          -100,
+		MaxAddressesPerResponse: 50,
+		MaxHeadersPerResponse:   1000,
+		NPAddrRefreshInterval:   180,
+		NPAddrRequestSize:       20,
+		NPMaxTxNoticeBatch:      1000,
+		NPReconnectInitialSec:   20,
+		NPReconnectIncrease:     0.6,
+		NPReconnectMaxTrial:     15,
+		NPKeepAliveInterval:     60,
+		NPPingInterval:          60,
 	}
 }
 
 func (ctx *ServerContext) GetDefaultBlockchainConfig() *BlockchainConfig {
-	return &BlockchainConfig{}
+	return &BlockchainConfig{
+		DBGCInterval: 600,
+		DBGCRatio:    0.5,
+		StateCache:   128,
+		AccountCache: 65536,
+		SyncMode:     SyncModeFull,
+	}
 }
 
 func (ctx *ServerContext) GetDefaultMempoolConfig() *MempoolConfig {
@@ -96,8 +125,12 @@ func (ctx *ServerContext) GetDefaultMempoolConfig() *MempoolConfig {
 
 func (ctx *ServerContext) GetDefaultConsensusConfig() *ConsensusConfig {
 	return &ConsensusConfig{
-		EnableBp:      true,
-		BlockInterval: consensus.DefaultBlockIntervalSec,
-		BpIds:         []string{},
+		EnableBp:           true,
+		BlockInterval:      consensus.DefaultBlockIntervalSec,
+		BpIds:              []string{},
+		NTPServer:          "",
+		SlotSkipAlert:      3,
+		SlotQueueMax:       100,
+		ProduceEmptyBlocks: true,
 	}
 }
@@ -0,0 +1,83 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package config
+
+import (
+	"fmt"
+)
+
+// Names of networks which BaseConfig.Network accepts.
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	// NetworkPrivate uses genesisseed and npaddpeers as configured.
+	NetworkPrivate = "private"
+)
+
+// NetworkPreset is a built-in network, which defines its genesis and the peers to join it.
+type NetworkPreset struct {
+	Name string
+	// ChainID is committed to the genesis block, so that the genesis of each network is distinct
+	ChainID string
+	// GenesisSeed is the timestamp of the genesis block of the network
+	GenesisSeed int64
+	// Seeds are the peers connected at startup. Their hosts are resolved by dns, so that the seed nodes can
+	// move without releasing a new version. No seed node is published yet, so npaddpeers must be configured to
+	// join a preset network for now.
+	Seeds []string
+}
+
+var networkPresets = map[string]*NetworkPreset{
+	NetworkMainnet: {
+		Name:        NetworkMainnet,
+		ChainID:     "aergo.io/mainnet",
+		GenesisSeed: 1541030400, // time.Parse(time.RFC3339, "2018-11-01T00:00:00Z")
+	},
+	NetworkTestnet: {
+		Name:        NetworkTestnet,
+		ChainID:     "aergo.io/testnet",
+		GenesisSeed: 1538352000, // time.Parse(time.RFC3339, "2018-10-01T00:00:00Z")
+	},
+}
+
+// GetNetworkPreset returns the preset of network name. It returns nil for private network, which has no preset.
+func GetNetworkPreset(name string) (*NetworkPreset, error) {
+	if name == "" || name == NetworkPrivate {
+		return nil, nil
+	}
+	preset, found := networkPresets[name]
+	if !found {
+		return nil, fmt.Errorf("unknown network %s, expected one of %s, %s or %s", name, NetworkMainnet,
+			NetworkTestnet, NetworkPrivate)
+	}
+	return preset, nil
+}
+
+// ApplyNetwork sets the genesis seed and the peers to connect of the preset network, which is selected by
+// Network. Explicit GenesisPath and non-empty NPAddPeers take precedence over the preset.
+func (cfg *Config) ApplyNetwork() error {
+	preset, err := GetNetworkPreset(cfg.Network)
+	if err != nil || preset == nil {
+		return err
+	}
+	if cfg.GenesisPath == "" {
+		cfg.GenesisSeed = preset.GenesisSeed
+	}
+	if cfg.P2P != nil && len(cfg.P2P.NPAddPeers) == 0 && len(preset.Seeds) > 0 {
+		cfg.P2P.NPAddPeers = append([]string{}, preset.Seeds...)
+	}
+	return nil
+}
+
+// ChainID returns the id of the chain which the node joins. It is committed to the genesis block of preset network,
+// and exchanged on handshake to refuse the peers of another chain. Private network is identified by its genesis
+// seed.
+func (cfg *BaseConfig) ChainID() string {
+	if preset, _ := GetNetworkPreset(cfg.Network); preset != nil {
+		return preset.ChainID
+	}
+	return fmt.Sprintf("%s/%d", NetworkPrivate, cfg.GenesisSeed)
+}
@@ -0,0 +1,52 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyNetwork(t *testing.T) {
+	newConf := func(network string) *Config {
+		conf := NewServerContext("", "").GetDefaultConfig().(*Config)
+		conf.Network = network
+		conf.GenesisPath = ""
+		return conf
+	}
+
+	testnet := newConf(NetworkTestnet)
+	assert.Nil(t, testnet.ApplyNetwork())
+	assert.Equal(t, networkPresets[NetworkTestnet].GenesisSeed, testnet.GenesisSeed)
+	// no seed is published yet
+	assert.Empty(t, testnet.P2P.NPAddPeers)
+	assert.Equal(t, "aergo.io/testnet", testnet.ChainID())
+	assert.NotEqual(t, testnet.ChainID(), newConf(NetworkMainnet).ChainID())
+
+	// private network keeps configured values
+	private := newConf(NetworkPrivate)
+	seed := private.GenesisSeed
+	assert.Nil(t, private.ApplyNetwork())
+	assert.Equal(t, seed, private.GenesisSeed)
+	assert.Empty(t, private.P2P.NPAddPeers)
+
+	// explicit peers and genesis file take precedence
+	dir, err := ioutil.TempDir("", "network")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	overridden := newConf(NetworkTestnet)
+	overridden.GenesisPath = filepath.Join(dir, "genesis.json")
+	assert.Nil(t, ioutil.WriteFile(overridden.GenesisPath, []byte(`{"timestamp":1}`), 0644))
+	overridden.P2P.NPAddPeers = []string{"127.0.0.1:7846@16Uiu2HAmHuBgtnisgPLbujFvxPNZw3Qvpk3VLUwTzh5C67LAZSFh"}
+	assert.Nil(t, overridden.ApplyNetwork())
+	assert.Equal(t, seed, overridden.GenesisSeed)
+	assert.Equal(t, 1, len(overridden.P2P.NPAddPeers))
+
+	assert.NotNil(t, newConf("unknown").ApplyNetwork())
+}
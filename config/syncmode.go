@@ -0,0 +1,30 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package config
+
+import "fmt"
+
+// Modes of BlockchainConfig.SyncMode, which decides how a node catches up the chain.
+const (
+	// SyncModeFull executes all the blocks from genesis, keeping the whole history of states.
+	SyncModeFull = "full"
+	// SyncModeFast would start from a state snapshot of a trusted recent block, verified against a checkpoint,
+	// skipping the execution of the blocks before it. It needs a state snapshot import, which is not available
+	// yet, so it's rejected for now.
+	SyncModeFast = "fast"
+)
+
+// CheckSyncMode returns an error if SyncMode is not supported. Empty SyncMode is of SyncModeFull.
+func (cfg *BlockchainConfig) CheckSyncMode() error {
+	switch cfg.SyncMode {
+	case "", SyncModeFull:
+		return nil
+	case SyncModeFast:
+		return fmt.Errorf("syncmode %s is not supported yet, since state snapshot import is not available", SyncModeFast)
+	default:
+		return fmt.Errorf("unknown syncmode %s, expected %s", cfg.SyncMode, SyncModeFull)
+	}
+}
@@ -0,0 +1,27 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSyncMode(t *testing.T) {
+	conf := NewServerContext("", "").GetDefaultConfig().(*Config)
+	assert.Equal(t, SyncModeFull, conf.Blockchain.SyncMode)
+	assert.Nil(t, conf.Blockchain.CheckSyncMode())
+
+	for mode, valid := range map[string]bool{"": true, SyncModeFull: true, SyncModeFast: false, "light": false} {
+		assert.Equal(t, valid, (&BlockchainConfig{SyncMode: mode}).CheckSyncMode() == nil, mode)
+	}
+
+	// the reason of refusing fast sync is told
+	err := (&BlockchainConfig{SyncMode: SyncModeFast}).CheckSyncMode()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "state snapshot import is not available")
+	}
+}
@@ -25,7 +25,8 @@ type Config struct {
 // BaseConfig defines base configurations for aergo server
 type BaseConfig struct {
 	DataDir       string `mapstructure:"datadir" description:"Directory to store datafiles"`
-	GenesisPath   string `mapstructure:"genesispath" description:"Genesis Block File Location"`
+	Network       string `mapstructure:"network" description:"Network to join; mainnet, testnet or private. Preset network selects its genesis and seed peers, unless genesispath or npaddpeers is given"`
+	GenesisPath   string `mapstructure:"genesispath" description:"Genesis file in json. If it is empty, genesis is made of the preset network or genesisseed"`
 	GenesisSeed   int64  `mapstructure:"genesisseed" description:"Generate Genesis Block using a single long seed"`
 	EnableProfile bool   `mapstructure:"enableprofile" description:"enable profiling"`
 	ProfilePort   int    `mapstructure:"profileport" description:"profiling port(default:6060)"`
@@ -56,15 +57,44 @@ type P2PConfig struct {
 	NetProtocolPort int      `mapstructure:"netprotocolport" description:"N2N network protocol port"`
 	NPEnableTLS     bool     `mapstructure:"nptls" description:"Enable TLS on N2N network"`
 	NPCert          string   `mapstructure:"npcert" description:"Certificate file for N2N network"`
+	NPCertKey       string   `mapstructure:"npcertkey" description:"Private key file of npcert, in PEM format"`
 	NPKey           string   `mapstructure:"npkey" description:"Private Key file for N2N network"`
+	NPKeystore      string   `mapstructure:"npkeystore" description:"Encrypted keystore file of private key for N2N network and block production. It is used instead of npkey if set"`
 	NPAddPeers      []string `mapstructure:"npaddpeers" description:"Add peers to connect with at startup"`
 	NPMaxPeers      int      `mapstructure:"npmaxpeers" description:"Maximum number of remote peers to keep"`
 	NPPeerPool      int      `mapstructure:"nppeerpool" description:"Max peer pool size"`
+	PeerAddrTTL     int      `mapstructure:"peeraddrttl" description:"TTL (sec) of discovered peer address in peerstore. Designated peers are kept permanently"`
+	RelayAddrs      []string `mapstructure:"relayaddrs" description:"Relay peers to dial via, when remote peer cannot be dialed directly"`
+	EnableRelay     bool     `mapstructure:"enablerelay" description:"Act as a relay for other peers"`
+
+	MaxStreamsPerPeer       int     `mapstructure:"maxstreamsperpeer" description:"Maximum number of inbound streams handled concurrently for a peer. 0 means unlimited"`
+	MaxConcurrentHandshakes int     `mapstructure:"maxconcurrenthandshakes" description:"Maximum number of inbound handshakes in progress at once. Excess inbound connections are refused. 0 means unlimited"`
+	ConfirmedTxCacheSize    int     `mapstructure:"confirmedtxcachesize" description:"Number of recently confirmed txs to ignore notices of. 0 disables it"`
+	NPVerifyMessages        bool    `mapstructure:"npverifymessages" description:"Verify signatures of incoming p2p messages and drop the invalid ones. Disable it only while migrating peers that do not sign"`
+	DesignatedRetry         int     `mapstructure:"designatedretry" description:"Number of reconnect trials to a disconnected designated peer. Negative value means retrying indefinitely"`
+	DiscoveredRetry         int     `mapstructure:"discoveredretry" description:"Number of reconnect trials to a disconnected peer discovered from other peers, before it is dropped from peer pool. 0 disables reconnecting"`
+	NPAddrRefreshInterval   int     `mapstructure:"npaddrrefreshinterval" description:"Interval (sec) of requesting peer addresses to connected peers, while peer pool is not full"`
+	NPAddrRequestSize       int     `mapstructure:"npaddrrequestsize" description:"Number of peer addresses requested to a peer at a time"`
+	MaxAddressesPerResponse int     `mapstructure:"maxaddressesperresponse" description:"Maximum number of peer addresses responded to an addresses request, regardless of the requested size"`
+	MaxHeadersPerResponse   int     `mapstructure:"maxheadersperresponse" description:"Maximum number of block headers responded to a block headers request, regardless of the requested size. The requester gets the rest by further requests"`
+	NPMinPeerScore          int     `mapstructure:"npminpeerscore" description:"Peer is disconnected and excluded from connecting for a while if its score drops below it. Score starts from 0, and is decreased by misbehaviors and increased by useful responses"`
+	NPMaxTxNoticeBatch      int     `mapstructure:"npmaxtxnoticebatch" description:"Maximum number of tx hashes in a new tx notice. More hashes are split into multiple notices"`
+	NPReconnectInitialSec   int     `mapstructure:"npreconnectinitialsec" description:"Interval (sec) before the first reconnect trial to a disconnected peer"`
+	NPReconnectIncrease     float64 `mapstructure:"npreconnectincrease" description:"Exponent increase of reconnect interval per trial. The interval of n-th trial is initialsec * e^(increase * n)"`
+	NPReconnectMaxTrial     int     `mapstructure:"npreconnectmaxtrial" description:"Number of trials during which reconnect interval increases. Later trials use the last interval. Whether to give up is decided by designatedretry and discoveredretry"`
+	NPKeepAliveInterval     int     `mapstructure:"npkeepaliveinterval" description:"Interval (sec) of TCP keep-alive probes on N2N connections, by which the os breaks the connection to a crashed host. 0 disables TCP keep-alive"`
+	NPPingInterval          int     `mapstructure:"nppinginterval" description:"Interval (sec) of pings to peers, by which the peer of a broken connection is detected and removed. 0 uses the default interval"`
 }
 
 // BlockchainConfig defines configurations for blockchain service
 type BlockchainConfig struct {
-	PlaceHolder bool `mapstructure:"blockchainplaceholder"`
+	PlaceHolder  bool    `mapstructure:"blockchainplaceholder"`
+	DBGCInterval int64   `mapstructure:"dbgcinterval" description:"interval of value log gc of state and contract db (sec). 0 disables gc"`
+	DBGCRatio    float64 `mapstructure:"dbgcratio" description:"value log file is rewritten by gc if its discardable portion is over this ratio"`
+	StateBuffer  int     `mapstructure:"statebuffer" description:"number of blocks whose states are buffered in memory and written to state db at once. 0 writes the state of each block on applying it"`
+	StateCache   int     `mapstructure:"statecache" description:"number of recent block states cached in memory, which are read again by rollback and reorg. 0 disables the cache"`
+	AccountCache int     `mapstructure:"accountcache" description:"number of account states cached in memory, evicting the least recently used ones. The accounts not yet written to state db are kept regardless. 0 disables the cache"`
+	SyncMode     string  `mapstructure:"syncmode" description:"how to catch up the chain. full executes all the blocks from genesis. fast, starting from a verified state snapshot of a recent block, is not supported yet"`
 }
 
 // MempoolConfig defines configurations for mempool service
@@ -75,10 +105,14 @@ type MempoolConfig struct {
 
 // ConsensusConfig defines configurations for consensus service
 type ConsensusConfig struct {
-	EnableBp      bool     `mapstructure:"enablebp" description:"enable block production"`
-	EnableDpos    bool     `mapstructure:"enabledpos" description:"enable DPoS consensus"`
-	BlockInterval int64    `mapstructure:"blockinterval" description:"block production interval (sec)"`
-	BpIds         []string `mapstructure:"bpids" description:"The IDs of the 23 block producers"`
+	EnableBp           bool     `mapstructure:"enablebp" description:"enable block production"`
+	EnableDpos         bool     `mapstructure:"enabledpos" description:"enable DPoS consensus"`
+	BlockInterval      int64    `mapstructure:"blockinterval" description:"block production interval (sec)"`
+	BpIds              []string `mapstructure:"bpids" description:"The IDs of the 23 block producers, used only if the genesis has no bps"`
+	NTPServer          string   `mapstructure:"ntpserver" description:"NTP server (host or host:port) to correct the clock for block production. Empty disables the correction"`
+	SlotSkipAlert      int      `mapstructure:"slotskipalert" description:"number of consecutive slots of this BP without block production, at which a critical alert is logged. 0 disables the alert"`
+	SlotQueueMax       int      `mapstructure:"slotqueuemax" description:"capacity of the queue of slots waiting for block production. A warning is logged when it is nearly full"`
+	ProduceEmptyBlocks bool     `mapstructure:"produceemptyblocks" description:"produce a block even if there is no tx. If false, slots without txs are passed, which saves storage of low-traffic chains, but the time of the best block no longer tells whether the BPs are alive, and the next block is produced late in its slot"`
 }
 
 /*
@@ -98,6 +132,7 @@ map = does not support
 const tomlConfigFileTemplate = `# aergo TOML Configuration File (https://github.com/toml-lang/toml)
 # base configurations
 datadir = "{{.BaseConfig.DataDir}}"
+network = "{{.BaseConfig.Network}}"
 genesispath = "{{.BaseConfig.GenesisPath}}"
 genesisseed = {{.BaseConfig.GenesisSeed}} # unix time
 enableprofile = {{.BaseConfig.EnableProfile}}
@@ -120,16 +155,46 @@ netprotocoladdr = "{{.P2P.NetProtocolAddr}}"
 netprotocolport = {{.P2P.NetProtocolPort}}
 nptls = {{.P2P.NPEnableTLS}}
 npcert = "{{.P2P.NPCert}}"
+npcertkey = "{{.P2P.NPCertKey}}"
 npkey = "{{.P2P.NPKey}}"
+npkeystore = "{{.P2P.NPKeystore}}"
 npaddpeers = [{{range .P2P.NPAddPeers}}
 "{{.}}", {{end}}
 ]
 npmaxpeers = "{{.P2P.NPMaxPeers}}"
 nppeerpool = "{{.P2P.NPPeerPool}}"
+peeraddrttl = "{{.P2P.PeerAddrTTL}}"
+relayaddrs = [{{range .P2P.RelayAddrs}}
+"{{.}}", {{end}}
+]
+enablerelay = {{.P2P.EnableRelay}}
+maxstreamsperpeer = {{.P2P.MaxStreamsPerPeer}}
+maxconcurrenthandshakes = {{.P2P.MaxConcurrentHandshakes}}
+confirmedtxcachesize = {{.P2P.ConfirmedTxCacheSize}}
+npverifymessages = {{.P2P.NPVerifyMessages}}
+designatedretry = {{.P2P.DesignatedRetry}}
+discoveredretry = {{.P2P.DiscoveredRetry}}
+npminpeerscore = {{.P2P.NPMinPeerScore}}
+maxaddressesperresponse = {{.P2P.MaxAddressesPerResponse}}
+maxheadersperresponse = {{.P2P.MaxHeadersPerResponse}}
+npaddrrefreshinterval = {{.P2P.NPAddrRefreshInterval}}
+npaddrrequestsize = {{.P2P.NPAddrRequestSize}}
+npmaxtxnoticebatch = {{.P2P.NPMaxTxNoticeBatch}}
+npreconnectinitialsec = {{.P2P.NPReconnectInitialSec}}
+npreconnectincrease = {{.P2P.NPReconnectIncrease}}
+npreconnectmaxtrial = {{.P2P.NPReconnectMaxTrial}}
+npkeepaliveinterval = {{.P2P.NPKeepAliveInterval}}
+nppinginterval = {{.P2P.NPPingInterval}}
 
 [blockchain]
 # blockchain configurations
 blockchainplaceholder = {{.Blockchain.PlaceHolder}}
+dbgcinterval = {{.Blockchain.DBGCInterval}}
+dbgcratio = {{.Blockchain.DBGCRatio}}
+statebuffer = {{.Blockchain.StateBuffer}}
+statecache = {{.Blockchain.StateCache}}
+accountcache = {{.Blockchain.AccountCache}}
+syncmode = "{{.Blockchain.SyncMode}}"
 
 [mempool]
 showmetrics = {{.Mempool.ShowMetrics}}
@@ -142,4 +207,8 @@ blockinterval = {{.Consensus.BlockInterval}}
 bpids = [{{range .Consensus.BpIds}}
 "{{.}}", {{end}}
 ]
+ntpserver = "{{.Consensus.NTPServer}}"
+slotskipalert = {{.Consensus.SlotSkipAlert}}
+slotqueuemax = {{.Consensus.SlotQueueMax}}
+produceemptyblocks = {{.Consensus.ProduceEmptyBlocks}}
 `
@@ -2,7 +2,10 @@ package chain
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/aergoio/aergo/blockchain"
+	"github.com/aergoio/aergo/internal/enc"
 	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
 	"github.com/golang/protobuf/proto"
@@ -13,8 +16,20 @@ var (
 	ErrQuit = errors.New("shutdown initiated")
 
 	errBlockSizeLimit = errors.New("the transactions included exceeded the block size limit")
+	errTxSignNotMatch = errors.New("tx signature does not match its account")
+	errNoStateRoot    = errors.New("no state root is computed for the block")
 )
 
+// errTxInvalid indicates that a tx is invalid. Such a tx is dropped by
+// GatherTXs, rather than stopping the selection.
+type errTxInvalid struct {
+	err error
+}
+
+func (e errTxInvalid) Error() string {
+	return "invalid tx: " + e.err.Error()
+}
+
 // TxOp is an interface used by GatherTXs for apply some transaction related operation.
 type TxOp interface {
 	Apply(tx *types.Tx) error
@@ -53,31 +68,111 @@ func NewBlockLimitOp(maxBlockBodySize int) TxOpFn {
 	})
 }
 
+// NewTxVerifyOp returns a TxOpFn which rejects a tx whose signature is not
+// made by its account, or whose nonce is out of order. The nonces of an
+// account are expected to increase by one from the one of its latest state.
+// A contract call whose sender can't afford the fee of its gas limit is
+// rejected as well. Since the selected nonces and balances are kept, a new one
+// must be made for each block.
+func NewTxVerifyOp(hs component.ICompSyncRequester) TxOpFn {
+	nonces := make(map[types.AccountID]uint64)
+	balances := make(map[types.AccountID]uint64)
+	return TxOpFn(func(tx *types.Tx) error {
+		body := tx.GetBody()
+		if body == nil {
+			return errTxInvalid{err: errors.New("no tx body")}
+		}
+
+		valid, err := tx.VerifySign()
+		if err != nil {
+			return errTxInvalid{err: err}
+		}
+		if !valid {
+			return errTxInvalid{err: errTxSignNotMatch}
+		}
+
+		id := types.ToAccountID(body.Account)
+		next, exist := nonces[id]
+		if !exist {
+			state, err := GetAccountState(hs, body.Account)
+			if err != nil {
+				return errTxInvalid{err: err}
+			}
+			next = state.GetNonce() + 1
+			nonces[id] = next
+			balances[id] = state.GetBalance()
+		}
+		if body.Nonce != next {
+			return errTxInvalid{err: fmt.Errorf("nonce %v out of order (expected: %v)", body.Nonce, next)}
+		}
+		// the executor transfers the amount first, and then requires the fee of the gas limit
+		var balance uint64
+		if body.Amount <= balances[id] {
+			balance = balances[id] - body.Amount
+		}
+		maxFee := blockchain.MaxFee(tx)
+		if balance < maxFee {
+			return errTxInvalid{err: blockchain.ErrInsufficientFee}
+		}
+		nonces[id] = next + 1
+		balances[id] = balance - maxFee
+
+		return nil
+	})
+}
+
 // GenerateBlock generate & return a new block
 func GenerateBlock(hs component.ICompSyncRequester, prevBlock *types.Block, txOp TxOp, ts int64) (*types.Block, error) {
 	txs, err := GatherTXs(hs, txOp)
 	if err != nil {
 		return nil, err
 	}
+	return NewBlockOfTXs(hs, prevBlock, txs, ts)
+}
 
+// NewBlockOfTXs returns a new block of txs on top of prevBlock. Its state root
+// is computed by the chain service.
+func NewBlockOfTXs(hs component.ICompSyncRequester, prevBlock *types.Block, txs []*types.Tx, ts int64) (*types.Block, error) {
 	block := types.NewBlock(prevBlock, txs, ts)
+	root, err := ComputeStateRoot(hs, block)
+	if err != nil {
+		return nil, err
+	}
+	block.SetStateRoot(root)
+	if len(root) == 0 {
+		// even the empty state has its root, so the block would be rejected by every node
+		return nil, errNoStateRoot
+	}
 
 	return block, nil
 }
 
 // GatherTXs returns transactions from txIn. The selection is done by applying
-// txDo.
+// txDo. A tx whose hash is already selected is skipped, so that the same tx is
+// never included twice in a block even if the mempool returns duplicates. A tx
+// rejected as invalid by txDo is dropped as well.
 func GatherTXs(hs component.ICompSyncRequester, txOp TxOp) ([]*types.Tx, error) {
 	txIn := FetchTXs(hs)
 	if len(txIn) == 0 {
 		return txIn, nil
 	}
 
-	end := 0
-	for i, tx := range txIn {
+	txRes := make([]*types.Tx, 0, len(txIn))
+	selected := make(map[types.TransactionID]bool, len(txIn))
+	rejected := 0
+	for _, tx := range txIn {
+		id := types.ToTransactionID(tx.GetHash())
+		if selected[id] {
+			logger.Warn().Str("hash", enc.ToString(tx.GetHash())).Msg("skip duplicate tx in block")
+			continue
+		}
 		err := txOp.Apply(tx)
 		if err == ErrQuit {
 			return nil, err
+		} else if _, invalid := err.(errTxInvalid); invalid {
+			logger.Debug().Err(err).Str("hash", enc.ToString(tx.GetHash())).Msg("drop invalid tx from block")
+			rejected++
+			continue
 		} else if err != nil {
 			// Actually, this is not an error. Here the error is used to
 			// indicate the block production timeout.
@@ -86,8 +181,12 @@ func GatherTXs(hs component.ICompSyncRequester, txOp TxOp) ([]*types.Tx, error)
 			// ErrQuit. Later skip conditions may be needed.
 			break
 		}
-		end = i
+		selected[id] = true
+		txRes = append(txRes, tx)
+	}
+	if rejected > 0 {
+		logger.Info().Int("rejected", rejected).Int("selected", len(txRes)).Msg("invalid txs are rejected from block")
 	}
 
-	return txIn[0 : end+1], nil
+	return txRes, nil
 }
@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/aergoio/aergo-actor/actor"
+	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,3 +40,121 @@ func TestGatherTXsWithError(t *testing.T) {
 	err := txDo.Apply(nil)
 	assert.New(t).NotNil(err)
 }
+
+// testRequester responds to the requests of block generation, without actors.
+type testRequester struct {
+	txs    []*types.Tx
+	states map[types.AccountID]*types.State
+	noRoot bool
+}
+
+func (r *testRequester) RequestFuture(targetName string, msg interface{}, timeout time.Duration, tip string) *actor.Future {
+	future := actor.NewFuture(timeout)
+	switch m := msg.(type) {
+	case *message.MemPoolGet:
+		future.PID().Tell(&message.MemPoolGetRsp{Txs: r.txs})
+	case *message.ComputeStateRoot:
+		if r.noRoot {
+			future.PID().Tell(message.ComputeStateRootRsp{})
+		} else {
+			future.PID().Tell(message.ComputeStateRootRsp{Root: []byte("root")})
+		}
+	case *message.GetState:
+		state, exist := r.states[types.ToAccountID(m.Account)]
+		if !exist {
+			state = &types.State{}
+		}
+		future.PID().Tell(message.GetStateRsp{State: state})
+	}
+	return future
+}
+
+func TestGenerateBlockDuplicateTx(t *testing.T) {
+	newTx := func(nonce uint64) *types.Tx {
+		tx := &types.Tx{Body: &types.TxBody{Nonce: nonce}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	tx1, tx2, tx3 := newTx(1), newTx(2), newTx(3)
+	dup := newTx(2)
+	hs := &testRequester{txs: []*types.Tx{tx1, tx2, dup, tx3, tx1}}
+
+	applied := 0
+	txOp := NewCompTxOp(func(tx *types.Tx) error {
+		applied++
+		return nil
+	})
+	block, err := GenerateBlock(hs, types.NewBlock(nil, nil, 0), txOp, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.Tx{tx1, tx2, tx3}, block.GetBody().GetTxs())
+	// duplicates are skipped before applied
+	assert.Equal(t, 3, applied)
+}
+
+func TestGenerateBlockStateRoot(t *testing.T) {
+	hs := &testRequester{}
+	block, err := GenerateBlock(hs, types.NewBlock(nil, nil, 0), NewCompTxOp(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("root"), block.GetHeader().GetStateRootHash())
+
+	hs.noRoot = true
+	_, err = GenerateBlock(hs, types.NewBlock(nil, nil, 0), NewCompTxOp(), 1)
+	assert.Equal(t, errNoStateRoot, err)
+}
+
+func TestGenerateBlockInvalidTx(t *testing.T) {
+	newKey := func() (*btcec.PrivateKey, []byte) {
+		key, err := btcec.NewPrivateKey(btcec.S256())
+		assert.Nil(t, err)
+		return key, types.AddressFromPubKey(&key.PublicKey)
+	}
+	signedTx := func(key *btcec.PrivateKey, account []byte, nonce uint64) *types.Tx {
+		tx := &types.Tx{Body: &types.TxBody{Account: account, Nonce: nonce}}
+		assert.Nil(t, tx.Sign(key))
+		return tx
+	}
+	key1, acc1 := newKey()
+	key2, acc2 := newKey()
+
+	ok1, ok2, ok3 := signedTx(key1, acc1, 5), signedTx(key1, acc1, 6), signedTx(key2, acc2, 1)
+	// signed by the key of other account
+	forged := signedTx(key2, acc1, 7)
+	// nonce already used or skipping one
+	used, gap := signedTx(key1, acc1, 4), signedTx(key2, acc2, 3)
+	unsigned := &types.Tx{Body: &types.TxBody{Account: acc2, Nonce: 2}}
+	unsigned.Hash = unsigned.CalculateTxHash()
+
+	hs := &testRequester{
+		txs: []*types.Tx{ok1, forged, ok2, ok3, used, unsigned, gap},
+		states: map[types.AccountID]*types.State{
+			types.ToAccountID(acc1): {Nonce: 4},
+		},
+	}
+	block, err := GenerateBlock(hs, types.NewBlock(nil, nil, 0), NewTxVerifyOp(hs), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.Tx{ok1, ok2, ok3}, block.GetBody().GetTxs())
+}
+
+func TestGenerateBlockUnaffordableFee(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	account := types.AddressFromPubKey(&key.PublicKey)
+	callTx := func(nonce, amount, limit uint64) *types.Tx {
+		tx := &types.Tx{Body: &types.TxBody{Account: account, Nonce: nonce, Amount: amount,
+			Recipient: []byte("contract"), Payload: []byte(`{"Name":"f"}`), Limit: limit, Price: 10}}
+		assert.Nil(t, tx.Sign(key))
+		return tx
+	}
+	// the balance of 1000 pays 100 of amount and the fee of 80 gas, but not the fee of 20 gas more
+	ok1, ok2, unaffordable := callTx(1, 100, 50), callTx(2, 0, 30), callTx(3, 0, 20)
+
+	hs := &testRequester{
+		txs: []*types.Tx{ok1, ok2, unaffordable},
+		states: map[types.AccountID]*types.State{
+			types.ToAccountID(account): {Balance: 1000},
+		},
+	}
+	block, err := GenerateBlock(hs, types.NewBlock(nil, nil, 0), NewTxVerifyOp(hs), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.Tx{ok1, ok2}, block.GetBody().GetTxs())
+}
@@ -30,18 +30,46 @@ func GetBestBlock(hs component.ICompSyncRequester) *types.Block {
 }
 
 // ConnectBlock send an AddBlock request to the chain service.
-func ConnectBlock(hs component.ICompSyncRequester, block *types.Block) {
-	_, err := hs.RequestFuture(message.ChainSvc, &message.AddBlock{PeerID: "", Block: block},
+func ConnectBlock(hs component.ICompSyncRequester, block *types.Block) error {
+	result, err := hs.RequestFuture(message.ChainSvc, &message.AddBlock{PeerID: "", Block: block},
 		time.Second, "consensus/util/info.ConnectBlock").Result()
+	if err == nil {
+		if rsp, ok := result.(message.AddBlockRsp); ok {
+			err = rsp.Err
+		}
+	}
 	if err != nil {
 
-		logger.Error().Uint64("no", block.Header.BlockNo).
+		logger.Error().Err(err).Uint64("no", block.Header.BlockNo).
 			Str("hash", block.ID()).
 			Str("prev", block.PrevID()).
 			Msg("failed to connect block")
 
-		return
+		return err
+	}
+	return nil
+}
+
+// ComputeStateRoot requests to the chain service the state root after the txs of block are applied.
+func ComputeStateRoot(hs component.ICompSyncRequester, block *types.Block) ([]byte, error) {
+	result, err := hs.RequestFuture(message.ChainSvc, &message.ComputeStateRoot{Block: block}, time.Second,
+		"consensus/util/info.ComputeStateRoot").Result()
+	if err != nil {
+		return nil, err
+	}
+	rsp := result.(message.ComputeStateRootRsp)
+	return rsp.Root, rsp.Err
+}
+
+// GetAccountState requests to the chain service the latest state of account.
+func GetAccountState(hs component.ICompSyncRequester, account []byte) (*types.State, error) {
+	result, err := hs.RequestFuture(message.ChainSvc, &message.GetState{Account: account}, time.Second,
+		"consensus/util/info.GetAccountState").Result()
+	if err != nil {
+		return nil, err
 	}
+	rsp := result.(message.GetStateRsp)
+	return rsp.State, rsp.Err
 }
 
 // FetchTXs requests to mempool and returns types.Tx array.
@@ -6,6 +6,8 @@
 package consensus
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -67,6 +69,43 @@ type ChainConsensus interface {
 	StatusUpdate()
 }
 
+// StatisticsProvider is implemented by the consensus which exposes its
+// internal statistics through the chain service.
+type StatisticsProvider interface {
+	Statistics() map[string]interface{}
+}
+
+// BpScheduleProvider is implemented by the consensus which assigns each time
+// slot to a block producer.
+type BpScheduleProvider interface {
+	// BpAssignment returns the block producer scheduled for the slot including
+	// ns (UNIX time in ns) and the actual producer of block. block is regarded
+	// as not produced unless it belongs to the slot.
+	BpAssignment(ns int64, block *types.Block) (*types.BpAssignment, error)
+	// CurrentProducer returns the ID of the block producer scheduled for the
+	// slot including now. It is empty if no producer is scheduled.
+	CurrentProducer(now time.Time) string
+	// NextSlotFor returns the beginning of the next slot assigned to bpID. It
+	// is the zero time if bpID is not a scheduled block producer.
+	NextSlotFor(bpID string) time.Time
+}
+
+// BlockOrderValidator is implemented by the consensus which validates a
+// block against its parent.
+type BlockOrderValidator interface {
+	// IsBlockOrderValid checks whether block can follow parent, such as by the
+	// order of their timestamps.
+	IsBlockOrderValid(block *types.Block, parent *types.Block) error
+}
+
+// ManualBlockProducer is implemented by the consensus which can produce a
+// block on demand, for development.
+type ManualBlockProducer interface {
+	// ProduceBlock produces a block on top of the best block immediately, and
+	// returns it after it is connected to the chain.
+	ProduceBlock(ctx context.Context) (*types.Block, error)
+}
+
 // BlockFactory is an interface for a block factory implementation.
 type BlockFactory interface {
 	Start()
@@ -96,7 +135,13 @@ func Start(c Consensus) {
 	}()
 }
 
-// Stop shutdown consensus service.
-func Stop(c Consensus) {
+// Stop shutdown consensus service. It fails if the service is already stopped.
+func Stop(c Consensus) error {
+	select {
+	case <-c.QuitChan():
+		return errors.New("consensus service is already stopped")
+	default:
+	}
 	close(c.QuitChan())
+	return nil
 }
@@ -6,7 +6,9 @@
 package dpos
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/aergoio/aergo-lib/log"
@@ -20,9 +22,21 @@ import (
 )
 
 const (
-	slotQueueMax = 100
+	// defaultSlotQueueMax is the capacity of the job queue unless configured.
+	defaultSlotQueueMax = 100
+	// slotQueueWarnPercent is the depth of the job queue in percentage of
+	// its capacity, at which the block production is warned to fall behind.
+	slotQueueWarnPercent = 80
 )
 
+// errEmptyBlock is returned when no block is produced since no tx is selected
+// and empty blocks are suppressed.
+var errEmptyBlock = errors.New("no tx to produce block")
+
+// errAbandoned is returned by the worker abandoned by the watchdog, which
+// must not connect the block it produced.
+var errAbandoned = errors.New("block factory worker is abandoned")
+
 type errTimeout struct {
 	kind    string
 	timeout int64
@@ -45,11 +59,33 @@ type BlockFactory struct {
 	maxBlockBodySize int
 	ID               string
 	privKey          crypto.PrivKey
-	txOp             chain.TxOp
+	// produce generates a block and connects it to the chain, unless the
+	// worker of generation gen is abandoned meanwhile.
+	produce func(bpi *bpInfo, gen uint32) error
+	// gatherTXs selects the txs of a block from mempool.
+	gatherTXs func(txOp chain.TxOp) ([]*types.Tx, error)
+	// produceEmpty makes a block produced even if no tx is selected.
+	produceEmpty bool
+
+	// workerGen is the generation of the running worker, which is increased
+	// when the worker is restarted. busySince and lastProduced are unix nano
+	// times. All of them must be accessed atomically.
+	workerGen    uint32
+	busySince    int64
+	lastProduced int64
+
+	// skippedSlots is the number of consecutive slots of this BP without block
+	// production, which is alerted at every skipAlert slots. It must be
+	// accessed atomically.
+	skippedSlots uint32
+	skipAlert    uint32
 }
 
 // NewBlockFactory returns a new BlockFactory
-func NewBlockFactory(hub *component.ComponentHub, id peer.ID, privKey crypto.PrivKey, quitC <-chan interface{}) *BlockFactory {
+func NewBlockFactory(hub *component.ComponentHub, id peer.ID, privKey crypto.PrivKey, skipAlert int, slotQueueMax int, produceEmpty bool, quitC <-chan interface{}) *BlockFactory {
+	if slotQueueMax <= 0 {
+		slotQueueMax = defaultSlotQueueMax
+	}
 	bf := &BlockFactory{
 		ComponentHub:     hub,
 		jobQueue:         make(chan interface{}, slotQueueMax),
@@ -58,17 +94,17 @@ func NewBlockFactory(hub *component.ComponentHub, id peer.ID, privKey crypto.Pri
 		maxBlockBodySize: chain.MaxBlockBodySize(),
 		ID:               enc.ToString([]byte(id)),
 		privKey:          privKey,
+		produceEmpty:     produceEmpty,
 		quit:             quitC,
 	}
+	if skipAlert > 0 {
+		bf.skipAlert = uint32(skipAlert)
+	}
 
-	bf.txOp = chain.NewCompTxOp(
-		// block size limit check
-		chain.NewBlockLimitOp(bf.maxBlockBodySize),
-		// timeout check
-		func(txIn *types.Tx) error {
-			return bf.checkBpTimeout()
-		},
-	)
+	bf.produce = bf.produceBlock
+	bf.gatherTXs = func(txOp chain.TxOp) ([]*types.Tx, error) {
+		return chain.GatherTXs(bf, txOp)
+	}
 
 	return bf
 }
@@ -78,6 +114,7 @@ func (bf *BlockFactory) Start() {
 	go func() {
 		go bf.worker()
 		go bf.controller()
+		go bf.watchdog()
 	}()
 }
 
@@ -86,6 +123,12 @@ func (bf *BlockFactory) JobQueue() chan<- interface{} {
 	return bf.jobQueue
 }
 
+// jobQueueNearlyFull reports whether the depth of jq reaches
+// slotQueueWarnPercent of its capacity.
+func jobQueueNearlyFull(jq chan<- interface{}) bool {
+	return cap(jq) > 0 && len(jq)*100 >= cap(jq)*slotQueueWarnPercent
+}
+
 func (bf *BlockFactory) controller() {
 	defer shutdownMsg("block factory controller")
 
@@ -108,6 +151,7 @@ func (bf *BlockFactory) controller() {
 			logger.Error().Msgf(
 				"skip block production for the slot %v (best block: %v) due to a pending job",
 				spew.Sdump(bpi.slot), bpi.bestBlock.ID())
+			bf.recordSkip(errPendingJob)
 		}
 		return nil
 	}
@@ -137,6 +181,7 @@ func (bf *BlockFactory) controller() {
 				return
 			} else if err != nil {
 				logger.Debug().Err(err).Msg("skip block production")
+				bf.recordSkip(err)
 				continue
 			}
 
@@ -151,18 +196,31 @@ func (bf *BlockFactory) controller() {
 func (bf *BlockFactory) worker() {
 	defer shutdownMsg("block factory worker")
 
+	gen := atomic.LoadUint32(&bf.workerGen)
 	for {
 		select {
 		case bpi := <-bf.workerQueue:
-			block, err := bf.generateBlock(bpi)
+			atomic.StoreInt64(&bf.busySince, time.Now().UnixNano())
+			err := bf.produce(bpi, gen)
+			if bf.abandoned(gen) {
+				logger.Info().Msg("stop the abandoned block factory worker")
+				return
+			}
+			atomic.StoreInt64(&bf.busySince, 0)
 			if err == chain.ErrQuit {
 				return
+			} else if err == errEmptyBlock {
+				// the slot is passed on purpose, which is not a failure of BP
+				logger.Debug().Msg("skip producing an empty block")
+				bf.resetSkip()
+				continue
 			} else if err != nil {
 				logger.Info().Err(err).Msg("failed to produce block")
+				bf.recordSkip(err)
 				continue
 			}
-
-			chain.ConnectBlock(bf, block)
+			atomic.StoreInt64(&bf.lastProduced, time.Now().UnixNano())
+			bf.resetSkip()
 
 		case <-bf.quit:
 			return
@@ -170,8 +228,54 @@ func (bf *BlockFactory) worker() {
 	}
 }
 
-func (bf *BlockFactory) generateBlock(bpi *bpInfo) (*types.Block, error) {
-	block, err := chain.GenerateBlock(bf, bpi.bestBlock, bf.txOp, bpi.slot.UnixNano())
+func (bf *BlockFactory) produceBlock(bpi *bpInfo, gen uint32) error {
+	block, err := bf.generateBlock(bpi, gen)
+	if err != nil {
+		return err
+	}
+	// the worker may be abandoned while the block is signed, and the new
+	// worker may produce a block of the same slot.
+	if bf.abandoned(gen) {
+		return errAbandoned
+	}
+	chain.ConnectBlock(bf, block)
+	return nil
+}
+
+// abandoned reports whether the worker of generation gen is replaced by the
+// watchdog.
+func (bf *BlockFactory) abandoned(gen uint32) bool {
+	return atomic.LoadUint32(&bf.workerGen) != gen
+}
+
+// txOp returns a new TxOp to select the txs of a block, since the ops keep the
+// state of the txs selected so far.
+func (bf *BlockFactory) txOp() chain.TxOp {
+	return chain.NewCompTxOp(
+		// signature and nonce check
+		chain.NewTxVerifyOp(bf),
+		// block size limit check
+		chain.NewBlockLimitOp(bf.maxBlockBodySize),
+		// timeout check
+		func(txIn *types.Tx) error {
+			return bf.checkBpTimeout()
+		},
+	)
+}
+
+func (bf *BlockFactory) generateBlock(bpi *bpInfo, gen uint32) (*types.Block, error) {
+	txs, err := bf.gatherTXs(bf.txOp())
+	if err != nil {
+		return nil, err
+	}
+	// a stuck tx op is the usual reason why the worker is abandoned
+	if bf.abandoned(gen) {
+		return nil, errAbandoned
+	}
+	if len(txs) == 0 && !bf.produceEmpty {
+		return nil, errEmptyBlock
+	}
+	block, err := chain.NewBlockOfTXs(bf, bpi.bestBlock, txs, bpi.slot.UnixNano())
 	if err != nil {
 		return nil, err
 	}
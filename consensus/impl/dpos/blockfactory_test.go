@@ -0,0 +1,84 @@
+package dpos
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/aergoio/aergo/consensus/chain"
+	"github.com/aergoio/aergo/consensus/impl/dpos/slot"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockFactory_slotQueueMax(t *testing.T) {
+	for _, tt := range []struct {
+		configured int
+		capacity   int
+	}{
+		{5, 5},
+		{1000, 1000},
+		{0, defaultSlotQueueMax},
+		{-1, defaultSlotQueueMax},
+	} {
+		bf := NewBlockFactory(nil, "", nil, 0, tt.configured, true, nil)
+		assert.Equal(t, tt.capacity, cap(bf.jobQueue), "configured %d", tt.configured)
+	}
+
+	bf := NewBlockFactory(nil, "", nil, 0, 5, true, nil)
+	jq := bf.JobQueue()
+	for depth := 0; depth < 5; depth++ {
+		// 80% of the capacity is nearly full
+		assert.Equal(t, depth >= 4, jobQueueNearlyFull(jq), "depth %d", depth)
+		jq <- &bpInfo{}
+	}
+	assert.True(t, jobQueueNearlyFull(jq))
+	select {
+	case jq <- &bpInfo{}:
+		t.Error("job queue must not exceed the configured capacity")
+	default:
+	}
+}
+
+func TestBlockFactory_suppressEmptyBlock(t *testing.T) {
+	quit := make(chan interface{})
+	defer close(quit)
+
+	// The hub is nil, so that producing a block panics unless it is suppressed.
+	bf := NewBlockFactory(nil, "", nil, 2, 0, false, quit)
+	gathered := make(chan bool, 1)
+	bf.gatherTXs = func(txOp chain.TxOp) ([]*types.Tx, error) {
+		gathered <- true
+		return nil, nil
+	}
+	bpi := &bpInfo{bestBlock: types.NewBlock(nil, nil, 0), slot: slot.Now()}
+	assert.Equal(t, errEmptyBlock, bf.produce(bpi, 0))
+	assert.Len(t, gathered, 1)
+
+	// The slot passed on purpose is not counted as skipped, and no block is
+	// recorded as produced.
+	atomic.StoreUint32(&bf.skippedSlots, 1)
+	go bf.worker()
+	bf.workerQueue <- bpi
+	<-gathered
+	// the next job is taken after the result of the previous one is recorded
+	bf.workerQueue <- bpi
+	<-gathered
+	assert.Equal(t, uint32(0), bf.SkippedSlots())
+	assert.True(t, bf.LastProduced().IsZero())
+}
+
+func TestBlockFactory_abandonedWorker(t *testing.T) {
+	quit := make(chan interface{})
+	defer close(quit)
+
+	// The hub is nil, so that connecting a block panics unless the abandoned
+	// worker gives it up.
+	bf := NewBlockFactory(nil, "", nil, 2, 0, true, quit)
+	bf.gatherTXs = func(txOp chain.TxOp) ([]*types.Tx, error) {
+		// the watchdog restarts the worker while the txs are gathered
+		atomic.AddUint32(&bf.workerGen, 1)
+		return nil, nil
+	}
+	bpi := &bpInfo{bestBlock: types.NewBlock(nil, nil, 0), slot: slot.Now()}
+	assert.Equal(t, errAbandoned, bf.produce(bpi, 0))
+}
@@ -8,6 +8,7 @@ package bp
 import (
 	"fmt"
 
+	"github.com/aergoio/aergo/types"
 	"github.com/libp2p/go-libp2p-peer"
 )
 
@@ -32,29 +33,28 @@ type blockProducer struct {
 	id peer.ID
 }
 
-// NewCluster returns a new bp.Cluster.
+// NewCluster returns a new bp.Cluster. It fails unless ids are exactly
+// blockProducers valid IDs without duplicates.
 func NewCluster(ids []string, blockProducers uint16) (*Cluster, error) {
+	bpIDs, err := types.DecodePeerIDs("bpids", ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(bpIDs) != int(blockProducers) {
+		return nil, errBpSize{required: blockProducers, given: uint16(len(bpIDs))}
+	}
+
 	c := &Cluster{
 		size:   blockProducers,
 		member: make(map[uint16]*blockProducer),
 		index:  make(map[peer.ID]uint16),
 	}
-
-	for i, id := range ids {
-		bpID, err := peer.IDB58Decode(id)
-		if err != nil {
-			return nil, fmt.Errorf("invalid node ID[%d]: %s", i, err.Error())
-		}
-
+	for i, bpID := range bpIDs {
 		index := uint16(i)
 		c.member[index] = newBlockProducer(bpID)
 		c.index[bpID] = index
 	}
 
-	if len(c.index) != int(blockProducers) {
-		return nil, errBpSize{required: blockProducers, given: uint16(len(ids))}
-	}
-
 	return c, nil
 }
 
@@ -3,6 +3,7 @@ package bp
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 
 	crypto "github.com/libp2p/go-libp2p-crypto"
@@ -61,3 +62,36 @@ func TestNewCluster(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, bpc, "Cluster alloc failed")
 }
+
+func TestNewClusterIDs(t *testing.T) {
+	ids := make([]string, 4)
+	for i := range ids {
+		_, pubKey, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+		assert.Nil(t, err)
+		id, err := peer.IDFromPublicKey(pubKey)
+		assert.Nil(t, err)
+		ids[i] = id.Pretty()
+	}
+
+	tcs := []struct {
+		name string
+		ids  []string
+		bad  string
+	}{
+		{"typo", []string{ids[0], ids[1][:5] + "0" + ids[1][6:], ids[2]}, "bpids[1]"},
+		{"empty", []string{ids[0], ids[1], ""}, "bpids[2]"},
+		{"duplicate", []string{ids[0], ids[1], ids[0]}, "bpids[2]"},
+	}
+	for _, tc := range tcs {
+		// the cluster of them is rejected even if the count matches
+		bpc, err := NewCluster(tc.ids, uint16(len(tc.ids)))
+		if assert.NotNil(t, err, tc.name) {
+			assert.True(t, strings.Contains(err.Error(), tc.bad), "%s: %v", tc.name, err)
+		}
+		assert.Nil(t, bpc, tc.name)
+	}
+
+	// the count must match the ring size
+	_, err := NewCluster(ids[:3], uint16(len(ids)))
+	assert.IsType(t, errBpSize{}, err)
+}
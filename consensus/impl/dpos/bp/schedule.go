@@ -0,0 +1,60 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package bp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Schedule keeps the history of the block producer clusters. Each cluster is
+// active from its starting time until the next one starts, so that the
+// producer scheduled for any past slot can be looked up.
+type Schedule struct {
+	sync.RWMutex
+	entries []scheduleEntry
+}
+
+type scheduleEntry struct {
+	fromNs  int64
+	cluster *Cluster
+}
+
+// NewSchedule returns a new Schedule, where genesis is active from the
+// beginning.
+func NewSchedule(genesis *Cluster) *Schedule {
+	return &Schedule{entries: []scheduleEntry{{fromNs: 0, cluster: genesis}}}
+}
+
+// Add registers c as the cluster active from fromNs (UNIX time in ns), which
+// must be later than the ones already added. It is only for a change of the
+// block producer set agreed by the whole network, such as by governance, and
+// persisted, so that every node switches at the same time even after restart.
+func (s *Schedule) Add(fromNs int64, c *Cluster) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if last := s.entries[len(s.entries)-1]; fromNs <= last.fromNs {
+		return fmt.Errorf("cluster must start after the last one: %v (last: %v)", fromNs, last.fromNs)
+	}
+	s.entries = append(s.entries, scheduleEntry{fromNs: fromNs, cluster: c})
+	return nil
+}
+
+// At returns the cluster which was active at ns (UNIX time in ns).
+func (s *Schedule) At(ns int64) *Cluster {
+	s.RLock()
+	defer s.RUnlock()
+
+	i := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].fromNs > ns
+	})
+	if i == 0 {
+		return s.entries[0].cluster
+	}
+	return s.entries[i-1].cluster
+}
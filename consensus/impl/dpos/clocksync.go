@@ -0,0 +1,118 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package dpos
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/aergoio/aergo/consensus/impl/dpos/slot"
+)
+
+const (
+	clockSyncInterval = time.Minute * 10
+	ntpTimeout        = time.Second * 5
+	// clockDriftWarn is the threshold of the local clock drift to warn.
+	clockDriftWarn = time.Millisecond * 100
+
+	ntpPacketSize = 48
+	// ntpEpochOffset is the seconds from 1900-01-01 (NTP epoch) to 1970-01-01 (UNIX epoch).
+	ntpEpochOffset = 2208988800
+)
+
+var (
+	errNTPShortPacket = errors.New("short ntp response")
+	errNTPInvalid     = errors.New("invalid ntp response")
+)
+
+// runClockSync corrects the clock of the slot scheduler periodically by the
+// offset estimated from ntpServer, until quit is closed.
+func runClockSync(ntpServer string, quit <-chan interface{}) {
+	ticker := time.NewTicker(clockSyncInterval)
+	defer ticker.Stop()
+	for {
+		syncClock(ntpServer)
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
+
+func syncClock(ntpServer string) {
+	offset, err := queryClockOffset(ntpServer, ntpTimeout)
+	if err != nil {
+		logger.Warn().Err(err).Str("server", ntpServer).Msg("failed to estimate clock offset")
+		return
+	}
+	applied := slot.SetClockOffset(offset)
+	if offset > clockDriftWarn || offset < -clockDriftWarn {
+		logger.Warn().Str("offset", offset.String()).Str("applied", applied.String()).
+			Msg("local clock drifts, check the time synchronization of this node")
+	} else {
+		logger.Debug().Str("offset", offset.String()).Msg("clock offset is corrected")
+	}
+}
+
+// queryClockOffset estimates the offset of local clock against the NTP
+// server, i.e. (server time - local time), by a single SNTP request.
+func queryClockOffset(server string, timeout time.Duration) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	req := make([]byte, ntpPacketSize)
+	// LI = 0 (no warning), VN = 3, Mode = 3 (client)
+	req[0] = 0x1B
+	t1 := time.Now()
+	// server echoes transmit timestamp of request back as originate timestamp
+	putNTPTime(req[40:], t1)
+	if _, err = conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	rsp := make([]byte, ntpPacketSize)
+	n, err := conn.Read(rsp)
+	t4 := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	if n < ntpPacketSize {
+		return 0, errNTPShortPacket
+	}
+	// mode must be 4 (server), and stratum 0 means kiss-of-death
+	if rsp[0]&0x7 != 4 || rsp[1] == 0 {
+		return 0, errNTPInvalid
+	}
+	if binary.BigEndian.Uint64(rsp[24:32]) != binary.BigEndian.Uint64(req[40:48]) {
+		return 0, fmt.Errorf("%s: not a response of the request", errNTPInvalid)
+	}
+
+	t2 := ntpTime(rsp[32:40])
+	t3 := ntpTime(rsp[40:48])
+	return (t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+func ntpTime(b []byte) time.Time {
+	sec := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(sec, (frac*int64(time.Second))>>32)
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+}
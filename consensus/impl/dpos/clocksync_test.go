@@ -0,0 +1,66 @@
+package dpos
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/consensus/impl/dpos/slot"
+	"github.com/stretchr/testify/assert"
+)
+
+// runTestNTPServer responds to a SNTP request with its clock which is off by
+// offset from the local clock.
+func runTestNTPServer(t *testing.T, offset time.Duration, stratum byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err.Error())
+	}
+	go func() {
+		defer conn.Close()
+		req := make([]byte, ntpPacketSize)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		rsp := make([]byte, ntpPacketSize)
+		// LI = 0, VN = 3, Mode = 4 (server)
+		rsp[0] = 0x1C
+		rsp[1] = stratum
+		copy(rsp[24:32], req[40:48])
+		putNTPTime(rsp[32:40], time.Now().Add(offset))
+		putNTPTime(rsp[40:48], time.Now().Add(offset))
+		conn.WriteTo(rsp, addr)
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQueryClockOffset(t *testing.T) {
+	for _, offset := range []time.Duration{300 * time.Millisecond, -2 * time.Second, 0} {
+		server := runTestNTPServer(t, offset, 2)
+		actual, err := queryClockOffset(server, time.Second)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(offset), float64(actual), float64(20*time.Millisecond))
+	}
+
+	// kiss-of-death
+	server := runTestNTPServer(t, 0, 0)
+	_, err := queryClockOffset(server, time.Second)
+	assert.NotNil(t, err)
+}
+
+func TestSyncClock(t *testing.T) {
+	slot.Init(1, blockProducers)
+	defer slot.SetClockOffset(0)
+
+	syncClock(runTestNTPServer(t, 150*time.Millisecond, 2))
+	assert.InDelta(t, float64(150*time.Millisecond), float64(slot.ClockOffset()), float64(20*time.Millisecond))
+
+	// too large drift is corrected within the cap
+	syncClock(runTestNTPServer(t, 5*time.Second, 2))
+	assert.Equal(t, slot.MaxClockOffset(), slot.ClockOffset())
+
+	// offset is kept if the server does not respond properly
+	syncClock(runTestNTPServer(t, 0, 0))
+	assert.Equal(t, slot.MaxClockOffset(), slot.ClockOffset())
+}
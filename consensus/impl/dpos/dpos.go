@@ -7,15 +7,16 @@ package dpos
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/blockchain"
 	"github.com/aergoio/aergo/config"
 	"github.com/aergoio/aergo/consensus"
 	"github.com/aergoio/aergo/consensus/chain"
 	"github.com/aergoio/aergo/consensus/impl/dpos/bp"
 	"github.com/aergoio/aergo/consensus/impl/dpos/slot"
-	"github.com/aergoio/aergo/internal/enc"
 	"github.com/aergoio/aergo/p2p"
 	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
@@ -37,7 +38,7 @@ var (
 type DPoS struct {
 	ID peer.ID
 	*component.ComponentHub
-	bpc  *bp.Cluster
+	bps  *bp.Schedule
 	bf   *BlockFactory
 	quit chan interface{}
 }
@@ -52,7 +53,15 @@ type bpInfo struct {
 func New(cfg *config.Config, hub *component.ComponentHub) (consensus.Consensus, error) {
 	Init(cfg.Consensus)
 
-	bpc, err := bp.NewCluster(cfg.Consensus.BpIds, blockProducers)
+	genesis, err := blockchain.LoadGenesis(&cfg.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	bpIDs, err := initialBPs(genesis, cfg.Consensus.BpIds)
+	if err != nil {
+		return nil, err
+	}
+	bpc, err := bp.NewCluster(bpIDs, blockProducers)
 	if err != nil {
 		return nil, err
 	}
@@ -60,16 +69,42 @@ func New(cfg *config.Config, hub *component.ComponentHub) (consensus.Consensus,
 	id, privKey := p2p.GetMyID()
 
 	quitC := make(chan interface{})
+	if ntpServer := cfg.Consensus.NTPServer; ntpServer != "" {
+		go runClockSync(ntpServer, quitC)
+	}
 
 	return &DPoS{
 		ID:           id,
 		ComponentHub: hub,
-		bpc:          bpc,
-		bf:           NewBlockFactory(hub, id, privKey, quitC),
+		bps:          bp.NewSchedule(bpc),
+		bf:           NewBlockFactory(hub, id, privKey, cfg.Consensus.SlotSkipAlert, cfg.Consensus.SlotQueueMax, cfg.Consensus.ProduceEmptyBlocks, quitC),
 		quit:         quitC,
 	}, nil
 }
 
+// initialBPs returns the IDs of the block producers from which the schedule
+// starts. The ones in genesis take precedence, so that every node joining the
+// chain agrees on them, and bpIDs (BpIds in config) is used only for a genesis
+// without them. bpIDs must be empty or the same as the ones in genesis.
+func initialBPs(genesis *types.Genesis, bpIDs []string) ([]string, error) {
+	ids, err := genesis.BlockProducers()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return bpIDs, nil
+	}
+
+	genesisIDs := make([]string, len(ids))
+	for i, id := range ids {
+		genesisIDs[i] = id.Pretty()
+	}
+	if len(bpIDs) != 0 && !reflect.DeepEqual(bpIDs, genesisIDs) {
+		return nil, fmt.Errorf("bpids in config differ from the block producers in genesis")
+	}
+	return genesisIDs, nil
+}
+
 // Init initilizes the DPoS parameters.
 func Init(cfg *config.ConsensusConfig) {
 	consensus.InitBlockInterval(cfg.BlockInterval)
@@ -84,8 +119,12 @@ func (dpos *DPoS) Ticker() *time.Ticker {
 
 // QueueJob send a block triggering information to jq.
 func (dpos *DPoS) QueueJob(now time.Time, jq chan<- interface{}) {
-	bpi := dpos.getBpInfo(now, lastJob)
+	bpi := dpos.getBpInfo(slot.Corrected(now), lastJob)
 	if bpi != nil {
+		if jobQueueNearlyFull(jq) {
+			logger.Warn().Int("depth", len(jq)).Int("capacity", cap(jq)).
+				Msg("block production is falling behind: job queue is nearly full")
+		}
 		jq <- bpi
 		lastJob = bpi.slot
 	}
@@ -123,15 +162,8 @@ func (dpos *DPoS) IsBlockValid(block *types.Block, bestBlock *types.Block) error
 		}
 	}
 
-	ns := block.GetHeader().GetTimestamp()
-	idx, ok := dpos.bpc.BpID2Index(id)
-	s := slot.NewFromUnixNano(ns)
-	// Check whether the BP ID is one of the current BP members and its
-	// corresponding BP index is consistent with the block timestamp.
-	if !ok || !s.IsFor(idx) {
-		return &consensus.ErrorConsensus{
-			Msg: fmt.Sprintf("BP %v is not permitted for the time slot %v", block.ID(), time.Unix(0, ns)),
-		}
+	if err := VerifyProducer(block, dpos.bps); err != nil {
+		return err
 	}
 
 	valid, err := block.VerifySign()
@@ -142,23 +174,144 @@ func (dpos *DPoS) IsBlockValid(block *types.Block, bestBlock *types.Block) error
 	return nil
 }
 
-// StatusUpdate updates the last irreversible block (LIB).
-func (dpos *DPoS) StatusUpdate() {
+// Statistics returns the statistics of block production.
+func (dpos *DPoS) Statistics() map[string]interface{} {
+	return map[string]interface{}{
+		"lastProduced": dpos.bf.LastProduced(),
+	}
 }
 
-func (dpos *DPoS) bpIdx() uint16 {
-	idx, exist := dpos.bpc.BpID2Index(dpos.ID)
-	if !exist {
-		logger.Fatal().Str("id", enc.ToString([]byte(dpos.ID))).Msg("BP has no correct BP membership")
+// BpAssignment returns the block producer scheduled for the slot including ns
+// by the producer set active at that time, and the one which actually
+// produced block.
+func (dpos *DPoS) BpAssignment(ns int64, block *types.Block) (*types.BpAssignment, error) {
+	s := slot.NewFromUnixNano(ns)
+	scheduled, ok := dpos.bps.At(ns).BpIndex2ID(s.BpIndex())
+	if !ok {
+		return nil, &consensus.ErrorConsensus{
+			Msg: fmt.Sprintf("no BP is scheduled for the time slot %v", time.Unix(0, ns)),
+		}
+	}
+
+	assignment := &types.BpAssignment{Timestamp: ns, Scheduled: scheduled}
+	// The slot was skipped if no block belongs to it.
+	if block == nil || !slot.Equal(s, slot.NewFromUnixNano(block.GetHeader().GetTimestamp())) {
+		return assignment, nil
 	}
 
-	return idx
+	actual, err := block.BPID()
+	if err != nil {
+		return nil, &consensus.ErrorConsensus{Msg: "bad public key in block", Err: err}
+	}
+	assignment.BlockNo = block.GetHeader().GetBlockNo()
+	assignment.BlockHash = block.BlockHash()
+	assignment.Actual = actual
+	assignment.Mismatch = actual != scheduled
+
+	return assignment, nil
+}
+
+// CurrentProducer returns the ID of the block producer scheduled for the slot
+// including now.
+func (dpos *DPoS) CurrentProducer(now time.Time) string {
+	ns := now.UnixNano()
+	id, ok := dpos.bps.At(ns).BpIndex2ID(slot.NewFromUnixNano(ns).BpIndex())
+	if !ok {
+		return ""
+	}
+	return id.Pretty()
+}
+
+// NextSlotFor returns the beginning of the next slot assigned to bpID, after
+// the current time corrected by the clock offset.
+func (dpos *DPoS) NextSlotFor(bpID string) time.Time {
+	return dpos.nextSlotFor(bpID, slot.Corrected(time.Now()))
+}
+
+func (dpos *DPoS) nextSlotFor(bpID string, now time.Time) time.Time {
+	id, err := peer.IDB58Decode(bpID)
+	if err != nil {
+		return time.Time{}
+	}
+	idx, ok := dpos.bps.At(now.UnixNano()).BpID2Index(id)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(0, slot.Time(now).NextFor(idx).UnixNano())
+}
+
+// IsBlockOrderValid checks whether the slot of block is after the one of
+// parent, since a BP produces at most one block in its slot.
+func (dpos *DPoS) IsBlockOrderValid(block *types.Block, parent *types.Block) error {
+	return VerifyBlockOrder(block, parent)
+}
+
+// VerifyBlockOrder checks whether block belongs to a slot after the one of
+// parent.
+func VerifyBlockOrder(block *types.Block, parent *types.Block) error {
+	s := slot.NewFromUnixNano(block.GetHeader().GetTimestamp())
+	ps := slot.NewFromUnixNano(parent.GetHeader().GetTimestamp())
+	if slot.LessEqual(s, ps) {
+		return &consensus.ErrorConsensus{
+			Msg: fmt.Sprintf("block %v at %v is not in a slot after its parent %v at %v", block.ID(),
+				time.Unix(0, s.UnixNano()), parent.ID(), time.Unix(0, ps.UnixNano())),
+		}
+	}
+	return nil
+}
+
+// VerifyProducer checks whether block was produced by the BP scheduled for
+// the time slot of its timestamp in bps. The block is rejected if its
+// timestamp is ahead of the local clock by more than a block interval, if its
+// BP is not a member of the cluster active at that time, or if the slot
+// belongs to another BP.
+func VerifyProducer(block *types.Block, bps *bp.Schedule) error {
+	id, err := block.BPID()
+	if err != nil {
+		return &consensus.ErrorConsensus{Msg: "bad public key in block", Err: err}
+	}
+
+	ns := block.GetHeader().GetTimestamp()
+	if limit := slot.Corrected(time.Now()).Add(consensus.BlockInterval); ns > limit.UnixNano() {
+		return &consensus.ErrorConsensus{
+			Msg: fmt.Sprintf("timestamp %v of block %v is too far in the future", time.Unix(0, ns), block.ID()),
+		}
+	}
+
+	bpc := bps.At(ns)
+	idx, ok := bpc.BpID2Index(id)
+	if !ok {
+		return &consensus.ErrorConsensus{
+			Msg: fmt.Sprintf("BP %v of block %v is not an authorized block producer", id.Pretty(), block.ID()),
+		}
+	}
+
+	s := slot.NewFromUnixNano(ns)
+	if !s.IsFor(idx) {
+		scheduled, _ := bpc.BpIndex2ID(s.BpIndex())
+		return &consensus.ErrorConsensus{
+			Msg: fmt.Sprintf("BP %v of block %v is not permitted for the time slot %v (scheduled: %v)",
+				id.Pretty(), block.ID(), time.Unix(0, ns), scheduled.Pretty()),
+		}
+	}
+
+	return nil
+}
+
+// StatusUpdate updates the last irreversible block (LIB).
+func (dpos *DPoS) StatusUpdate() {
+}
+
+// bpIdx returns the index of this node in the block producers active at ns.
+// ok is false if this node is not one of them.
+func (dpos *DPoS) bpIdx(ns int64) (idx uint16, ok bool) {
+	return dpos.bps.At(ns).BpID2Index(dpos.ID)
 }
 
 func (dpos *DPoS) getBpInfo(now time.Time, slotQueued *slot.Slot) *bpInfo {
 	s := slot.Time(now)
 
-	if !s.IsFor(dpos.bpIdx()) {
+	if idx, ok := dpos.bpIdx(now.UnixNano()); !ok || !s.IsFor(idx) {
 		return nil
 	}
 
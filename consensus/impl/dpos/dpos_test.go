@@ -2,9 +2,14 @@ package dpos
 
 import (
 	"testing"
+	"time"
 
+	"github.com/aergoio/aergo/consensus"
+	"github.com/aergoio/aergo/consensus/impl/dpos/bp"
+	"github.com/aergoio/aergo/consensus/impl/dpos/slot"
 	"github.com/aergoio/aergo/types"
 	crypto "github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/libp2p/go-libp2p-peer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,3 +78,282 @@ func TestBlockSign(t *testing.T) {
 	signAssert.Nil(err)
 	signAssert.True(valid)
 }
+
+func TestBpAssignment(t *testing.T) {
+	a := assert.New(t)
+	slot.Init(1, blockProducers)
+
+	privKeys := make([]crypto.PrivKey, blockProducers)
+	ids := make([]string, blockProducers)
+	for i := range privKeys {
+		privKey, pubKey := genKeyPair(a)
+		id, err := peer.IDFromPublicKey(pubKey)
+		a.Nil(err)
+		privKeys[i], ids[i] = privKey, id.Pretty()
+	}
+	bpc, err := bp.NewCluster(ids, blockProducers)
+	a.Nil(err)
+	dpos := &DPoS{bps: bp.NewSchedule(bpc)}
+
+	// The slot k covers ((k-1)s, ks] and is assigned to the BP k % blockProducers.
+	base := int64(blockProducers * 1000000)
+	slotTime := func(k int64) int64 {
+		return (base+k)*int64(time.Second) - int64(time.Second)/2
+	}
+	substitute := 3
+
+	var prev *types.Block
+	for k := 0; k < 6; k++ {
+		producer := k
+		if k == substitute {
+			producer = k + 7
+		}
+		block := types.NewBlock(prev, nil, slotTime(int64(k)))
+		a.Nil(block.Sign(privKeys[producer]))
+		prev = block
+
+		assignment, err := dpos.BpAssignment(slotTime(int64(k)), block)
+		a.Nil(err)
+		a.Equal(ids[k], assignment.Scheduled.Pretty())
+		a.Equal(ids[producer], assignment.Actual.Pretty())
+		a.Equal(k == substitute, assignment.Mismatch)
+		a.Equal(block.GetHeader().GetBlockNo(), assignment.BlockNo)
+		a.Equal(block.BlockHash(), assignment.BlockHash)
+	}
+
+	// No block belongs to the slot after the last block.
+	assignment, err := dpos.BpAssignment(slotTime(6), prev)
+	a.Nil(err)
+	a.Equal(ids[6], assignment.Scheduled.Pretty())
+	a.Empty(assignment.Actual)
+	a.False(assignment.Mismatch)
+
+	// The producer set changed later does not affect the past slots.
+	rotated := append(append([]string{}, ids[1:]...), ids[0])
+	bpc2, err := bp.NewCluster(rotated, blockProducers)
+	a.Nil(err)
+	a.Nil(dpos.bps.Add(slotTime(10), bpc2))
+	a.NotNil(dpos.bps.Add(slotTime(9), bpc2))
+
+	assignment, err = dpos.BpAssignment(slotTime(0), nil)
+	a.Nil(err)
+	a.Equal(ids[0], assignment.Scheduled.Pretty())
+	assignment, err = dpos.BpAssignment(slotTime(10), nil)
+	a.Nil(err)
+	a.Equal(ids[11], assignment.Scheduled.Pretty())
+}
+
+func TestBpIdx(t *testing.T) {
+	a := assert.New(t)
+	slot.Init(1, blockProducers)
+
+	ids := make([]string, blockProducers+1)
+	for i := range ids {
+		_, pubKey := genKeyPair(a)
+		id, err := peer.IDFromPublicKey(pubKey)
+		a.Nil(err)
+		ids[i] = id.Pretty()
+	}
+	bpc, err := bp.NewCluster(ids[:blockProducers], blockProducers)
+	a.Nil(err)
+	me, err := peer.IDB58Decode(ids[0])
+	a.Nil(err)
+	dpos := &DPoS{ID: me, bps: bp.NewSchedule(bpc)}
+
+	now := time.Now()
+	from := now.Add(time.Hour).UnixNano()
+	idx, ok := dpos.bpIdx(from)
+	a.True(ok)
+	a.Equal(uint16(0), idx)
+
+	// ids[0] is replaced with ids[blockProducers] from an hour later.
+	bpc2, err := bp.NewCluster(ids[1:], blockProducers)
+	a.Nil(err)
+	a.Nil(dpos.bps.Add(from, bpc2))
+
+	_, ok = dpos.bpIdx(from - 1)
+	a.True(ok)
+	_, ok = dpos.bpIdx(from)
+	a.False(ok)
+	a.Nil(dpos.getBpInfo(time.Unix(0, from), nil))
+}
+
+func TestVerifyProducer(t *testing.T) {
+	a := assert.New(t)
+	const ringSize = 3
+	slot.Init(1, ringSize)
+	defer slot.Init(1, blockProducers)
+
+	genBPs := func(n int) ([]crypto.PrivKey, []string) {
+		privKeys := make([]crypto.PrivKey, n)
+		ids := make([]string, n)
+		for i := range privKeys {
+			privKey, pubKey := genKeyPair(a)
+			id, err := peer.IDFromPublicKey(pubKey)
+			a.Nil(err)
+			privKeys[i], ids[i] = privKey, id.Pretty()
+		}
+		return privKeys, ids
+	}
+	privKeys, ids := genBPs(ringSize)
+	bpc, err := bp.NewCluster(ids, ringSize)
+	a.Nil(err)
+	bps := bp.NewSchedule(bpc)
+
+	// The slot k covers ((k-1)s, ks] and is assigned to the BP k % ringSize.
+	base := int64(ringSize * 1000000)
+	slotTime := func(k int64) int64 {
+		return (base+k)*int64(time.Second) - int64(time.Second)/2
+	}
+	signedBlock := func(k int64, privKey crypto.PrivKey) *types.Block {
+		block := types.NewBlock(nil, nil, slotTime(k))
+		a.Nil(block.Sign(privKey))
+		return block
+	}
+
+	for k := int64(0); k < 2*ringSize; k++ {
+		a.Nil(VerifyProducer(signedBlock(k, privKeys[k%ringSize]), bps), "slot %d", k)
+		// The other BPs in the ring are out of turn.
+		for i := int64(1); i < ringSize; i++ {
+			err := VerifyProducer(signedBlock(k, privKeys[(k+i)%ringSize]), bps)
+			a.IsType(&consensus.ErrorConsensus{}, err, "slot %d", k)
+		}
+	}
+
+	// A BP outside of the ring is never permitted.
+	outsider, _ := genBPs(1)
+	a.IsType(&consensus.ErrorConsensus{}, VerifyProducer(signedBlock(0, outsider[0]), bps))
+
+	// After the ring is rotated, the blocks are verified against the new
+	// order, while the earlier ones remain valid.
+	rotated := append(append([]string{}, ids[1:]...), ids[0])
+	bpc2, err := bp.NewCluster(rotated, ringSize)
+	a.Nil(err)
+	a.Nil(bps.Add(slotTime(10), bpc2))
+
+	a.Nil(VerifyProducer(signedBlock(0, privKeys[0]), bps))
+	a.Nil(VerifyProducer(signedBlock(12, privKeys[1]), bps))
+	a.NotNil(VerifyProducer(signedBlock(12, privKeys[0]), bps))
+
+	// A block too far in the future is rejected, whoever produced it.
+	future := time.Now().Add(consensus.BlockInterval + time.Minute)
+	for _, privKey := range privKeys {
+		block := types.NewBlock(nil, nil, future.UnixNano())
+		a.Nil(block.Sign(privKey))
+		a.IsType(&consensus.ErrorConsensus{}, VerifyProducer(block, bps))
+	}
+}
+
+func TestVerifyBlockOrder(t *testing.T) {
+	a := assert.New(t)
+	slot.Init(1, blockProducers)
+
+	// The slot k covers ((k-1)s, ks].
+	slotTime := func(k int64, offset time.Duration) int64 {
+		return k*int64(time.Second) - int64(time.Second)/2 + int64(offset)
+	}
+	parent := types.NewBlock(nil, nil, slotTime(100, 0))
+
+	a.Nil(VerifyBlockOrder(types.NewBlock(parent, nil, slotTime(101, 0)), parent))
+	a.Nil(VerifyBlockOrder(types.NewBlock(parent, nil, slotTime(150, 0)), parent))
+	// A block in the slot of its parent, or before it, is rejected.
+	a.IsType(&consensus.ErrorConsensus{}, VerifyBlockOrder(types.NewBlock(parent, nil, slotTime(100, time.Second/4)), parent))
+	a.IsType(&consensus.ErrorConsensus{}, VerifyBlockOrder(types.NewBlock(parent, nil, slotTime(99, 0)), parent))
+}
+
+func TestInitialBPs(t *testing.T) {
+	a := assert.New(t)
+	slot.Init(1, blockProducers)
+
+	ids := make([]string, blockProducers)
+	for i := range ids {
+		_, pubKey := genKeyPair(a)
+		id, err := peer.IDFromPublicKey(pubKey)
+		a.Nil(err)
+		ids[i] = id.Pretty()
+	}
+	genesis := &types.Genesis{BPs: ids}
+
+	// The BPs in genesis drive the initial schedule without BpIds.
+	bpIDs, err := initialBPs(genesis, nil)
+	a.Nil(err)
+	bpc, err := bp.NewCluster(bpIDs, blockProducers)
+	a.Nil(err)
+	dpos := &DPoS{bps: bp.NewSchedule(bpc)}
+	for k := int64(0); k < blockProducers; k++ {
+		ns := (int64(blockProducers*1000000)+k)*int64(time.Second) - int64(time.Second)/2
+		assignment, err := dpos.BpAssignment(ns, nil)
+		a.Nil(err)
+		a.Equal(ids[k], assignment.Scheduled.Pretty())
+	}
+
+	// BpIds is allowed only if it is the same as genesis.
+	bpIDs, err = initialBPs(genesis, append([]string{}, ids...))
+	a.Nil(err)
+	a.Equal(ids, bpIDs)
+	rotated := append(append([]string{}, ids[1:]...), ids[0])
+	_, err = initialBPs(genesis, rotated)
+	a.NotNil(err)
+
+	// BpIds is used for a genesis without BPs.
+	bpIDs, err = initialBPs(&types.Genesis{}, rotated)
+	a.Nil(err)
+	a.Equal(rotated, bpIDs)
+
+	// A genesis with a malformed BP is rejected.
+	malformed := append(append([]string{}, ids[1:]...), "malformed")
+	_, err = initialBPs(&types.Genesis{BPs: malformed}, nil)
+	a.NotNil(err)
+}
+
+func TestBpSchedule(t *testing.T) {
+	a := assert.New(t)
+	const ringSize = 3
+	slot.Init(2, ringSize)
+	defer slot.Init(1, blockProducers)
+
+	ids := make([]string, ringSize)
+	for i := range ids {
+		_, pubKey := genKeyPair(a)
+		id, err := peer.IDFromPublicKey(pubKey)
+		a.Nil(err)
+		ids[i] = id.Pretty()
+	}
+	bpc, err := bp.NewCluster(ids, ringSize)
+	a.Nil(err)
+	dpos := &DPoS{bps: bp.NewSchedule(bpc)}
+
+	// The slot k covers ((k-1)*2s, k*2s] and is assigned to the BP k % ringSize.
+	base := int64(ringSize * 1000000)
+	at := func(k int64, offsetMs int64) time.Time {
+		return time.Unix(0, ((base+k-1)*2000+offsetMs)*int64(time.Millisecond))
+	}
+	tests := []struct {
+		now     time.Time
+		current int
+		// next is the slot index next assigned to each BP
+		next [ringSize]int64
+	}{
+		{at(0, 1), 0, [ringSize]int64{3, 1, 2}},
+		{at(0, 1000), 0, [ringSize]int64{3, 1, 2}},
+		{at(0, 2000), 0, [ringSize]int64{3, 1, 2}},
+		{at(1, 1), 1, [ringSize]int64{3, 4, 2}},
+		{at(2, 1999), 2, [ringSize]int64{3, 4, 5}},
+		{at(3, 500), 0, [ringSize]int64{6, 4, 5}},
+		{at(7, 2000), 1, [ringSize]int64{9, 10, 8}},
+	}
+	for _, tt := range tests {
+		a.Equal(ids[tt.current], dpos.CurrentProducer(tt.now), "at %v", tt.now)
+		for i, id := range ids {
+			a.Equal(at(tt.next[i], 1), dpos.nextSlotFor(id, tt.now), "BP %d at %v", i, tt.now)
+			a.Equal(ids[i], dpos.CurrentProducer(dpos.nextSlotFor(id, tt.now)))
+		}
+	}
+
+	// No slot is assigned to an unknown BP.
+	_, pubKey := genKeyPair(a)
+	outsider, err := peer.IDFromPublicKey(pubKey)
+	a.Nil(err)
+	a.True(dpos.nextSlotFor(outsider.Pretty(), at(0, 1)).IsZero())
+	a.True(dpos.nextSlotFor("malformed", at(0, 1)).IsZero())
+}
@@ -0,0 +1,42 @@
+package slot
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clockOffsetNs is the correction of local clock in nanoseconds, which is
+// added to the local time when the current slot is computed. It must be
+// accessed atomically.
+var clockOffsetNs int64
+
+// MaxClockOffset returns the cap of the clock correction. It is as large as
+// the minimum block generation time limit, so that the correction can't move
+// block production into the slots of other block producers.
+func MaxClockOffset() time.Duration {
+	return time.Duration(bpMinTimeLimitMs) * time.Millisecond
+}
+
+// SetClockOffset sets the correction of local clock, which is estimated as
+// (reference time - local time). The correction is truncated to the cap, and
+// the applied one is returned.
+func SetClockOffset(offset time.Duration) time.Duration {
+	max := MaxClockOffset()
+	if offset > max {
+		offset = max
+	} else if offset < -max {
+		offset = -max
+	}
+	atomic.StoreInt64(&clockOffsetNs, int64(offset))
+	return offset
+}
+
+// ClockOffset returns the correction of local clock.
+func ClockOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&clockOffsetNs))
+}
+
+// Corrected returns t corrected by the clock offset.
+func Corrected(t time.Time) time.Time {
+	return t.Add(ClockOffset())
+}
@@ -31,9 +31,10 @@ func Init(blockIntervalSec int64, bps uint16) {
 	blockProducers = bps
 }
 
-// Now returns a Slot corresponding to the current local time.
+// Now returns a Slot corresponding to the current local time, which is
+// corrected by the clock offset.
 func Now() *Slot {
-	return Time(time.Now())
+	return Time(Corrected(time.Now()))
 }
 
 // NewFromUnixNano returns a Slot corresponding to a UNIX time value (ns).
@@ -61,6 +62,12 @@ func fromUnixNs(ns int64) *Slot {
 	}
 }
 
+// fromIndex returns the Slot of idx at the earliest time in it. A slot
+// excludes its starting time, which belongs to the previous one.
+func fromIndex(idx int64) *Slot {
+	return fromUnixNs(((idx-1)*blockIntervalMs + 1) * 1000000)
+}
+
 // IsValidNow reports whether the Slot is still valid at the time when it's
 // called.
 func (s *Slot) IsValidNow() bool {
@@ -99,6 +106,20 @@ func (s *Slot) IsFor(bpIdx uint16) bool {
 	return s.nextBpIndex() == int64(bpIdx)
 }
 
+// BpIndex returns the index of the block producer to which s is assigned.
+func (s *Slot) BpIndex() uint16 {
+	return uint16(s.nextBpIndex())
+}
+
+// NextFor returns the first slot after s, which is assigned to bpIdx. The
+// returned Slot corresponds to the beginning of the slot.
+func (s *Slot) NextFor(bpIdx uint16) *Slot {
+	n := int64(blockProducers)
+	idx := s.nextIndex + 1
+	idx += (int64(bpIdx) - absToBpIndex(idx) + n) % n
+	return fromIndex(idx)
+}
+
 // GetBpTimeout returns the time available for block production.
 func (s *Slot) GetBpTimeout() int64 {
 	rTime := s.RemainingTimeMS()
@@ -113,7 +134,7 @@ func (s *Slot) GetBpTimeout() int64 {
 // RemainingTimeMS returns the remaining duration until the next block
 // generation time.
 func (s *Slot) RemainingTimeMS() int64 {
-	return s.nextIndex*blockIntervalMs - nsToMs(time.Now().UnixNano())
+	return s.nextIndex*blockIntervalMs - nsToMs(Corrected(time.Now()).UnixNano())
 }
 
 // TimesUp reports whether the reminaing time <= BpMinTimeLimitMs
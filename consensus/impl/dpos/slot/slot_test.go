@@ -45,3 +45,35 @@ func TestSlotConversion(t *testing.T) {
 func TestSlotValidNow(t *testing.T) {
 	assert.True(t, Now().IsValidNow(), "invalid slot")
 }
+
+func TestClockOffset(t *testing.T) {
+	Init(1, 23)
+	defer SetClockOffset(0)
+
+	tests := []struct {
+		name     string
+		offset   time.Duration
+		expected time.Duration
+	}{
+		{"TBehind", 200 * time.Millisecond, 200 * time.Millisecond},
+		{"TAhead", -200 * time.Millisecond, -200 * time.Millisecond},
+		{"TCappedBehind", 10 * time.Second, MaxClockOffset()},
+		{"TCappedAhead", -10 * time.Second, -MaxClockOffset()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SetClockOffset(tt.offset))
+			assert.Equal(t, tt.expected, ClockOffset())
+
+			// the scheduler's current time is compensated by the applied offset only
+			before := time.Now()
+			s := Now()
+			after := time.Now()
+			assert.True(t, s.UnixNano() >= before.Add(tt.expected).UnixNano())
+			assert.True(t, s.UnixNano() <= after.Add(tt.expected).UnixNano())
+			remaining := s.nextIndex*blockIntervalMs - nsToMs(after.Add(tt.expected).UnixNano())
+			assert.True(t, s.RemainingTimeMS() <= remaining)
+		})
+	}
+	assert.Equal(t, 250*time.Millisecond, MaxClockOffset())
+}
@@ -0,0 +1,56 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package dpos
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// Metric names of the block production health of this BP. The gauge is the
+// number of consecutive slots skipped so far, and the counter is the number of
+// alerts raised.
+const (
+	SkippedSlotsMetric   = "dpos.bp.skippedslots"
+	SlotSkipAlertsMetric = "dpos.bp.slotskipalerts"
+)
+
+var errPendingJob = errors.New("pending job")
+
+var (
+	skippedSlotsGauge   = metrics.GetOrRegisterGauge(SkippedSlotsMetric, metrics.DefaultRegistry)
+	slotSkipAlertsCount = metrics.GetOrRegisterCounter(SlotSkipAlertsMetric, metrics.DefaultRegistry)
+)
+
+// recordSkip counts a slot of this BP which is skipped without block
+// production, and raises an alert whenever the number of consecutive skips
+// reaches a multiple of skipAlert. It returns true if the alert is raised.
+func (bf *BlockFactory) recordSkip(cause error) bool {
+	skipped := atomic.AddUint32(&bf.skippedSlots, 1)
+	skippedSlotsGauge.Update(int64(skipped))
+	if bf.skipAlert == 0 || skipped%bf.skipAlert != 0 {
+		return false
+	}
+
+	slotSkipAlertsCount.Inc(1)
+	logger.Error().Err(cause).Uint32("skipped", skipped).Time("lastProduced", bf.LastProduced()).
+		Msg("CRITICAL: block production is skipped for consecutive slots. check the network and the host of this BP")
+	return true
+}
+
+// resetSkip clears the count of consecutive skips after a block is produced.
+func (bf *BlockFactory) resetSkip() {
+	atomic.StoreUint32(&bf.skippedSlots, 0)
+	skippedSlotsGauge.Update(0)
+}
+
+// SkippedSlots returns the number of consecutive slots of this BP skipped
+// without block production.
+func (bf *BlockFactory) SkippedSlots() uint32 {
+	return atomic.LoadUint32(&bf.skippedSlots)
+}
@@ -0,0 +1,66 @@
+package dpos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockFactory_slotSkipAlert(t *testing.T) {
+	bf := &BlockFactory{skipAlert: 3}
+	errSkip := errors.New("skipped")
+	alerts := slotSkipAlertsCount.Count()
+
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.True(t, bf.recordSkip(errSkip))
+	assert.Equal(t, uint32(3), bf.SkippedSlots())
+	assert.Equal(t, int64(3), skippedSlotsGauge.Value())
+	assert.Equal(t, alerts+1, slotSkipAlertsCount.Count())
+
+	// alerted again at every threshold while skipping continues
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.True(t, bf.recordSkip(errSkip))
+	assert.Equal(t, alerts+2, slotSkipAlertsCount.Count())
+
+	// a successful production resets the count
+	bf.resetSkip()
+	assert.Equal(t, uint32(0), bf.SkippedSlots())
+	assert.Equal(t, int64(0), skippedSlotsGauge.Value())
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.False(t, bf.recordSkip(errSkip))
+	assert.True(t, bf.recordSkip(errSkip))
+}
+
+func TestBlockFactory_slotSkipAlertDisabled(t *testing.T) {
+	bf := &BlockFactory{}
+	for i := 0; i < 10; i++ {
+		assert.False(t, bf.recordSkip(errPendingJob))
+	}
+	assert.Equal(t, uint32(10), bf.SkippedSlots())
+}
+
+func TestBlockFactory_workerResetsSkip(t *testing.T) {
+	quit := make(chan interface{})
+	defer close(quit)
+
+	bf := &BlockFactory{workerQueue: make(chan *bpInfo), quit: quit, skipAlert: 2}
+	results := make(chan error)
+	bf.produce = func(bpi *bpInfo, gen uint32) error {
+		return <-results
+	}
+	go bf.worker()
+
+	for i := 0; i < 2; i++ {
+		bf.workerQueue <- &bpInfo{}
+		results <- errors.New("failed")
+	}
+	bf.workerQueue <- &bpInfo{}
+	results <- nil
+	// the next job is taken after the result of the previous one is recorded
+	bf.workerQueue <- &bpInfo{}
+	assert.Equal(t, uint32(0), bf.SkippedSlots())
+	results <- nil
+}
@@ -0,0 +1,75 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package dpos
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/aergoio/aergo/consensus"
+)
+
+// stuckFactor is the multiple of block interval, after which the block
+// production job being processed is regarded as stuck.
+const stuckFactor = 10
+
+func stuckLimit() time.Duration {
+	return consensus.BlockInterval * stuckFactor
+}
+
+// watchdog restarts the worker when it is stuck in a block production job,
+// e.g. by a deadlock or a hung tx op, until the block factory is shut down.
+func (bf *BlockFactory) watchdog() {
+	defer shutdownMsg("block factory watchdog")
+
+	ticker := time.NewTicker(consensus.BlockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			bf.checkWorker(now)
+		case <-bf.quit:
+			return
+		}
+	}
+}
+
+// checkWorker restarts the worker if the job in process was taken more than
+// stuckLimit ago. It returns true if the worker is restarted.
+func (bf *BlockFactory) checkWorker(now time.Time) bool {
+	busySince := atomic.LoadInt64(&bf.busySince)
+	if busySince == 0 {
+		return false
+	}
+	elapsed := now.Sub(time.Unix(0, busySince))
+	if elapsed <= stuckLimit() {
+		return false
+	}
+
+	logger.Error().Str("elapsed", elapsed.String()).Time("lastProduced", bf.LastProduced()).
+		Msg("CRITICAL: block factory worker is stuck, block production is stopped. restarting the worker")
+	bf.restartWorker()
+	return true
+}
+
+// restartWorker abandons the current worker and starts a new one. The
+// abandoned worker checks its generation before connecting a block, so it
+// exits without connecting the block being produced if it ever resumes.
+func (bf *BlockFactory) restartWorker() {
+	atomic.AddUint32(&bf.workerGen, 1)
+	atomic.StoreInt64(&bf.busySince, 0)
+	go bf.worker()
+}
+
+// LastProduced returns the time when this node produced a block lastly. It
+// returns zero time if no block has been produced yet.
+func (bf *BlockFactory) LastProduced() time.Time {
+	lastProduced := atomic.LoadInt64(&bf.lastProduced)
+	if lastProduced == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastProduced)
+}
@@ -0,0 +1,56 @@
+package dpos
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockFactory_restartStuckWorker(t *testing.T) {
+	quit := make(chan interface{})
+	defer close(quit)
+
+	bf := &BlockFactory{
+		workerQueue: make(chan *bpInfo),
+		quit:        quit,
+	}
+	hang := make(chan struct{})
+	var produced int32
+	bf.produce = func(bpi *bpInfo, gen uint32) error {
+		if atomic.AddInt32(&produced, 1) == 1 {
+			// The first job hangs until released.
+			<-hang
+		}
+		return nil
+	}
+	go bf.worker()
+
+	now := time.Now()
+	assert.False(t, bf.checkWorker(now.Add(stuckLimit()*2)), "idle worker must not be restarted")
+
+	bf.workerQueue <- &bpInfo{}
+	for atomic.LoadInt64(&bf.busySince) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, bf.checkWorker(time.Now()))
+	assert.True(t, bf.LastProduced().IsZero())
+
+	assert.True(t, bf.checkWorker(time.Now().Add(stuckLimit()+time.Second)))
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&bf.workerGen))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&bf.busySince))
+
+	// The new worker takes the next job.
+	bf.workerQueue <- &bpInfo{}
+	for bf.LastProduced().IsZero() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, bf.checkWorker(time.Now().Add(stuckLimit()*2)))
+
+	// The abandoned worker exits without recording its result.
+	lastProduced := bf.LastProduced()
+	close(hang)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, lastProduced, bf.LastProduced())
+}
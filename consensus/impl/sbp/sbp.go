@@ -1,6 +1,8 @@
 package sbp
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/aergoio/aergo-lib/log"
@@ -15,7 +17,11 @@ const (
 	slotQueueMax = 100
 )
 
-var logger *log.Logger
+var (
+	logger *log.Logger
+
+	errNoBestBlock = errors.New("no best block to produce block on")
+)
 
 func init() {
 	logger = log.NewLogger("sbp")
@@ -25,7 +31,7 @@ func init() {
 //
 // This can be used for testing purpose.
 type SimpleBlockFactory struct {
-	*component.ComponentHub
+	component.ICompSyncRequester
 	jobQueue         chan interface{}
 	blockInterval    time.Duration
 	maxBlockBodySize int
@@ -38,11 +44,11 @@ func New(cfg *config.Config, hub *component.ComponentHub) (*SimpleBlockFactory,
 	consensus.InitBlockInterval(cfg.Consensus.BlockInterval)
 
 	s := &SimpleBlockFactory{
-		ComponentHub:     hub,
-		jobQueue:         make(chan interface{}, slotQueueMax),
-		blockInterval:    consensus.BlockInterval,
-		maxBlockBodySize: chain.MaxBlockBodySize(),
-		quit:             make(chan interface{}),
+		ICompSyncRequester: hub,
+		jobQueue:           make(chan interface{}, slotQueueMax),
+		blockInterval:      consensus.BlockInterval,
+		maxBlockBodySize:   chain.MaxBlockBodySize(),
+		quit:               make(chan interface{}),
 	}
 
 	s.txOp = chain.NewCompTxOp(
@@ -65,11 +71,11 @@ func (s *SimpleBlockFactory) Ticker() *time.Ticker {
 	return time.NewTicker(s.blockInterval)
 }
 
-// QueueJob send a block triggering information to jq.
+// QueueJob send a block triggering information to jq. Only the trigger time
+// is queued: the block is produced on top of the best block at the time of
+// production, which may be changed meanwhile by a block produced on request.
 func (s *SimpleBlockFactory) QueueJob(now time.Time, jq chan<- interface{}) {
-	if b := chain.GetBestBlock(s); b != nil {
-		jq <- b
-	}
+	jq <- now
 }
 
 // IsTransactionValid checks the onsensus level validity of a transaction
@@ -99,6 +105,39 @@ func (s *SimpleBlockFactory) BlockFactory() consensus.BlockFactory {
 	return s
 }
 
+// produceRequest is a job to produce a block on top of the best block
+// immediately. The result is sent to done.
+type produceRequest struct {
+	done chan produceResult
+}
+
+type produceResult struct {
+	block *types.Block
+	err   error
+}
+
+// ProduceBlock produces a block on top of the best block without waiting for
+// the next block interval, and returns it after it is connected to the chain.
+func (s *SimpleBlockFactory) ProduceBlock(ctx context.Context) (*types.Block, error) {
+	req := &produceRequest{done: make(chan produceResult, 1)}
+	select {
+	case s.jobQueue <- req:
+	case <-s.quit:
+		return nil, chain.ErrQuit
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case res := <-req.done:
+		return res.block, res.err
+	case <-s.quit:
+		return nil, chain.ErrQuit
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // Start run a simple block factory service.
 func (s *SimpleBlockFactory) Start() {
 	defer logger.Info().Msg("shutdown initiated. stop the service")
@@ -106,18 +145,13 @@ func (s *SimpleBlockFactory) Start() {
 	for {
 		select {
 		case e := <-s.jobQueue:
-			if prevBlock, ok := e.(*types.Block); ok {
-				block, err := chain.GenerateBlock(s, prevBlock, s.txOp, time.Now().UnixNano())
-				if err == chain.ErrQuit {
-					return
-				} else if err != nil {
-					logger.Info().Err(err).Msg("failed to produce block")
-					continue
-				}
-				logger.Info().Uint64("no", block.GetHeader().GetBlockNo()).Str("hash", block.ID()).
-					Err(err).Msg("block produced")
-
-				chain.ConnectBlock(s, block)
+			var res produceResult
+			res.block, res.err = s.produceOnBest()
+			if req, ok := e.(*produceRequest); ok {
+				req.done <- res
+			}
+			if res.err == chain.ErrQuit {
+				return
 			}
 		case <-s.quit:
 			return
@@ -125,6 +159,33 @@ func (s *SimpleBlockFactory) Start() {
 	}
 }
 
+// produce generates a block on top of prevBlock and connects it to the chain.
+// produceOnBest produces a block on top of the best block at the moment.
+func (s *SimpleBlockFactory) produceOnBest() (*types.Block, error) {
+	prevBlock := chain.GetBestBlock(s)
+	if prevBlock == nil {
+		return nil, errNoBestBlock
+	}
+	return s.produce(prevBlock)
+}
+
+func (s *SimpleBlockFactory) produce(prevBlock *types.Block) (*types.Block, error) {
+	block, err := chain.GenerateBlock(s, prevBlock, s.txOp, time.Now().UnixNano())
+	if err == chain.ErrQuit {
+		return nil, err
+	} else if err != nil {
+		logger.Info().Err(err).Msg("failed to produce block")
+		return nil, err
+	}
+	logger.Info().Uint64("no", block.GetHeader().GetBlockNo()).Str("hash", block.ID()).
+		Err(err).Msg("block produced")
+
+	if err := chain.ConnectBlock(s, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
 // JobQueue returns the queue for block production triggering.
 func (s *SimpleBlockFactory) JobQueue() chan<- interface{} {
 	return s.jobQueue
@@ -0,0 +1,22 @@
+package sbp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduceBlockNotRunning(t *testing.T) {
+	cfg := config.NewServerContext("", "").GetDefaultConfig().(*config.Config)
+	s, err := New(cfg, nil)
+	assert.Nil(t, err)
+
+	// no block is produced unless the block factory is started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.ProduceBlock(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,129 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package contract
+
+import (
+	"github.com/aergoio/aergo-lib/db"
+	"github.com/aergoio/aergo/types"
+)
+
+// dbStage buffers the db writes of a single contract call, so that they can be
+// committed all together or discarded when the call is reverted.
+type dbStage struct {
+	store db.DB
+	// parent, if set, is the stage which the changes are committed to, instead of store
+	parent  *dbStage
+	updates map[string][]byte
+	deletes map[string]bool
+	// events are emitted by the call. They are kept only if the call succeeds.
+	events []*types.Event
+
+	// writes and writeSize are the number and the total bytes of the writes
+	// so far, which are limited by maxWrites and maxWriteSize. 0 means no
+	// limit. Once a limit is exceeded, err is set and all the following
+	// writes fail. gas is the gas used by the writes.
+	writes, writeSize       int
+	maxWrites, maxWriteSize int
+	gas                     uint64
+	err                     error
+}
+
+func newDBStage(store db.DB, maxWrites, maxWriteSize int) *dbStage {
+	return &dbStage{
+		store:        store,
+		updates:      make(map[string][]byte),
+		deletes:      make(map[string]bool),
+		maxWrites:    maxWrites,
+		maxWriteSize: maxWriteSize,
+	}
+}
+
+// count adds a write of size bytes, and fails if it exceeds the limits.
+func (s *dbStage) count(size int) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.writes++
+	s.writeSize += size
+	s.gas += storageWriteGas + uint64(size)*storageByteGas
+	if (s.maxWrites > 0 && s.writes > s.maxWrites) ||
+		(s.maxWriteSize > 0 && s.writeSize > s.maxWriteSize) {
+		s.err = ErrStorageLimit
+	}
+	return s.err
+}
+
+func (s *dbStage) set(key, value []byte) error {
+	if err := s.count(len(key) + len(value)); err != nil {
+		return err
+	}
+
+	delete(s.deletes, string(key))
+	s.updates[string(key)] = value
+	return nil
+}
+
+// addEvent keeps the event emitted by the call. It is stored like a write, so
+// it counts toward the limits.
+func (s *dbStage) addEvent(name, jsonArgs string) error {
+	if err := s.count(len(name) + len(jsonArgs)); err != nil {
+		return err
+	}
+	s.events = append(s.events, &types.Event{
+		EventName: name,
+		JsonArgs:  jsonArgs,
+		EventIdx:  int32(len(s.events)),
+	})
+	return nil
+}
+
+func (s *dbStage) get(key []byte) []byte {
+	if s.deletes[string(key)] {
+		return nil
+	}
+	if value, exists := s.updates[string(key)]; exists {
+		return value
+	}
+	if s.parent != nil {
+		return s.parent.get(key)
+	}
+	return s.store.Get(key)
+}
+
+// delete removes key. It counts toward the limits like a write.
+func (s *dbStage) delete(key []byte) error {
+	if err := s.count(len(key)); err != nil {
+		return err
+	}
+
+	delete(s.updates, string(key))
+	s.deletes[string(key)] = true
+	return nil
+}
+
+// commit writes the buffered changes to the store in a single transaction, or to the parent stage if it is set.
+func (s *dbStage) commit() {
+	if len(s.updates) == 0 && len(s.deletes) == 0 {
+		return
+	}
+	if s.parent != nil {
+		for k, v := range s.updates {
+			s.parent.set([]byte(k), v)
+		}
+		for k := range s.deletes {
+			s.parent.delete([]byte(k))
+		}
+		return
+	}
+	tx := s.store.NewTx(true)
+	for k, v := range s.updates {
+		tx.Set([]byte(k), v)
+	}
+	for k := range s.deletes {
+		tx.Delete([]byte(k))
+	}
+	tx.Commit()
+}
@@ -0,0 +1,106 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package contract
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mr-tron/base58/base58"
+)
+
+// contractState is the serialized form of a contract, which has its code and
+// the items of its storage. Since the keys and values of storage items are
+// arbitrary bytes, the keys are hex encoded and the values are base64
+// encoded as any []byte.
+type contractState struct {
+	Address string            `json:"address"`
+	Code    []byte            `json:"code"`
+	Storage map[string][]byte `json:"storage"`
+}
+
+// storagePrefix returns the prefix of the db keys of the storage items of
+// the contract, which is the contract ID the vm namespaces them by.
+func storagePrefix(contractAddress []byte) []byte {
+	return []byte(base58.Encode(contractAddress) + "_")
+}
+
+// prefixEnd returns the smallest key greater than all the keys with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.TrimRight(prefix, "\xff")
+	if len(end) == 0 {
+		return nil
+	}
+	end = append([]byte{}, end...)
+	end[len(end)-1]++
+	return end
+}
+
+// ExportContractState writes the code and all the storage items of the
+// contract to w.
+func ExportContractState(address []byte, w io.Writer) error {
+	contract := getContract(address)
+	if contract == nil {
+		return fmt.Errorf("cannot find contract %s", base58.Encode(address))
+	}
+
+	state := &contractState{
+		Address: base58.Encode(address),
+		Code:    contract.code,
+		Storage: make(map[string][]byte),
+	}
+	prefix := storagePrefix(address)
+	for iter := DB.Iterator(prefix, prefixEnd(prefix)); iter.Valid(); iter.Next() {
+		state.Storage[hex.EncodeToString(iter.Key()[len(prefix):])] = append([]byte{}, iter.Value()...)
+	}
+
+	return json.NewEncoder(w).Encode(state)
+}
+
+// ImportContractState reads the state of a contract written by
+// ExportContractState from r, and stores it as the contract of address. The
+// storage items which the contract already has are replaced.
+func ImportContractState(address []byte, r io.Reader) error {
+	var state contractState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	if len(state.Code) == 0 {
+		return fmt.Errorf("no contract code in the state of %s", state.Address)
+	}
+
+	prefix := storagePrefix(address)
+	items := make(map[string][]byte, len(state.Storage))
+	for key, value := range state.Storage {
+		k, err := hex.DecodeString(key)
+		if err != nil {
+			return fmt.Errorf("invalid storage key %q in the state of %s: %s", key, state.Address, err.Error())
+		}
+		items[string(append(append([]byte{}, prefix...), k...))] = value
+	}
+
+	var stale [][]byte
+	for iter := DB.Iterator(prefix, prefixEnd(prefix)); iter.Valid(); iter.Next() {
+		stale = append(stale, append([]byte{}, iter.Key()...))
+	}
+
+	tx := DB.NewTx(true)
+	for _, key := range stale {
+		tx.Delete(key)
+	}
+	tx.Set(address, state.Code)
+	for key, value := range items {
+		tx.Set([]byte(key), value)
+	}
+	tx.Commit()
+
+	ctrLog.Info().Str("contractAddress", base58.Encode(address)).Str("from", state.Address).
+		Int("items", len(state.Storage)).Msg("contract state is imported")
+	return nil
+}
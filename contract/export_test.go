@@ -0,0 +1,63 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package contract
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aergoio/aergo-lib/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportContractState(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	assert.NoError(t, callTestContract(t, "tx1", `{"Name":"set","Args":["k1","v1"]}`))
+	assert.NoError(t, callTestContract(t, "tx2", `{"Name":"set","Args":["k2","v2"]}`))
+	// keys and values are not necessarily valid utf-8
+	DB.Set(testContractKey("\xff\xfe"), []byte{0xc3, 0x28, 0x00, 0xff})
+	items := map[string][]byte{
+		"k1":       DB.Get(testContractKey("k1")),
+		"k2":       DB.Get(testContractKey("k2")),
+		"\xff\xfe": DB.Get(testContractKey("\xff\xfe")),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportContractState(testContractAddress, &buf))
+	assert.Error(t, ExportContractState([]byte("unknown"), &bytes.Buffer{}))
+
+	// re-import into a fresh db
+	src := DB
+	dataDir, err := ioutil.TempDir("", "contract")
+	assert.NoError(t, err)
+	DB = db.NewDB(db.BadgerImpl, dataDir)
+	defer func() {
+		DB.Close()
+		os.RemoveAll(dataDir)
+		DB = src
+	}()
+
+	// stale item of the contract is replaced
+	DB.Set(testContractKey("k3"), []byte(`"v3"`))
+	assert.NoError(t, ImportContractState(testContractAddress, bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, []byte(testContractCode), DB.Get(testContractAddress))
+	for key, value := range items {
+		assert.Equal(t, value, DB.Get(testContractKey(key)))
+	}
+	assert.Empty(t, DB.Get(testContractKey("k3")))
+
+	// the imported contract keeps working
+	assert.NoError(t, callTestContract(t, "tx3", `{"Name":"set","Args":["k3","v3"]}`))
+	assert.NotEmpty(t, DB.Get(testContractKey("k3")))
+
+	assert.Error(t, ImportContractState(testContractAddress, bytes.NewReader([]byte("{}"))))
+	assert.Error(t, ImportContractState(testContractAddress,
+		bytes.NewReader([]byte(`{"code":"AA==","storage":{"not hex":"AA=="}}`))))
+}
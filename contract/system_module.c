@@ -4,7 +4,11 @@
 #include "util.h"
 #include "_cgo_export.h"
 
-extern const bc_ctx_t *getLuaExecContext(lua_State *L);
+/* getLuaExecContext returns the execution context, which is the upvalue of the functions of the module */
+static const bc_ctx_t *getLuaExecContext(lua_State *L)
+{
+	return (const bc_ctx_t *)lua_touserdata(L, lua_upvalueindex(1));
+}
 
 static int systemPrint(lua_State *L)
 {
@@ -17,6 +21,7 @@ static int setItem(lua_State *L)
 	const char *key;
 	char *jsonValue;
 	char *dbKey;
+	char *errMsg;
 	const bc_ctx_t *exec = getLuaExecContext(L);
 	if (exec == NULL) {
 		luaL_error(L, "cannot find execution context");
@@ -28,9 +33,14 @@ static int setItem(lua_State *L)
 
 	dbKey = lua_util_get_db_key(exec, key);
 
-	LuaSetDB(dbKey, jsonValue);
+	errMsg = LuaSetDB(dbKey, jsonValue);
 	free(jsonValue);
 	free(dbKey);
+	if (errMsg != NULL) {
+		lua_pushstring(L, errMsg);
+		free(errMsg);
+		lua_error(L);
+	}
 
 	return 0;
 }
@@ -61,17 +71,22 @@ static int delItem(lua_State *L)
 {
 	const char *key;
 	char *dbKey;
+	char *errMsg;
 	const bc_ctx_t *exec = getLuaExecContext(L);
-	char *jsonValue;
 	if (exec == NULL) {
 		luaL_error(L, "cannot find execution context");
 	}
 	key = luaL_checkstring(L, 1);
 	dbKey = lua_util_get_db_key(exec, key);
 
-	LuaDelDB(dbKey);
-
+	errMsg = LuaDelDB(dbKey);
 	free(dbKey);
+	if (errMsg != NULL) {
+		lua_pushstring(L, errMsg);
+		free(errMsg);
+		lua_error(L);
+	}
+
 	return 0;
 }
 
@@ -135,6 +150,33 @@ static int getContractID(lua_State *L)
 	return 1;
 }
 
+static int emitEvent(lua_State *L)
+{
+	const char *eventName;
+	char *jsonArgs;
+	char *errMsg;
+	sbuff_t sbuf;
+	const bc_ctx_t *exec = getLuaExecContext(L);
+	if (exec == NULL) {
+		luaL_error(L, "cannot find execution context");
+	}
+	eventName = luaL_checkstring(L, 1);
+
+	lua_util_sbuf_init(&sbuf, 64);
+	jsonArgs = lua_util_get_json_from_ret(L, lua_gettop(L) - 1, &sbuf);
+	jsonArgs[sbuf.idx] = '\0';
+
+	errMsg = LuaEvent((char *)eventName, jsonArgs);
+	free(sbuf.buf);
+	if (errMsg != NULL) {
+		lua_pushstring(L, errMsg);
+		free(errMsg);
+		lua_error(L);
+	}
+
+	return 0;
+}
+
 static const luaL_Reg sys_lib[] = {
 	{"print", systemPrint},
 	{"setItem", setItem},
@@ -147,11 +189,13 @@ static const luaL_Reg sys_lib[] = {
 	{"getBlockheight", getBlockHeight},
 	{"getTimestamp", getTimestamp},
 	{"getContractID", getContractID},
+	{"event", emitEvent},
 	{NULL, NULL}
 };
 
+/* luaopen_system opens the module, whose functions have the execution context on the top of the stack as their upvalue */
 int luaopen_system(lua_State *L)
 {
-	luaL_register(L, "system", sys_lib);
+	luaL_openlib(L, "system", sys_lib, 1);
 	return 1;
 }
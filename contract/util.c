@@ -34,7 +34,8 @@ static void dump_json (lua_State *L, int idx, sbuff_t *sbuf)
 	switch (lua_type(L, idx)) {
 	case LUA_TNUMBER: {
 		char tmp[128];
-		len = sprintf (tmp, "%g,", lua_tonumber(L, idx));
+		/* the number format of Lua, which rounds numbers to 14 significant digits */
+		len = sprintf (tmp, "%.14g,", lua_tonumber(L, idx));
 		src_val = tmp;
 		break;
 	}
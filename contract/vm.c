@@ -4,50 +4,367 @@
 #include "system_module.h"
 #include "util.h"
 
-const char *luaExecContext= "__exec_context__";
-
-static void preloadModules(lua_State *L)
+/*
+ * The execution context is given to the functions of the system module as
+ * their upvalue, which contract code can neither read nor change.
+ */
+static void preloadModules(lua_State *L, bc_ctx_t *bc_ctx)
 {
+	lua_pushlightuserdata(L, bc_ctx);
 	luaopen_system(L);
+	lua_pop(L, 1);
 }
 
-static void setLuaExecContext(lua_State *L, bc_ctx_t *bc_ctx)
+/*
+ * Only the libraries which give the same results on every node are opened, so
+ * that the contract calls are deterministic. io, os, package, debug, jit and
+ * ffi are not opened; the time is given only by system.getTimestamp, which is
+ * the timestamp of the block. The following functions are removed as well:
+ *   dofile, loadfile, require  access the file system
+ *   load, loadstring           load any chunk, including LuaJIT bytecode
+ *                              which can escape the sandbox
+ *   string.dump                makes the bytecode of a function
+ *   collectgarbage, gcinfo     expose the memory usage of the node
+ *   print                      writes to the node; system.print is used instead
+ *   math.random, randomseed    are not same among nodes
+ * tostring and string.format are replaced by the ones which don't print the
+ * addresses of tables, functions, userdata and threads, since the addresses
+ * differ among nodes. error and assert are replaced by the ones which mark
+ * the raised error as a revert of the contract, see vmRevertKey.
+ */
+static const luaL_Reg safe_libs[] = {
+	{"", luaopen_base},
+	{LUA_TABLIBNAME, luaopen_table},
+	{LUA_STRLIBNAME, luaopen_string},
+	{LUA_MATHLIBNAME, luaopen_math},
+	{LUA_BITLIBNAME, luaopen_bit},
+	{NULL, NULL}
+};
+
+static const char *removed_globals[] = {
+	"dofile", "loadfile", "require", "load", "loadstring", "collectgarbage", "gcinfo", "print", NULL
+};
+
+static const char *removed_math[] = {
+	"random", "randomseed", NULL
+};
+
+static const char *removed_string[] = {
+	"dump", NULL
+};
+
+/*
+ * pushSafeString pushes the string of the value at idx. A value having an
+ * address is converted by its __tostring metamethod, or to its type name.
+ */
+static void pushSafeString(lua_State *L, int idx)
 {
-	lua_pushlightuserdata(L, bc_ctx);
-	lua_setglobal(L, luaExecContext);
+	switch (lua_type(L, idx)) {
+	case LUA_TTABLE:
+	case LUA_TFUNCTION:
+	case LUA_TUSERDATA:
+	case LUA_TLIGHTUSERDATA:
+	case LUA_TTHREAD:
+		if (!luaL_callmeta(L, idx, "__tostring")) {
+			lua_pushstring(L, luaL_typename(L, idx));
+		}
+		return;
+	}
+	/* the others are converted by the original tostring */
+	lua_pushvalue(L, lua_upvalueindex(1));
+	lua_pushvalue(L, idx);
+	lua_call(L, 1, 1);
+}
+
+static int safeTostring(lua_State *L)
+{
+	luaL_checkany(L, 1);
+	pushSafeString(L, 1);
+	return 1;
+}
+
+static int safeFormat(lua_State *L)
+{
+	int i, n = lua_gettop(L);
+
+	for (i = 2; i <= n; i++) {
+		switch (lua_type(L, i)) {
+		case LUA_TTABLE:
+		case LUA_TFUNCTION:
+		case LUA_TUSERDATA:
+		case LUA_TLIGHTUSERDATA:
+		case LUA_TTHREAD:
+			pushSafeString(L, i);
+			lua_replace(L, i);
+		}
+	}
+	lua_pushvalue(L, lua_upvalueindex(2));
+	lua_insert(L, 1);
+	lua_call(L, n, 1);
+	return 1;
+}
+
+/*
+ * The value raised by error or assert of the contract is kept in the registry,
+ * so that vm_pcall can tell a revert of the contract from the errors raised by
+ * the VM, like indexing nil, running out of memory or gas.
+ */
+static const char *vmRevertKey = "__revert__";
+
+/* raiseRevert raises the value at -1 as a revert */
+static int raiseRevert(lua_State *L)
+{
+	lua_pushvalue(L, -1);
+	lua_setfield(L, LUA_REGISTRYINDEX, vmRevertKey);
+	return lua_error(L);
+}
+
+/* contractError is error of the base library, which raises a revert */
+static int contractError(lua_State *L)
+{
+	int level = luaL_optint(L, 2, 1);
+	lua_settop(L, 1);
+	if (lua_isstring(L, 1) && level > 0) {
+		luaL_where(L, level);
+		lua_pushvalue(L, 1);
+		lua_concat(L, 2);
+	}
+	return raiseRevert(L);
+}
+
+/* contractAssert is assert of the base library, which raises a revert */
+static int contractAssert(lua_State *L)
+{
+	luaL_checkany(L, 1);
+	if (!lua_toboolean(L, 1)) {
+		lua_pushstring(L, luaL_optstring(L, 2, "assertion failed!"));
+		return raiseRevert(L);
+	}
+	return lua_gettop(L);
+}
+
+/* replaceFunc replaces the function name of the table at -1 by fn, which has the original as its upvalues */
+static void replaceFunc(lua_State *L, const char *name, lua_CFunction fn, int nup)
+{
+	lua_pushcclosure(L, fn, nup);
+	lua_setfield(L, -2, name);
+}
+
+static void openSafeLibs(lua_State *L)
+{
+	const luaL_Reg *lib;
+	const char **name;
+
+	for (lib = safe_libs; lib->func != NULL; lib++) {
+		lua_pushcfunction(L, lib->func);
+		lua_pushstring(L, lib->name);
+		lua_call(L, 1, 0);
+	}
+	for (name = removed_globals; *name != NULL; name++) {
+		lua_pushnil(L);
+		lua_setglobal(L, *name);
+	}
+	lua_getglobal(L, LUA_MATHLIBNAME);
+	for (name = removed_math; *name != NULL; name++) {
+		lua_pushnil(L);
+		lua_setfield(L, -2, *name);
+	}
+	lua_pop(L, 1);
+
+	lua_getglobal(L, LUA_STRLIBNAME);
+	for (name = removed_string; *name != NULL; name++) {
+		lua_pushnil(L);
+		lua_setfield(L, -2, *name);
+	}
+	/* string.format calls the original tostring, and then the original format */
+	lua_getglobal(L, "tostring");
+	lua_getfield(L, -2, "format");
+	replaceFunc(L, "format", safeFormat, 2);
+	lua_pop(L, 1);
+
+	lua_pushvalue(L, LUA_GLOBALSINDEX);
+	lua_getglobal(L, "tostring");
+	replaceFunc(L, "tostring", safeTostring, 1);
+	replaceFunc(L, "error", contractError, 0);
+	replaceFunc(L, "assert", contractAssert, 0);
+	lua_pop(L, 1);
 }
 
-const bc_ctx_t *getLuaExecContext(lua_State *L)
+/*
+ * The gas of a call is the number of instructions per instrPerGas, plus the
+ * bytes allocated by the call per VM_BYTES_PER_GAS. The allocations are
+ * metered by the allocator, since the library functions like string.rep or
+ * table.concat can allocate any amount of memory within a single instruction.
+ * An allocation which exceeds the gas limit or VM_MAX_MEMORY fails.
+ */
+#define VM_BYTES_PER_GAS 100
+#define VM_MAX_MEMORY (256 * 1024 * 1024)
+
+typedef struct vm_gas {
+	unsigned long long limit;
+	unsigned long long used;
+	/*
+	 * allocated is the total bytes allocated while metered, and live is the
+	 * bytes in use. The allocations are metered only within the protected
+	 * calls, since a failed allocation out of them aborts the node.
+	 */
+	unsigned long long allocated;
+	size_t live;
+	int metered;
+	int exceeded;
+	/* the allocator of the state, which the allocations are passed to */
+	lua_Alloc allocf;
+	void *allocd;
+} vm_gas_t;
+
+/* the gas is kept in the registry, which contract code can't access */
+static const char *vmGasKey = "__gas__";
+
+static vm_gas_t *getGas(lua_State *L)
 {
-	bc_ctx_t *exec;
-	lua_getglobal(L, luaExecContext);
-	exec = (bc_ctx_t *)lua_touserdata(L, -1);
+	vm_gas_t *gas;
+	lua_getfield(L, LUA_REGISTRYINDEX, vmGasKey);
+	gas = (vm_gas_t *)lua_touserdata(L, -1);
 	lua_pop(L, 1);
 
-	return exec;
+	return gas;
+}
+
+static unsigned long long gasUsed(vm_gas_t *gas)
+{
+	return gas->used + gas->allocated / VM_BYTES_PER_GAS;
+}
+
+static void *meteredAlloc(void *ud, void *ptr, size_t osize, size_t nsize)
+{
+	vm_gas_t *gas = (vm_gas_t *)ud;
+	void *p;
+
+	if (gas->metered && nsize > osize) {
+		size_t grow = nsize - osize;
+		if (gas->exceeded || gas->live + grow > VM_MAX_MEMORY ||
+			gas->used + (gas->allocated + grow) / VM_BYTES_PER_GAS > gas->limit) {
+			/* the VM raises a memory error, and the call is aborted by the hook even if it catches it */
+			gas->exceeded = 1;
+			return NULL;
+		}
+		gas->allocated += grow;
+	}
+	p = gas->allocf(gas->allocd, ptr, osize, nsize);
+	if (p == NULL && nsize > 0) {
+		return NULL;
+	}
+	if (nsize >= osize) {
+		gas->live += nsize - osize;
+	} else if (gas->live > osize - nsize) {
+		gas->live -= osize - nsize;
+	} else {
+		/* the memory allocated before the allocator is set up is freed */
+		gas->live = 0;
+	}
+	return p;
+}
+
+static void gasHook(lua_State *L, lua_Debug *ar)
+{
+	vm_gas_t *gas = getGas(L);
+	if (gas == NULL) {
+		return;
+	}
+	if (!gas->exceeded) {
+		gas->used++;
+		if (gasUsed(gas) <= gas->limit) {
+			return;
+		}
+		gas->exceeded = 1;
+	}
+	/* raise the error at every following instruction, so that the contract can't go on by catching it */
+	lua_sethook(L, gasHook, LUA_MASKCOUNT, 1);
+	luaL_error(L, "out of gas");
+}
+
+/* meteredPcall calls the function on the stack like lua_pcall, metering the allocations made by the call */
+static int meteredPcall(lua_State *L, int nargs, int nresults)
+{
+	vm_gas_t *gas = getGas(L);
+	int err;
+
+	gas->metered = 1;
+	err = lua_pcall(L, nargs, nresults, 0);
+	gas->metered = 0;
+	return err;
+}
+
+void vm_set_gas(lua_State *L, unsigned long long limit, int instrPerGas)
+{
+	vm_gas_t *gas = getGas(L);
+	gas->limit = limit;
+	gas->used = 0;
+	gas->allocated = 0;
+	gas->exceeded = 0;
+
+	/* hooks are not called from jit-compiled code, so the instructions are counted only by the interpreter */
+	luaJIT_setmode(L, 0, LUAJIT_MODE_ENGINE | LUAJIT_MODE_OFF);
+	lua_sethook(L, gasHook, LUA_MASKCOUNT, instrPerGas);
+}
+
+unsigned long long vm_gas_used(lua_State *L, int *exceeded)
+{
+	vm_gas_t *gas = getGas(L);
+	unsigned long long used;
+	if (gas == NULL) {
+		*exceeded = 0;
+		return 0;
+	}
+	used = gasUsed(gas);
+	*exceeded = gas->exceeded || used > gas->limit;
+	if (used > gas->limit) {
+		used = gas->limit;
+	}
+	return used;
 }
 
 lua_State *vm_newstate()
 {
 	lua_State *L = luaL_newstate();
-	luaL_openlibs(L);
-	preloadModules(L);
+	vm_gas_t *gas;
+
+	if (L == NULL) {
+		return NULL;
+	}
+	/* the allocations are not metered until vm_set_gas */
+	gas = (vm_gas_t *)calloc(1, sizeof(vm_gas_t));
+	gas->allocf = lua_getallocf(L, &gas->allocd);
+	lua_setallocf(L, meteredAlloc, gas);
+	lua_pushlightuserdata(L, gas);
+	lua_setfield(L, LUA_REGISTRYINDEX, vmGasKey);
+
+	openSafeLibs(L);
 	return L;
 }
 
+void vm_close(lua_State *L)
+{
+	void *gas;
+
+	lua_getallocf(L, &gas);
+	lua_close(L);
+	free(gas);
+}
+
 const char *vm_loadbuff(lua_State *L, const char *code, size_t sz, const char *name, bc_ctx_t *bc_ctx)
 {
 	int err;
 	const char *errMsg = NULL;
 
-	setLuaExecContext(L, bc_ctx);
+	preloadModules(L, bc_ctx);
 
 	err = luaL_loadbuffer(L, code, sz, name);
 	if (err != 0) {
 		errMsg = strdup(lua_tostring(L, -1));
 		return errMsg;
 	}
-	err = lua_pcall(L, 0, 0, 0);
+	err = meteredPcall(L, 0, 0);
 	if (err != 0) {
 		errMsg = strdup(lua_tostring(L, -1));
 		return errMsg;
@@ -60,15 +377,26 @@ void vm_getfield(lua_State *L, const char *name)
 	lua_getfield(L, LUA_GLOBALSINDEX, name);
 }
 
-const char *vm_pcall(lua_State *L, int argc, int *nresult)
+const char *vm_pcall(lua_State *L, int argc, int *nresult, int *reverted)
 {
 	int err;
 	const char *errMsg = NULL;
 	int nr = lua_gettop(L);
 
-	err = lua_pcall(L, argc, LUA_MULTRET, 0);
+	lua_pushnil(L);
+	lua_setfield(L, LUA_REGISTRYINDEX, vmRevertKey);
+	err = meteredPcall(L, argc, LUA_MULTRET);
 	if (err != 0) {
-		errMsg = strdup(lua_tostring(L, -1));
+		/* the call is reverted only if the error is the last one raised by the contract */
+		lua_getfield(L, LUA_REGISTRYINDEX, vmRevertKey);
+		*reverted = err == LUA_ERRRUN && lua_rawequal(L, -1, -2);
+		lua_pop(L, 1);
+		if (lua_isstring(L, -1)) {
+			errMsg = strdup(lua_tostring(L, -1));
+		} else {
+			/* an error object, like a table, has no message */
+			errMsg = strdup(luaL_typename(L, -1));
+		}
 		return errMsg;
 	}
 	*nresult = lua_gettop(L) - nr + 1;
@@ -85,4 +413,3 @@ const char *vm_get_json_ret(lua_State *L, int nresult)
 	
 	return lua_tostring(L, -1);
 }
-
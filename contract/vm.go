@@ -15,10 +15,14 @@ package contract
 */
 import "C"
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"unsafe"
 
 	"github.com/aergoio/aergo-lib/db"
@@ -29,9 +33,36 @@ import (
 
 const DbName = "contracts.db"
 
+const (
+	// gasInstructions is the number of VM instructions which cost 1 gas.
+	gasInstructions = 100
+	// defaultGasLimit is the gas limit of a call whose tx doesn't set it.
+	defaultGasLimit = 1000000
+
+	// maxStorageWrites and maxStorageWriteSize are the limits of the number and the total bytes of the storage
+	// writes, deletes and events per call. They are part of the protocol, so that every node gets the same result.
+	maxStorageWrites    = 10000
+	maxStorageWriteSize = 4 * 1024 * 1024
+	// storageWriteGas is the gas of a storage write, delete or event, and storageByteGas is the gas of each byte of
+	// its key and value.
+	storageWriteGas = 100
+	storageByteGas  = 1
+)
+
 var (
 	ctrLog *log.Logger
 	DB     db.DB
+
+	// ErrOutOfGas is the error of a call aborted by exceeding its gas limit.
+	ErrOutOfGas = errors.New("out of gas")
+	// ErrStorageLimit is the error of a call aborted by writing to the storage over the limits per call. The storage
+	// writes are what the gas of a call is mostly paid for, so it is reported as running out of gas.
+	ErrStorageLimit = errors.New("out of gas: storage write limit exceeded")
+
+	// curStage buffers the db writes of the contract call being executed.
+	curStage *dbStage
+	// blockStage keeps the db writes of the calls executed by DryRun or Stage, instead of DB.
+	blockStage *dbStage
 )
 
 type Contract struct {
@@ -39,6 +70,32 @@ type Contract struct {
 	address []byte
 }
 
+// revertError is the error raised by the contract code itself by error or assert. The state changes of the
+// call are rolled back, but the transaction remains valid. The errors raised by the VM, like indexing nil or
+// running out of memory, are not reverts.
+type revertError struct {
+	reason string
+}
+
+func (e *revertError) Error() string {
+	return e.reason
+}
+
+// receiptStatus returns the receipt status corresponding to the result of a call.
+func receiptStatus(err error) string {
+	switch err.(type) {
+	case nil:
+		return types.ReceiptSuccess
+	case *revertError:
+		return types.ReceiptReverted
+	}
+	switch err {
+	case ErrOutOfGas, ErrStorageLimit:
+		return types.ReceiptOutOfGas
+	}
+	return types.ReceiptError
+}
+
 type LState = C.struct_lua_State
 type LBlockchainCtx = C.struct_blockchain_ctx
 
@@ -80,15 +137,17 @@ func newLState() *LState {
 
 func (L *LState) Close() {
 	if L != nil {
-		C.lua_close(L)
+		C.vm_close(L)
 	}
 }
 
-func newExecutor(contract *Contract, bcCtx *LBlockchainCtx) *Executor {
+func newExecutor(contract *Contract, bcCtx *LBlockchainCtx, gasLimit uint64) *Executor {
 	ce := &Executor{
 		contract: contract,
 		L:        newLState(),
 	}
+	// loading the code is also charged, since it executes the top level statements
+	C.vm_set_gas(ce.L, C.ulonglong(gasLimit), C.int(gasInstructions))
 	if cErrMsg := C.vm_loadbuff(
 		ce.L,
 		(*C.char)(unsafe.Pointer(&contract.code[0])),
@@ -112,33 +171,150 @@ func (ce *Executor) call(abi *types.ABI) {
 	C.lua_getfield(ce.L, -1, C.CString("call"))
 	C.lua_pushstring(ce.L, C.CString(abi.Name))
 	for _, v := range abi.Args {
-		switch arg := v.(type) {
-		case string:
-			C.lua_pushstring(ce.L, C.CString(arg))
-		case int:
-			C.lua_pushinteger(ce.L, C.long(arg))
-		case bool:
-			var b int
-			if arg {
-				b = 1
-			}
-			C.lua_pushboolean(ce.L, C.int(b))
-		default:
-			ce.err = errors.New("unsupported type")
+		if err := ce.pushValue(v); err != nil {
+			ce.err = err
 			return
 		}
 	}
 	nret := C.int(0)
-	if cErrMsg := C.vm_pcall(ce.L, C.int(len(abi.Args)+1), &nret); cErrMsg != nil {
+	reverted := C.int(0)
+	if cErrMsg := C.vm_pcall(ce.L, C.int(len(abi.Args)+1), &nret, &reverted); cErrMsg != nil {
 		errMsg := C.GoString(cErrMsg)
 		C.free(unsafe.Pointer(cErrMsg))
-		ctrLog.Warn().Str("error", errMsg).Msgf("contract %s", base58.Encode(ce.contract.address))
-		ce.err = errors.New(errMsg)
+		ctrLog.Warn().Str("error", errMsg).Bool("reverted", reverted != 0).
+			Msgf("contract %s", base58.Encode(ce.contract.address))
+		if reverted != 0 {
+			ce.err = &revertError{reason: errMsg}
+		} else {
+			ce.err = errors.New(errMsg)
+		}
 		return
 	}
 	ce.jsonRet = C.GoString(C.vm_get_json_ret(ce.L, nret))
 }
 
+// maxExactInteger is the largest integer which a Lua number represents exactly.
+const maxExactInteger = 1 << 53
+
+// pushValue pushes an argument of a call onto the Lua stack. Byte arrays are pushed as Lua strings, and maps and
+// slices, such as decoded from JSON objects and arrays, are pushed as Lua tables.
+func (ce *Executor) pushValue(v interface{}) error {
+	if C.lua_checkstack(ce.L, 2) == 0 {
+		return errors.New("argument is too deep")
+	}
+	switch arg := v.(type) {
+	case nil:
+		C.lua_pushnil(ce.L)
+	case string:
+		ce.pushBytes([]byte(arg))
+	case []byte:
+		ce.pushBytes(arg)
+	case int:
+		C.lua_pushinteger(ce.L, C.long(arg))
+	case json.Number:
+		if !strings.ContainsAny(arg.String(), ".eE") {
+			// an integer is rejected rather than rounded if a Lua number can't hold it
+			i, err := arg.Int64()
+			if err != nil || i > maxExactInteger || i < -maxExactInteger {
+				return fmt.Errorf("integer out of range: %s", arg)
+			}
+			C.lua_pushinteger(ce.L, C.long(i))
+			break
+		}
+		f, err := arg.Float64()
+		if err != nil {
+			return err
+		}
+		C.lua_pushnumber(ce.L, C.double(f))
+	case bool:
+		var b int
+		if arg {
+			b = 1
+		}
+		C.lua_pushboolean(ce.L, C.int(b))
+	case []interface{}:
+		C.lua_createtable(ce.L, C.int(len(arg)), 0)
+		for i, elem := range arg {
+			if err := ce.pushValue(elem); err != nil {
+				return err
+			}
+			C.lua_rawseti(ce.L, -2, C.int(i+1))
+		}
+	case map[string]interface{}:
+		if b, ok, err := bytesArg(arg); ok {
+			if err != nil {
+				return err
+			}
+			ce.pushBytes(b)
+			break
+		}
+		// the keys are inserted in order, so that the table is iterated in the same order on every node
+		keys := make([]string, 0, len(arg))
+		for k := range arg {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		C.lua_createtable(ce.L, 0, C.int(len(arg)))
+		for _, k := range keys {
+			ce.pushBytes([]byte(k))
+			if err := ce.pushValue(arg[k]); err != nil {
+				return err
+			}
+			C.lua_rawset(ce.L, -3)
+		}
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+	return nil
+}
+
+// bytesArg returns the byte array which arg encodes, if it is the object of types.ABIBytesKey only.
+func bytesArg(arg map[string]interface{}) ([]byte, bool, error) {
+	encoded, ok := arg[types.ABIBytesKey]
+	if !ok || len(arg) != 1 {
+		return nil, false, nil
+	}
+	s, ok := encoded.(string)
+	if !ok {
+		return nil, true, fmt.Errorf("byte array argument is not a base58 string: %v", encoded)
+	}
+	b, err := base58.Decode(s)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid byte array argument %s: %s", s, err.Error())
+	}
+	return b, true, nil
+}
+
+// decodeABI decodes the JSON ABI of a call. Numbers are decoded as json.Number, so that an integer argument isn't
+// rounded through float64.
+func decodeABI(code []byte, abi *types.ABI) error {
+	dec := json.NewDecoder(bytes.NewReader(code))
+	dec.UseNumber()
+	if err := dec.Decode(abi); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid data after the ABI")
+	}
+	return nil
+}
+
+// pushBytes pushes b as a Lua string, which may have zero bytes in it.
+func (ce *Executor) pushBytes(b []byte) {
+	n := len(b)
+	if n == 0 {
+		b = []byte{0}
+	}
+	C.lua_pushlstring(ce.L, (*C.char)(unsafe.Pointer(&b[0])), C.size_t(n))
+}
+
+// gasUsed returns the gas used by the executor so far, and whether it ran out of gas.
+func (ce *Executor) gasUsed() (uint64, bool) {
+	var exceeded C.int
+	used := C.vm_gas_used(ce.L, &exceeded)
+	return uint64(used), exceeded != 0
+}
+
 func (ce *Executor) close() {
 	if ce != nil {
 		ce.L.Close()
@@ -153,44 +329,130 @@ func (ce *Executor) close() {
 	}
 }
 
-func Call(code, contractAddress, txHash []byte, bcCtx *LBlockchainCtx) error {
+// Call executes the contract call of tx within gasLimit, and returns the gas used by it. 0 gasLimit means
+// defaultGasLimit. The VM is interrupted as soon as the call runs out of gas, and the call is still a valid tx
+// whose contract changes are discarded. So is the call of a missing contract or with an invalid ABI, whose
+// failure is recorded in the receipt.
+func Call(code, contractAddress, txHash []byte, bcCtx *LBlockchainCtx, gasLimit uint64) (uint64, error) {
+	gasLimit = GasLimit(gasLimit)
 	var err error
+	var gasUsed uint64
+	var events []*types.Event
+	var abi types.ABI
 	contract := getContract(contractAddress)
 	if contract == nil {
-		err = fmt.Errorf("cannot find contract %s", string(contractAddress))
-		ctrLog.Warn().AnErr("err", err)
-	}
-	var abi types.ABI
-	err = json.Unmarshal(code, &abi)
-	if err != nil {
+		err = fmt.Errorf("cannot find contract %s", base58.Encode(contractAddress))
+		ctrLog.Warn().AnErr("err", err).Msg("failed to call contract")
+	} else if err = decodeABI(code, &abi); err != nil {
 		ctrLog.Warn().AnErr("error", err).Msgf("contract %s", base58.Encode(contractAddress))
 	}
+	// ce is nil unless the call is executed
 	var ce *Executor
-	defer ce.close()
+	defer func() {
+		ce.close()
+	}()
 	if err == nil {
 		ctrLog.Debug().Str("abi", string(code)).Msgf("contract %s", base58.Encode(contractAddress))
-		ce = newExecutor(contract, bcCtx)
+		curStage = newDBStage(DB, maxStorageWrites, maxStorageWriteSize)
+		curStage.parent = blockStage
+		ce = newExecutor(contract, bcCtx, gasLimit)
 		ce.call(&abi)
 		err = ce.err
+		var outOfGas bool
+		gasUsed, outOfGas = ce.gasUsed()
+		// the storage writes are paid by gas as well as the instructions
+		if gasUsed += curStage.gas; gasUsed > gasLimit {
+			gasUsed, outOfGas = gasLimit, true
+		}
+		// the limits are enforced even if the contract code catches the error
+		if outOfGas {
+			ctrLog.Warn().Uint64("limit", gasLimit).Msgf("contract %s ran out of gas", base58.Encode(contractAddress))
+			err = ErrOutOfGas
+		} else if curStage.err != nil {
+			ctrLog.Warn().Int("writes", curStage.writes).Int("size", curStage.writeSize).
+				Msgf("contract %s exceeded storage write limit", base58.Encode(contractAddress))
+			err = curStage.err
+		}
+		if err == nil {
+			curStage.commit()
+			events = curStage.events
+		}
+		curStage = nil
 	}
-	receipt := types.NewReceipt(contractAddress, "SUCCESS", ce.jsonRet)
+	receipt := types.NewReceipt(contractAddress, receiptStatus(err), "")
+	receipt.GasUsed = gasUsed
 	if err != nil {
-		receipt.Status = err.Error()
+		receipt.Ret = err.Error()
+	} else {
+		receipt.Ret = ce.jsonRet
+		for _, event := range events {
+			event.ContractAddress = contractAddress
+		}
+		receipt.Events = events
 	}
-	DB.Set(txHash, receipt.Bytes())
-	return err
+	dbSet(txHash, receipt.Bytes())
+	// the tx is valid although the contract isn't executed or its changes are discarded, and the result is
+	// recorded in the receipt
+	return gasUsed, nil
 }
 
 func Create(code, contractAddress, txHash []byte) error {
 	ctrLog.Debug().Str("contractAddress", base58.Encode(contractAddress)).Msg("new contract is deployed")
-	DB.Set(contractAddress, code)
-	receipt := types.NewReceipt(contractAddress, "CREATED", "{}")
-	DB.Set(txHash, receipt.Bytes())
+	dbSet(contractAddress, code)
+	receipt := types.NewReceipt(contractAddress, types.ReceiptCreated, "{}")
+	dbSet(txHash, receipt.Bytes())
 	return nil
 }
 
+// GasLimit returns the gas limit of a call whose tx sets limit, which is defaultGasLimit if it's not set.
+func GasLimit(limit uint64) uint64 {
+	if limit == 0 {
+		return defaultGasLimit
+	}
+	return limit
+}
+
+// DryRun executes the contract calls in fn without changing DB, such as to compute the result of txs before they
+// are added to chain. The writes of a call are seen by the following calls in fn, and discarded after fn returns.
+func DryRun(fn func() error) error {
+	_, err := Stage(fn)
+	return err
+}
+
+// Stage executes the contract calls in fn like DryRun, but keeps their writes, including the receipts, until the
+// returned commit writes them to DB in a single transaction, such as after the block of the calls is validated.
+// The writes are discarded unless commit is called, and commit is nil if fn fails.
+func Stage(fn func() error) (commit func(), err error) {
+	stage := newDBStage(DB, 0, 0)
+	blockStage = stage
+	defer func() {
+		blockStage = nil
+	}()
+	if err := fn(); err != nil {
+		return nil, err
+	}
+	return stage.commit, nil
+}
+
+// dbSet writes to DB, or to the stage of DryRun or Stage while it is in progress.
+func dbSet(key, value []byte) {
+	if blockStage != nil {
+		blockStage.set(key, value)
+		return
+	}
+	DB.Set(key, value)
+}
+
+// dbGet reads from DB, seeing the writes of DryRun or Stage while it is in progress.
+func dbGet(key []byte) []byte {
+	if blockStage != nil {
+		return blockStage.get(key)
+	}
+	return DB.Get(key)
+}
+
 func getContract(contractAddress []byte) *Contract {
-	val := DB.Get(contractAddress)
+	val := dbGet(contractAddress)
 	if len(val) > 0 {
 		return &Contract{
 			code:    val,
@@ -200,33 +462,62 @@ func getContract(contractAddress []byte) *Contract {
 	return nil
 }
 
+// GetEvents returns the events emitted by the contract call of tx. The location of events in chain is not filled.
+func GetEvents(txHash []byte) []*types.Event {
+	receipt := GetReceipt(txHash)
+	if receipt == nil {
+		return nil
+	}
+	return receipt.Events
+}
+
+// GetReceipt returns the receipt of the contract call of tx, with the events emitted by the call.
 func GetReceipt(txHash []byte) *types.Receipt {
-	val := DB.Get(txHash)
+	val := dbGet(txHash)
 	if len(val) == 0 {
 		return nil
 	}
-	return types.NewReceiptFromBytes(val)
+	receipt, err := types.NewReceiptFromBytes(val)
+	if err != nil {
+		ctrLog.Error().Err(err).Msg("failed to load receipt")
+		return nil
+	}
+	return receipt
 }
 
 //export LuaSetDB
-func LuaSetDB(key *C.char, value *C.char) {
+func LuaSetDB(key *C.char, value *C.char) *C.char {
 	keyString := C.GoString(key)
 	valueString := C.GoString(value)
 
-	DB.Set([]byte(keyString), []byte(valueString))
+	if err := curStage.set([]byte(keyString), []byte(valueString)); err != nil {
+		return C.CString(err.Error())
+	}
+	return nil
 }
 
 //export LuaGetDB
 func LuaGetDB(key *C.char) unsafe.Pointer {
 	keyString := C.GoString(key)
 
-	return C.CBytes(DB.Get([]byte(keyString)))
+	return C.CBytes(curStage.get([]byte(keyString)))
+}
+
+//export LuaEvent
+func LuaEvent(eventName *C.char, jsonArgs *C.char) *C.char {
+	if err := curStage.addEvent(C.GoString(eventName), C.GoString(jsonArgs)); err != nil {
+		return C.CString(err.Error())
+	}
+	return nil
 }
 
 //export LuaDelDB
-func LuaDelDB(key *C.char) {
+func LuaDelDB(key *C.char) *C.char {
 	keyString := C.GoString(key)
 
-	DB.Delete([]byte(keyString))
+	if err := curStage.delete([]byte(keyString)); err != nil {
+		return C.CString(err.Error())
+	}
+	return nil
 }
 
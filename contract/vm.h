@@ -17,9 +17,12 @@ typedef struct blockchain_ctx {
 } bc_ctx_t;
 
 lua_State *vm_newstate();
+void vm_close(lua_State *L);
 void vm_getfield(lua_State *L, const char *name);
 const char *vm_loadbuff(lua_State *L, const char *code, size_t sz, const char *name, bc_ctx_t *bc_ctx);
-const char *vm_pcall(lua_State *L, int argc, int* nresult);
+const char *vm_pcall(lua_State *L, int argc, int* nresult, int *reverted);
 const char *vm_get_json_ret(lua_State *L, int nresult);
+void vm_set_gas(lua_State *L, unsigned long long limit, int instrPerGas);
+unsigned long long vm_gas_used(lua_State *L, int *exceeded);
 
 #endif /* _VM_H */
@@ -0,0 +1,569 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/aergoio/aergo-lib/db"
+	"github.com/aergoio/aergo/types"
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+const testContractCode = `
+function set(key, value)
+	system.setItem(key, value)
+end
+
+function setAndRevert(key, value)
+	system.setItem(key, value)
+	error("revert: " .. key)
+end
+
+function delAndRevert(key)
+	system.delItem(key)
+	error("revert: " .. key)
+end
+
+function setMany(n)
+	for i = 1, tonumber(n) do
+		system.setItem("k" .. i, "v" .. i)
+	end
+end
+
+function setManyCatch(n)
+	pcall(setMany, n)
+end
+
+function delMany(n)
+	for i = 1, tonumber(n) do
+		system.delItem("k" .. i)
+	end
+end
+
+function setBig(n)
+	system.setItem("big", string.rep("a", tonumber(n)))
+end
+
+function emit(name, value)
+	system.event(name, value, 1)
+	system.event(name .. "2")
+end
+
+function emitAndRevert(name)
+	system.event(name)
+	error("revert: " .. name)
+end
+
+function sum(n)
+	local s = 0
+	for i = 1, tonumber(n) do
+		s = s + i
+	end
+	return s
+end
+
+function setAndLoop(key)
+	system.setItem(key, "v")
+	while true do
+	end
+end
+
+function loopCatch(key)
+	pcall(setAndLoop, key)
+	system.setItem(key .. "_caught", "v")
+end
+
+function rep(key, n)
+	pcall(string.rep, "a", tonumber(n))
+	system.setItem(key, "v")
+end
+
+function add(a, b)
+	return a + b
+end
+
+function types(...)
+	local r = {}
+	for i = 1, select("#", ...) do
+		r[i] = type((select(i, ...)))
+	end
+	return unpack(r, 1, select("#", ...))
+end
+
+function nested(t)
+	return t.a.b[1], t.a.b[2], #t.a.b, t.c
+end
+
+function sandbox()
+	local t = {}
+	for i = 1, 10 do
+		t["k" .. i] = i * 1.5
+	end
+	return type(os), type(io), type(require), type(dofile), type(collectgarbage), type(math.random),
+		type(jit), type(string.format), system.getTimestamp(), system.getBlockheight(), t
+end
+
+function noAddress()
+	__exec_context__ = {}
+	return tostring({}), tostring(abi.call), string.format("%s %d", {}, 1),
+		tostring(setmetatable({}, {__tostring = function() return "t" end})), tostring(1.5),
+		type(load), type(loadstring), type(string.dump), system.getBlockheight()
+end
+
+function length(s)
+	return #s, string.byte(s, 2)
+end
+
+function assertAndRevert(key)
+	system.setItem(key, "v")
+	assert(false, "revert: " .. key)
+end
+
+function revertObject()
+	error({})
+end
+
+function rethrow()
+	local _, e = pcall(error, "revert: rethrown", 0)
+	error(e, 0)
+end
+
+function setAndIndexNil(key)
+	system.setItem(key, "v")
+	local t
+	return t.x
+end
+
+function indexNilAfterRevert()
+	pcall(error, "revert: caught")
+	local t
+	return t.x
+end
+
+abi = {}
+function abi.call(name, ...)
+	return _G[name](...)
+end
+`
+
+var testContractAddress = []byte("01234567890123456789")
+
+func initTestDB(t *testing.T) func() {
+	dataDir, err := ioutil.TempDir("", "contract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	DB = db.NewDB(db.BadgerImpl, dataDir)
+	if err := Create([]byte(testContractCode), testContractAddress, []byte("deploy")); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		DB.Close()
+		os.RemoveAll(dataDir)
+	}
+}
+
+func callTestContract(t *testing.T, txHash string, abi string) error {
+	_, err := callTestContractWithGas(t, txHash, abi, 0)
+	return err
+}
+
+func callTestContractWithGas(t *testing.T, txHash string, abi string, gasLimit uint64) (uint64, error) {
+	bcCtx := NewContext([]byte("sender"), []byte("block"), []byte(txHash), 1, 0, "", false,
+		testContractAddress)
+	return Call([]byte(abi), testContractAddress, []byte(txHash), bcCtx, gasLimit)
+}
+
+func testContractKey(key string) []byte {
+	return []byte(base58.Encode(testContractAddress) + "_" + key)
+}
+
+func TestCall_Success(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	err := callTestContract(t, "tx1", `{"Name":"set","Args":["k1","v1"]}`)
+	assert.NoError(t, err)
+
+	receipt := GetReceipt([]byte("tx1"))
+	assert.NotNil(t, receipt)
+	assert.Equal(t, types.ReceiptSuccess, receipt.Status)
+	assert.NotEmpty(t, DB.Get(testContractKey("k1")))
+}
+
+func TestCall_Revert(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	err := callTestContract(t, "tx1", `{"Name":"setAndRevert","Args":["k1","v1"]}`)
+	// a reverted call is still a valid tx, so the sender is charged for it
+	assert.NoError(t, err)
+
+	receipt := GetReceipt([]byte("tx1"))
+	assert.NotNil(t, receipt)
+	assert.Equal(t, types.ReceiptReverted, receipt.Status)
+	assert.Contains(t, receipt.Ret, "revert: k1")
+	assert.Empty(t, DB.Get(testContractKey("k1")), "state write of reverted call must be rolled back")
+}
+
+func TestCall_NoContract(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	unknown := []byte("98765432109876543210")
+	bcCtx := NewContext([]byte("sender"), []byte("block"), []byte("tx1"), 1, 0, "", false, unknown)
+	_, err := Call([]byte(`{"Name":"set","Args":["k1","v1"]}`), unknown, []byte("tx1"), bcCtx, 0)
+	// the tx is still valid, and the failure is recorded in its receipt
+	assert.NoError(t, err)
+
+	receipt := GetReceipt([]byte("tx1"))
+	if assert.NotNil(t, receipt) {
+		assert.Equal(t, types.ReceiptError, receipt.Status)
+		assert.Contains(t, receipt.Ret, "cannot find contract")
+	}
+}
+
+func TestCall_RevertDelete(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	assert.NoError(t, callTestContract(t, "tx1", `{"Name":"set","Args":["k1","v1"]}`))
+	err := callTestContract(t, "tx2", `{"Name":"delAndRevert","Args":["k1"]}`)
+	assert.NoError(t, err)
+
+	receipt := GetReceipt([]byte("tx2"))
+	assert.Equal(t, types.ReceiptReverted, receipt.Status)
+	assert.NotEmpty(t, DB.Get(testContractKey("k1")), "delete of reverted call must be rolled back")
+}
+
+func TestCall_Error(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	err := callTestContract(t, "tx1", `{"Name":"set","Args":"k1"}`)
+	assert.NoError(t, err)
+
+	receipt := GetReceipt([]byte("tx1"))
+	assert.NotNil(t, receipt)
+	assert.Equal(t, types.ReceiptError, receipt.Status)
+}
+
+func TestCall_RevertOrError(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name     string
+		abi      string
+		gasLimit uint64
+		want     string
+		ret      string
+	}{
+		// the errors raised by the contract itself are reverts
+		{"error", `{"Name":"setAndRevert","Args":["k1","v1"]}`, 0, types.ReceiptReverted, "revert: k1"},
+		{"assert", `{"Name":"assertAndRevert","Args":["k1"]}`, 0, types.ReceiptReverted, "revert: k1"},
+		{"error object", `{"Name":"revertObject"}`, 0, types.ReceiptReverted, "table"},
+		{"rethrow", `{"Name":"rethrow"}`, 0, types.ReceiptReverted, "revert: rethrown"},
+		// the errors raised by the VM are not
+		{"index nil", `{"Name":"setAndIndexNil","Args":["k1"]}`, 0, types.ReceiptError, "attempt to index"},
+		{"index nil after revert", `{"Name":"indexNilAfterRevert"}`, 0, types.ReceiptError, "attempt to index"},
+		{"out of gas", `{"Name":"setAndLoop","Args":["k1"]}`, 10000, types.ReceiptOutOfGas, "out of gas"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txHash := "tx" + strconv.Itoa(i)
+			_, err := callTestContractWithGas(t, txHash, tt.abi, tt.gasLimit)
+			assert.NoError(t, err)
+
+			receipt := GetReceipt([]byte(txHash))
+			assert.Equal(t, tt.want, receipt.Status)
+			assert.Contains(t, receipt.Ret, tt.ret)
+			assert.Empty(t, DB.Get(testContractKey("k1")), "state write of failed call must be rolled back")
+		})
+	}
+}
+
+func TestCall_StorageLimit(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+	const gasLimit = 100000000
+
+	gasUsed, err := callTestContractWithGas(t, "tx1", `{"Name":"setMany","Args":["3"]}`, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReceiptSuccess, GetReceipt([]byte("tx1")).Status)
+	assert.NotEmpty(t, DB.Get(testContractKey("k3")))
+	assert.True(t, gasUsed >= 3*storageWriteGas, "storage writes must be paid by gas")
+
+	tooMany := strconv.Itoa(maxStorageWrites + 1)
+	for _, name := range []string{"setMany", "setManyCatch", "delMany"} {
+		t.Run(name, func(t *testing.T) {
+			txHash := "tx_" + name
+			_, err := callTestContractWithGas(t, txHash, `{"Name":"`+name+`","Args":["`+tooMany+`"]}`, gasLimit)
+			// the call fails, but the tx is still valid and charged
+			assert.NoError(t, err)
+
+			receipt := GetReceipt([]byte(txHash))
+			assert.NotNil(t, receipt)
+			assert.Equal(t, types.ReceiptOutOfGas, receipt.Status)
+			assert.Contains(t, receipt.Ret, "storage write limit")
+			assert.NotEmpty(t, DB.Get(testContractKey("k3")), "changes of the call must be rolled back")
+			assert.Empty(t, DB.Get(testContractKey("k4")), "changes of the call must be rolled back")
+		})
+	}
+
+	// limit of the total bytes
+	_, err = callTestContractWithGas(t, "tx2", `{"Name":"setBig","Args":["`+strconv.Itoa(maxStorageWriteSize)+`"]}`,
+		gasLimit)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReceiptOutOfGas, GetReceipt([]byte("tx2")).Status)
+	assert.Empty(t, DB.Get(testContractKey("big")))
+
+	// the gas of storage writes counts toward the gas limit
+	gasUsed, err = callTestContractWithGas(t, "tx3", `{"Name":"setMany","Args":["10"]}`, 5*storageWriteGas)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5*storageWriteGas), gasUsed)
+	assert.Equal(t, types.ReceiptOutOfGas, GetReceipt([]byte("tx3")).Status)
+	assert.Empty(t, DB.Get(testContractKey("k10")))
+}
+
+func TestCall_Event(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	err := callTestContract(t, "tx1", `{"Name":"emit","Args":["transfer","v1"]}`)
+	assert.NoError(t, err)
+	events := GetEvents([]byte("tx1"))
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, testContractAddress, events[0].ContractAddress)
+		assert.Equal(t, "transfer", events[0].EventName)
+		assert.Equal(t, `["v1",1]`, events[0].JsonArgs)
+		assert.Equal(t, int32(0), events[0].EventIdx)
+		assert.Equal(t, "transfer2", events[1].EventName)
+		assert.Equal(t, `[]`, events[1].JsonArgs)
+		assert.Equal(t, int32(1), events[1].EventIdx)
+	}
+	assert.Equal(t, events, GetReceipt([]byte("tx1")).Events, "events must be returned with receipt")
+
+	// events of reverted call are discarded
+	err = callTestContract(t, "tx2", `{"Name":"emitAndRevert","Args":["transfer"]}`)
+	assert.NoError(t, err)
+	receipt := GetReceipt([]byte("tx2"))
+	assert.Equal(t, types.ReceiptReverted, receipt.Status)
+	assert.Empty(t, receipt.Events)
+	assert.Empty(t, GetEvents([]byte("tx2")))
+}
+
+func TestCall_Gas(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	used1, err := callTestContractWithGas(t, "tx1", `{"Name":"sum","Args":["10000"]}`, 0)
+	assert.NoError(t, err)
+	assert.True(t, used1 > 0)
+	receipt := GetReceipt([]byte("tx1"))
+	assert.Equal(t, types.ReceiptSuccess, receipt.Status)
+	assert.Equal(t, used1, receipt.GasUsed)
+
+	// the same call uses the same gas, and a longer one uses more
+	used2, err := callTestContractWithGas(t, "tx2", `{"Name":"sum","Args":["10000"]}`, used1+100)
+	assert.NoError(t, err)
+	assert.Equal(t, used1, used2)
+	used3, err := callTestContractWithGas(t, "tx3", `{"Name":"sum","Args":["20000"]}`, 0)
+	assert.NoError(t, err)
+	assert.True(t, used3 > used1)
+
+	// the call needing more gas than the limit is aborted at the limit
+	used, err := callTestContractWithGas(t, "tx4", `{"Name":"sum","Args":["10000"]}`, used1-1)
+	assert.NoError(t, err)
+	assert.Equal(t, used1-1, used)
+	assert.Equal(t, types.ReceiptOutOfGas, GetReceipt([]byte("tx4")).Status)
+}
+
+func TestCall_OutOfGas(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	for _, name := range []string{"setAndLoop", "loopCatch"} {
+		t.Run(name, func(t *testing.T) {
+			txHash := "tx_" + name
+			// the endless call is interrupted, even if it catches the error
+			used, err := callTestContractWithGas(t, txHash, `{"Name":"`+name+`","Args":["k1"]}`, 1000)
+			assert.NoError(t, err, "the tx is still valid and charged")
+			assert.Equal(t, uint64(1000), used)
+
+			receipt := GetReceipt([]byte(txHash))
+			assert.Equal(t, types.ReceiptOutOfGas, receipt.Status)
+			assert.Equal(t, uint64(1000), receipt.GasUsed)
+			assert.Empty(t, DB.Get(testContractKey("k1")), "writes of the call must be rolled back")
+			assert.Empty(t, DB.Get(testContractKey("k1_caught")))
+		})
+	}
+}
+
+func TestCall_MemoryGas(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	// the allocation of a library call is charged, so that a single instruction can't allocate beyond the limit
+	used, err := callTestContractWithGas(t, "tx1", `{"Name":"rep","Args":["k1","100000"]}`, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReceiptSuccess, GetReceipt([]byte("tx1")).Status)
+	assert.True(t, used > 100000/100, "the allocated bytes must be paid by gas")
+
+	used, err = callTestContractWithGas(t, "tx2", `{"Name":"rep","Args":["k2","10000000000"]}`, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(defaultGasLimit), used)
+	assert.Equal(t, types.ReceiptOutOfGas, GetReceipt([]byte("tx2")).Status)
+	assert.Empty(t, DB.Get(testContractKey("k2")), "the call must be aborted even if it catches the error")
+}
+
+func TestDryRun(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	var used uint64
+	err := DryRun(func() error {
+		if err := callTestContract(t, "tx1", `{"Name":"set","Args":["k1","v1"]}`); err != nil {
+			return err
+		}
+		// the writes of the former call are seen
+		assert.NotEmpty(t, dbGet(testContractKey("k1")))
+		var err error
+		used, err = callTestContractWithGas(t, "tx2", `{"Name":"sum","Args":["100"]}`, 0)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.True(t, used > 0)
+	// nothing is written to db
+	assert.Empty(t, DB.Get(testContractKey("k1")))
+	assert.Nil(t, GetReceipt([]byte("tx1")))
+	assert.Nil(t, GetReceipt([]byte("tx2")))
+
+	// the same call uses the same gas out of dry run
+	actual, err := callTestContractWithGas(t, "tx2", `{"Name":"sum","Args":["100"]}`, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, used, actual)
+}
+
+func TestCall_Args(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name string
+		abi  string
+		want string
+	}{
+		{"float", `{"Name":"add","Args":[1.5,12345678901]}`, `[12345678902.5]`},
+		{"types", `{"Name":"types","Args":[true,null,1,"s",[1],{"k":"v"}]}`,
+			`["boolean","nil","number","string","table","table"]`},
+		{"nested", `{"Name":"nested","Args":[{"a":{"b":[10,"x"]},"c":false}]}`, `[10,"x",2,false]`},
+		{"zero byte", `{"Name":"length","Args":["\u0001\u0000\u0002"]}`, `[3,0]`},
+		{"bytes", `{"Name":"length","Args":[{"_bytes":"LUy"}]}`, `[3,0]`},
+		{"bytes key among others", `{"Name":"types","Args":[{"_bytes":"LUy","k":"v"}]}`, `["table"]`},
+		{"max integer", `{"Name":"add","Args":[9007199254740992,0]}`, `[9.007199254741e+15]`},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txHash := "tx" + strconv.Itoa(i)
+			assert.NoError(t, callTestContract(t, txHash, tt.abi))
+			receipt := GetReceipt([]byte(txHash))
+			assert.Equal(t, types.ReceiptSuccess, receipt.Status)
+			assert.Equal(t, tt.want, receipt.Ret)
+		})
+	}
+}
+
+func TestCall_ArgsOutOfRange(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	// the integers which a Lua number can't hold are rejected rather than rounded
+	for i, arg := range []string{"9007199254740993", "-9007199254740993", "18446744073709551616"} {
+		txHash := "tx" + strconv.Itoa(i)
+		assert.NoError(t, callTestContract(t, txHash, `{"Name":"add","Args":[`+arg+`,0]}`))
+		assert.Equal(t, types.ReceiptError, GetReceipt([]byte(txHash)).Status, arg)
+	}
+	assert.NoError(t, callTestContract(t, "tx3", `{"Name":"add","Args":[1,2]} x`))
+	assert.Equal(t, types.ReceiptError, GetReceipt([]byte("tx3")).Status)
+}
+
+func TestCall_ArgsBytes(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	abi, err := json.Marshal(types.ABI{Name: "length", Args: []interface{}{types.ABIBytes([]byte{1, 0, 2})}})
+	assert.NoError(t, err)
+	assert.NoError(t, callTestContract(t, "tx0", string(abi)))
+	receipt := GetReceipt([]byte("tx0"))
+	assert.Equal(t, types.ReceiptSuccess, receipt.Status)
+	assert.Equal(t, `[3,0]`, receipt.Ret)
+
+	// a byte array which isn't a base58 string is rejected
+	for i, arg := range []string{`{"_bytes":"0OIl"}`, `{"_bytes":1}`} {
+		txHash := "tx" + strconv.Itoa(i+1)
+		assert.NoError(t, callTestContract(t, txHash, `{"Name":"length","Args":[`+arg+`]}`))
+		assert.Equal(t, types.ReceiptError, GetReceipt([]byte(txHash)).Status, arg)
+	}
+}
+
+func TestExecutor_Deterministic(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	var rets []string
+	for i := 0; i < 2; i++ {
+		bcCtx := NewContext([]byte("sender"), []byte("block"), []byte("tx1"), 7, 1540000000, "node", false,
+			testContractAddress)
+		ce := newExecutor(getContract(testContractAddress), bcCtx, defaultGasLimit)
+		ce.call(&types.ABI{Name: "sandbox"})
+		assert.NoError(t, ce.err)
+		rets = append(rets, ce.jsonRet)
+		ce.close()
+	}
+	// nondeterministic libraries are removed, and the time is the one of block
+	assert.Contains(t, rets[0], `["nil","nil","nil","nil","nil","nil","nil","function",1540000000,7,{`)
+	assert.Equal(t, rets[0], rets[1])
+}
+
+func TestExecutor_Sandbox(t *testing.T) {
+	cleanup := initTestDB(t)
+	defer cleanup()
+
+	bcCtx := NewContext([]byte("sender"), []byte("block"), []byte("tx1"), 7, 0, "", false, testContractAddress)
+	ce := newExecutor(getContract(testContractAddress), bcCtx, defaultGasLimit)
+	defer ce.close()
+	ce.call(&types.ABI{Name: "noAddress"})
+	assert.NoError(t, ce.err)
+	// no address is printed, the loaders of chunks are removed, and the execution context can't be replaced
+	assert.Equal(t, `["table","function","table 1","t","1.5","nil","nil","nil",7]`, ce.jsonRet)
+}
+
+func TestReceiptStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"success", nil, types.ReceiptSuccess},
+		{"reverted", &revertError{reason: "revert"}, types.ReceiptReverted},
+		{"outOfGas", ErrOutOfGas, types.ReceiptOutOfGas},
+		{"storageLimit", ErrStorageLimit, types.ReceiptOutOfGas},
+		{"error", errors.New("vm failure"), types.ReceiptError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, receiptStatus(tt.err))
+		})
+	}
+}
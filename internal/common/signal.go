@@ -8,12 +8,21 @@ import (
 	"github.com/aergoio/aergo-lib/log"
 )
 
+// KillSignals are the killing signals (interrupt, quit and terminate), on
+// which the program shuts down.
+var KillSignals = []os.Signal{syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+
+// NotifyKillSig returns a channel which receives killing signals.
+func NotifyKillSig() <-chan os.Signal {
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, KillSignals...)
+	return sigChannel
+}
+
 // HandleKillSig gets killing signals (interrupt, quit and terminate) and calls
 // a registered handler function for cleanup. Finally, this will exit program
 func HandleKillSig(handler func(), logger *log.Logger) {
-	sigChannel := make(chan os.Signal, 1)
-
-	signal.Notify(sigChannel, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	sigChannel := NotifyKillSig()
 	go func() {
 		for signal := range sigChannel {
 			logger.Info().Msgf("Receive signal %s, Shutting down...", signal)
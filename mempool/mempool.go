@@ -32,6 +32,9 @@ const (
 	running = iota
 )
 
+// stopDrainTimeout is the time limit to handle the messages queued at the mailbox, when mempool service is stopped.
+const stopDrainTimeout = 3 * time.Second
+
 // MemPool is main structure of mempool service
 type MemPool struct {
 	*component.BaseComponent
@@ -96,6 +99,11 @@ func (mp *MemPool) BeforeStart() {
 	//mp.Info("mempool start on: current Block :", mp.curBestBlockNo)
 }
 
+// Stop handles the txs queued at the mailbox before stopping, so that they are dumped as well
+func (mp *MemPool) Stop() {
+	mp.StopGraceful(stopDrainTimeout)
+}
+
 // Stop handles clean-up for mempool service
 func (mp *MemPool) BeforeStop() {
 	mp.dumpTxsToFile()
@@ -164,6 +172,27 @@ func (mp *MemPool) get() ([]*types.Tx, error) {
 	return txs, nil
 }
 
+// Snapshot returns a copy of all the txs in mempool including orphans. It is
+// taken under the lock, so that the txs are the ones in mempool at a moment.
+func (mp *MemPool) Snapshot() []*types.Tx {
+	mp.RLock()
+	defer mp.RUnlock()
+	txs := make([]*types.Tx, 0, len(mp.cache))
+	for _, list := range mp.pool {
+		for _, v := range list.GetAll() {
+			txs = append(txs, proto.Clone(v).(*types.Tx))
+		}
+	}
+	return txs
+}
+
+// Restore puts txs, such as the ones of a snapshot, into mempool. Each tx is
+// validated as if it is newly received, and its error is returned in the
+// same order, which is nil if it is admitted.
+func (mp *MemPool) Restore(txs []*types.Tx) []error {
+	return mp.puts(txs...)
+}
+
 // check existence.
 // validate
 // add pool if possible, else pendings
@@ -243,7 +272,7 @@ func (mp *MemPool) removeOnBlockArrival(blockNo types.BlockNo, txs ...*types.Tx)
 }
 
 // check tx sanity
-// TODO sender's signiture
+// check if tx is signed by its account
 // check if sender has enough balance
 // check tx account is lower than known value
 func (mp *MemPool) validate(tx *types.Tx) error {
@@ -254,16 +283,21 @@ func (mp *MemPool) validate(tx *types.Tx) error {
 	if !bytes.Equal(tx.Hash, tx.CalculateTxHash()) {
 		return message.ErrTxHasInvalidHash
 	}
+	if proto.Size(tx) > message.MaxTxSize {
+		return message.ErrTxTooLarge
+	}
+	if valid, err := tx.VerifySign(); err != nil || !valid {
+		return message.ErrSignNotMatch
+	}
 
 	ns, err := mp.getAccountState(account, false)
 	if err != nil {
 		return err
 	}
-	/*
-		if tx.GetBody().GetAmount() > ns.Balance {
-			return ErrInsufficientBalance
-		}
-	*/
+	// the fee is checked when the tx is included in block, since it depends on the gas price then
+	if tx.GetBody().GetAmount() > ns.Balance {
+		return message.ErrInsufficientBalance
+	}
 	if tx.GetBody().GetNonce() <= ns.Nonce {
 		return message.ErrTxNonceTooLow
 	}
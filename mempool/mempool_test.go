@@ -8,10 +8,13 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
+	"github.com/btcsuite/btcd/btcec"
 )
 
 const (
@@ -23,6 +26,8 @@ var (
 	pool      *MemPool
 	account   [maxAccount][]byte
 	recipient [maxRecipient][]byte
+	// keys sign the txs of the accounts of the same index
+	keys [maxAccount]*btcec.PrivateKey
 )
 
 func _itobU32(argv uint32) []byte {
@@ -59,7 +64,10 @@ func initTest() {
 	pool.BeforeStart()
 
 	for i := 0; i < maxAccount; i++ {
-		account[i] = _itobU32(uint32(i))
+		if keys[i] == nil {
+			keys[i], _ = btcec.NewPrivateKey(btcec.S256())
+			account[i] = types.AddressFromPubKey(keys[i].PubKey().ToECDSA())
+		}
 	}
 	for i := 0; i < maxRecipient; i++ {
 		recipient[i] = _itobU32(uint32(i))
@@ -100,36 +108,47 @@ func genTx(acc int, rec int, nonce uint64, amount uint64) *types.Tx {
 			Amount:    amount,
 		},
 	}
-	tx.Hash = tx.CalculateTxHash()
+	tx.Sign(keys[acc])
 	return &tx
 }
 
-// func TestInvalidTransaction(t *testing.T) {
-
-// 	initTest()
-// 	defer deinitTest()
-// 	err := pool.put(genTx(0, 1, 1, defaultBalance*2))
-// 	if err != ErrInsufficientBalance {
-// 		t.Errorf("check valid failed, err != ErrInsufficientBalance, but %s", err)
-// 	}
-
-// 	err = pool.put(genTx(0, 1, 1, 1))
-// 	if err != nil {
-// 		t.Errorf("tx should be accepted, err:%s", err)
-// 	}
-// 	err = pool.put(genTx(0, 1, 1, 1))
-// 	if err != ErrTxAlreadyInMempool {
-// 		t.Errorf("tx should be denied /w ErrTxAlreadyInMempool, err:%s", err)
-// 	}
-// 	txs := []*types.Tx{genTx(0, 1, 1, 1)}
-// 	simulateBlockGen(txs...)
-
-// 	pool.removeOnBlockArrival(getCurrentBestBlockNoMock(), txs...)
-// 	err = pool.put(genTx(0, 1, 1, 1))
-// 	if err != ErrTxNonceTooLow {
-// 		t.Errorf("tx should be denied /w ErrTxNonceTooLow, err:%s", err)
-// 	}
-// }
+func TestInvalidTransaction(t *testing.T) {
+	initTest()
+	defer deinitTest()
+
+	if err := pool.put(genTx(0, 1, 1, defaultBalance*2)); err != message.ErrInsufficientBalance {
+		t.Errorf("tx over balance should be rejected, but %v", err)
+	}
+
+	// signed by the key of other account
+	tx := genTx(0, 1, 1, 1)
+	tx.Body.Account = account[1]
+	tx.Hash = tx.CalculateTxHash()
+	if err := pool.put(tx); err != message.ErrSignNotMatch {
+		t.Errorf("tx of wrong signature should be rejected, but %v", err)
+	}
+	tx.Body.Sign = nil
+	tx.Hash = tx.CalculateTxHash()
+	if err := pool.put(tx); err != message.ErrSignNotMatch {
+		t.Errorf("unsigned tx should be rejected, but %v", err)
+	}
+
+	if err := pool.put(genTx(0, 1, 1, 1)); err != nil {
+		t.Errorf("tx should be accepted, but %v", err)
+	}
+}
+
+func TestTooLargeTransaction(t *testing.T) {
+	initTest()
+	defer deinitTest()
+
+	tx := genTx(0, 1, 1, 2)
+	tx.Body.Payload = make([]byte, message.MaxTxSize)
+	tx.Hash = tx.CalculateTxHash()
+	if err := pool.put(tx); err != message.ErrTxTooLarge {
+		t.Errorf("too large tx should be rejected, but %v", err)
+	}
+}
 
 func TestOrphanTransaction(t *testing.T) {
 	//	t.Errorf("Sum was incorrect, ")
@@ -462,3 +481,82 @@ func BenchmarkReverse(b *testing.B) {
 	}
 
 }
+
+func TestSnapshot(t *testing.T) {
+	initTest()
+	defer deinitTest()
+
+	const accounts = 4
+	const txsPerAccount = 50
+	done := make(chan bool)
+	for i := 0; i < accounts; i++ {
+		go func(acc int) {
+			for n := uint64(1); n <= txsPerAccount; n++ {
+				if err := pool.put(genTx(acc, 1, n, 2)); err != nil {
+					t.Error("put tx should be succeeded", err)
+				}
+			}
+			done <- true
+		}(100 + i)
+	}
+
+	// every snapshot taken during submissions has the txs of each account
+	// up to some nonce without a gap
+	check := func(txs []*types.Tx) {
+		nonces := map[string][]uint64{}
+		for _, tx := range txs {
+			acc := getAccount(tx)
+			nonces[acc] = append(nonces[acc], tx.GetBody().GetNonce())
+		}
+		for acc, ns := range nonces {
+			sort.Slice(ns, func(i, j int) bool { return ns[i] < ns[j] })
+			for i, n := range ns {
+				if n != uint64(i+1) {
+					t.Errorf("inconsistent snapshot of %s: nonces %v", acc, ns)
+					break
+				}
+			}
+		}
+	}
+	for finished := 0; finished < accounts; {
+		select {
+		case <-done:
+			finished++
+		default:
+			check(pool.Snapshot())
+		}
+	}
+	snapshot := pool.Snapshot()
+	check(snapshot)
+	if len(snapshot) != accounts*txsPerAccount {
+		t.Errorf("snapshot should have all txs, but %d", len(snapshot))
+	}
+
+	// the snapshot is a copy, which is restored into another mempool
+	snapshot[0].Body.Amount = 100
+	if tx := pool.exists(snapshot[0].Hash); tx == nil || tx.GetBody().GetAmount() != 2 {
+		t.Error("snapshot should not share txs with mempool")
+	}
+	snapshot[0].Body.Amount = 2
+	original := pool
+	initTest()
+	for i, err := range pool.Restore(snapshot) {
+		if err != nil {
+			t.Errorf("restoring tx %d should be succeeded: %v", i, err)
+		}
+	}
+	if p1, p2 := pool.Size(); !(p1 == accounts*txsPerAccount && p2 == 0) {
+		t.Errorf("invalid count status pool:%d orphan:%d", p1, p2)
+	}
+
+	// restored txs are validated
+	errs := original.Restore(snapshot[:1])
+	if errs[0] != message.ErrTxAlreadyInMempool {
+		t.Errorf("tx in mempool should be rejected, but %v", errs[0])
+	}
+	invalid := genTx(100, 1, txsPerAccount+1, 2)
+	invalid.Hash = nil
+	if errs = pool.Restore([]*types.Tx{invalid}); errs[0] != message.ErrTxHasInvalidHash {
+		t.Errorf("tx of invalid hash should be rejected, but %v", errs[0])
+	}
+}
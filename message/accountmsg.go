@@ -2,6 +2,7 @@ package message
 
 import (
 	"errors"
+	"time"
 
 	"github.com/aergoio/aergo/types"
 )
@@ -10,6 +11,8 @@ var (
 	ErrSignNotMatch           = errors.New("signature not matched")
 	ErrShouldUnlockAccount    = errors.New("should unlock account first")
 	ErrWrongAddressOrPassWord = errors.New("address or password is incorrect")
+	ErrUnknownAccount         = errors.New("unknown account")
+	ErrAccountNotMatch        = errors.New("account of tx does not match signer")
 )
 
 const AccountsSvc = "AccountsSvc"
@@ -22,13 +25,14 @@ type CreateAccountRsp struct {
 }
 
 type LockAccount struct {
-	Account    *types.Account
-	Passphrase string
+	Account *types.Account
 }
 
+// UnlockAccount keeps the account unlocked for Duration. Zero Duration means until it is locked.
 type UnlockAccount struct {
 	Account    *types.Account
 	Passphrase string
+	Duration   time.Duration
 }
 
 type AccountRsp struct {
@@ -56,6 +60,12 @@ type SignTxRsp struct {
 	Tx  *types.Tx
 	Err error
 }
+
+// SignTxFrom asks to sign Tx with the unlocked account From. Response is SignTxRsp.
+type SignTxFrom struct {
+	Tx   *types.Tx
+	From []byte
+}
 type VerifyTx struct {
 	Tx *types.Tx
 }
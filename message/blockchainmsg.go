@@ -6,6 +6,8 @@
 package message
 
 import (
+	"time"
+
 	"github.com/aergoio/aergo/types"
 	"github.com/libp2p/go-libp2p-peer"
 )
@@ -50,8 +52,21 @@ type AddBlockRsp struct {
 	BlockHash []byte
 	Err       error
 }
+
+// ComputeStateRoot is request to compute the state root after txs of block are applied.
+// The state is not changed by this request.
+type ComputeStateRoot struct {
+	Block *types.Block
+}
+type ComputeStateRootRsp struct {
+	Root []byte
+	Err  error
+}
+
 type GetState struct {
 	Account []byte
+	// Confirmations is the depth from the best block of the state to get. 0 means the latest state.
+	Confirmations uint64
 }
 type GetStateRsp struct {
 	State *types.State
@@ -79,3 +94,45 @@ type SyncBlockState struct {
 	BlockNo   types.BlockNo
 	BlockHash []byte
 }
+
+// GetSyncState requests chain service to compare the best block with the ones of peers.
+// It returns GetSyncStateRsp
+type GetSyncState struct {
+}
+type GetSyncStateRsp struct {
+	State types.SyncState
+	Err   error
+}
+
+// GetBpAssignment requests the block producer scheduled for the slot of block BlockNo, and the one which actually
+// produced the block. If Timestamp (UNIX time in ns) is not zero, the slot including it is looked up instead.
+// It returns GetBpAssignmentRsp
+type GetBpAssignment struct {
+	BlockNo   types.BlockNo
+	Timestamp int64
+}
+type GetBpAssignmentRsp struct {
+	Assignment *types.BpAssignment
+	Err        error
+}
+
+// GetBpSchedule requests the block producer of the current slot, and the next slot of the block producer BpID.
+// It returns GetBpScheduleRsp
+type GetBpSchedule struct {
+	BpID string
+}
+type GetBpScheduleRsp struct {
+	Current  string
+	NextSlot time.Time
+	Err      error
+}
+
+// GetLogs requests the events selected by Filter in the main chain.
+// It returns GetLogsRsp
+type GetLogs struct {
+	Filter *types.FilterInfo
+}
+type GetLogsRsp struct {
+	Events []*types.Event
+	Err    error
+}
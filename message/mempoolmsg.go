@@ -32,11 +32,17 @@ var (
 
 	//ErrTxNonceToohigh is for internal use only
 	ErrTxNonceToohigh = errors.New("nonce is too high")
+
+	//ErrTxTooLarge is returned by MemPool Service if transaction is larger than MaxTxSize
+	ErrTxTooLarge = errors.New("tx is too large")
 )
 
 // MemPoolSvc is exported name for MemPool service
 const MemPoolSvc = "MemPoolSvc"
 
+// MaxTxSize is the maximum size of serialized transaction accepted by MemPool service
+const MaxTxSize = 1 << 20
+
 // MemPoolPut is interface of MemPool service for inserting transactions
 type MemPoolPut struct {
 	Txs []*types.Tx
@@ -6,6 +6,8 @@
 package message
 
 import (
+	"time"
+
 	"github.com/aergoio/aergo/types"
 	"github.com/libp2p/go-libp2p-peer"
 )
@@ -36,6 +38,13 @@ type NotifyNewBlock struct {
 type BlockHash []byte
 type TXHash []byte
 
+// NotifyTxsReorged tells p2p service the txs which are confirmed by roll forward of reorg,
+// and the txs which are not confirmed anymore by roll back.
+type NotifyTxsReorged struct {
+	Confirmed [][]byte
+	Reverted  [][]byte
+}
+
 // NotifyNewTransactions send types.NewTransactionsNotice to other peers.
 // The actor returns true if sending is successful.
 type NotifyNewTransactions struct {
@@ -81,6 +90,14 @@ type GetBlockInfos struct {
 	Hashes []BlockHash
 }
 
+// GetBlocksByRange send types.GetBlockRangeRequest to dest peer, to get Count blocks from
+// block number StartNo. The actor returns true if sending is successful.
+type GetBlocksByRange struct {
+	ToWhom  peer.ID
+	StartNo uint64
+	Count   uint64
+}
+
 // GetMissingBlocks send types.GetMissingRequest to dest peer.
 // The actor returns true if sending is successful.
 // Not used (need to be async operation)
@@ -101,7 +118,62 @@ type GetPeers struct {
 }
 
 // GetPeersRsp contains peer meta information and current states.
+// DisconnectReasons is empty string unless the peer is being disconnected.
 type GetPeersRsp struct {
-	Peers  []*types.PeerAddress
-	States []types.PeerState
+	Peers             []*types.PeerAddress
+	States            []types.PeerState
+	DisconnectReasons []string
+}
+
+// GetPeerHeights requests p2p actor to get the best block numbers reported by running peers.
+// The actor returns *GetPeerHeightsRsp
+type GetPeerHeights struct {
+}
+
+// GetPeerHeightsRsp contains the best block numbers of peers
+type GetPeerHeightsRsp struct {
+	Heights map[peer.ID]types.BlockNo
+}
+
+// DumpP2PState requests p2p actor to dump the state of peers and connections at the moment.
+// The actor returns *DumpP2PStateRsp
+type DumpP2PState struct {
+}
+
+// DumpP2PStateRsp is the snapshot of p2p subsystem for troubleshooting.
+type DumpP2PStateRsp struct {
+	RemotePeers     []P2PRemotePeer
+	PeerPool        []P2PPeerAddr
+	DesignatedPeers []P2PPeerAddr
+	Peerstore       []P2PPeerstoreEntry
+	ReconnectJobs   []P2PPeerAddr
+	BannedAddrs     []P2PBannedAddr
+}
+
+// P2PPeerAddr is the address of peer. ID is base58 encoded peer id.
+type P2PPeerAddr struct {
+	ID         string
+	Address    string
+	Designated bool
+}
+
+// P2PRemotePeer is the state of connected peer.
+type P2PRemotePeer struct {
+	P2PPeerAddr
+	State      string
+	BestHeight types.BlockNo
+	Latency    string
+	Score      int32
+}
+
+// P2PPeerstoreEntry is the addresses of peer kept in peerstore.
+type P2PPeerstoreEntry struct {
+	ID    string
+	Addrs []string
+}
+
+// P2PBannedAddr is the address banned until the time.
+type P2PBannedAddr struct {
+	Address string
+	Until   time.Time
 }
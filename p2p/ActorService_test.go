@@ -6,6 +6,7 @@ package p2p
 
 import actor "github.com/aergoio/aergo-actor/actor"
 import mock "github.com/stretchr/testify/mock"
+import time "time"
 
 // MockActorService is an autogenerated mock type for the MockActorService type
 type MockActorService struct {
@@ -35,6 +36,29 @@ func (_m *MockActorService) CallRequest(_a0 string, msg interface{}) (interface{
 	return r0, r1
 }
 
+// CallRequestWithTimeout provides a mock function with given fields: _a0, msg, timeout
+func (_m *MockActorService) CallRequestWithTimeout(_a0 string, msg interface{}, timeout time.Duration) (interface{}, error) {
+	ret := _m.Called(_a0, msg, timeout)
+
+	var r0 interface{}
+	if rf, ok := ret.Get(0).(func(string, interface{}, time.Duration) interface{}); ok {
+		r0 = rf(_a0, msg, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, interface{}, time.Duration) error); ok {
+		r1 = rf(_a0, msg, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FutureRequest provides a mock function with given fields: _a0, msg
 func (_m *MockActorService) FutureRequest(_a0 string, msg interface{}) *actor.Future {
 	ret := _m.Called(_a0, msg)
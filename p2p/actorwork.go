@@ -18,7 +18,7 @@ func (p *P2P) GetAddresses(peerID peer.ID, size uint32) bool {
 	senderAddr := p.pm.SelfMeta().ToPeerAddress()
 	// create message data
 	req := &types.AddressesRequest{MessageData: &types.MessageData{},
-		Sender: &senderAddr, MaxSize: 50}
+		Sender: &senderAddr, MaxSize: size}
 	remotePeer.sendMessage(newPbMsgRequestOrder(true, false, addressesRequest, req))
 	return true
 }
@@ -62,16 +62,43 @@ func (p *P2P) GetBlocks(peerID peer.ID, blockHashes []message.BlockHash) bool {
 	return true
 }
 
+// GetBlocksByRange send request message of count blocks from block number startNo to peer.
+// Peer may respond fewer blocks than count, since the number of blocks in a response is limited.
+func (p *P2P) GetBlocksByRange(peerID peer.ID, startNo, count uint64) bool {
+	remotePeer, exists := p.pm.GetPeer(peerID)
+	if !exists {
+		p.Warn().Str(LogPeerID, peerID.Pretty()).Str(LogProtoID, getBlockRangeRequest.String()).Msg("Message to Unknown peer, check if a bug")
+		return false
+	}
+	if count == 0 {
+		p.Warn().Msg("zero block count requested")
+		return false
+	}
+	if count > maxBlockRangeSize {
+		count = maxBlockRangeSize
+	}
+	p.Debug().Str(LogPeerID, peerID.Pretty()).Uint64("start_no", startNo).Uint64("count", count).Msg("Sending Get block range request")
+
+	// create message data
+	req := &types.GetBlockRangeRequest{MessageData: &types.MessageData{},
+		StartNo: startNo, Count: uint32(count)}
+
+	remotePeer.sendMessage(newPbMsgRequestOrder(true, true, getBlockRangeRequest, req))
+	return true
+}
+
 // NotifyNewBlock send notice message of new block to a peer
 func (p *P2P) NotifyNewBlock(newBlock message.NotifyNewBlock) bool {
+	localBestBlock.update(newBlock.Block)
 	// create message data
 	for _, neighbor := range p.pm.GetPeers() {
 		if neighbor == nil {
 			continue
 		}
 		req := &types.NewBlockNotice{MessageData: &types.MessageData{},
-			BlockHash: newBlock.Block.Hash,
-			BlockNo:   newBlock.BlockNo}
+			BlockHash:      newBlock.Block.Hash,
+			BlockNo:        newBlock.BlockNo,
+			BlockTimestamp: newBlock.Block.GetHeader().GetTimestamp()}
 		msg := newPbMsgBroadcastOrder(false, newBlockNotice, req)
 		if neighbor.State() == types.RUNNING {
 			p.Debug().Str(LogPeerID, neighbor.meta.ID.Pretty()).Str("hash", enc.ToString(newBlock.Block.Hash)).Msg("Notifying new block")
@@ -136,21 +163,59 @@ func (p *P2P) GetTXs(peerID peer.ID, txHashes []message.TXHash) bool {
 	return true
 }
 
+// DefaultMaxTxNoticeBatch is the default max number of tx hashes in a tx notice
+const DefaultMaxTxNoticeBatch = 1000
+
 // NotifyNewTX notice tx(s) id created
 func (p *P2P) NotifyNewTX(newTXs message.NotifyNewTransactions) bool {
+	if len(newTXs.Txs) == 0 {
+		return false
+	}
 	hashes := make([][]byte, len(newTXs.Txs))
 	for i, tx := range newTXs.Txs {
 		hashes[i] = tx.Hash
 	}
-	p.Debug().Int("peer_cnt", len(p.pm.GetPeers())).Str("hashes", bytesArrToString(hashes)).Msg("Notifying newTXs to peers")
-	// send to peers
-	for _, peer := range p.pm.GetPeers() {
-		// create message data
-		req := &types.NewTransactionsNotice{MessageData: &types.MessageData{},
-			TxHashes: hashes,
+	peers := p.pm.GetPeers()
+	p.Debug().Int("peer_cnt", len(peers)).Str("hashes", bytesArrToString(hashes)).Msg("Notifying newTXs to peers")
+	// send to peers, except txs which each peer already knows.
+	// notices are not signed, so notices of all txs are made once and shared by peers which know none of them.
+	var allNotices []msgOrder
+	skipped := 0
+	for _, peer := range peers {
+		toSend := peer.filterNewTxs(hashes)
+		skipped += len(hashes) - len(toSend)
+		var notices []msgOrder
+		if len(toSend) == len(hashes) {
+			if allNotices == nil {
+				allNotices = newTxNoticeOrders(hashes, p.txNoticeBatch)
+			}
+			notices = allNotices
+		} else {
+			notices = newTxNoticeOrders(toSend, p.txNoticeBatch)
+		}
+		for _, notice := range notices {
+			peer.sendMessage(notice)
 		}
-		peer.sendMessage(newPbMsgBroadcastOrder(false, newBlockNotice, req))
+	}
+	if skipped > 0 {
+		p.Debug().Int("skip_cnt", skipped).Msg("Skipped tx hashes which peers already know")
 	}
 
 	return true
 }
+
+// newTxNoticeOrders makes tx notices, each of which has at most batchSize hashes.
+func newTxNoticeOrders(hashes [][]byte, batchSize int) []msgOrder {
+	orders := make([]msgOrder, 0, (len(hashes)+batchSize-1)/batchSize)
+	for start := 0; start < len(hashes); start += batchSize {
+		end := start + batchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		req := &types.NewTransactionsNotice{MessageData: &types.MessageData{},
+			TxHashes: hashes[start:end],
+		}
+		orders = append(orders, newPbMsgBroadcastOrder(false, newTxNotice, req))
+	}
+	return orders
+}
@@ -0,0 +1,59 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTxNoticeOrders(t *testing.T) {
+	tests := []struct {
+		name      string
+		hashCnt   int
+		batchSize int
+		wantCnt   int
+	}{
+		{"TEmpty", 0, 10, 0},
+		{"TSmaller", 3, 10, 1},
+		{"TExact", 10, 10, 1},
+		{"TOneMore", 11, 10, 2},
+		{"TMany", 1000, 30, 34},
+		{"TOneByOne", 5, 1, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes := make([][]byte, tt.hashCnt)
+			for i := range hashes {
+				hashes[i] = []byte(fmt.Sprintf("tx%d", i))
+			}
+			orders := newTxNoticeOrders(hashes, tt.batchSize)
+			assert.Equal(t, tt.wantCnt, len(orders))
+
+			// all hashes are sent once in order
+			sent := make([][]byte, 0, tt.hashCnt)
+			for _, order := range orders {
+				assert.Equal(t, newTxNotice, order.GetProtocolID())
+				notice := &types.NewTransactionsNotice{}
+				assert.Nil(t, unmarshalMessage(order.(*pbMessageOrder).message.(*types.P2PMessage).Data, notice))
+				assert.True(t, len(notice.TxHashes) <= tt.batchSize)
+				sent = append(sent, notice.TxHashes...)
+			}
+			assert.Equal(t, hashes, sent)
+		})
+	}
+}
+
+func TestP2P_NotifyNewTXEmpty(t *testing.T) {
+	mockPM := new(MockP2PService)
+	p := &P2P{pm: mockPM, txNoticeBatch: DefaultMaxTxNoticeBatch}
+
+	assert.False(t, p.NotifyNewTX(message.NotifyNewTransactions{}))
+	mockPM.AssertNotCalled(t, "GetPeers")
+}
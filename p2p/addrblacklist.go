@@ -0,0 +1,210 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// handshakeFailThreshold is the number of consecutive failures of connecting or handshaking,
+	// after which the address is banned.
+	handshakeFailThreshold = 3
+	// addrBanDuration is the duration of first ban, and it is doubled each time the address is banned again.
+	addrBanDuration    = time.Minute
+	maxAddrBanDuration = time.Hour
+	// addrFailureTTL is how long an address is remembered after its last failure or the end of its ban.
+	addrFailureTTL = time.Hour * 24
+	// maxBlacklistEntries is the max number of addresses tracked by addrBlacklist.
+	maxBlacklistEntries = 4096
+)
+
+// addrBlacklist tracks consecutive handshake failures of peer addresses, and bans the address
+// which fails too many times for a while, so that it does not waste dial slots repeatedly.
+// At most size addresses are tracked, and the one forgotten first is dropped to make room for another.
+// nil addrBlacklist bans nothing.
+type addrBlacklist struct {
+	mutex     sync.Mutex
+	threshold int
+	size      int
+	entries   map[string]*addrFailure
+	// now is replaceable for test
+	now func() time.Time
+}
+
+type addrFailure struct {
+	failures int
+	bans     uint
+	until    time.Time
+	updated  time.Time
+}
+
+// forgetAt returns the time after which the entry is dropped.
+func (e *addrFailure) forgetAt() time.Time {
+	if e.until.After(e.updated) {
+		return e.until.Add(addrFailureTTL)
+	}
+	return e.updated.Add(addrFailureTTL)
+}
+
+func newAddrBlacklist(threshold int) *addrBlacklist {
+	return &addrBlacklist{threshold: threshold, size: maxBlacklistEntries, entries: make(map[string]*addrFailure), now: time.Now}
+}
+
+func addrKey(meta PeerMeta) string {
+	return net.JoinHostPort(meta.IPAddress, strconv.Itoa(int(meta.Port)))
+}
+
+// fail records a failure of the address of meta, and returns true if the address is banned by it.
+func (bl *addrBlacklist) fail(meta PeerMeta) bool {
+	if bl == nil {
+		return false
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	entry := bl.entryOf(addrKey(meta))
+	entry.failures++
+	if entry.failures < bl.threshold {
+		return false
+	}
+	bl.banEntry(entry)
+	return true
+}
+
+// ban bans the address of meta right now, regardless of the failures.
+func (bl *addrBlacklist) ban(meta PeerMeta) {
+	if bl == nil {
+		return
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	bl.banEntry(bl.entryOf(addrKey(meta)))
+}
+
+// entryOf returns the entry of key, adding it if not found, and marks it updated now. It must be called with lock
+// held.
+func (bl *addrBlacklist) entryOf(key string) *addrFailure {
+	entry, found := bl.entries[key]
+	if !found {
+		if len(bl.entries) >= bl.size {
+			bl.evict()
+		}
+		entry = &addrFailure{}
+		bl.entries[key] = entry
+	}
+	entry.updated = bl.now()
+	return entry
+}
+
+// evict drops the entries to be forgotten, or the one forgotten first if there is none. It must be called with
+// lock held.
+func (bl *addrBlacklist) evict() {
+	if bl.pruneEntries() > 0 {
+		return
+	}
+	var firstKey string
+	var first time.Time
+	for key, entry := range bl.entries {
+		if firstKey == "" || entry.forgetAt().Before(first) {
+			firstKey, first = key, entry.forgetAt()
+		}
+	}
+	delete(bl.entries, firstKey)
+}
+
+// prune drops the addresses which are neither banned nor failed for addrFailureTTL.
+func (bl *addrBlacklist) prune() {
+	if bl == nil {
+		return
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	bl.pruneEntries()
+}
+
+// pruneEntries must be called with lock held. It returns the number of dropped entries.
+func (bl *addrBlacklist) pruneEntries() int {
+	now := bl.now()
+	pruned := 0
+	for key, entry := range bl.entries {
+		if !now.Before(entry.forgetAt()) {
+			delete(bl.entries, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// banEntry must be called with lock held.
+func (bl *addrBlacklist) banEntry(entry *addrFailure) {
+	ban := addrBanDuration << entry.bans
+	if ban > maxAddrBanDuration || ban <= 0 {
+		ban = maxAddrBanDuration
+	}
+	entry.failures = 0
+	entry.bans++
+	entry.until = bl.now().Add(ban)
+}
+
+// succeed clears the failure history of the address of meta.
+func (bl *addrBlacklist) succeed(meta PeerMeta) {
+	if bl == nil {
+		return
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	delete(bl.entries, addrKey(meta))
+}
+
+// bannedAddrs returns the addresses banned now and the time until which those are banned.
+func (bl *addrBlacklist) bannedAddrs() map[string]time.Time {
+	addrs := make(map[string]time.Time)
+	if bl == nil {
+		return addrs
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	now := bl.now()
+	for key, entry := range bl.entries {
+		if now.Before(entry.until) {
+			addrs[key] = entry.until
+		}
+	}
+	return addrs
+}
+
+// restoreBan bans the address of key until the time, which was banned before restart.
+func (bl *addrBlacklist) restoreBan(key string, until time.Time) {
+	if bl == nil {
+		return
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	if !bl.now().Before(until) {
+		return
+	}
+	entry := bl.entryOf(key)
+	if entry.bans == 0 {
+		entry.bans = 1
+	}
+	if entry.until.Before(until) {
+		entry.until = until
+	}
+}
+
+// banned returns true if the address of meta is banned now.
+func (bl *addrBlacklist) banned(meta PeerMeta) bool {
+	if bl == nil {
+		return false
+	}
+	bl.mutex.Lock()
+	defer bl.mutex.Unlock()
+	entry, found := bl.entries[addrKey(meta)]
+	return found && bl.now().Before(entry.until)
+}
@@ -0,0 +1,134 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	cfg "github.com/aergoio/aergo/config"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddrBlacklist_Backoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	bl := newAddrBlacklist(3)
+	bl.now = func() time.Time { return now }
+	meta := PeerMeta{ID: dummyPeerID, IPAddress: "172.21.11.12", Port: 7846}
+	// other port of same ip is another address
+	other := PeerMeta{ID: dummyPeerID, IPAddress: "172.21.11.12", Port: 7847}
+
+	expectedBans := []time.Duration{addrBanDuration, addrBanDuration * 2, addrBanDuration * 4}
+	for _, ban := range expectedBans {
+		assert.False(t, bl.fail(meta))
+		assert.False(t, bl.fail(meta))
+		assert.True(t, bl.fail(meta))
+		assert.True(t, bl.banned(meta))
+		assert.False(t, bl.banned(other))
+
+		now = now.Add(ban - time.Second)
+		assert.True(t, bl.banned(meta))
+		now = now.Add(time.Second)
+		assert.False(t, bl.banned(meta))
+	}
+
+	// ban is not longer than max
+	for i := 0; i < 30; i++ {
+		bl.fail(meta)
+	}
+	now = now.Add(maxAddrBanDuration)
+	assert.False(t, bl.banned(meta))
+
+	// success clears history
+	bl.fail(meta)
+	bl.fail(meta)
+	bl.succeed(meta)
+	assert.False(t, bl.fail(meta))
+	assert.False(t, bl.banned(meta))
+}
+
+func TestAddrBlacklist_Bound(t *testing.T) {
+	now := time.Unix(1000, 0)
+	bl := newAddrBlacklist(3)
+	bl.size = 2
+	bl.now = func() time.Time { return now }
+	metas := make([]PeerMeta, 3)
+	for i := range metas {
+		metas[i] = PeerMeta{ID: dummyPeerID, IPAddress: "172.21.11.12", Port: uint32(7846 + i)}
+	}
+
+	// the address forgotten first is dropped to make room, even if it is banned
+	bl.ban(metas[0])
+	now = now.Add(time.Second)
+	bl.ban(metas[1])
+	now = now.Add(time.Second)
+	bl.fail(metas[2])
+	assert.Equal(t, 2, len(bl.entries))
+	assert.False(t, bl.banned(metas[0]))
+	assert.True(t, bl.banned(metas[1]))
+
+	// the addresses are forgotten after ttl since the last failure or the end of ban
+	now = now.Add(addrFailureTTL)
+	bl.prune()
+	assert.Equal(t, 1, len(bl.entries))
+	_, found := bl.entries[addrKey(metas[1])]
+	assert.True(t, found)
+	now = now.Add(addrBanDuration)
+	bl.prune()
+	assert.Empty(t, bl.entries)
+}
+
+func TestPeerManager_tryConnectPeersSkipBanned(t *testing.T) {
+	// address which refuses connection
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	now := time.Unix(1000, 0)
+	bl := newAddrBlacklist(3)
+	bl.now = func() time.Time { return now }
+	conf := cfg.NewServerContext("", "").GetDefaultP2PConfig()
+	target := &peerManager{conf: conf, log: logger, mutex: &sync.Mutex{}, addrTTL: DefaultNodeTTL, addrBlacklist: bl,
+		remotePeers: make(map[peer.ID]*RemotePeer), designatedPeers: make(map[peer.ID]PeerMeta)}
+	target.Host = newTestHost(t)
+	defer target.Host.Close()
+
+	meta := PeerMeta{ID: dummyPeerID, IPAddress: "127.0.0.1", Port: uint32(port), Outbound: true}
+	failures := func() int {
+		if entry, found := bl.entries[addrKey(meta)]; found {
+			return entry.failures
+		}
+		return 0
+	}
+	target.peerPool = map[peer.ID]PeerMeta{meta.ID: meta}
+	for i := 1; i < handshakeFailThreshold; i++ {
+		target.tryConnectPeers()
+		assert.Equal(t, i, failures())
+	}
+	target.tryConnectPeers()
+	assert.True(t, bl.banned(meta))
+
+	// skipped in later sweeps
+	for i := 0; i < 3; i++ {
+		target.tryConnectPeers()
+		assert.Equal(t, 0, failures())
+	}
+
+	// and tried again after cooldown
+	now = now.Add(addrBanDuration)
+	target.tryConnectPeers()
+	assert.Equal(t, 1, failures())
+
+	// designated peer is exempt
+	target.designatedPeers[meta.ID] = meta
+	now = now.Add(maxAddrBanDuration)
+	for i := 0; i < handshakeFailThreshold*2; i++ {
+		target.tryConnectPeers()
+	}
+	assert.False(t, bl.banned(meta))
+}
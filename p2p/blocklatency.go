@@ -0,0 +1,63 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// BlockPropagationMetric is the name of histogram metric of block propagation latency in nanoseconds.
+const BlockPropagationMetric = "p2p.block.propagation"
+
+// blockPropagation records how long new blocks take to reach this node since they are produced.
+var blockPropagation = newBlockLatency(metrics.DefaultRegistry)
+
+type blockLatency struct {
+	histogram metrics.Histogram
+}
+
+func newBlockLatency(registry metrics.Registry) *blockLatency {
+	histogram := metrics.GetOrRegisterHistogram(BlockPropagationMetric, registry, metrics.NewExpDecaySample(1028, 0.015))
+	return &blockLatency{histogram: histogram}
+}
+
+// record adds the latency between blockTime (unix nano) and receivedAt. Notices from old peers
+// have no block time and are not recorded. Negative latency caused by clock skew is regarded as zero.
+func (bl *blockLatency) record(blockTime int64, receivedAt time.Time) (time.Duration, bool) {
+	if blockTime <= 0 {
+		return 0, false
+	}
+	latency := receivedAt.Sub(time.Unix(0, blockTime))
+	if latency < 0 {
+		latency = 0
+	}
+	bl.histogram.Update(int64(latency))
+	return latency, true
+}
+
+// percentiles returns latencies of the given percentiles, e.g. 0.5 for median.
+func (bl *blockLatency) percentiles(ps ...float64) []time.Duration {
+	values := bl.histogram.Percentiles(ps)
+	latencies := make([]time.Duration, len(values))
+	for i, v := range values {
+		latencies[i] = time.Duration(v)
+	}
+	return latencies
+}
+
+// stats returns the number of recorded latencies and their median, 90th and 99th percentiles, which are exposed
+// through the statistics of p2p service.
+func (bl *blockLatency) stats() map[string]interface{} {
+	latencies := bl.percentiles(0.5, 0.9, 0.99)
+	return map[string]interface{}{
+		"count": bl.histogram.Count(),
+		"p50":   latencies[0].String(),
+		"p90":   latencies[1].String(),
+		"p99":   latencies[2].String(),
+	}
+}
@@ -0,0 +1,59 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockLatency_Record(t *testing.T) {
+	receivedAt := time.Unix(1000, 0)
+	tests := []struct {
+		name      string
+		blockTime int64
+		expected  time.Duration
+		recorded  bool
+	}{
+		{"TNormal", receivedAt.Add(-300 * time.Millisecond).UnixNano(), 300 * time.Millisecond, true},
+		{"TNoTime", 0, 0, false},
+		{"TSkewed", receivedAt.Add(time.Second).UnixNano(), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bl := newBlockLatency(metrics.NewRegistry())
+			latency, recorded := bl.record(tt.blockTime, receivedAt)
+			assert.Equal(t, tt.recorded, recorded)
+			assert.Equal(t, tt.expected, latency)
+		})
+	}
+}
+
+func TestBlockLatency_Percentiles(t *testing.T) {
+	registry := metrics.NewRegistry()
+	bl := newBlockLatency(registry)
+	receivedAt := time.Unix(1000, 0)
+	for i := 1; i <= 100; i++ {
+		bl.record(receivedAt.Add(-time.Duration(i)*time.Millisecond).UnixNano(), receivedAt)
+	}
+	ps := bl.percentiles(0.5, 0.99)
+	assert.Equal(t, 50500*time.Microsecond, ps[0])
+	assert.Equal(t, 99990*time.Microsecond, ps[1])
+
+	// exposed in metrics registry
+	histogram, ok := registry.Get(BlockPropagationMetric).(metrics.Histogram)
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), histogram.Count())
+	assert.Equal(t, int64(100*time.Millisecond), histogram.Max())
+
+	// and in the statistics of p2p service
+	stats := bl.stats()
+	assert.Equal(t, int64(100), stats["count"])
+	assert.Equal(t, "50.5ms", stats["p50"])
+	assert.Equal(t, "99.99ms", stats["p99"])
+}
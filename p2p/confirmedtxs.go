@@ -0,0 +1,60 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"github.com/aergoio/aergo/internal/enc"
+	"github.com/aergoio/aergo/types"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// confirmedTxSet keeps hashes of txs recently confirmed in main chain, so that notices of them
+// are not handled again. The oldest ones are evicted when the set is full.
+type confirmedTxSet struct {
+	cache *lru.Cache
+}
+
+// newConfirmedTxSet creates the set of which size is bounded by size. It returns nil, which
+// contains nothing, if size is not positive.
+func newConfirmedTxSet(size int) *confirmedTxSet {
+	if size <= 0 {
+		return nil
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		panic("Failed to create confirmed tx set " + err.Error())
+	}
+	return &confirmedTxSet{cache: cache}
+}
+
+func (s *confirmedTxSet) update(confirmed, reverted [][]byte) {
+	if s == nil {
+		return
+	}
+	for _, hash := range confirmed {
+		s.cache.Add(enc.ToString(hash), true)
+	}
+	// txs rolled back by reorg can be gossiped and mined again
+	for _, hash := range reverted {
+		s.cache.Remove(enc.ToString(hash))
+	}
+}
+
+func (s *confirmedTxSet) contains(hash []byte) bool {
+	if s == nil {
+		return false
+	}
+	return s.cache.Contains(enc.ToString(hash))
+}
+
+func txHashes(block *types.Block) [][]byte {
+	txs := block.GetBody().GetTxs()
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.GetHash()
+	}
+	return hashes
+}
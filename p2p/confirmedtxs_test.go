@@ -0,0 +1,55 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/types"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTxProtocol_handleNewTXsNoticeOfConfirmedTx(t *testing.T) {
+	mockActorServ := &MockActorService{}
+	mockActorServ.On("SendRequest", message.P2PSvc, mock.AnythingOfType("*message.GetTransactions"))
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, confirmedTxs: newConfirmedTxSet(10)}
+	remotePeer := newRemotePeer(PeerMeta{ID: peer.ID("remote")}, pm, mockActorServ, logger)
+	handler := NewTxHandler(pm, remotePeer, logger)
+
+	txHash := []byte("confirmedTx")
+	notice := func() *types.P2PMessage {
+		data, _ := marshalMessage(&types.NewTransactionsNotice{MessageData: &types.MessageData{},
+			TxHashes: [][]byte{txHash}})
+		return &types.P2PMessage{Header: &types.MessageData{Subprotocol: newTxNotice.Uint32()}, Data: data}
+	}
+
+	// tx is confirmed by new block
+	pm.UpdateConfirmedTxs([][]byte{txHash}, nil)
+	handler.handleNewTXsNotice(notice())
+	mockActorServ.AssertNotCalled(t, "SendRequest", message.P2PSvc, mock.Anything)
+
+	// tx is reverted by reorg, and notice of it is accepted again
+	pm.UpdateConfirmedTxs(nil, [][]byte{txHash})
+	handler.handleNewTXsNotice(notice())
+	mockActorServ.AssertCalled(t, "SendRequest", message.P2PSvc, &message.GetTransactions{ToWhom: peer.ID("remote"),
+		Hashes: []message.TXHash{txHash}})
+}
+
+func TestConfirmedTxSet_Bounded(t *testing.T) {
+	set := newConfirmedTxSet(2)
+	set.update([][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3")}, nil)
+	assert.False(t, set.contains([]byte("tx1")), "oldest tx must be evicted")
+	assert.True(t, set.contains([]byte("tx2")))
+	assert.True(t, set.contains([]byte("tx3")))
+
+	// disabled set contains nothing
+	var disabled = newConfirmedTxSet(0)
+	disabled.update([][]byte{[]byte("tx1")}, nil)
+	assert.False(t, disabled.contains([]byte("tx1")))
+}
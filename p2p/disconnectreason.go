@@ -0,0 +1,26 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+// DisconnectReason is the reason why peer is disconnected
+type DisconnectReason int32
+
+// Zero value means that the peer is not disconnected.
+const (
+	_ DisconnectReason = iota
+	// ProtocolViolation means that the peer sent invalid or unexpected message.
+	ProtocolViolation
+	// Timeout means that the peer did not respond in time.
+	Timeout
+	// TooManyPeers means that this node has no room for the peer.
+	TooManyPeers
+	// Manual means that the peer is disconnected by request of operator or other module.
+	Manual
+	// BrokenConnection means that writing to the peer failed, such as the connection to a crashed host.
+	BrokenConnection
+)
+
+//go:generate stringer -type=DisconnectReason
@@ -0,0 +1,135 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+)
+
+const (
+	// DefaultDNSCacheTTL is how long the addresses resolved from hostname of peer are reused.
+	DefaultDNSCacheTTL = time.Minute * 5
+	// DefaultDNSCacheSize is the max number of hostnames of which resolved addresses are kept. Only the hostnames of
+	// designated peers are resolved, so it is far more than needed.
+	DefaultDNSCacheSize = 256
+	dnsResolveTimeout   = time.Second * 10
+)
+
+// dnsAddrCache caches ip addresses resolved from dns multiaddr of peers, so that hostname is not resolved
+// on every dial, while the change of ip address is followed after ttl. At most size hostnames are kept, and the
+// one expiring first is dropped to make room for another.
+type dnsAddrCache struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	size      int
+	entries   map[string]dnsAddrEntry
+	resolving map[string]bool
+	// resolve and now are replaceable for test
+	resolve func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error)
+	now     func() time.Time
+}
+
+type dnsAddrEntry struct {
+	addrs  []ma.Multiaddr
+	expire time.Time
+}
+
+func newDNSAddrCache(ttl time.Duration, size int) *dnsAddrCache {
+	return &dnsAddrCache{ttl: ttl, size: size, entries: make(map[string]dnsAddrEntry), resolving: make(map[string]bool),
+		resolve: madns.Resolve, now: time.Now}
+}
+
+// lookup returns the addresses of addr which were resolved and not expired yet, without resolving addr. addr is
+// returned as is if it is not a dns multiaddr.
+func (c *dnsAddrCache) lookup(addr ma.Multiaddr) ([]ma.Multiaddr, bool) {
+	if !madns.Matches(addr) {
+		return []ma.Multiaddr{addr}, true
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, found := c.entries[addr.String()]
+	if !found || !c.now().Before(entry.expire) {
+		return nil, false
+	}
+	return entry.addrs, true
+}
+
+// resolveAddr returns dialable addresses of addr. addr is returned as is if it is not a dns multiaddr. It blocks
+// until addr is resolved, up to dnsResolveTimeout.
+func (c *dnsAddrCache) resolveAddr(addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+	if addrs, found := c.lookup(addr); found {
+		return addrs, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dnsResolveTimeout)
+	defer cancel()
+	addrs, err := c.resolve(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.store(addr.String(), dnsAddrEntry{addrs: addrs, expire: c.now().Add(c.ttl)})
+	c.mutex.Unlock()
+	return addrs, nil
+}
+
+// resolveInBackground resolves addr in another goroutine and calls done with the result, so that the caller is
+// not blocked by a slow dns server. It does nothing if addr is being resolved already.
+func (c *dnsAddrCache) resolveInBackground(addr ma.Multiaddr, done func(err error)) {
+	key := addr.String()
+	c.mutex.Lock()
+	if c.resolving[key] {
+		c.mutex.Unlock()
+		return
+	}
+	c.resolving[key] = true
+	c.mutex.Unlock()
+
+	go func() {
+		_, err := c.resolveAddr(addr)
+		c.mutex.Lock()
+		delete(c.resolving, key)
+		c.mutex.Unlock()
+		done(err)
+	}()
+}
+
+// store puts entry of key, dropping expired entries, or the one expiring first, if the cache is full. It must be
+// called with mutex locked.
+func (c *dnsAddrCache) store(key string, entry dnsAddrEntry) {
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.size {
+		now := c.now()
+		var firstKey string
+		var first time.Time
+		for k, e := range c.entries {
+			if !now.Before(e.expire) {
+				delete(c.entries, k)
+			} else if firstKey == "" || e.expire.Before(first) {
+				firstKey, first = k, e.expire
+			}
+		}
+		if len(c.entries) >= c.size {
+			delete(c.entries, firstKey)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// addrTTL returns how long the resolved addresses are kept in peerstore.
+func (c *dnsAddrCache) addrTTL() time.Duration {
+	return c.ttl
+}
+
+// expire drops resolved addresses of addr, so that it is resolved again on next dial.
+func (c *dnsAddrCache) expire(addr ma.Multiaddr) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, addr.String())
+}
@@ -0,0 +1,141 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSAddrCache_resolveAddr(t *testing.T) {
+	now := time.Unix(1000, 0)
+	resolved := []string{"/ip4/172.21.11.12/tcp/7846"}
+	resolveCnt := 0
+	cache := newDNSAddrCache(time.Minute, 10)
+	cache.now = func() time.Time { return now }
+	cache.resolve = func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+		resolveCnt++
+		if addr.String() != "/dns4/node1.example.com/tcp/7846" {
+			return nil, fmt.Errorf("no such host")
+		}
+		addrs := make([]ma.Multiaddr, 0, len(resolved))
+		for _, r := range resolved {
+			addr, _ := ma.NewMultiaddr(r)
+			addrs = append(addrs, addr)
+		}
+		return addrs, nil
+	}
+	dnsAddr, err := toPeerMultiAddr("node1.example.com", 7846)
+	assert.Nil(t, err)
+
+	addrs, err := cache.resolveAddr(dnsAddr)
+	assert.Nil(t, err)
+	assert.Equal(t, "/ip4/172.21.11.12/tcp/7846", addrs[0].String())
+
+	// resolved addresses are reused within ttl, even if ip address is changed
+	resolved = []string{"/ip4/172.21.11.13/tcp/7846"}
+	now = now.Add(time.Minute - time.Second)
+	addrs, _ = cache.resolveAddr(dnsAddr)
+	assert.Equal(t, "/ip4/172.21.11.12/tcp/7846", addrs[0].String())
+	assert.Equal(t, 1, resolveCnt)
+
+	// and then resolved again
+	now = now.Add(time.Second)
+	addrs, _ = cache.resolveAddr(dnsAddr)
+	assert.Equal(t, "/ip4/172.21.11.13/tcp/7846", addrs[0].String())
+	assert.Equal(t, 2, resolveCnt)
+
+	// expired address is resolved again on next dial
+	resolved = []string{"/ip4/172.21.11.14/tcp/7846"}
+	cache.expire(dnsAddr)
+	addrs, _ = cache.resolveAddr(dnsAddr)
+	assert.Equal(t, "/ip4/172.21.11.14/tcp/7846", addrs[0].String())
+	assert.Equal(t, 3, resolveCnt)
+
+	// ip address is not resolved
+	ipAddr, _ := toPeerMultiAddr("172.21.11.15", 7846)
+	addrs, err = cache.resolveAddr(ipAddr)
+	assert.Nil(t, err)
+	assert.Equal(t, []ma.Multiaddr{ipAddr}, addrs)
+	assert.Equal(t, 3, resolveCnt)
+
+	// failure is not cached
+	unknown, _ := toPeerMultiAddr("unknown.example.com", 7846)
+	_, err = cache.resolveAddr(unknown)
+	assert.NotNil(t, err)
+	_, err = cache.resolveAddr(unknown)
+	assert.NotNil(t, err)
+	assert.Equal(t, 5, resolveCnt)
+}
+
+func TestDNSAddrCache_size(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newDNSAddrCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+	cache.resolve = func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+		ipAddr, _ := toPeerMultiAddr("172.21.11.12", 7846)
+		return []ma.Multiaddr{ipAddr}, nil
+	}
+	hosts := make([]ma.Multiaddr, 3)
+	for i := range hosts {
+		hosts[i], _ = toPeerMultiAddr(fmt.Sprintf("node%d.example.com", i), 7846)
+		_, err := cache.resolveAddr(hosts[i])
+		assert.Nil(t, err)
+		now = now.Add(time.Second)
+	}
+	// the one expiring first is dropped
+	assert.Equal(t, 2, len(cache.entries))
+	_, found := cache.lookup(hosts[0])
+	assert.False(t, found)
+	_, found = cache.lookup(hosts[2])
+	assert.True(t, found)
+
+	// expired entries are dropped before the others
+	now = now.Add(time.Minute - 2*time.Second)
+	_, found = cache.lookup(hosts[1])
+	assert.False(t, found)
+	cache.resolveAddr(hosts[0])
+	_, found = cache.lookup(hosts[2])
+	assert.True(t, found)
+	_, found = cache.lookup(hosts[0])
+	assert.True(t, found)
+}
+
+func TestDNSAddrCache_resolveInBackground(t *testing.T) {
+	release := make(chan struct{})
+	resolveCnt := 0
+	cache := newDNSAddrCache(time.Minute, 10)
+	cache.resolve = func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+		resolveCnt++
+		<-release
+		ipAddr, _ := toPeerMultiAddr("172.21.11.12", 7846)
+		return []ma.Multiaddr{ipAddr}, nil
+	}
+	dnsAddr, _ := toPeerMultiAddr("node1.example.com", 7846)
+
+	done := make(chan error, 2)
+	cache.resolveInBackground(dnsAddr, func(err error) { done <- err })
+	// the caller is not blocked, and the host being resolved is not resolved again
+	cache.resolveInBackground(dnsAddr, func(err error) { done <- err })
+	_, found := cache.lookup(dnsAddr)
+	assert.False(t, found)
+
+	close(release)
+	assert.Nil(t, <-done)
+	select {
+	case <-done:
+		t.Fatal("resolved twice")
+	case <-time.After(time.Millisecond * 100):
+	}
+	assert.Equal(t, 1, resolveCnt)
+	addrs, found := cache.lookup(dnsAddr)
+	assert.True(t, found)
+	assert.Equal(t, "/ip4/172.21.11.12/tcp/7846", addrs[0].String())
+}
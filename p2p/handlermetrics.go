@@ -0,0 +1,63 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// HandlerMetricPrefix is the prefix of metric names of message handlers. The timer of handling is named with
+// subprotocol, such as p2p.handler.getBlocksRequest, and the counter of errors has additional suffix .error
+const HandlerMetricPrefix = "p2p.handler."
+
+const handlerErrorSuffix = ".error"
+
+// handlerStats records count, duration and errors of handling incoming messages per subprotocol.
+var handlerStats = newHandlerMetrics(metrics.DefaultRegistry)
+
+type handlerMetrics struct {
+	registry metrics.Registry
+}
+
+func newHandlerMetrics(registry metrics.Registry) *handlerMetrics {
+	return &handlerMetrics{registry: registry}
+}
+
+// record adds a handling of message of protocol, which took elapsed and failed or not.
+func (hm *handlerMetrics) record(protocol SubProtocol, elapsed time.Duration, failed bool) {
+	name := HandlerMetricPrefix + protocol.String()
+	metrics.GetOrRegisterTimer(name, hm.registry).Update(elapsed)
+	errCounter := metrics.GetOrRegisterCounter(name+handlerErrorSuffix, hm.registry)
+	if failed {
+		errCounter.Inc(1)
+	}
+}
+
+// stats returns statistics of handlers, keyed by subprotocol name.
+func (hm *handlerMetrics) stats() map[string]interface{} {
+	stats := make(map[string]interface{})
+	hm.registry.Each(func(name string, i interface{}) {
+		timer, ok := i.(metrics.Timer)
+		if !ok || !strings.HasPrefix(name, HandlerMetricPrefix) {
+			return
+		}
+		var errCount int64
+		if counter, ok := hm.registry.Get(name + handlerErrorSuffix).(metrics.Counter); ok {
+			errCount = counter.Count()
+		}
+		stats[strings.TrimPrefix(name, HandlerMetricPrefix)] = map[string]interface{}{
+			"count":  timer.Count(),
+			"errors": errCount,
+			"total":  time.Duration(timer.Sum()).String(),
+			"mean":   time.Duration(int64(timer.Mean())).String(),
+			"max":    time.Duration(timer.Max()).String(),
+		}
+	})
+	return stats
+}
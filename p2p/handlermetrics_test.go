@@ -0,0 +1,70 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/types"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerMetrics_record(t *testing.T) {
+	hm := newHandlerMetrics(metrics.NewRegistry())
+	hm.record(getBlocksRequest, time.Millisecond, false)
+	hm.record(getBlocksRequest, 3*time.Millisecond, true)
+	hm.record(newTxNotice, time.Millisecond, false)
+
+	stats := hm.stats()
+	assert.Len(t, stats, 2)
+	blkStat := stats[getBlocksRequest.String()].(map[string]interface{})
+	assert.Equal(t, int64(2), blkStat["count"])
+	assert.Equal(t, int64(1), blkStat["errors"])
+	assert.Equal(t, (4 * time.Millisecond).String(), blkStat["total"])
+	assert.Equal(t, (3 * time.Millisecond).String(), blkStat["max"])
+	txStat := stats[newTxNotice.String()].(map[string]interface{})
+	assert.Equal(t, int64(1), txStat["count"])
+	assert.Equal(t, int64(0), txStat["errors"])
+}
+
+func TestRemotePeer_handleMsgMetrics(t *testing.T) {
+	// protocols not used by real handlers, so that metrics are not affected by other tests
+	const (
+		okProto    = SubProtocol(0x0f01)
+		authProto  = SubProtocol(0x0f02)
+		panicProto = SubProtocol(0x0f03)
+	)
+	p := newRemotePeer(PeerMeta{ID: dummyPeerID}, new(MockP2PService), new(MockActorService), logger)
+	p.handlers[okProto] = func(msg *types.P2PMessage) {}
+	p.handlers[authProto] = func(msg *types.P2PMessage) { atomic.AddUint32(&p.failCounter, 1) }
+	p.handlers[panicProto] = func(msg *types.P2PMessage) { panic("test panic") }
+	dispatch := func(proto SubProtocol) error {
+		return p.handleMsg(&types.P2PMessage{Header: &types.MessageData{Subprotocol: proto.Uint32()}})
+	}
+
+	assert.Nil(t, dispatch(okProto))
+	assert.Nil(t, dispatch(okProto))
+	assert.Nil(t, dispatch(authProto))
+	assert.NotNil(t, dispatch(panicProto))
+
+	stats := handlerStats.stats()
+	expected := []struct {
+		proto  SubProtocol
+		count  int64
+		errors int64
+	}{
+		{okProto, 2, 0},
+		{authProto, 1, 1},
+		{panicProto, 1, 1},
+	}
+	for _, e := range expected {
+		stat := stats[e.proto.String()].(map[string]interface{})
+		assert.Equal(t, e.count, stat["count"], e.proto.String())
+		assert.Equal(t, e.errors, stat["errors"], e.proto.String())
+	}
+}
@@ -7,6 +7,8 @@ package p2p
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/aergoio/aergo/message"
@@ -18,7 +20,51 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-const aergoP2PSub protocol.ID = "/aergop2p/0.2"
+const (
+	// aergoP2PSubPrefix is prefix of aergo p2p protocol ID, which is followed by semantic version
+	aergoP2PSubPrefix = "/aergo/p2p/"
+	// aergoP2PSub is the protocol ID used before versioning. It is same as version 1.0.0 on wire.
+	aergoP2PSub protocol.ID = "/aergop2p/0.2"
+)
+
+// defaultHandshakeTimeout is the default time limit of the status exchange of inbound handshake.
+const defaultHandshakeTimeout = time.Second * 10
+
+// p2pProtocolIDs are protocol IDs that this node supports, in order of preference. The newest
+// version must be first, so that the highest version supported by both side is selected while
+// opening stream.
+var p2pProtocolIDs = []protocol.ID{aergoP2PSubPrefix + "1.0.0", aergoP2PSub}
+
+// supportedProtocols returns protocol IDs of peer manager in order of preference.
+func (pm *peerManager) supportedProtocols() []protocol.ID {
+	if len(pm.protocolIDs) == 0 {
+		return p2pProtocolIDs
+	}
+	return pm.protocolIDs
+}
+
+// setProtocolHandlers sets handler of streams for all supported versions of protocol.
+func (pm *peerManager) setProtocolHandlers(handler inet.StreamHandler) {
+	for _, pid := range pm.supportedProtocols() {
+		pm.SetStreamHandler(pid, handler)
+	}
+}
+
+// newP2PStream opens stream to peer in the highest protocol version which both sides support.
+// It fails if the peer supports none of them.
+func (pm *peerManager) newP2PStream(ctx context.Context, peerID peer.ID) (inet.Stream, error) {
+	pids := pm.supportedProtocols()
+	s, err := pm.NewStream(ctx, peerID, pids...)
+	if err != nil && len(pm.relayPeers) > 0 {
+		pm.log.Debug().Err(err).Str(LogPeerID, peerID.Pretty()).Msg("Direct dial is failed. Trying via relay")
+		s, err = pm.newStreamViaRelay(ctx, peerID, pids...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pm.log.Debug().Str(LogPeerID, peerID.Pretty()).Str(LogProtoID, string(s.Protocol())).Msg("Protocol is negotiated")
+	return s, nil
+}
 
 func doHandshake(pm *peerManager, peerID peer.ID, rw *bufio.ReadWriter) bool {
 	pm.log.Debug().Str(LogPeerID, peerID.Pretty()).Msg("Starting Handshake")
@@ -34,6 +80,7 @@ func doHandshake(pm *peerManager, peerID peer.ID, rw *bufio.ReadWriter) bool {
 		pm.log.Warn().Err(err).Msg("failed to create status message")
 		return false
 	}
+	statusMsg.ChainID = pm.chainID
 	serialized, err := marshalMessage(statusMsg)
 	if err != nil {
 		pm.log.Warn().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("failed to marshal")
@@ -68,6 +115,10 @@ func doHandshake(pm *peerManager, peerID peer.ID, rw *bufio.ReadWriter) bool {
 		pm.log.Warn().Err(err).Msg("Failed to decode status message")
 		return false
 	}
+	if err := pm.checkChainID(statusResp); err != nil {
+		pm.log.Info().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("Refusing peer of another chain")
+		return false
+	}
 
 	// check status message
 	return true
@@ -77,6 +128,13 @@ func (pm *peerManager) onHandshake(s inet.Stream) {
 	peerID := s.Conn().RemotePeer()
 	rw := &bufio.ReadWriter{Reader: bufio.NewReader(s), Writer: bufio.NewWriter(s)}
 
+	// a peer which doesn't finish the status exchange in time is dropped, so that it can't hold the handshake slot
+	timeout := pm.handshakeTimeout
+	if timeout <= 0 {
+		timeout = defaultHandshakeTimeout
+	}
+	s.SetDeadline(time.Now().Add(timeout))
+
 	// first message must be status
 	data := &types.P2PMessage{}
 	decoder := mc_pb.Multicodec(nil).Decoder(s)
@@ -104,8 +162,26 @@ func (pm *peerManager) onHandshake(s inet.Stream) {
 		return
 	}
 
-	// TODO: check status
+	if err := pm.checkChainID(statusMsg); err != nil {
+		pm.log.Info().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("Refusing peer of another chain")
+		pm.sendGoAway(rw, "different chain")
+		s.Close()
+		return
+	}
+	// the peer id is the one authenticated by the connection, not the one the peer claims
+	if statusMsg.Sender == nil {
+		pm.log.Info().Str(LogPeerID, peerID.Pretty()).Msg("Status message has no sender")
+		pm.sendGoAway(rw, "invalid status message")
+		s.Close()
+		return
+	}
 	meta := FromPeerAddress(statusMsg.Sender)
+	if meta.ID != peerID {
+		pm.log.Info().Str(LogPeerID, peerID.Pretty()).Str("claimed", meta.ID.Pretty()).Msg("Peer claims another peer id")
+		pm.sendGoAway(rw, "peer id mismatch")
+		s.Close()
+		return
+	}
 
 	// send my status message as response
 	statusResp, err := createStatusMsg(pm, pm.iServ)
@@ -115,6 +191,7 @@ func (pm *peerManager) onHandshake(s inet.Stream) {
 		s.Close()
 		return
 	}
+	statusResp.ChainID = pm.chainID
 	serialized, err := marshalMessage(statusResp)
 	if err != nil {
 		pm.log.Warn().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("failed to marshal")
@@ -129,17 +206,28 @@ func (pm *peerManager) onHandshake(s inet.Stream) {
 	err = SendProtoMessage(container, rw)
 	if err != nil {
 		pm.log.Warn().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("failed to send response status ")
+		s.Close()
 		return
 	}
+	// the stream is used by the peer from now on
+	s.SetDeadline(time.Time{})
 
 	// try Add peer
-	if !pm.tryAddInboundPeer(meta, rw) {
+	if !pm.tryAddInboundPeer(meta, rw, s.Protocol()) {
 		// failed to add
 		pm.sendGoAway(rw, "Concurrent handshake")
 		s.Close()
 	}
 }
 
+// checkChainID checks that the peer of status joins the same chain as this node.
+func (pm *peerManager) checkChainID(status *types.Status) error {
+	if status.GetChainID() != pm.chainID {
+		return fmt.Errorf("chain id mismatch: mine=%s, peer=%s", pm.chainID, status.GetChainID())
+	}
+	return nil
+}
+
 func (pm *peerManager) sendGoAway(rw *bufio.ReadWriter, msg string) {
 	serialized, err := marshalMessage(&types.GoAwayNotice{MessageData: &types.MessageData{}, Message: msg})
 	if err != nil {
@@ -154,7 +242,7 @@ func (pm *peerManager) sendGoAway(rw *bufio.ReadWriter, msg string) {
 
 func createStatusMsg(ps PeerManager, actorServ ActorService) (*types.Status, error) {
 	// find my best block
-	bestBlock, err := extractBlockFromRequest(actorServ.CallRequest(message.ChainSvc, &message.GetBestBlock{}))
+	bestBlock, err := extractBlockFromRequest(callRequestWithin(actorServ, message.ChainSvc, &message.GetBestBlock{}))
 	if err != nil {
 		return nil, err
 	}
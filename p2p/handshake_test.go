@@ -0,0 +1,94 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/types"
+	inet "github.com/libp2p/go-libp2p-net"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerManager_newP2PStream(t *testing.T) {
+	const v1, v2, v3 = protocol.ID(aergoP2PSubPrefix + "1.0.0"), protocol.ID(aergoP2PSubPrefix + "1.1.0"), protocol.ID(aergoP2PSubPrefix + "2.0.0")
+	tests := []struct {
+		name     string
+		local    []protocol.ID
+		remote   []protocol.ID
+		expected protocol.ID
+	}{
+		{"TSame", []protocol.ID{v2, v1}, []protocol.ID{v2, v1}, v2},
+		{"TLocalNewer", []protocol.ID{v3, v2, v1}, []protocol.ID{v2, v1}, v2},
+		{"TRemoteNewer", []protocol.ID{v2, v1}, []protocol.ID{v3, v2, v1}, v2},
+		{"TLegacy", []protocol.ID{v1, aergoP2PSub}, []protocol.ID{aergoP2PSub}, aergoP2PSub},
+		{"TIncompatible", []protocol.ID{v3}, []protocol.ID{v2, v1}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := &peerManager{log: logger, mutex: &sync.Mutex{}, protocolIDs: tt.remote}
+			remote.Host = newTestHost(t)
+			defer remote.Host.Close()
+			remote.setProtocolHandlers(func(s inet.Stream) {
+				s.Close()
+			})
+
+			local := &peerManager{log: logger, mutex: &sync.Mutex{}, protocolIDs: tt.local}
+			local.Host = newTestHost(t)
+			defer local.Host.Close()
+			local.Peerstore().AddAddrs(remote.ID(), remote.Addrs(), pstore.TempAddrTTL)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+			defer cancel()
+			s, err := local.newP2PStream(ctx, remote.ID())
+			if tt.expected == "" {
+				assert.NotNil(t, err)
+				return
+			}
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.expected, s.Protocol())
+				s.Close()
+			}
+		})
+	}
+}
+
+func TestPeerManager_onHandshakeTimeout(t *testing.T) {
+	remote := &peerManager{log: logger, mutex: &sync.Mutex{}, handshakeSlots: newHandshakeSlots(1),
+		handshakeTimeout: time.Millisecond * 200}
+	remote.Host = newTestHost(t)
+	defer remote.Host.Close()
+	remote.setProtocolHandlers(remote.limitHandshakes(remote.onHandshake))
+
+	local := &peerManager{log: logger, mutex: &sync.Mutex{}}
+	local.Host = newTestHost(t)
+	defer local.Host.Close()
+	local.Peerstore().AddAddrs(remote.ID(), remote.Addrs(), pstore.TempAddrTTL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	s, err := local.newP2PStream(ctx, remote.ID())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Close()
+
+	// the peer sending nothing holds the slot only until the time limit of status exchange
+	assert.True(t, waitUntil(func() bool { return len(remote.handshakeSlots) == 1 }, time.Second))
+	assert.True(t, waitUntil(func() bool { return len(remote.handshakeSlots) == 0 }, time.Second))
+}
+
+func TestPeerManager_checkChainID(t *testing.T) {
+	pm := &peerManager{chainID: "aergo.io/testnet"}
+	assert.Nil(t, pm.checkChainID(&types.Status{ChainID: "aergo.io/testnet"}))
+	assert.NotNil(t, pm.checkChainID(&types.Status{ChainID: "aergo.io/mainnet"}))
+	// the peer which doesn't tell its chain is refused too
+	assert.NotNil(t, pm.checkChainID(&types.Status{}))
+}
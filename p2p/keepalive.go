@@ -0,0 +1,85 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	ss "github.com/libp2p/go-conn-security"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+// keepAliveTransport wraps a security transport to enable TCP keep-alive on connections before securing them.
+// The os breaks the connection to a crashed host by keep-alive probes, so that the peer is removed instead of
+// being kept as a live peer over a half-open connection.
+type keepAliveTransport struct {
+	ss.Transport
+	period time.Duration
+	// enabled is the number of connections on which keep-alive is enabled. It must be accessed atomically.
+	enabled int32
+}
+
+var _ ss.Transport = (*keepAliveTransport)(nil)
+
+func (t *keepAliveTransport) SecureInbound(ctx context.Context, insecure net.Conn) (ss.Conn, error) {
+	t.setKeepAlive(insecure)
+	return t.Transport.SecureInbound(ctx, insecure)
+}
+
+func (t *keepAliveTransport) SecureOutbound(ctx context.Context, insecure net.Conn, p peer.ID) (ss.Conn, error) {
+	t.setKeepAlive(insecure)
+	return t.Transport.SecureOutbound(ctx, insecure, p)
+}
+
+func (t *keepAliveTransport) setKeepAlive(conn net.Conn) {
+	if setKeepAlive(conn, t.period) {
+		atomic.AddInt32(&t.enabled, 1)
+	}
+}
+
+// maxConnWrappers is the maximum depth of wrappers around a TCP connection, which are unwrapped to find it.
+const maxConnWrappers = 4
+
+// tcpConn returns the TCP connection wrapped in conn, or nil if conn is not a TCP connection, such as a relayed
+// one. The connections given to security transports are wrapped with multiaddrs by go-multiaddr-net, which embeds
+// the TCP connection as net.Conn, so the keep-alive methods of it are not promoted to the wrapper.
+func tcpConn(conn net.Conn) *net.TCPConn {
+	for i := 0; i <= maxConnWrappers && conn != nil; i++ {
+		if tc, ok := conn.(*net.TCPConn); ok {
+			return tc
+		}
+		v := reflect.ValueOf(conn)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		embedded := v.FieldByName("Conn")
+		if !embedded.IsValid() || !embedded.CanInterface() {
+			return nil
+		}
+		conn, _ = embedded.Interface().(net.Conn)
+	}
+	return nil
+}
+
+// setKeepAlive enables TCP keep-alive on conn with period. It returns false if conn is not a TCP connection,
+// such as a relayed one, which is left as it is.
+func setKeepAlive(conn net.Conn, period time.Duration) bool {
+	tc := tcpConn(conn)
+	if tc == nil {
+		return false
+	}
+	if err := tc.SetKeepAlive(true); err != nil {
+		return false
+	}
+	return tc.SetKeepAlivePeriod(period) == nil
+}
@@ -0,0 +1,90 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	secio "github.com/libp2p/go-libp2p-secio"
+	mnet "github.com/multiformats/go-multiaddr-net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetKeepAlive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := listener.Accept()
+		accepted <- conn
+	}()
+	dialed, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dialed.Close()
+	inbound := <-accepted
+	defer inbound.Close()
+
+	assert.True(t, setKeepAlive(dialed, time.Minute))
+	assert.True(t, setKeepAlive(inbound, time.Minute))
+
+	// the connection given to security transports is wrapped with multiaddrs
+	wrapped, err := mnet.WrapNetConn(dialed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, setKeepAlive(wrapped, time.Minute))
+
+	// not a tcp connection
+	piped, other := net.Pipe()
+	defer piped.Close()
+	defer other.Close()
+	assert.False(t, setKeepAlive(piped, time.Minute))
+}
+
+func TestKeepAliveTransport(t *testing.T) {
+	newTransport := func() (*keepAliveTransport, crypto.PrivKey) {
+		privKey, _, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secioTransport, err := secio.New(privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &keepAliveTransport{Transport: secioTransport, period: time.Minute}, privKey
+	}
+	dialerTransport, dialerKey := newTransport()
+	dialer := newTestHost(t, libp2p.Identity(dialerKey), libp2p.Security(secio.ID, dialerTransport))
+	defer dialer.Close()
+	listenerTransport, listenerKey := newTransport()
+	listener := newTestHost(t, libp2p.Identity(listenerKey), libp2p.Security(secio.ID, listenerTransport))
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err := dialer.Connect(ctx, pstore.PeerInfo{ID: listener.ID(), Addrs: listener.Addrs()})
+	if !assert.Nil(t, err) {
+		return
+	}
+	// keep-alive is enabled on the tcp connections of both sides
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dialerTransport.enabled))
+	for i := 0; i < 100 && atomic.LoadInt32(&listenerTransport.enabled) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&listenerTransport.enabled))
+}
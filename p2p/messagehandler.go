@@ -1,7 +1,12 @@
 package p2p
 
 import (
+	"errors"
+	"sync/atomic"
+	"time"
+
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
 )
 
@@ -15,6 +20,63 @@ type BaseMsgHandler struct {
 	pm    PeerManager
 	peer  *RemotePeer
 	actor ActorService
+	// callTimeout is the time limit of the calls to other services while a message is handled.
+	// defaultHandlerCallTimeout is used if it is not positive.
+	callTimeout time.Duration
 
 	logger *log.Logger
 }
+
+// defaultHandlerCallTimeout is the default time limit of the calls to other services while a message is handled,
+// so that a slow service doesn't block the read loop of the peer for long. It also limits the calls made to send
+// a message or to handshake.
+const defaultHandlerCallTimeout = time.Second * 2
+
+// errCallTimeout is the error of a call to other service, which is not finished by the deadline of handling message.
+var errCallTimeout = errors.New("timeout of call to service while handling message")
+
+// callDeadline returns the deadline of the calls to other services, for the message being handled from now.
+func (bh *BaseMsgHandler) callDeadline() time.Time {
+	timeout := bh.callTimeout
+	if timeout <= 0 {
+		timeout = defaultHandlerCallTimeout
+	}
+	return time.Now().Add(timeout)
+}
+
+// callRequest calls actor within the time limit of handling a message.
+func (bh *BaseMsgHandler) callRequest(actor string, msg interface{}) (interface{}, error) {
+	return callRequestUntil(bh.actor, bh.callDeadline(), actor, msg)
+}
+
+// callRequestWithin calls actor within defaultHandlerCallTimeout, for the calls made out of handling a message.
+func callRequestWithin(actorServ ActorService, actor string, msg interface{}) (interface{}, error) {
+	return callRequestUntil(actorServ, time.Now().Add(defaultHandlerCallTimeout), actor, msg)
+}
+
+// callRequestUntil calls actor, but returns errCallTimeout without waiting for the response after deadline. The
+// deadline is passed to the future of the request, so the response is dropped by it if it arrives late.
+func callRequestUntil(actorServ ActorService, deadline time.Time, actor string, msg interface{}) (interface{}, error) {
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return nil, errCallTimeout
+	}
+	rsp, err := actorServ.CallRequestWithTimeout(actor, msg, timeout)
+	if err == component.ErrComponentTimeout {
+		return nil, errCallTimeout
+	}
+	return rsp, err
+}
+
+// authenticate verifies the signature of msg, which is signed with its header as a whole by sender.
+// Message failed to be authenticated must be dropped, and it is counted as a failure of the peer.
+func (bh *BaseMsgHandler) authenticate(msg *types.P2PMessage) bool {
+	if bh.pm.AuthenticateMessage(msg, msg.Header) {
+		return true
+	}
+	bh.logger.Info().Str(LogPeerID, bh.peer.ID().Pretty()).Str(LogProtoID, SubProtocol(msg.Header.Subprotocol).String()).
+		Str(LogMsgID, msg.Header.Id).Msg("Failed to authenticate message")
+	atomic.AddUint32(&bh.peer.failCounter, 1)
+	bh.peer.adjustScore(authFailurePenalty, ProtocolViolation)
+	return false
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"context"
 
+	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
 	"github.com/golang/protobuf/proto"
@@ -102,11 +103,26 @@ func (_m *MockP2PService) RemovePeer(_a0 peer.ID) {
 	_m.Called(_a0)
 }
 
+// DisconnectPeer provides a mock function with given fields: peerID, reason
+func (_m *MockP2PService) DisconnectPeer(peerID peer.ID, reason DisconnectReason) {
+	_m.Called(peerID, reason)
+}
+
+// RemovePeerFromPool provides a mock function with given fields: peerID
+func (_m *MockP2PService) RemovePeerFromPool(peerID peer.ID) {
+	_m.Called(peerID)
+}
+
 // NotifyPeerHandshake provides a mock function with given fields: _a0
 func (_m *MockP2PService) NotifyPeerHandshake(_a0 peer.ID) {
 	_m.Called(_a0)
 }
 
+// ReportHandshakeFailure provides a mock function with given fields: meta
+func (_m *MockP2PService) ReportHandshakeFailure(meta PeerMeta) {
+	_m.Called(meta)
+}
+
 // NotifyPeerAddressReceived provides a mock function with given fields: _a0
 func (_m *MockP2PService) NotifyPeerAddressReceived(_a0 []PeerMeta) {
 	_m.Called(_a0)
@@ -237,6 +253,119 @@ func (_m *MockP2PService) GetPeer(ID peer.ID) (*RemotePeer, bool) {
 	return r0, r1
 }
 
+// DumpState provides a mock function with given fields:
+func (_m *MockP2PService) DumpState() *message.DumpP2PStateRsp {
+	ret := _m.Called()
+
+	var r0 *message.DumpP2PStateRsp
+	if rf, ok := ret.Get(0).(func() *message.DumpP2PStateRsp); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*message.DumpP2PStateRsp)
+		}
+	}
+
+	return r0
+}
+
+// GetPeerScores provides a mock function with given fields:
+func (_m *MockP2PService) GetPeerScores() map[peer.ID]int32 {
+	ret := _m.Called()
+
+	var r0 map[peer.ID]int32
+	if rf, ok := ret.Get(0).(func() map[peer.ID]int32); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[peer.ID]int32)
+		}
+	}
+
+	return r0
+}
+
+// GetPeerHeights provides a mock function with given fields:
+func (_m *MockP2PService) GetPeerHeights() map[peer.ID]types.BlockNo {
+	ret := _m.Called()
+
+	var r0 map[peer.ID]types.BlockNo
+	if rf, ok := ret.Get(0).(func() map[peer.ID]types.BlockNo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[peer.ID]types.BlockNo)
+		}
+	}
+
+	return r0
+}
+
+// PeerCounts provides a mock function with given fields:
+func (_m *MockP2PService) PeerCounts() (int, int, int) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func() int); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 int
+	if rf, ok := ret.Get(2).(func() int); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Get(2).(int)
+	}
+
+	return r0, r1, r2
+}
+
+// IsFull provides a mock function with given fields:
+func (_m *MockP2PService) IsFull() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// SelectPeerForRequest provides a mock function with given fields:
+func (_m *MockP2PService) SelectPeerForRequest() (*RemotePeer, bool) {
+	ret := _m.Called()
+
+	var r0 *RemotePeer
+	if rf, ok := ret.Get(0).(func() *RemotePeer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RemotePeer)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // GetPeers provides a mock function with given fields:
 func (_m *MockP2PService) GetPeers() []*RemotePeer {
 	ret := _m.Called()
@@ -254,7 +383,7 @@ func (_m *MockP2PService) GetPeers() []*RemotePeer {
 }
 
 // GetPeerAddresses provides a mock function with given fields:
-func (_m *MockP2PService) GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState) {
+func (_m *MockP2PService) GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState, []DisconnectReason) {
 	ret := _m.Called()
 
 	var r0 []*types.PeerAddress
@@ -273,7 +402,14 @@ func (_m *MockP2PService) GetPeerAddresses() ([]*types.PeerAddress, []types.Peer
 		r1 = ret.Get(1).([]types.PeerState)
 	}
 
-	return r0, r1
+	var r2 []DisconnectReason
+	if rf, ok := ret.Get(2).(func() []DisconnectReason); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Get(2).([]DisconnectReason)
+	}
+
+	return r0, r1, r2
 }
 
 // GetStatus provides a mock function with given fields:
@@ -542,3 +678,22 @@ func (_m *MockP2PService) Stop() error {
 func (_m *MockP2PService) HandleNewBlockNotice(peerID peer.ID, b64hash string, data *types.NewBlockNotice) {
 	_m.Called(peerID, b64hash, data)
 }
+
+// UpdateConfirmedTxs provides a mock function with given fields: confirmed, reverted
+func (_m *MockP2PService) UpdateConfirmedTxs(confirmed [][]byte, reverted [][]byte) {
+	_m.Called(confirmed, reverted)
+}
+
+// IsConfirmedTx provides a mock function with given fields: hash
+func (_m *MockP2PService) IsConfirmedTx(hash []byte) bool {
+	ret := _m.Called(hash)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func([]byte) bool); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
@@ -24,6 +24,22 @@ func (_m *MockReconnectManager) CancelJob(pid peer.ID) {
 	_m.Called(pid)
 }
 
+// Jobs provides a mock function with given fields:
+func (_m *MockReconnectManager) Jobs() []PeerMeta {
+	ret := _m.Called()
+
+	var r0 []PeerMeta
+	if rf, ok := ret.Get(0).(func() []PeerMeta); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]PeerMeta)
+		}
+	}
+
+	return r0
+}
+
 // Stop provides a mock function with given fields:
 func (_m *MockReconnectManager) Stop() {
 	_m.Called()
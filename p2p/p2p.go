@@ -29,6 +29,9 @@ type P2P struct {
 	addrs *AddressesProtocol
 	blk   *BlockProtocol
 	txs   *TxProtocol
+
+	// txNoticeBatch is the max number of tx hashes in a tx notice
+	txNoticeBatch int
 }
 
 //var _ component.IComponent = (*P2PComponent)(nil)
@@ -58,11 +61,16 @@ func (ns *P2P) BeforeStop() {
 }
 
 func (ns *P2P) Statics() *map[string]interface{} {
-	return nil
+	return &map[string]interface{}{
+		"handlers":         handlerStats.stats(),
+		"blockPropagation": blockPropagation.stats(),
+	}
 }
 
 func (ns *P2P) init(cfg *config.Config, chainsvc *blockchain.ChainService) {
 	reconMan := NewReconnectManager(ns.Logger)
+	reconMan.setPolicies(cfg.P2P.DesignatedRetry, cfg.P2P.DiscoveredRetry)
+	reconMan.setBackoff(cfg.P2P.NPReconnectInitialSec, cfg.P2P.NPReconnectIncrease, cfg.P2P.NPReconnectMaxTrial)
 	peerMan := NewPeerManager(ns, cfg, reconMan, ns.Logger)
 
 	// connect managers each other
@@ -70,6 +78,12 @@ func (ns *P2P) init(cfg *config.Config, chainsvc *blockchain.ChainService) {
 
 	ns.pm = peerMan
 	ns.rm = reconMan
+
+	ns.txNoticeBatch = cfg.P2P.NPMaxTxNoticeBatch
+	if ns.txNoticeBatch <= 0 {
+		ns.Warn().Int("npmaxtxnoticebatch", ns.txNoticeBatch).Int("default", DefaultMaxTxNoticeBatch).Msg("Invalid tx notice batch size, using default")
+		ns.txNoticeBatch = DefaultMaxTxNoticeBatch
+	}
 }
 
 const success bool = true
@@ -89,9 +103,14 @@ func (ns *P2P) Receive(context actor.Context) {
 		ns.GetBlockHeaders(msg)
 	case *message.GetBlockInfos:
 		ns.GetBlocks(msg.ToWhom, msg.Hashes)
+	case *message.GetBlocksByRange:
+		ns.GetBlocksByRange(msg.ToWhom, msg.StartNo, msg.Count)
 	case *message.NotifyNewBlock:
+		ns.pm.UpdateConfirmedTxs(txHashes(msg.Block), nil)
 		// TODO remove conversion
 		ns.NotifyNewBlock(*msg)
+	case *message.NotifyTxsReorged:
+		ns.pm.UpdateConfirmedTxs(msg.Confirmed, msg.Reverted)
 	case *message.GetMissingBlocks:
 		ns.GetMissingBlocks(msg.ToWhom, msg.Hashes)
 	case *message.GetTransactions:
@@ -99,8 +118,22 @@ func (ns *P2P) Receive(context actor.Context) {
 	case *message.NotifyNewTransactions:
 		ns.NotifyNewTX(*msg)
 	case *message.GetPeers:
-		peers, states := ns.pm.GetPeerAddresses()
-		context.Respond(&message.GetPeersRsp{Peers: peers, States: states})
+		peers, states, reasons := ns.pm.GetPeerAddresses()
+		disconnecting := make([]string, len(reasons))
+		for i, reason := range reasons {
+			if reason != 0 {
+				disconnecting[i] = reason.String()
+			}
+		}
+		context.Respond(&message.GetPeersRsp{Peers: peers, States: states, DisconnectReasons: disconnecting})
+	case *message.GetPeerHeights:
+		context.Respond(&message.GetPeerHeightsRsp{Heights: ns.pm.GetPeerHeights()})
+	case *message.DumpP2PState:
+		// the snapshot is taken by the peer manager goroutine, which the actor must not wait for
+		sender := context.Sender()
+		go func() {
+			sender.Tell(ns.pm.DumpState())
+		}()
 	}
 }
 
@@ -116,7 +149,10 @@ func (ns *P2P) FutureRequest(actor string, msg interface{}) *actor.Future {
 
 // CallRequest implement interface method of ActorService
 func (ns *P2P) CallRequest(actor string, msg interface{}) (interface{}, error) {
-	future := ns.RequestToFuture(actor, msg, defaultTTL)
+	return ns.RequestToFutureResult(actor, msg, defaultTTL)
+}
 
-	return future.Result()
+// CallRequestWithTimeout implement interface method of ActorService
+func (ns *P2P) CallRequestWithTimeout(actor string, msg interface{}, timeout time.Duration) (interface{}, error) {
+	return ns.RequestToFutureResult(actor, msg, timeout)
 }
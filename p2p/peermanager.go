@@ -8,13 +8,16 @@ package p2p
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -22,6 +25,7 @@ import (
 	"github.com/libp2p/go-libp2p-host"
 
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/account"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
 
@@ -31,6 +35,7 @@ import (
 	crypto "github.com/libp2p/go-libp2p-crypto"
 	peer "github.com/libp2p/go-libp2p-peer"
 	pstore "github.com/libp2p/go-libp2p-peerstore"
+	protocol "github.com/libp2p/go-libp2p-protocol"
 	ma "github.com/multiformats/go-multiaddr"
 )
 
@@ -42,12 +47,21 @@ type peerInfo struct {
 	privKey *crypto.PrivKey
 }
 
+// ErrPeerManagerStopped is returned when stopped peerManager is started again.
+var ErrPeerManagerStopped = errors.New("peer manager is already stopped")
+
 // TODO this value better related to max peer and block produce interval, not constant
 const (
 	DefaultGlobalInvCacheSize = 100
 	DefaultPeerInvCacheSize   = 30
 )
 
+// default interval and size of collecting peer addresses from connected peers
+const (
+	DefaultAddrRefreshInterval = time.Minute * 3
+	DefaultAddrRequestSize     = 20
+)
+
 // PeerManager is internal service that provide peer management
 type PeerManager interface {
 	host.Host
@@ -61,16 +75,42 @@ type PeerManager interface {
 	SelfNodeID() peer.ID
 
 	AddNewPeer(peer PeerMeta)
+	// RemovePeer disconnects the peer with Manual reason.
 	RemovePeer(peerID peer.ID)
+	// DisconnectPeer sends GoAway with the reason to the peer and then disconnects it.
+	DisconnectPeer(peerID peer.ID, reason DisconnectReason)
+	// RemovePeerFromPool forgets the address of peer, so that it is not connected until it is discovered again.
+	RemovePeerFromPool(peerID peer.ID)
 	NotifyPeerHandshake(peerID peer.ID)
 	NotifyPeerAddressReceived([]PeerMeta)
+	// ReportHandshakeFailure counts the handshake failure against the address of peer, which is banned for a while
+	// by repeated failures.
+	ReportHandshakeFailure(meta PeerMeta)
 
 	HandleNewBlockNotice(peerID peer.ID, b64hash string, data *types.NewBlockNotice)
+	// UpdateConfirmedTxs marks txs confirmed in main chain, and unmarks txs reverted by reorg.
+	UpdateConfirmedTxs(confirmed, reverted [][]byte)
+	// IsConfirmedTx returns true if tx was confirmed in main chain recently.
+	IsConfirmedTx(hash []byte) bool
 
 	// GetPeer return registered(handshaked) remote peer object
 	GetPeer(ID peer.ID) (*RemotePeer, bool)
 	GetPeers() []*RemotePeer
-	GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState)
+	// GetPeerAddresses returns addresses, states and the reasons of disconnection of peers. The reason is zero
+	// unless the peer is being disconnected.
+	GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState, []DisconnectReason)
+	// GetPeerHeights returns the best block numbers reported by running peers.
+	GetPeerHeights() map[peer.ID]types.BlockNo
+	// GetPeerScores returns the scores of connected peers.
+	GetPeerScores() map[peer.ID]int32
+	// PeerCounts returns the number of connected peers, addresses in peer pool and designated peers.
+	PeerCounts() (connected, pool, designated int)
+	// IsFull returns true if connected peers reach NPMaxPeers.
+	IsFull() bool
+	// DumpState returns the snapshot of peers, addresses, reconnect jobs and banned addresses.
+	DumpState() *message.DumpP2PStateRsp
+	// SelectPeerForRequest return a running peer which is expected to respond fastest.
+	SelectPeerForRequest() (*RemotePeer, bool)
 
 	// deprecated methods... use sendmessage helper functions instead
 	SignProtoMessage(message proto.Message) ([]byte, error)
@@ -90,6 +130,8 @@ type peerManager struct {
 	rm         ReconnectManager
 
 	designatedPeers map[peer.ID]PeerMeta
+	relayPeers      []pstore.PeerInfo
+	tlsTransport    *tlsTransport
 
 	subProtocols []subProtocol
 	remotePeers  map[peer.ID]*RemotePeer
@@ -98,17 +140,59 @@ type peerManager struct {
 	log          *log.Logger
 	mutex        *sync.Mutex
 	peerCache    []*RemotePeer
+	addrTTL      time.Duration
+	// minPeerScore is the score under which peer is disconnected
+	minPeerScore int32
+	// maxAddrsPerResponse is the maximum number of addresses in a response to addresses request
+	maxAddrsPerResponse int
+	// maxHeadersPerResponse is the maximum number of headers in a response to block headers request
+	maxHeadersPerResponse int
+	// addresses are requested to connected peers periodically by addrRefreshInterval
+	addrRefreshInterval time.Duration
+	addrRequestSize     uint32
+	// keepAlive is the interval of TCP keep-alive probes. TCP keep-alive is disabled if zero.
+	keepAlive time.Duration
+	// pingInterval is the interval of pings to peers
+	pingInterval time.Duration
+	// chainID is the id of the chain which this node joins. Peers of another chain are refused on handshake.
+	chainID string
 
 	status component.Status
 
 	addPeerChannel    chan PeerMeta
 	removePeerChannel chan peer.ID
+	dropPoolChannel   chan peer.ID
 	hsPeerChannel     chan peer.ID
 	fillPoolChannel   chan []PeerMeta
-	finishChannel     chan struct{}
-	eventListeners    []PeerEventListener
+	dumpChannel       chan chan *message.DumpP2PStateRsp
+	// finishChannel is closed to signal shutdown, and manageDone is closed when runManagePeers finished.
+	finishChannel  chan struct{}
+	manageDone     chan struct{}
+	stopOnce       sync.Once
+	eventListeners []PeerEventListener
 
 	invCache *lru.Cache
+
+	selectCounter uint32
+	// poolSize is the size of peerPool, which can be read without lock
+	poolSize int32
+
+	streamLimiter *streamLimiter
+	// handshakeSlots is a semaphore of inbound handshakes in progress. It is nil if they are unlimited.
+	handshakeSlots chan struct{}
+	// handshakeTimeout is the time limit of the status exchange of inbound handshake. defaultHandshakeTimeout is
+	// used if it is not positive.
+	handshakeTimeout time.Duration
+	confirmedTxs     *confirmedTxSet
+	addrBlacklist    *addrBlacklist
+	// reputations keeps the scores of disconnected peers. It is saved only if there is data directory.
+	reputations *peerReputations
+	dnsCache    *dnsAddrCache
+	// protocolIDs are versions of p2p protocol in order of preference. p2pProtocolIDs is used if empty.
+	protocolIDs []protocol.ID
+	// defaultKeyFile keeps generated private key if neither npkey nor npkeystore is set. Temporary key is
+	// used if it is empty.
+	defaultKeyFile string
 }
 
 var _ PeerManager = (*peerManager)(nil)
@@ -118,8 +202,8 @@ type PeerEventListener interface {
 	// OnAddPeer is called just after the peer is added.
 	OnAddPeer(peerID peer.ID)
 
-	// OnRemovePeer is called just before the peer is removed
-	OnRemovePeer(peerID peer.ID)
+	// OnRemovePeer is called just before the peer is removed, with the reason of disconnection.
+	OnRemovePeer(peerID peer.ID, reason DisconnectReason)
 }
 
 // subProtocol is sub protocol of p2p protocol
@@ -137,26 +221,73 @@ func NewPeerManager(iServ ActorService, cfg *cfg.Config, rm ReconnectManager, lo
 	p2pConf := cfg.P2P
 	//logger.SetLevel("debug")
 	hl := &peerManager{
-		iServ: iServ,
-		conf:  p2pConf,
-		rm:    rm,
-		log:   logger,
-		mutex: &sync.Mutex{},
+		iServ:   iServ,
+		conf:    p2pConf,
+		rm:      rm,
+		log:     logger,
+		mutex:   &sync.Mutex{},
+		chainID: cfg.ChainID(),
 
 		designatedPeers: make(map[peer.ID]PeerMeta, len(cfg.P2P.NPAddPeers)),
 
-		remotePeers: make(map[peer.ID]*RemotePeer, p2pConf.NPMaxPeers),
-		peerPool:    make(map[peer.ID]PeerMeta, p2pConf.NPPeerPool),
-		peerCache:   make([]*RemotePeer, 0, p2pConf.NPMaxPeers),
+		remotePeers:           make(map[peer.ID]*RemotePeer, p2pConf.NPMaxPeers),
+		peerPool:              make(map[peer.ID]PeerMeta, p2pConf.NPPeerPool),
+		peerCache:             make([]*RemotePeer, 0, p2pConf.NPMaxPeers),
+		addrTTL:               DefaultNodeTTL,
+		minPeerScore:          int32(p2pConf.NPMinPeerScore),
+		maxAddrsPerResponse:   p2pConf.MaxAddressesPerResponse,
+		maxHeadersPerResponse: p2pConf.MaxHeadersPerResponse,
+
+		streamLimiter:  newStreamLimiter(p2pConf.MaxStreamsPerPeer),
+		handshakeSlots: newHandshakeSlots(p2pConf.MaxConcurrentHandshakes),
+		confirmedTxs:   newConfirmedTxSet(p2pConf.ConfirmedTxCacheSize),
+		addrBlacklist:  newAddrBlacklist(handshakeFailThreshold),
+		dnsCache:       newDNSAddrCache(DefaultDNSCacheTTL, DefaultDNSCacheSize),
 
 		subProtocols:      make([]subProtocol, 0, 4),
 		status:            component.StoppedStatus,
 		addPeerChannel:    make(chan PeerMeta, 2),
 		removePeerChannel: make(chan peer.ID),
+		dropPoolChannel:   make(chan peer.ID),
 		hsPeerChannel:     make(chan peer.ID),
 		fillPoolChannel:   make(chan []PeerMeta),
+		dumpChannel:       make(chan chan *message.DumpP2PStateRsp),
 		eventListeners:    make([]PeerEventListener, 0, 4),
 		finishChannel:     make(chan struct{}),
+		manageDone:        make(chan struct{}),
+	}
+
+	if p2pConf.PeerAddrTTL > 0 {
+		hl.addrTTL = time.Duration(p2pConf.PeerAddrTTL) * time.Second
+	}
+	hl.addrRefreshInterval = DefaultAddrRefreshInterval
+	if p2pConf.NPAddrRefreshInterval > 0 {
+		hl.addrRefreshInterval = time.Duration(p2pConf.NPAddrRefreshInterval) * time.Second
+	} else {
+		logger.Warn().Int("interval", p2pConf.NPAddrRefreshInterval).Msg("NPAddrRefreshInterval must be positive, default value is used")
+	}
+	hl.addrRequestSize = DefaultAddrRequestSize
+	if p2pConf.NPAddrRequestSize > 0 {
+		hl.addrRequestSize = uint32(p2pConf.NPAddrRequestSize)
+	} else {
+		logger.Warn().Int("size", p2pConf.NPAddrRequestSize).Msg("NPAddrRequestSize must be positive, default value is used")
+	}
+	if p2pConf.NPKeepAliveInterval > 0 {
+		hl.keepAlive = time.Duration(p2pConf.NPKeepAliveInterval) * time.Second
+	}
+	hl.pingInterval = defaultPingInterval
+	if p2pConf.NPPingInterval > 0 {
+		hl.pingInterval = time.Duration(p2pConf.NPPingInterval) * time.Second
+	}
+
+	// scores are kept by peer id even without data directory, so that reconnecting does not reset the score.
+	hl.reputations = newPeerReputations("", maxStoredReputations)
+	if cfg.DataDir != "" {
+		hl.defaultKeyFile = filepath.Join(cfg.DataDir, DefaultPeerKeyFile)
+		hl.reputations.path = filepath.Join(cfg.DataDir, DefaultPeerReputationFile)
+		if err := hl.reputations.load(hl.addrBlacklist); err != nil {
+			logger.Warn().Err(err).Msg("Failed to load peer reputations, starting without them")
+		}
 	}
 
 	var err error
@@ -193,11 +324,67 @@ func (ps *peerManager) RegisterEventListener(listener PeerEventListener) {
 	ps.eventListeners = append(ps.eventListeners, listener)
 }
 
+// loadKeystore reads the private key from encrypted keystore. It is used both as identity of
+// this node and as signing key of block producer.
+func loadKeystore(path string) (crypto.PrivKey, error) {
+	passphrase, err := account.ReadPassphrase("Passphrase of " + path + ": ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passphrase of keystore: %v", err)
+	}
+	raw, err := account.LoadKeystore(path, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keystore: %v", err)
+	}
+	priv, err := crypto.UnmarshalSecp256k1PrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in keystore: %v", err)
+	}
+	return priv, nil
+}
+
+// DefaultPeerKeyFile is the name of file in data directory, which keeps the private key of this node if neither
+// npkey nor npkeystore is set, so that the peer id is not changed by restart.
+const DefaultPeerKeyFile = "peer.key"
+
+// loadOrCreateKeyFile reads the private key from the file, which is in same format as npkey. A new key is
+// generated and saved to the file if it does not exist yet.
+func loadOrCreateKeyFile(path string) (crypto.PrivKey, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err == nil {
+		return crypto.UnmarshalPrivateKey(dat)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	priv, _, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+	if err != nil {
+		return nil, err
+	}
+	dat, err = crypto.MarshalPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(path, dat, 0600); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
 func (ps *peerManager) init() {
 	// check Key and address
 	var priv crypto.PrivKey
 	var pub crypto.PubKey
-	if ps.conf.NPKey != "" {
+	if ps.conf.NPKeystore != "" {
+		// the node must not run under another identity than the block producer's one
+		var err error
+		priv, err = loadKeystore(ps.conf.NPKeystore)
+		if err != nil {
+			panic("Couldn't load npkeystore " + ps.conf.NPKeystore + ": " + err.Error())
+		}
+		pub = priv.GetPublic()
+	} else if ps.conf.NPKey != "" {
 		dat, err := ioutil.ReadFile(ps.conf.NPKey)
 		if err == nil {
 			priv, err = crypto.UnmarshalPrivateKey(dat)
@@ -208,6 +395,14 @@ func (ps *peerManager) init() {
 		} else {
 			ps.log.Warn().Str("npkey", ps.conf.NPKey).Msg("invalid keyfile path")
 		}
+	} else if ps.defaultKeyFile != "" {
+		// a temporary key would change the peer id, which the key file is kept for
+		var err error
+		priv, err = loadOrCreateKeyFile(ps.defaultKeyFile)
+		if err != nil {
+			panic("Couldn't load or create private key file " + ps.defaultKeyFile + ": " + err.Error())
+		}
+		pub = priv.GetPublic()
 	}
 	if nil == priv {
 		ps.log.Info().Msg("No valid private key file is found. use temporary pk instead")
@@ -215,6 +410,7 @@ func (ps *peerManager) init() {
 	}
 	pid, _ := peer.IDFromPublicKey(pub)
 	myPeerInfo.set(&pid, &priv)
+	ps.log.Info().Str(LogPeerID, pid.Pretty()).Msg("Peer id of this node")
 
 	listenAddr := net.ParseIP(ps.conf.NetProtocolAddr)
 	listenPort := ps.conf.NetProtocolPort
@@ -225,7 +421,7 @@ func (ps *peerManager) init() {
 		ps.log.Info().Str("ps.conf.NetProtocolAddr", ps.conf.NetProtocolAddr).Int("listenPort", listenPort).Msg("Using NetProtocolAddr in configfile")
 	} else {
 		listenAddr, err = externalIP()
-		ps.log.Info().Str("addr", listenAddr.To4().String()).Int("port", listenPort).Msg("No NetProtocolAddr is specified")
+		ps.log.Info().Str("addr", listenAddr.String()).Int("port", listenPort).Msg("No NetProtocolAddr is specified")
 		if err != nil {
 			panic("Couldn't find listening ip address: " + err.Error())
 		}
@@ -236,8 +432,18 @@ func (ps *peerManager) init() {
 	ps.selfMeta.Port = uint32(listenPort)
 	ps.selfMeta.ID = pid
 
+	if ps.conf.NPEnableTLS {
+		ps.tlsTransport, err = newTLSTransport(ps.conf.NPCert, ps.conf.NPCertKey, priv)
+		if err != nil {
+			panic("Couldn't enable TLS on N2N network: " + err.Error())
+		}
+		ps.log.Info().Str("npcert", ps.conf.NPCert).Msg("TLS is enabled on N2N network")
+	}
+
 	// set designated peers
 	ps.addDesignatedPeers()
+	// set relay peers
+	ps.addRelayPeers()
 }
 
 func (ps *peerManager) run() {
@@ -246,14 +452,18 @@ func (ps *peerManager) run() {
 	// need to start listen after chainservice is read to init
 	// FIXME: adhoc code
 	go func() {
-		time.Sleep(time.Second * 3)
+		select {
+		case <-time.After(time.Second * 3):
+		case <-ps.finishChannel:
+			return
+		}
 		ps.startListener()
 
 		// addition should start after all modules are started
 		go func() {
 			time.Sleep(time.Second * 2)
 			for _, meta := range ps.designatedPeers {
-				ps.addPeerChannel <- meta
+				ps.AddNewPeer(meta)
 			}
 		}()
 	}()
@@ -261,89 +471,99 @@ func (ps *peerManager) run() {
 
 func (ps *peerManager) addDesignatedPeers() {
 	// add remote node from config
+	failed := make([]string, 0)
 	for _, target := range ps.conf.NPAddPeers {
-		// go-multiaddr implementation does not support recent p2p protocol yet, but deprecated name ipfs.
-		// This adhoc will be removed when go-multiaddr is patched.
-		target = strings.Replace(target, "/p2p/", "/ipfs/", 1)
-		targetAddr, err := ma.NewMultiaddr(target)
+		peerMeta, err := parsePeerMeta(target)
 		if err != nil {
 			ps.log.Warn().Err(err).Str("target", target).Msg("invalid NPAddPeer address")
+			failed = append(failed, target)
 			continue
 		}
-		splitted := strings.Split(targetAddr.String(), "/")
-		if len(splitted) != 7 {
-			ps.log.Warn().Str("target", target).Msg("invalid NPAddPeer address")
-			continue
-		}
-		peerAddrString := splitted[2]
-		peerPortString := splitted[4]
-		peerPort, err := strconv.Atoi(peerPortString)
-		if err != nil {
-			ps.log.Warn().Str("port", peerPortString).Msg("invalid Peer port")
-			continue
-		}
-		peerIDString := splitted[6]
-		peerID, err := peer.IDB58Decode(peerIDString)
-		if err != nil {
-			ps.log.Warn().Str(LogPeerID, peerIDString).Msg("invalid PeerID")
-			continue
-		}
-		peerMeta := PeerMeta{
-			ID:         peerID,
-			Port:       uint32(peerPort),
-			IPAddress:  peerAddrString,
-			Designated: true,
-			Outbound:   true,
-		}
-		ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("addr", peerAddrString).Int("port", peerPort).Msg("Adding Designated peer")
-		ps.designatedPeers[peerID] = peerMeta
+		peerMeta.Designated = true
+		peerMeta.Outbound = true
+		ps.log.Info().Str(LogPeerID, peerMeta.ID.Pretty()).Str("addr", peerMeta.IPAddress).Uint32("port", peerMeta.Port).Msg("Adding Designated peer")
+		ps.designatedPeers[peerMeta.ID] = peerMeta
+	}
+	if len(failed) > 0 {
+		ps.log.Error().Int("count", len(failed)).Str("targets", strings.Join(failed, ", ")).Str("forms", peerAddrForms).
+			Msg("Some of NPAddPeers are ignored since they can not be parsed")
 	}
 }
 
 func (ps *peerManager) runManagePeers() {
-	addrDuration := time.Minute * 3
-	addrTicker := time.NewTicker(addrDuration)
+	addrTicker := time.NewTicker(ps.addrRefreshInterval)
+	pruneTicker := time.NewTicker(ps.addrTTL)
+	reputationTicker := time.NewTicker(reputationSaveInterval)
 	// reconnectRunners := make(map[peer.ID]*reconnectRunner)
 MANLOOP:
 	for {
 		select {
 		case meta := <-ps.addPeerChannel:
 			if ps.addOutboundPeer(meta) {
-				if _, found := ps.designatedPeers[meta.ID]; found {
-					ps.rm.CancelJob(meta.ID)
-				}
+				ps.rm.CancelJob(meta.ID)
 			}
 		case id := <-ps.removePeerChannel:
-			if ps.removePeer(id) {
-				if meta, found := ps.designatedPeers[id]; found {
-					ps.rm.AddJob(meta)
-				}
+			if target, removed := ps.removePeer(id); removed {
+				ps.afterPeerRemoved(target)
 			}
+		case id := <-ps.dropPoolChannel:
+			ps.dropPoolPeer(id)
 		case <-addrTicker.C:
 			ps.checkAndCollectPeerListFromAll()
+		case <-pruneTicker.C:
+			ps.pruneExpiredAddrs()
+			ps.addrBlacklist.prune()
+		case <-reputationTicker.C:
+			ps.saveReputations()
 		case peerID := <-ps.hsPeerChannel:
 			ps.checkAndCollectPeerList(peerID)
 		case peerMetas := <-ps.fillPoolChannel:
 			ps.tryFillPool(&peerMetas)
+		case rsp := <-ps.dumpChannel:
+			rsp <- ps.dumpState()
 		case <-ps.finishChannel:
 			break MANLOOP
 		}
 	}
 	addrTicker.Stop()
+	pruneTicker.Stop()
+	reputationTicker.Stop()
 
 	// cleanup peers
 	for peerID := range ps.remotePeers {
 		ps.removePeer(peerID)
 	}
+	close(ps.manageDone)
+}
+
+// saveReputations records the scores of connected peers, and saves all the reputations.
+func (ps *peerManager) saveReputations() {
+	ps.mutex.Lock()
+	for peerID, remotePeer := range ps.remotePeers {
+		ps.reputations.record(peerID, remotePeer.Score())
+	}
+	ps.mutex.Unlock()
+	if err := ps.reputations.save(ps.addrBlacklist); err != nil {
+		ps.log.Warn().Err(err).Msg("Failed to save peer reputations")
+	}
+}
+
+// lowReputation returns true if the score of peer, which is restored from its previous connection, is below the
+// minimum. Such peer is refused, since it would have been evicted.
+func (ps *peerManager) lowReputation(peerID peer.ID) bool {
+	return ps.reputations.score(peerID) < ps.minPeerScore
 }
 
 // addOutboundPeer try to connect and handshake to remote peer. it can be called after peermanager is inited.
 // It return true if peer is added or already exist, or return false if failed to add peer.
 func (ps *peerManager) addOutboundPeer(meta PeerMeta) bool {
-	addrString := fmt.Sprintf("/ip4/%s/tcp/%d", meta.IPAddress, meta.Port)
-	var peerAddr, err = ma.NewMultiaddr(addrString)
+	peerAddr, err := toPeerMultiAddr(meta.IPAddress, meta.Port)
 	if err != nil {
-		ps.log.Warn().Err(err).Str("addr", addrString).Msg("invalid NPAddPeer address")
+		ps.log.Warn().Err(err).Str("addr", meta.IPAddress).Uint32("port", meta.Port).Msg("invalid NPAddPeer address")
+		return false
+	}
+	if ps.lowReputation(meta.ID) {
+		ps.log.Info().Str(LogPeerID, meta.ID.Pretty()).Msg("Skipping peer of low score")
 		return false
 	}
 	var peerID = meta.ID
@@ -361,15 +581,37 @@ func (ps *peerManager) addOutboundPeer(meta PeerMeta) bool {
 	}
 	ps.mutex.Unlock()
 
-	// if peer exists in peerstore already, reuse that peer again.
-	if !ps.checkInPeerstore(peerID) {
-		ps.Peerstore().AddAddr(peerID, peerAddr, meta.TTL())
+	if meta.IsHostname() {
+		// only the hostnames in config are resolved, not to be driven to arbitrary dns lookups by remote peers.
+		if !meta.Designated {
+			ps.log.Debug().Str(LogPeerID, meta.ID.Pretty()).Str("addr", meta.IPAddress).Msg("Skipping hostname of undesignated peer")
+			return false
+		}
+		// resolved addresses are kept only for a while, since ip address of the host can be changed.
+		dialAddrs, found := ps.dnsCache.lookup(peerAddr)
+		if !found {
+			// resolving can take long, so it is done out of runManagePeers, and the peer is added again after it.
+			ps.dnsCache.resolveInBackground(peerAddr, func(err error) {
+				if err != nil {
+					ps.log.Warn().Err(err).Str(LogPeerID, meta.ID.Pretty()).Str("addr", peerAddr.String()).Msg("Failed to resolve peer address")
+					return
+				}
+				ps.AddNewPeer(meta)
+			})
+			return false
+		}
+		ps.Peerstore().AddAddrs(peerID, dialAddrs, ps.dnsCache.addrTTL())
+	} else if meta.Designated || !ps.checkInPeerstore(peerID) {
+		// if peer exists in peerstore already, reuse that peer again.
+		// designated peer is always added to prolong the ttl of address, which was added by discovery.
+		ps.Peerstore().AddAddr(peerID, peerAddr, ps.peerAddrTTL(meta))
 	}
 
-	ctx := context.Background()
-	s, err := ps.NewStream(ctx, meta.ID, aergoP2PSub)
+	s, err := ps.newP2PStream(context.Background(), meta.ID)
 	if err != nil {
-		ps.log.Warn().Err(err).Str(LogPeerID, meta.ID.Pretty()).Str(LogProtoID, string(aergoP2PSub)).Msg("Error while get stream")
+		ps.log.Warn().Err(err).Str(LogPeerID, meta.ID.Pretty()).Msg("Error while get stream")
+		ps.dnsCache.expire(peerAddr)
+		ps.recordHandshakeFailure(meta)
 		return false
 	}
 	rw := &bufio.ReadWriter{Reader: bufio.NewReader(s), Writer: bufio.NewWriter(s)}
@@ -378,8 +620,10 @@ func (ps *peerManager) addOutboundPeer(meta PeerMeta) bool {
 	if !success {
 		ps.sendGoAway(rw, "Failed to handshake")
 		s.Close()
+		ps.recordHandshakeFailure(meta)
 		return false
 	}
+	ps.addrBlacklist.succeed(meta)
 
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
@@ -393,6 +637,12 @@ func (ps *peerManager) addOutboundPeer(meta PeerMeta) bool {
 	}
 
 	newPeer = newRemotePeer(meta, ps, ps.iServ, ps.log)
+	newPeer.protocolID = s.Protocol()
+	newPeer.minScore = ps.minPeerScore
+	if ps.pingInterval > 0 {
+		newPeer.pingDuration = ps.pingInterval
+	}
+	newPeer.score = ps.reputations.score(peerID)
 	newPeer.rw = &bufio.ReadWriter{Reader: bufio.NewReader(s), Writer: bufio.NewWriter(s)}
 	// insert Handlers
 	ps.insertHandlers(newPeer)
@@ -400,13 +650,14 @@ func (ps *peerManager) addOutboundPeer(meta PeerMeta) bool {
 	newPeer.setState(types.RUNNING)
 
 	ps.insertPeer(peerID, newPeer)
-	ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("addr", peerAddr.String()).Msg("Outbound peer is  added to peerService")
+	ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("addr", peerAddr.String()).Str(LogProtoID, string(newPeer.protocolID)).Msg("Outbound peer is  added to peerService")
 	return true
 }
 
 func (ps *peerManager) insertHandlers(peer *RemotePeer) {
 	// PingHandler
 	ph := NewPingHandler(ps, peer, ps.log)
+	ph.maxAddrs = ps.maxAddrsPerResponse
 	peer.handlers[pingRequest] = ph.handlePing
 	peer.handlers[pingResponse] = ph.handlePingResponse
 	peer.handlers[goAway] = ph.handleGoAway
@@ -415,6 +666,7 @@ func (ps *peerManager) insertHandlers(peer *RemotePeer) {
 
 	// BlockHandler
 	bh := NewBlockHandler(ps, peer, ps.log)
+	bh.maxHeaders = ps.maxHeadersPerResponse
 	peer.handlers[getBlocksRequest] = bh.handleBlockRequest
 	peer.handlers[getBlocksResponse] = bh.handleGetBlockResponse
 	peer.handlers[getBlockHeadersRequest] = bh.handleGetBlockHeadersRequest
@@ -422,13 +674,15 @@ func (ps *peerManager) insertHandlers(peer *RemotePeer) {
 	peer.handlers[getMissingRequest] = bh.handleGetMissingRequest
 	// peer.handlers[getMissingResponse] = // no function yet
 	peer.handlers[newBlockNotice] = bh.handleNewBlockNotice
+	peer.handlers[getBlockRangeRequest] = bh.handleGetBlockRangeRequest
+	peer.handlers[getBlockRangeResponse] = bh.handleGetBlockRangeResponse
 
 	th := NewTxHandler(ps, peer, ps.log)
 	peer.handlers[getTXsRequest] = th.handleGetTXsRequest
 	peer.handlers[getTxsResponse] = th.handleGetTXsResponse
 	peer.handlers[newTxNotice] = th.handleNewTXsNotice
 }
-func (ps *peerManager) tryAddInboundPeer(meta PeerMeta, rw *bufio.ReadWriter) bool {
+func (ps *peerManager) tryAddInboundPeer(meta PeerMeta, rw *bufio.ReadWriter, pid protocol.ID) bool {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
 	peerID := meta.ID
@@ -440,14 +694,24 @@ func (ps *peerManager) tryAddInboundPeer(meta PeerMeta, rw *bufio.ReadWriter) bo
 			return false
 		}
 	}
+	if ps.lowReputation(peerID) {
+		ps.log.Info().Str(LogPeerID, peerID.Pretty()).Msg("Refusing inbound peer of low score")
+		return false
+	}
 	peer = newRemotePeer(meta, ps, ps.iServ, ps.log)
+	peer.protocolID = pid
+	peer.minScore = ps.minPeerScore
+	if ps.pingInterval > 0 {
+		peer.pingDuration = ps.pingInterval
+	}
+	peer.score = ps.reputations.score(peerID)
 	peer.rw = rw
 	ps.insertHandlers(peer)
 	go peer.runPeer()
 	peer.setState(types.RUNNING)
 	ps.insertPeer(peerID, peer)
 	peerAddr := meta.ToPeerAddress()
-	ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("addr", peerAddr.String()).Msg("Inbound peer is  added to peerService")
+	ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("addr", peerAddr.String()).Str(LogProtoID, string(pid)).Msg("Inbound peer is  added to peerService")
 	return true
 }
 
@@ -462,32 +726,116 @@ func (ps *peerManager) checkInPeerstore(peerID peer.ID) bool {
 	return found
 }
 
+// peerAddrTTL returns how long the address of peer is kept in peerstore.
+func (ps *peerManager) peerAddrTTL(meta PeerMeta) time.Duration {
+	if meta.Designated {
+		return meta.TTL()
+	}
+	return ps.addrTTL
+}
+
+// pruneExpiredAddrs removes expired addresses from peerstore. Addresses of designated peers
+// and connected peers are left untouched.
+func (ps *peerManager) pruneExpiredAddrs() {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	pruned := 0
+	for _, peerID := range ps.Peerstore().Peers() {
+		if peerID == ps.selfMeta.ID {
+			continue
+		}
+		if _, found := ps.designatedPeers[peerID]; found {
+			continue
+		}
+		if _, found := ps.remotePeers[peerID]; found {
+			continue
+		}
+		// peerstore drops expired addresses while looking up addresses of peer.
+		if len(ps.Peerstore().Addrs(peerID)) == 0 {
+			ps.Peerstore().ClearAddrs(peerID)
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		ps.log.Debug().Int("pruned", pruned).Msg("Expired peer addresses are pruned from peerstore")
+	}
+}
+
+// requests to runManagePeers below are dropped after peerManager is stopped.
+
 func (ps *peerManager) AddNewPeer(peer PeerMeta) {
-	ps.addPeerChannel <- peer
+	select {
+	case ps.addPeerChannel <- peer:
+	case <-ps.finishChannel:
+	}
 }
 
 func (ps *peerManager) RemovePeer(peerID peer.ID) {
-	ps.removePeerChannel <- peerID
+	ps.DisconnectPeer(peerID, Manual)
+}
+
+func (ps *peerManager) DisconnectPeer(peerID peer.ID, reason DisconnectReason) {
+	if target, found := ps.GetPeer(peerID); found && target.setDisconnectReason(reason) {
+		ps.log.Info().Str(LogPeerID, peerID.Pretty()).Str("reason", reason.String()).Msg("Disconnecting peer")
+		if target.State() != types.STOPPED {
+			target.sendMessage(newPbMsgRequestOrder(false, true, goAway, &types.GoAwayNotice{MessageData: &types.MessageData{}, Message: reason.String()}))
+		}
+	}
+	select {
+	case ps.removePeerChannel <- peerID:
+	case <-ps.finishChannel:
+	}
+}
+
+func (ps *peerManager) RemovePeerFromPool(peerID peer.ID) {
+	select {
+	case ps.dropPoolChannel <- peerID:
+	case <-ps.finishChannel:
+	}
 }
 
 func (ps *peerManager) NotifyPeerHandshake(peerID peer.ID) {
-	ps.hsPeerChannel <- peerID
+	select {
+	case ps.hsPeerChannel <- peerID:
+	case <-ps.finishChannel:
+	}
 }
 
 func (ps *peerManager) NotifyPeerAddressReceived(metas []PeerMeta) {
-	ps.fillPoolChannel <- metas
+	select {
+	case ps.fillPoolChannel <- metas:
+	case <-ps.finishChannel:
+	}
+}
+
+// afterPeerRemoved schedules reconnecting to the removed peer. Peer evicted by low score is not reconnected
+// and its address is banned for a while, unless it is designated.
+func (ps *peerManager) afterPeerRemoved(target *RemotePeer) {
+	if designated, found := ps.designatedPeers[target.ID()]; found {
+		ps.rm.AddJob(designated)
+		return
+	}
+	// the peer kicked for misbehaviour is not reconnected, and its address is banned for a while.
+	if target.evicted() || target.DisconnectReason() == ProtocolViolation {
+		ps.addrBlacklist.ban(target.meta)
+		return
+	}
+	meta := target.meta
+	meta.Designated = false
+	ps.rm.AddJob(meta)
 }
 
 // removePeer remove and disconnect managed remote peer connection
 // It return true if peer is exist and managed by peermanager
-func (ps *peerManager) removePeer(peerID peer.ID) bool {
+func (ps *peerManager) removePeer(peerID peer.ID) (*RemotePeer, bool) {
 	ps.mutex.Lock()
 	target, ok := ps.remotePeers[peerID]
 	if !ok {
 		ps.mutex.Unlock()
-		return false
+		return nil, false
 	}
 	ps.deletePeer(peerID)
+	ps.reputations.record(peerID, target.Score())
 	// No internal module access this peer anymore, but remote message can be received.
 	target.stop()
 	ps.mutex.Unlock()
@@ -496,13 +844,24 @@ func (ps *peerManager) removePeer(peerID peer.ID) bool {
 	for _, existingPeerID := range ps.Peerstore().Peers() {
 		if existingPeerID == peerID {
 			for _, listener := range ps.eventListeners {
-				listener.OnRemovePeer(peerID)
+				listener.OnRemovePeer(peerID, target.DisconnectReason())
 			}
 			ps.Network().ClosePeer(peerID)
-			return true
+			return target, true
 		}
 	}
-	return true
+	return target, true
+}
+
+// dropPoolPeer should be called in runManagePeers() only
+func (ps *peerManager) dropPoolPeer(peerID peer.ID) {
+	if _, found := ps.designatedPeers[peerID]; found {
+		return
+	}
+	delete(ps.peerPool, peerID)
+	ps.updatePoolSize()
+	ps.Peerstore().ClearAddrs(peerID)
+	ps.log.Debug().Str(LogPeerID, peerID.Pretty()).Msg("Dropped peer from peerpool")
 }
 
 func (ps *peerManager) Peerstore() pstore.Peerstore {
@@ -510,37 +869,56 @@ func (ps *peerManager) Peerstore() pstore.Peerstore {
 }
 
 func (ps *peerManager) startListener() {
-	var err error
-	listens := make([]ma.Multiaddr, 0, 2)
-	// FIXME: should also support ip6 later
-	listen, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d",
-		ps.selfMeta.IPAddress, ps.selfMeta.Port))
+	listens := make([]ma.Multiaddr, 0, 3)
+	listen, err := toMultiAddr(ps.selfMeta.IPAddress, ps.selfMeta.Port)
 	if err != nil {
 		panic("Can't estabilish listening address: " + err.Error())
 	}
 	listens = append(listens, listen)
-	listen, _ = ma.NewMultiaddr(fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", ps.selfMeta.Port))
-	listens = append(listens, listen)
+	// listen on both families if address is not specified in config, or on the unspecified address of the same family.
+	if configured := net.ParseIP(ps.conf.NetProtocolAddr); configured.IsUnspecified() {
+		listens = append(listens, unspecifiedAddrs(ps.selfMeta.Port, "ip4", "ip6")...)
+	} else {
+		listens = append(listens, unspecifiedAddrs(ps.selfMeta.Port, ipProtocol(configured))...)
+	}
 
 	peerStore := pstore.NewPeerstore()
 
-	newHost, err := libp2p.New(context.Background(), libp2p.Identity(ps.privateKey), libp2p.Peerstore(peerStore), libp2p.ListenAddrs(listens...))
+	opts := []libp2p.Option{libp2p.Identity(ps.privateKey), libp2p.Peerstore(peerStore), libp2p.ListenAddrs(listens...)}
+	opts = append(opts, ps.relayOptions()...)
+	opts = append(opts, ps.securityOptions()...)
+	newHost, err := libp2p.New(context.Background(), opts...)
 	if err != nil {
 		ps.log.Fatal().Err(err).Str("addr", listen.String()).Msg("Couldn't listen from")
 		panic(err.Error())
 	}
 
-	ps.log.Info().Str("pid", ps.SelfNodeID().Pretty()).Str("addr[0]", listens[0].String()).Str("addr[1]", listens[1].String()).
+	ps.log.Info().Str("pid", ps.SelfNodeID().Pretty()).Str("addrs", fmt.Sprint(listens)).
 		Msg("Set self node's pid, and listening for connections")
 	ps.Host = newHost
 
-	ps.SetStreamHandler(aergoP2PSub, ps.onHandshake)
+	// streams are limited by SetStreamHandler
+	ps.setProtocolHandlers(ps.limitHandshakes(ps.onHandshake))
 	// // listen subprotocols also
 	// for _, sub := range ps.subProtocols {
 	// 	sub.startHandling()
 	// }
 }
 
+// unspecifiedAddrs returns multiaddrs of unspecified address of each given ip protocol, i.e. 0.0.0.0 and ::
+func unspecifiedAddrs(port uint32, protocols ...string) []ma.Multiaddr {
+	addrs := make([]ma.Multiaddr, 0, len(protocols))
+	for _, protocol := range protocols {
+		ip := net.IPv4zero
+		if protocol == "ip6" {
+			ip = net.IPv6unspecified
+		}
+		addr, _ := toMultiAddr(ip.String(), port)
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
 func (pi *peerInfo) set(id *peer.ID, privKey *crypto.PrivKey) {
 	pi.Lock()
 	pi.id = id
@@ -566,32 +944,52 @@ func GetMyID() (peer.ID, crypto.PrivKey) {
 	return *id, *pk
 }
 
+// Start starts managing peers. Starting again does nothing, and peerManager can't be restarted after it is stopped.
 func (ps *peerManager) Start() error {
+	select {
+	case <-ps.finishChannel:
+		return ErrPeerManagerStopped
+	default:
+	}
+	if !atomic.CompareAndSwapUint32(&ps.status, component.StoppedStatus, component.StartedStatus) {
+		if ps.GetStatus() == component.StartedStatus {
+			ps.log.Debug().Msg("Peer manager is already started")
+			return nil
+		}
+		return ErrPeerManagerStopped
+	}
 	ps.run()
-	ps.status = component.StartedStatus
 	//ps.conf.NPAddPeers
 	return nil
 }
+
+// Stop signals shutdown to runManagePeers and waits it to cleanup peers. It is safe to call Stop more than once.
 func (ps *peerManager) Stop() error {
-	// TODO stop service
-	ps.status = component.StoppingStatus
-	close(ps.addPeerChannel)
-	close(ps.removePeerChannel)
-	ps.status = component.StoppedStatus
-	ps.finishChannel <- struct{}{}
+	ps.stopOnce.Do(func() {
+		started := atomic.SwapUint32(&ps.status, component.StoppingStatus) == component.StartedStatus
+		close(ps.finishChannel)
+		if started {
+			<-ps.manageDone
+		}
+		// peers are removed by now, so their scores are recorded
+		if err := ps.reputations.save(ps.addrBlacklist); err != nil {
+			ps.log.Warn().Err(err).Msg("Failed to save peer reputations")
+		}
+		atomic.StoreUint32(&ps.status, component.StoppedStatus)
+	})
 	return nil
 }
 
 func (ps *peerManager) GetStatus() component.Status {
-	return ps.status
+	return atomic.LoadUint32(&ps.status)
 }
 
 func (ps *peerManager) Started() bool {
-	return ps.status == component.StartedStatus
+	return ps.GetStatus() == component.StartedStatus
 }
 
 func (ps *peerManager) Ended() bool {
-	return ps.status == component.StoppedStatus
+	return ps.GetStatus() == component.StoppedStatus
 }
 
 func (ps *peerManager) GetName() string {
@@ -603,7 +1001,7 @@ func (ps *peerManager) checkAndCollectPeerListFromAll() {
 		return
 	}
 	for _, remotePeer := range ps.remotePeers {
-		ps.iServ.SendRequest(message.P2PSvc, &message.GetAddressesMsg{ToWhom: remotePeer.meta.ID, Size: 20, Offset: 0})
+		ps.iServ.SendRequest(message.P2PSvc, &message.GetAddressesMsg{ToWhom: remotePeer.meta.ID, Size: ps.addrRequestSize, Offset: 0})
 	}
 }
 
@@ -617,13 +1015,18 @@ func (ps *peerManager) checkAndCollectPeerList(ID peer.ID) {
 		ps.log.Warn().Str(LogPeerID, ID.Pretty()).Msg("invalid peer id")
 		return
 	}
-	ps.iServ.SendRequest(message.P2PSvc, &message.GetAddressesMsg{ToWhom: peer.meta.ID, Size: 20, Offset: 0})
+	ps.iServ.SendRequest(message.P2PSvc, &message.GetAddressesMsg{ToWhom: peer.meta.ID, Size: ps.addrRequestSize, Offset: 0})
 }
 
 func (ps *peerManager) hasEnoughPeers() bool {
 	return len(ps.peerPool) >= ps.conf.NPPeerPool
 }
 
+// updatePoolSize should be called in runManagePeers() only, after peerPool is changed
+func (ps *peerManager) updatePoolSize() {
+	atomic.StoreInt32(&ps.poolSize, int32(len(ps.peerPool)))
+}
+
 // tryConnectPeers should be called in runManagePeers() only
 func (ps *peerManager) tryFillPool(metas *[]PeerMeta) {
 	added := make([]PeerMeta, 0, len(*metas))
@@ -634,6 +1037,7 @@ func (ps *peerManager) tryFillPool(metas *[]PeerMeta) {
 			meta.Outbound = true
 			meta.Designated = false
 			ps.peerPool[meta.ID] = meta
+			ps.updatePoolSize()
 			added = append(added, meta)
 		}
 	}
@@ -641,12 +1045,28 @@ func (ps *peerManager) tryFillPool(metas *[]PeerMeta) {
 	ps.tryConnectPeers()
 }
 
+// ReportHandshakeFailure implements PeerManager.
+func (ps *peerManager) ReportHandshakeFailure(meta PeerMeta) {
+	ps.recordHandshakeFailure(meta)
+}
+
+// recordHandshakeFailure bans the address of peer which fails handshake repeatedly. Designated peers are exempt.
+func (ps *peerManager) recordHandshakeFailure(meta PeerMeta) {
+	if _, designated := ps.designatedPeers[meta.ID]; designated || meta.Designated {
+		return
+	}
+	if ps.addrBlacklist.fail(meta) {
+		ps.log.Info().Str(LogPeerID, meta.ID.Pretty()).Str("addr", addrKey(meta)).Msg("Address is banned for a while by repeated handshake failures")
+	}
+}
+
 // tryConnectPeers should be called in runManagePeers() only
 func (ps *peerManager) tryConnectPeers() {
 	remained := ps.conf.NPMaxPeers - len(ps.remotePeers)
 	for ID, meta := range ps.peerPool {
 		if _, found := ps.GetPeer(ID); found {
 			delete(ps.peerPool, ID)
+			ps.updatePoolSize()
 			continue
 		}
 		if meta.IPAddress == "" || meta.Port == 0 {
@@ -654,6 +1074,9 @@ func (ps *peerManager) tryConnectPeers() {
 				Uint32("port", meta.Port).Msg("Invalid peer meta informations")
 			continue
 		}
+		if _, designated := ps.designatedPeers[ID]; !designated && ps.addrBlacklist.banned(meta) {
+			continue
+		}
 		// in same go rountine.
 		ps.addOutboundPeer(meta)
 		remained--
@@ -663,28 +1086,30 @@ func (ps *peerManager) tryConnectPeers() {
 	}
 }
 
-// Authenticate incoming p2p message
-// message: a protobufs go data object
-// data: common p2p message data
+// AuthenticateMessage verifies incoming p2p message. It always succeeds if verification is disabled by config.
+// message: a protobufs go data object, which was signed by sender
+// data: common p2p message data, which contains the signature
 func (ps *peerManager) AuthenticateMessage(message proto.Message, data *types.MessageData) bool {
-	// for Test only
-	return true
+	if !ps.conf.NPVerifyMessages {
+		return true
+	}
+	if data == nil || len(data.Sign) == 0 {
+		ps.log.Warn().Msg("message is not signed")
+		return false
+	}
 
-	// store a temp ref to signature and remove it from message data
-	// sign is a string to allow easy reset to zero-value (empty string)
+	// store a temp ref to signature and remove it from message data, since sender signed the message without it.
 	sign := data.Sign
-	data.Sign = []byte{}
-
+	data.Sign = nil
 	// marshall data without the signature to protobufs3 binary format
 	bin, err := proto.Marshal(message)
+	// restore sig in message data (for possible future use)
+	data.Sign = sign
 	if err != nil {
-		ps.log.Warn().Msg("failed to marshal pb message")
+		ps.log.Warn().Err(err).Msg("failed to marshal pb message")
 		return false
 	}
 
-	// restore sig in message data (for possible future use)
-	data.Sign = sign
-
 	// restore peer peer.ID binary format from base58 encoded node peer.ID data
 	peerID, err := peer.IDB58Decode(data.PeerID)
 	if err != nil {
@@ -694,7 +1119,7 @@ func (ps *peerManager) AuthenticateMessage(message proto.Message, data *types.Me
 
 	// verify the data was authored by the signing peer identified by the public key
 	// and signature included in the message
-	return ps.VerifyData(bin, []byte(sign), peerID, data.NodePubKey)
+	return ps.VerifyData(bin, sign, peerID, data.NodePubKey)
 }
 
 // sign an outgoing p2p message payload
@@ -766,15 +1191,111 @@ func (ps *peerManager) GetPeers() []*RemotePeer {
 	return ps.peerCache
 }
 
-func (ps *peerManager) GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState) {
+// PeerCounts returns the number of connected peers, addresses in peer pool which are waiting to be connected,
+// and designated peers. Maps are not walked, so it is cheap enough to be called for monitoring.
+func (ps *peerManager) PeerCounts() (connected, pool, designated int) {
+	ps.mutex.Lock()
+	connected = len(ps.remotePeers)
+	ps.mutex.Unlock()
+	// designatedPeers is not changed after init
+	return connected, int(atomic.LoadInt32(&ps.poolSize)), len(ps.designatedPeers)
+}
+
+// IsFull returns true if the number of connected peers reaches NPMaxPeers, so that no more peer is connected
+// from peer pool.
+func (ps *peerManager) IsFull() bool {
+	connected, _, _ := ps.PeerCounts()
+	return connected >= ps.conf.NPMaxPeers
+}
+
+func (ps *peerManager) GetPeerAddresses() ([]*types.PeerAddress, []types.PeerState, []DisconnectReason) {
 	peers := make([]*types.PeerAddress, 0, len(ps.remotePeers))
 	states := make([]types.PeerState, 0, len(ps.remotePeers))
+	reasons := make([]DisconnectReason, 0, len(ps.remotePeers))
 	for _, aPeer := range ps.remotePeers {
 		addr := aPeer.meta.ToPeerAddress()
 		peers = append(peers, &addr)
 		states = append(states, aPeer.state)
+		reasons = append(reasons, aPeer.DisconnectReason())
+	}
+	return peers, states, reasons
+}
+
+func (ps *peerManager) GetPeerScores() map[peer.ID]int32 {
+	scores := make(map[peer.ID]int32)
+	for _, aPeer := range ps.GetPeers() {
+		scores[aPeer.ID()] = aPeer.Score()
+	}
+	return scores
+}
+
+func (ps *peerManager) GetPeerHeights() map[peer.ID]types.BlockNo {
+	heights := make(map[peer.ID]types.BlockNo)
+	for _, aPeer := range ps.GetPeers() {
+		if aPeer.State() != types.RUNNING {
+			continue
+		}
+		heights[aPeer.ID()] = aPeer.BestHeight()
 	}
-	return peers, states
+	return heights
+}
+
+// DumpState asks runManagePeers to take the snapshot, since peer pool is accessed only by that goroutine.
+func (ps *peerManager) DumpState() *message.DumpP2PStateRsp {
+	rsp := make(chan *message.DumpP2PStateRsp, 1)
+	select {
+	case ps.dumpChannel <- rsp:
+		return <-rsp
+	case <-ps.finishChannel:
+		return &message.DumpP2PStateRsp{}
+	}
+}
+
+func (ps *peerManager) dumpState() *message.DumpP2PStateRsp {
+	dump := &message.DumpP2PStateRsp{}
+	ps.mutex.Lock()
+	for _, aPeer := range ps.remotePeers {
+		dump.RemotePeers = append(dump.RemotePeers, message.P2PRemotePeer{P2PPeerAddr: dumpPeerAddr(aPeer.meta),
+			State: aPeer.State().String(), BestHeight: aPeer.BestHeight(), Latency: aPeer.Latency().String(),
+			Score: aPeer.Score()})
+	}
+	ps.mutex.Unlock()
+	for _, meta := range ps.peerPool {
+		dump.PeerPool = append(dump.PeerPool, dumpPeerAddr(meta))
+	}
+	for _, meta := range ps.designatedPeers {
+		dump.DesignatedPeers = append(dump.DesignatedPeers, dumpPeerAddr(meta))
+	}
+	if ps.Host != nil {
+		for _, peerID := range ps.Peerstore().Peers() {
+			entry := message.P2PPeerstoreEntry{ID: peerID.Pretty()}
+			for _, addr := range ps.Peerstore().Addrs(peerID) {
+				entry.Addrs = append(entry.Addrs, addr.String())
+			}
+			dump.Peerstore = append(dump.Peerstore, entry)
+		}
+	}
+	if ps.rm != nil {
+		for _, meta := range ps.rm.Jobs() {
+			dump.ReconnectJobs = append(dump.ReconnectJobs, dumpPeerAddr(meta))
+		}
+	}
+	for addr, until := range ps.addrBlacklist.bannedAddrs() {
+		dump.BannedAddrs = append(dump.BannedAddrs, message.P2PBannedAddr{Address: addr, Until: until})
+	}
+	return dump
+}
+
+func dumpPeerAddr(meta PeerMeta) message.P2PPeerAddr {
+	return message.P2PPeerAddr{ID: meta.ID.Pretty(), Address: addrKey(meta), Designated: meta.Designated}
+}
+
+func (ps *peerManager) UpdateConfirmedTxs(confirmed, reverted [][]byte) {
+	ps.confirmedTxs.update(confirmed, reverted)
+}
+
+func (ps *peerManager) IsConfirmedTx(hash []byte) bool {
+	return ps.confirmedTxs.contains(hash)
 }
 
 func (ps *peerManager) HandleNewBlockNotice(peerID peer.ID, b64hash string, data *types.NewBlockNotice) {
@@ -785,9 +1306,13 @@ func (ps *peerManager) HandleNewBlockNotice(peerID peer.ID, b64hash string, data
 		// this notice is already sent to chainservice
 		return
 	}
+	// only the first notice of a block tells how long it took to reach this node
+	if latency, recorded := blockPropagation.record(data.BlockTimestamp, time.Now()); recorded {
+		ps.log.Debug().Str(LogBlkHash, b64hash).Str("latency", latency.String()).Msg("Block propagated")
+	}
 
 	// request block info if selfnode does not have block already
-	rawResp, err := ps.iServ.CallRequest(message.ChainSvc, &message.GetBlock{BlockHash: message.BlockHash(data.BlockHash)})
+	rawResp, err := callRequestWithin(ps.iServ, message.ChainSvc, &message.GetBlock{BlockHash: message.BlockHash(data.BlockHash)})
 	if err != nil {
 		ps.log.Warn().Err(err).Msg("actor return error on getblock")
 		return
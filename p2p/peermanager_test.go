@@ -7,8 +7,13 @@ package p2p
 import (
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,11 +21,16 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/account"
 	cfg "github.com/aergoio/aergo/config"
 	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
+	"github.com/golang/protobuf/proto"
+	crypto "github.com/libp2p/go-libp2p-crypto"
 	"github.com/libp2p/go-libp2p-peer"
 	pstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 var dummyBlockHash, _ = hex.DecodeString("4f461d85e869ade8a0544f8313987c33a9c06534e50c4ad941498299579bd7ac")
@@ -30,7 +40,7 @@ var dummyBlockHeight uint64 = 100215
 func IgrenoreTestP2PServiceRunAddPeer(t *testing.T) {
 	mockActorServ := MockActorService{}
 	dummyBlock := types.Block{Hash: dummyBlockHash, Header: &types.BlockHeader{BlockNo: dummyBlockHeight}}
-	mockActorServ.On("CallRequest", mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
+	mockActorServ.On("CallRequestWithTimeout", mock.Anything, mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
 	target := NewPeerManager(&mockActorServ,
 		cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config),
 		new(MockReconnectManager),
@@ -58,7 +68,7 @@ func IgrenoreTestP2PServiceRunAddPeer(t *testing.T) {
 func FailTestGetPeers(t *testing.T) {
 	mockActorServ := &MockActorService{}
 	dummyBlock := types.Block{Hash: dummyBlockHash, Header: &types.BlockHeader{BlockNo: dummyBlockHeight}}
-	mockActorServ.On("CallRequest", mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
+	mockActorServ.On("CallRequestWithTimeout", mock.Anything, mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
 	target := NewPeerManager(mockActorServ,
 		cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config),
 		new(MockReconnectManager),
@@ -93,9 +103,12 @@ func FailTestGetPeers(t *testing.T) {
 func TestGetPeers(t *testing.T) {
 	mockActorServ := &MockActorService{}
 	dummyBlock := types.Block{Hash: dummyBlockHash, Header: &types.BlockHeader{BlockNo: dummyBlockHeight}}
-	mockActorServ.On("CallRequest", mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
+	mockActorServ.On("CallRequestWithTimeout", mock.Anything, mock.Anything, mock.Anything).Return(message.GetBlockRsp{Block: &dummyBlock}, nil)
+	conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+	// not to leave key file in data directory of the user
+	conf.DataDir = ""
 	target := NewPeerManager(mockActorServ,
-		cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config),
+		conf,
 		new(MockReconnectManager),
 		log.NewLogger("test.p2p")).(*peerManager)
 
@@ -132,3 +145,418 @@ func TestGetPeers(t *testing.T) {
 	wgAll.Wait()
 	assert.True(t, iterSize == len(target.GetPeers()))
 }
+
+func TestPeerManager_pruneExpiredAddrs(t *testing.T) {
+	designatedMeta := PeerMeta{ID: peer.ID("designated"), IPAddress: "192.168.0.1", Port: 7846, Designated: true, Outbound: true}
+	discoveredMeta := PeerMeta{ID: peer.ID("discovered"), IPAddress: "192.168.0.2", Port: 7846, Outbound: true}
+	target := &peerManager{
+		Host:            &mockHost{pstore.NewPeerstore()},
+		log:             logger,
+		mutex:           &sync.Mutex{},
+		addrTTL:         time.Millisecond * 100,
+		designatedPeers: map[peer.ID]PeerMeta{designatedMeta.ID: designatedMeta},
+		remotePeers:     make(map[peer.ID]*RemotePeer),
+	}
+	assert.Equal(t, pstore.PermanentAddrTTL, target.peerAddrTTL(designatedMeta))
+	assert.Equal(t, time.Millisecond*100, target.peerAddrTTL(discoveredMeta))
+
+	for _, meta := range []PeerMeta{designatedMeta, discoveredMeta} {
+		addr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", meta.IPAddress, meta.Port))
+		assert.Nil(t, err)
+		target.Peerstore().AddAddr(meta.ID, addr, target.peerAddrTTL(meta))
+	}
+	target.pruneExpiredAddrs()
+	assert.Equal(t, 1, len(target.Peerstore().Addrs(designatedMeta.ID)))
+	assert.Equal(t, 1, len(target.Peerstore().Addrs(discoveredMeta.ID)))
+
+	time.Sleep(time.Millisecond * 200)
+	target.pruneExpiredAddrs()
+	assert.Equal(t, 1, len(target.Peerstore().Addrs(designatedMeta.ID)))
+	assert.Equal(t, 0, len(target.Peerstore().Addrs(discoveredMeta.ID)))
+}
+
+func TestPeerManager_GetPeerHeights(t *testing.T) {
+	mockActorServ := &MockActorService{}
+	running1 := newRemotePeer(PeerMeta{ID: peer.ID("running1")}, nil, mockActorServ, logger)
+	running1.setState(types.RUNNING)
+	running2 := newRemotePeer(PeerMeta{ID: peer.ID("running2")}, nil, mockActorServ, logger)
+	running2.setState(types.RUNNING)
+	handshaking := newRemotePeer(PeerMeta{ID: peer.ID("handshaking")}, nil, mockActorServ, logger)
+	handshaking.setState(types.HANDSHAKING)
+	target := &peerManager{mutex: &sync.Mutex{}, peerCache: []*RemotePeer{running1, running2, handshaking}}
+
+	heights := target.GetPeerHeights()
+	assert.Equal(t, 2, len(heights))
+	assert.Equal(t, types.BlockNo(0), heights[running1.ID()])
+
+	running1.updateBestHeight(100)
+	running2.updateBestHeight(200)
+	handshaking.updateBestHeight(300)
+	heights = target.GetPeerHeights()
+	assert.Equal(t, 2, len(heights))
+	assert.Equal(t, types.BlockNo(100), heights[running1.ID()])
+	assert.Equal(t, types.BlockNo(200), heights[running2.ID()])
+	_, exists := heights[handshaking.ID()]
+	assert.False(t, exists)
+
+	running1.updateBestHeight(150)
+	assert.Equal(t, types.BlockNo(150), target.GetPeerHeights()[running1.ID()])
+}
+
+func newKeyedPeerManager(t *testing.T, verify bool) *peerManager {
+	priv, pub, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err.Error())
+	}
+	id, _ := peer.IDFromPublicKey(pub)
+	return &peerManager{log: logger, mutex: &sync.Mutex{}, privateKey: priv, publicKey: pub, selfMeta: PeerMeta{ID: id},
+		conf: &cfg.P2PConfig{NPVerifyMessages: verify}}
+}
+
+// signedMessage returns message signed by sender, as it is received from wire
+func signedMessage(t *testing.T, sender *peerManager) *types.P2PMessage {
+	order := newPbMsgRequestOrder(true, true, getTXsRequest, &types.GetTransactionsRequest{MessageData: &types.MessageData{},
+		Hashes: [][]byte{[]byte("tx1"), []byte("tx2")}})
+	// signing again for another peer must not be affected by previous signature
+	for i := 0; i < 2; i++ {
+		if err := order.SignWith(sender); err != nil {
+			t.Fatalf("failed to sign: %s", err.Error())
+		}
+	}
+	bin, _ := proto.Marshal(order.message)
+	msg := &types.P2PMessage{}
+	if err := proto.Unmarshal(bin, msg); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err.Error())
+	}
+	return msg
+}
+
+func TestPeerManager_AuthenticateMessage(t *testing.T) {
+	sender := newKeyedPeerManager(t, true)
+	other := newKeyedPeerManager(t, true)
+	otherPubKey, _ := other.PublicKey().Bytes()
+
+	tests := []struct {
+		name     string
+		verify   bool
+		modify   func(msg *types.P2PMessage)
+		expected bool
+	}{
+		{"TValid", true, func(msg *types.P2PMessage) {}, true},
+		{"TTampered", true, func(msg *types.P2PMessage) { msg.Data = append(msg.Data, 0) }, false},
+		{"TMalformedSign", true, func(msg *types.P2PMessage) { msg.Header.Sign = msg.Header.Sign[1:] }, false},
+		{"TNotSigned", true, func(msg *types.P2PMessage) { msg.Header.Sign = nil }, false},
+		{"TOtherKey", true, func(msg *types.P2PMessage) { msg.Header.NodePubKey = otherPubKey }, false},
+		{"TBadPeerID", true, func(msg *types.P2PMessage) { msg.Header.PeerID = "invalid" }, false},
+		{"TNotVerified", false, func(msg *types.P2PMessage) { msg.Header.Sign = msg.Header.Sign[1:] }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newKeyedPeerManager(t, tt.verify)
+			msg := signedMessage(t, sender)
+			tt.modify(msg)
+			sign := msg.Header.Sign
+
+			assert.Equal(t, tt.expected, target.AuthenticateMessage(msg, msg.Header))
+			// signature is restored after verification
+			assert.Equal(t, sign, msg.Header.Sign)
+		})
+	}
+}
+
+func TestPeerManager_DropUnauthenticatedMessage(t *testing.T) {
+	mockActorServ := &MockActorService{}
+	sender := newKeyedPeerManager(t, true)
+	target := newKeyedPeerManager(t, true)
+	remotePeer := newRemotePeer(PeerMeta{ID: sender.SelfNodeID()}, target, mockActorServ, logger)
+	handler := NewTxHandler(target, remotePeer, logger)
+
+	msg := signedMessage(t, sender)
+	msg.Header.Sign = msg.Header.Sign[1:]
+	handler.handleGetTXsRequest(msg)
+
+	mockActorServ.AssertNotCalled(t, "CallRequestWithTimeout", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&remotePeer.failCounter))
+}
+
+// newStoppedPeerManager returns peerManager which is not started yet, without host.
+func newStoppedPeerManager() *peerManager {
+	return &peerManager{log: logger, mutex: &sync.Mutex{}, addrTTL: DefaultNodeTTL, status: component.StoppedStatus,
+		remotePeers: make(map[peer.ID]*RemotePeer), designatedPeers: make(map[peer.ID]PeerMeta),
+		addPeerChannel: make(chan PeerMeta, 2), removePeerChannel: make(chan peer.ID),
+		dropPoolChannel: make(chan peer.ID), hsPeerChannel: make(chan peer.ID),
+		fillPoolChannel: make(chan []PeerMeta), dumpChannel: make(chan chan *message.DumpP2PStateRsp),
+		addrRefreshInterval: DefaultAddrRefreshInterval, addrRequestSize: DefaultAddrRequestSize, finishChannel: make(chan struct{}), manageDone: make(chan struct{})}
+}
+
+// newRunningPeerManager returns peerManager of which managing loop is running, without host.
+func newRunningPeerManager() *peerManager {
+	pm := newStoppedPeerManager()
+	go pm.runManagePeers()
+	pm.status = component.StartedStatus
+	return pm
+}
+
+func TestPeerManager_StopTwice(t *testing.T) {
+	pm := newRunningPeerManager()
+
+	assert.Nil(t, pm.Stop())
+	assert.Equal(t, component.StoppedStatus, pm.status)
+	assert.NotPanics(t, func() { pm.Stop() })
+
+	// requests after stop are dropped without blocking
+	finished := make(chan struct{})
+	go func() {
+		pm.AddNewPeer(PeerMeta{ID: dummyPeerID})
+		pm.RemovePeer(dummyPeerID)
+		pm.NotifyPeerHandshake(dummyPeerID)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("request to stopped peer manager is blocked")
+	}
+
+	// stopped peer manager can't be started again
+	assert.Equal(t, ErrPeerManagerStopped, pm.Start())
+}
+
+func TestPeerManager_StartTwice(t *testing.T) {
+	before := runtime.NumGoroutine()
+	pm := newStoppedPeerManager()
+
+	assert.Nil(t, pm.Start())
+	assert.Nil(t, pm.Start())
+	assert.Equal(t, component.StartedStatus, pm.GetStatus())
+	// only one managing loop and one listener starter are running
+	assert.True(t, runtime.NumGoroutine() <= before+2)
+
+	assert.Nil(t, pm.Stop())
+	assert.Nil(t, pm.Stop())
+	assert.True(t, pm.Ended())
+	assert.True(t, waitUntil(func() bool { return runtime.NumGoroutine() <= before }, time.Second), "goroutines are leaked")
+}
+
+func TestPeerManager_StopBeforeStart(t *testing.T) {
+	pm := newStoppedPeerManager()
+
+	finished := make(chan struct{})
+	go func() {
+		pm.Stop()
+		pm.Stop()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("stopping peer manager which is not started is blocked")
+	}
+	assert.Equal(t, ErrPeerManagerStopped, pm.Start())
+	assert.Equal(t, component.StoppedStatus, pm.GetStatus())
+}
+
+func TestPeerManager_AddNewPeerWhileStop(t *testing.T) {
+	pm := newRunningPeerManager()
+	// invalid address, so that peer manager doesn't try to connect
+	meta := PeerMeta{ID: dummyPeerID, IPAddress: "invalid", Port: 7846}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pm.AddNewPeer(meta)
+				pm.RemovePeer(dummyPeerID)
+			}
+		}()
+	}
+	assert.NotPanics(t, func() { pm.Stop() })
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second * 3):
+		t.Fatal("AddNewPeer is blocked after stop")
+	}
+}
+
+func TestPeerManager_DumpState(t *testing.T) {
+	pm := newRunningPeerManager()
+	pm.Host = newTestHost(t)
+	defer pm.Host.Close()
+	rm := NewReconnectManager(logger)
+	rm.pm = pm
+	pm.rm = rm
+	defer rm.Stop()
+
+	connected := PeerMeta{ID: dummyPeerID, IPAddress: "192.168.0.1", Port: 7846, Designated: true}
+	pooled := PeerMeta{ID: dummyPeerID2, IPAddress: "192.168.0.2", Port: 7846}
+	reconnecting := PeerMeta{ID: dummyPeerID3, IPAddress: "192.168.0.3", Port: 7846}
+	pm.mutex.Lock()
+	pm.remotePeers[connected.ID] = newRemotePeer(connected, pm, &MockActorService{}, logger)
+	pm.mutex.Unlock()
+	pm.peerPool = map[peer.ID]PeerMeta{pooled.ID: pooled}
+	rm.AddJob(reconnecting)
+
+	dump := pm.DumpState()
+	if assert.Len(t, dump.RemotePeers, 1) {
+		assert.Equal(t, dumpPeerAddr(connected), dump.RemotePeers[0].P2PPeerAddr)
+		assert.Equal(t, types.STARTING.String(), dump.RemotePeers[0].State)
+	}
+	assert.Equal(t, []message.P2PPeerAddr{dumpPeerAddr(pooled)}, dump.PeerPool)
+	assert.Equal(t, []message.P2PPeerAddr{dumpPeerAddr(reconnecting)}, dump.ReconnectJobs)
+	assert.Empty(t, dump.BannedAddrs)
+}
+
+func TestPeerManager_DisconnectPeer(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(pm *peerManager, id peer.ID)
+		reason DisconnectReason
+	}{
+		{"TTimeout", func(pm *peerManager, id peer.ID) { pm.DisconnectPeer(id, Timeout) }, Timeout},
+		{"TRemovePeer", func(pm *peerManager, id peer.ID) { pm.RemovePeer(id) }, Manual},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := &peerManager{log: logger, mutex: &sync.Mutex{}, remotePeers: make(map[peer.ID]*RemotePeer),
+				removePeerChannel: make(chan peer.ID, 2), finishChannel: make(chan struct{})}
+			target := newRemotePeer(PeerMeta{ID: dummyPeerID}, pm, &MockActorService{}, logger)
+			pm.remotePeers[dummyPeerID] = target
+
+			sent := make(chan msgOrder, 1)
+			go func() { sent <- <-target.write }()
+			tt.remove(pm, dummyPeerID)
+
+			assert.Equal(t, tt.reason, target.DisconnectReason())
+			_, _, reasons := pm.GetPeerAddresses()
+			assert.Equal(t, []DisconnectReason{tt.reason}, reasons)
+			order := (<-sent).(*pbMessageOrder)
+			assert.Equal(t, goAway, order.GetProtocolID())
+			goAwayMsg := &types.GoAwayNotice{}
+			assert.Nil(t, unmarshalMessage(order.message.(*types.P2PMessage).Data, goAwayMsg))
+			assert.Equal(t, tt.reason.String(), goAwayMsg.Message)
+			assert.Equal(t, dummyPeerID, <-pm.removePeerChannel)
+
+			// the first reason is kept, and goaway is not sent again
+			pm.DisconnectPeer(dummyPeerID, ProtocolViolation)
+			assert.Equal(t, tt.reason, target.DisconnectReason())
+			assert.Equal(t, dummyPeerID, <-pm.removePeerChannel)
+		})
+	}
+}
+
+func TestPeerManager_initKeyFile(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "peerkey")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+	newPM := func(npKey string) *peerManager {
+		conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+		conf.DataDir = dataDir
+		conf.P2P.NetProtocolAddr = "127.0.0.1"
+		conf.P2P.NPKey = npKey
+		return NewPeerManager(&MockActorService{}, conf, new(MockReconnectManager), logger).(*peerManager)
+	}
+
+	first := newPM("")
+	keyFile := filepath.Join(dataDir, DefaultPeerKeyFile)
+	info, err := os.Stat(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	// peer id is kept by the key file
+	second := newPM("")
+	assert.Equal(t, first.SelfNodeID(), second.SelfNodeID())
+
+	// npkey takes precedence
+	priv, _, _ := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+	dat, _ := crypto.MarshalPrivateKey(priv)
+	npKey := filepath.Join(dataDir, "npkey.key")
+	assert.Nil(t, ioutil.WriteFile(npKey, dat, 0600))
+	expected, _ := peer.IDFromPrivateKey(priv)
+	assert.Equal(t, expected, newPM(npKey).SelfNodeID())
+
+	// the node doesn't start under a temporary id if the key file is broken
+	assert.Nil(t, ioutil.WriteFile(keyFile, []byte("broken"), 0600))
+	assert.Panics(t, func() { newPM("") })
+}
+
+func TestPeerManager_initKeystore(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "peerkey")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+	keystore := filepath.Join(dataDir, "bp.keystore")
+	key, err := account.CreateKeystore(keystore, "right")
+	assert.Nil(t, err)
+	defer os.Unsetenv(account.KeystorePassphraseEnv)
+	newPM := func() *peerManager {
+		conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+		conf.DataDir = dataDir
+		conf.P2P.NetProtocolAddr = "127.0.0.1"
+		conf.P2P.NPKeystore = keystore
+		return NewPeerManager(&MockActorService{}, conf, new(MockReconnectManager), logger).(*peerManager)
+	}
+
+	os.Setenv(account.KeystorePassphraseEnv, "right")
+	expected, _ := peer.IDFromPublicKey((*crypto.Secp256k1PublicKey)(key.PubKey()))
+	assert.Equal(t, expected, newPM().SelfNodeID())
+
+	// the node doesn't start under another identity than the one of keystore
+	os.Setenv(account.KeystorePassphraseEnv, "wrong")
+	assert.Panics(t, func() { newPM() })
+}
+
+func TestPeerManager_PeerCounts(t *testing.T) {
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, conf: &cfg.P2PConfig{NPMaxPeers: 2},
+		remotePeers: make(map[peer.ID]*RemotePeer), peerPool: make(map[peer.ID]PeerMeta),
+		designatedPeers: map[peer.ID]PeerMeta{dummyPeerID: {ID: dummyPeerID, Designated: true}}}
+	assertCounts := func(connected, pool, designated int, full bool) {
+		c, p, d := pm.PeerCounts()
+		assert.Equal(t, []int{connected, pool, designated}, []int{c, p, d})
+		assert.Equal(t, full, pm.IsFull())
+	}
+	assertCounts(0, 0, 1, false)
+
+	for _, id := range []peer.ID{"pooled1", "pooled2", "pooled3"} {
+		pm.peerPool[id] = PeerMeta{ID: id}
+	}
+	pm.updatePoolSize()
+	assertCounts(0, 3, 1, false)
+
+	pm.remotePeers[dummyPeerID] = newRemotePeer(PeerMeta{ID: dummyPeerID}, pm, &MockActorService{}, logger)
+	assertCounts(1, 3, 1, false)
+	pm.remotePeers["pooled1"] = newRemotePeer(PeerMeta{ID: "pooled1"}, pm, &MockActorService{}, logger)
+	assertCounts(2, 3, 1, true)
+}
+
+func TestPeerManager_AddrRefreshInterval(t *testing.T) {
+	var requested int32
+	mockActorServ := &MockActorService{}
+	mockActorServ.On("SendRequest", message.P2PSvc, mock.MatchedBy(func(msg *message.GetAddressesMsg) bool {
+		return msg.ToWhom == dummyPeerID && msg.Size == 5
+	})).Run(func(mock.Arguments) { atomic.AddInt32(&requested, 1) })
+
+	pm := newStoppedPeerManager()
+	pm.Host = newTestHost(t)
+	defer pm.Host.Close()
+	pm.iServ = mockActorServ
+	pm.conf = &cfg.P2PConfig{NPPeerPool: 10}
+	pm.addrRefreshInterval = time.Millisecond * 20
+	pm.addrRequestSize = 5
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, pm, mockActorServ, logger)
+	pm.remotePeers[dummyPeerID] = target
+	// receive stop signal instead of running peer
+	go func() { <-target.stopChan }()
+	go pm.runManagePeers()
+	pm.status = component.StartedStatus
+
+	assert.True(t, waitUntil(func() bool { return atomic.LoadInt32(&requested) >= 3 }, time.Second),
+		"addresses are not requested by configured interval")
+
+	pm.Stop()
+}
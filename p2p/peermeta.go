@@ -6,25 +6,34 @@
 package p2p
 
 import (
+	"fmt"
 	"net"
+	"regexp"
+	"strings"
 	"time"
 
 	"strconv"
 
 	"github.com/aergoio/aergo/types"
 	peer "github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
 )
 
-// TTLs are node ttl
+// TTLs are node ttl. Address of designated peer is kept in peerstore permanently,
+// and address of peer discovered from other peers is expired after DefaultNodeTTL,
+// unless other value is set in config.
 const (
-	DesignatedNodeTTL time.Duration = time.Minute * 60
+	DesignatedNodeTTL time.Duration = pstore.PermanentAddrTTL
 	DefaultNodeTTL    time.Duration = time.Minute * 10
 )
 
 // PeerMeta contains non changeable information of peer node during connected state
 // TODO: PeerMeta is almost same as PeerAddress, so TODO to unify them.
 type PeerMeta struct {
-	// IPAddress is human readable form of ip address such as "192.168.0.1" or "2001:0db8:0a0b:12f0:33:1"
+	// IPAddress is human readable form of ip address such as "192.168.0.1" or "2001:0db8:0a0b:12f0:33:1",
+	// or dns hostname such as "node1.example.com", which is resolved on every dialing.
 	IPAddress  string
 	Port       uint32
 	ID         peer.ID
@@ -40,21 +49,155 @@ func (m PeerMeta) String() string {
 func FromPeerAddress(addr *types.PeerAddress) PeerMeta {
 	meta := PeerMeta{IPAddress: net.IP(addr.Address).String(),
 		Port: addr.Port, ID: peer.ID(addr.PeerID)}
+	if len(addr.Host) > 0 {
+		meta.IPAddress = addr.Host
+	}
 	return meta
 }
 
-// ToPeerAddress convert PeerMeta to PeerAddress
+// ToPeerAddress convert PeerMeta to PeerAddress. hostname is kept as it is, not the resolved address.
 func (m PeerMeta) ToPeerAddress() types.PeerAddress {
-	addr := types.PeerAddress{Address: []byte(net.ParseIP(m.IPAddress)), Port: m.Port,
-		PeerID: []byte(m.ID)}
+	addr := types.PeerAddress{Port: m.Port, PeerID: []byte(m.ID)}
+	if ip := net.ParseIP(m.IPAddress); ip != nil {
+		addr.Address = []byte(ip)
+	} else {
+		addr.Host = m.IPAddress
+	}
 	return addr
 }
 
+// IsHostname returns whether the address of peer is dns hostname rather than ip address.
+func (m PeerMeta) IsHostname() bool {
+	return len(m.IPAddress) > 0 && net.ParseIP(m.IPAddress) == nil
+}
+
 // TTL return node's ttl
 func (m PeerMeta) TTL() time.Duration {
 	if m.Designated {
 		return DesignatedNodeTTL
-	} else {
-		return defaultTTL
+	}
+	return DefaultNodeTTL
+}
+
+// peerAddrForms is the description of address forms which parsePeerMeta accepts.
+const peerAddrForms = "multiaddr (/ip4/<ip>/tcp/<port>/p2p/<id>), <host>:<port>@<id>, <id>@<host>:<port> or <host>,<port>,<id>"
+
+// parsePeerMeta parses address of peer which contains peer id. Accepted forms are
+//   - full multiaddr, such as /ip4/172.21.11.12/tcp/7846/p2p/16Uiu2HAmHuBgtnisgPLbujFvxPNZw3Qvpk3VLUwTzh5C67LAZSFh .
+//     ipv6 address can be written in brackets, such as /ip6/[2001:db8::1]/tcp/7846/p2p/16Uiu2... ,
+//     and hostname can be used with dns protocol, such as /dns4/node1.example.com/tcp/7846/p2p/16Uiu2...
+//   - host:port@peerid, such as 172.21.11.12:7846@16Uiu2... or [2001:db8::1]:7846@16Uiu2...
+//   - peerid@host:port, such as 16Uiu2...@node1.example.com:7846
+//   - comma separated fields host,port,peerid, such as 172.21.11.12,7846,16Uiu2...
+//
+// hostname is kept in PeerMeta, and resolved on dialing. deprecated protocol name ipfs is also accepted instead of p2p.
+func parsePeerMeta(target string) (PeerMeta, error) {
+	target = strings.TrimSpace(target)
+	switch {
+	case strings.HasPrefix(target, "/"):
+		return parseMultiAddrPeerMeta(target)
+	case strings.Contains(target, "@"):
+		return parseAtSignPeerMeta(target)
+	case strings.Contains(target, ","):
+		fields := strings.Split(target, ",")
+		if len(fields) != 3 {
+			return PeerMeta{}, fmt.Errorf("expected 3 fields host,port,peerid but %d", len(fields))
+		}
+		return newPeerMetaFromFields(strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2]))
+	default:
+		return PeerMeta{}, fmt.Errorf("unknown address form, expected one of %s", peerAddrForms)
 	}
 }
+
+// hostProtocols are the multiaddr protocols of host component in address of peer.
+var hostProtocols = []int{ma.P_IP4, ma.P_IP6, madns.Dns4Protocol.Code, madns.Dns6Protocol.Code}
+
+// parseMultiAddrPeerMeta parses multiaddr which consists of exactly three components; ip4, ip6, dns4
+// or dns6 host, tcp port and p2p peer id. Components are extracted by protocol, not by the position
+// in the string, since an ip6 address or a hostname can take any form.
+func parseMultiAddrPeerMeta(target string) (PeerMeta, error) {
+	targetAddr, err := ma.NewMultiaddr(normalizeAddrString(target))
+	if err != nil {
+		return PeerMeta{}, err
+	}
+	if comps := ma.Split(targetAddr); len(comps) != 3 {
+		return PeerMeta{}, fmt.Errorf("expected host, tcp and p2p components but %d components", len(comps))
+	}
+	host, err := multiAddrHost(targetAddr)
+	if err != nil {
+		return PeerMeta{}, err
+	}
+	port, err := targetAddr.ValueForProtocol(ma.P_TCP)
+	if err != nil {
+		return PeerMeta{}, fmt.Errorf("tcp component is missing")
+	}
+	pid, err := targetAddr.ValueForProtocol(ma.P_IPFS)
+	if err != nil {
+		return PeerMeta{}, fmt.Errorf("p2p component is missing")
+	}
+	return newPeerMetaFromFields(host, port, pid)
+}
+
+// multiAddrHost returns the value of host component, which must be the first component of addr.
+func multiAddrHost(addr ma.Multiaddr) (string, error) {
+	first := addr.Protocols()[0]
+	for _, code := range hostProtocols {
+		if first.Code == code {
+			return addr.ValueForProtocol(code)
+		}
+	}
+	return "", fmt.Errorf("host component is missing, expected ip4, ip6, dns4 or dns6 but %s", first.Name)
+}
+
+// parseAtSignPeerMeta parses host:port@peerid or peerid@host:port
+func parseAtSignPeerMeta(target string) (PeerMeta, error) {
+	fields := strings.Split(target, "@")
+	if len(fields) != 2 {
+		return PeerMeta{}, fmt.Errorf("expected only one @ between host:port and peerid")
+	}
+	hostPort, pid := fields[0], fields[1]
+	// base58 encoded peer id never contains colon
+	if !strings.Contains(hostPort, ":") {
+		hostPort, pid = pid, hostPort
+	}
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return PeerMeta{}, fmt.Errorf("invalid host:port %s", hostPort)
+	}
+	return newPeerMetaFromFields(host, port, pid)
+}
+
+func newPeerMetaFromFields(host, port, pid string) (PeerMeta, error) {
+	peerPort, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return PeerMeta{}, fmt.Errorf("invalid port %s", port)
+	}
+	peerID, err := peer.IDB58Decode(pid)
+	if err != nil {
+		return PeerMeta{}, fmt.Errorf("invalid peer id %s", pid)
+	}
+	host, err = normalizeHost(host)
+	if err != nil {
+		return PeerMeta{}, err
+	}
+	return PeerMeta{ID: peerID, Port: uint32(peerPort), IPAddress: host}, nil
+}
+
+// normalizeHost returns canonical form of host, which is either ip address or dns hostname.
+// hostname is not resolved here, since ip address of it may be changed later.
+func normalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if len(host) == 0 {
+		return "", fmt.Errorf("empty host")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+	if !validHostname.MatchString(host) {
+		return "", fmt.Errorf("invalid hostname %s", host)
+	}
+	return strings.ToLower(strings.TrimSuffix(host, ".")), nil
+}
+
+// validHostname matches dns hostname consists of dot separated labels
+var validHostname = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
@@ -88,3 +88,129 @@ func TestPeerMeta_String(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePeerMeta(t *testing.T) {
+	pidString := "16Uiu2HAkvvhjxVm2WE9yFBDdPQ9qx6pX9taF6TTwDNHs8VPi1EeR"
+	pid, _ := peer.IDB58Decode(pidString)
+	tests := []struct {
+		name    string
+		target  string
+		ip      string
+		port    uint32
+		wantErr bool
+	}{
+		{"TIP4", "/ip4/172.21.11.12/tcp/7846/p2p/" + pidString, "172.21.11.12", 7846, false},
+		{"TIP4ipfs", "/ip4/172.21.11.12/tcp/7846/ipfs/" + pidString, "172.21.11.12", 7846, false},
+		{"TIP6", "/ip6/2001:db8::1/tcp/7846/p2p/" + pidString, "2001:db8::1", 7846, false},
+		{"TIP6Bracket", "/ip6/[2001:db8::1]/tcp/7846/p2p/" + pidString, "2001:db8::1", 7846, false},
+		{"TIP6Loopback", "/ip6/[::1]/tcp/7847/p2p/" + pidString, "::1", 7847, false},
+		{"TNoPeerID", "/ip4/172.21.11.12/tcp/7846", "", 0, true},
+		{"TNotTCP", "/ip4/172.21.11.12/udp/7846/p2p/" + pidString, "", 0, true},
+		{"TInvalidIP6", "/ip6/[2001:db8::zz]/tcp/7846/p2p/" + pidString, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parsePeerMeta(tt.target)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, pid, actual.ID)
+			assert.Equal(t, tt.ip, actual.IPAddress)
+			assert.Equal(t, tt.port, actual.Port)
+
+			// dialing address of parsed meta is same family
+			addr, err := toMultiAddr(actual.IPAddress, actual.Port)
+			assert.Nil(t, err)
+			assert.True(t, strings.HasPrefix(normalizeAddrString(tt.target), addr.String()))
+		})
+	}
+}
+
+func TestParsePeerMeta_forms(t *testing.T) {
+	pidString := "16Uiu2HAkvvhjxVm2WE9yFBDdPQ9qx6pX9taF6TTwDNHs8VPi1EeR"
+	pid, _ := peer.IDB58Decode(pidString)
+	tests := []struct {
+		name    string
+		target  string
+		ip      string
+		port    uint32
+		wantErr bool
+	}{
+		{"TMultiAddr", "/ip4/172.21.11.12/tcp/7846/p2p/" + pidString, "172.21.11.12", 7846, false},
+		{"TMultiAddrDNS", "/dns4/node1.example.com/tcp/7846/p2p/" + pidString, "node1.example.com", 7846, false},
+		{"TMultiAddrDNS6", "/dns6/Node6.Example.com/tcp/7846/p2p/" + pidString, "node6.example.com", 7846, false},
+		{"THostPortAtID", "172.21.11.12:7846@" + pidString, "172.21.11.12", 7846, false},
+		{"THostPortAtIDIP6", "[2001:db8::1]:7846@" + pidString, "2001:db8::1", 7846, false},
+		{"THostPortAtIDDNS", "node1.example.com:7846@" + pidString, "node1.example.com", 7846, false},
+		{"TIDAtHostPort", pidString + "@172.21.11.12:7846", "172.21.11.12", 7846, false},
+		{"TIDAtHostPortDNS", pidString + "@node6.example.com.:7847", "node6.example.com", 7847, false},
+		{"TFields", "172.21.11.12,7846," + pidString, "172.21.11.12", 7846, false},
+		{"TFieldsSpaces", " node1.example.com, 7846, " + pidString + " ", "node1.example.com", 7846, false},
+
+		{"TMalformed", "172.21.11.12/7846/" + pidString, "", 0, true},
+		{"TNoPort", "172.21.11.12@" + pidString, "", 0, true},
+		{"TBadPort", "172.21.11.12:78460@" + pidString, "", 0, true},
+		{"TTwoAtSigns", "172.21.11.12:7846@" + pidString + "@", "", 0, true},
+		{"TBadPeerID", "172.21.11.12:7846@notapeerid", "", 0, true},
+		{"TBadHostname", "node_1.example.com:7846@" + pidString, "", 0, true},
+		{"TEmptyHost", ",7846," + pidString, "", 0, true},
+		{"TTooManyFields", "172.21.11.12,7846," + pidString + ",extra", "", 0, true},
+		{"TDNSNotTCP", "/dns4/node1.example.com/udp/7846/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrIP6", "/ip6/2001:db8::1/tcp/7846/p2p/" + pidString, "2001:db8::1", 7846, false},
+		{"TMultiAddrIP6Full", "/ip6/2001:db8:0:0:0:0:0:1/tcp/7846/p2p/" + pidString, "2001:db8::1", 7846, false},
+		{"TMultiAddrNoTCP", "/ip4/172.21.11.12/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrNoHost", "/tcp/7846/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrNoPeerID", "/dns4/node1.example.com/tcp/7846", "", 0, true},
+		{"TMultiAddrExtra", "/ip4/172.21.11.12/tcp/7846/p2p/" + pidString + "/tcp/7847", "", 0, true},
+		{"TMultiAddrTwoHosts", "/ip4/172.21.11.12/ip4/172.21.11.13/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrBadIP4", "/ip4/172.21.11/tcp/7846/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrBadPort", "/ip4/172.21.11.12/tcp/78460/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrBadHostname", "/dns4/-node1.example.com/tcp/7846/p2p/" + pidString, "", 0, true},
+		{"TMultiAddrTrailing", "/ip4/172.21.11.12/tcp/7846/p2p/", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parsePeerMeta(tt.target)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, pid, actual.ID)
+			assert.Equal(t, tt.ip, actual.IPAddress)
+			assert.Equal(t, tt.port, actual.Port)
+		})
+	}
+}
+
+func TestPeerMeta_PeerAddress(t *testing.T) {
+	pid, _ := peer.IDB58Decode("16Uiu2HAkvvhjxVm2WE9yFBDdPQ9qx6pX9taF6TTwDNHs8VPi1EeR")
+	tests := []struct {
+		name     string
+		addr     string
+		hostname bool
+	}{
+		{"TIP4", "172.21.11.12", false},
+		{"TIP6", "2001:db8::1", false},
+		{"THostname", "node1.example.com", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := PeerMeta{IPAddress: tt.addr, Port: 7846, ID: pid}
+			assert.Equal(t, tt.hostname, meta.IsHostname())
+
+			pAddr := meta.ToPeerAddress()
+			if tt.hostname {
+				assert.Equal(t, tt.addr, pAddr.Host)
+				assert.Empty(t, pAddr.Address)
+			} else {
+				assert.Empty(t, pAddr.Host)
+				assert.True(t, net.ParseIP(tt.addr).Equal(net.IP(pAddr.Address)))
+			}
+			// hostname is preserved through the round trip
+			assert.Equal(t, meta, FromPeerAddress(&pAddr))
+		})
+	}
+}
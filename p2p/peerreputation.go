@@ -0,0 +1,186 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+// DefaultPeerReputationFile is the name of file in data directory, which keeps the scores of peers and banned
+// addresses, so that misbehaving peers are remembered after restart.
+const DefaultPeerReputationFile = "peer_reputation.json"
+
+const (
+	// maxStoredReputations is the maximum number of peers whose score is kept. The least recently updated one is
+	// forgotten first.
+	maxStoredReputations = 1000
+	// reputationHalfLife is the duration by which the score of peer decays half toward neutral.
+	reputationHalfLife = 6 * time.Hour
+	// reputationSaveInterval is the interval of saving reputations, so that they are not lost by crash.
+	reputationSaveInterval = 5 * time.Minute
+)
+
+// peerReputations keeps the scores of peers which were connected before, so that a peer starts from its previous
+// score when it connects again. Scores decay toward zero as time goes. nil peerReputations keeps nothing, and
+// peerReputations without path is kept only in memory.
+type peerReputations struct {
+	mutex   sync.Mutex
+	path    string
+	max     int
+	entries map[peer.ID]*reputation
+	// now is replaceable for test
+	now func() time.Time
+}
+
+type reputation struct {
+	score   int32
+	updated time.Time
+}
+
+// storedReputations is the form of reputations and banned addresses in file.
+type storedReputations struct {
+	Peers []storedPeerScore `json:"peers"`
+	Bans  []storedAddrBan   `json:"bans"`
+}
+
+type storedPeerScore struct {
+	ID      string    `json:"id"`
+	Score   int32     `json:"score"`
+	Updated time.Time `json:"updated"`
+}
+
+type storedAddrBan struct {
+	Addr  string    `json:"addr"`
+	Until time.Time `json:"until"`
+}
+
+func newPeerReputations(path string, max int) *peerReputations {
+	return &peerReputations{path: path, max: max, entries: make(map[peer.ID]*reputation), now: time.Now}
+}
+
+// decayScore returns the score decayed toward zero for elapsed time.
+func decayScore(score int32, elapsed time.Duration) int32 {
+	if elapsed <= 0 {
+		return score
+	}
+	return int32(float64(score) * math.Pow(0.5, float64(elapsed)/float64(reputationHalfLife)))
+}
+
+// record keeps the score of peer. Neutral score is not kept.
+func (pr *peerReputations) record(id peer.ID, score int32) {
+	if pr == nil {
+		return
+	}
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+	if score == 0 {
+		delete(pr.entries, id)
+		return
+	}
+	pr.entries[id] = &reputation{score: score, updated: pr.now()}
+	pr.evictOldest()
+}
+
+// evictOldest removes the least recently updated entries over max. It must be called with lock held.
+func (pr *peerReputations) evictOldest() {
+	for len(pr.entries) > pr.max {
+		var oldest peer.ID
+		var oldestTime time.Time
+		for id, entry := range pr.entries {
+			if oldestTime.IsZero() || entry.updated.Before(oldestTime) {
+				oldest, oldestTime = id, entry.updated
+			}
+		}
+		delete(pr.entries, oldest)
+	}
+}
+
+// score returns the decayed score of peer, which is zero for unknown peer.
+func (pr *peerReputations) score(id peer.ID) int32 {
+	if pr == nil {
+		return 0
+	}
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+	entry, found := pr.entries[id]
+	if !found {
+		return 0
+	}
+	return decayScore(entry.score, pr.now().Sub(entry.updated))
+}
+
+// load reads the reputations and the banned addresses into bl from file. Missing file is not an error.
+func (pr *peerReputations) load(bl *addrBlacklist) error {
+	if pr == nil || pr.path == "" {
+		return nil
+	}
+	dat, err := ioutil.ReadFile(pr.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var stored storedReputations
+	if err := json.Unmarshal(dat, &stored); err != nil {
+		return err
+	}
+
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+	for _, s := range stored.Peers {
+		id, err := peer.IDB58Decode(s.ID)
+		if err != nil || s.Score == 0 {
+			continue
+		}
+		pr.entries[id] = &reputation{score: s.Score, updated: s.Updated}
+	}
+	pr.evictOldest()
+	for _, ban := range stored.Bans {
+		bl.restoreBan(ban.Addr, ban.Until)
+	}
+	return nil
+}
+
+// save writes the reputations and the addresses banned in bl to file.
+func (pr *peerReputations) save(bl *addrBlacklist) error {
+	if pr == nil || pr.path == "" {
+		return nil
+	}
+	pr.mutex.Lock()
+	stored := storedReputations{
+		Peers: make([]storedPeerScore, 0, len(pr.entries)),
+	}
+	now := pr.now()
+	for id, entry := range pr.entries {
+		score := decayScore(entry.score, now.Sub(entry.updated))
+		if score == 0 {
+			continue
+		}
+		stored.Peers = append(stored.Peers, storedPeerScore{ID: peer.IDB58Encode(id), Score: score, Updated: now})
+	}
+	pr.mutex.Unlock()
+	for addr, until := range bl.bannedAddrs() {
+		stored.Bans = append(stored.Bans, storedAddrBan{Addr: addr, Until: until})
+	}
+
+	dat, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	// write to temporary file first, so that crash while writing does not break the previous one
+	tmp := pr.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, dat, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pr.path)
+}
@@ -0,0 +1,109 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	cfg "github.com/aergoio/aergo/config"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerReputations_Decay(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pr := newPeerReputations("", 2)
+	pr.now = func() time.Time { return now }
+
+	pr.record("bad", -80)
+	pr.record("good", 40)
+	assert.Equal(t, int32(-80), pr.score("bad"))
+	assert.Equal(t, int32(0), pr.score("unknown"))
+
+	now = now.Add(reputationHalfLife)
+	assert.Equal(t, int32(-40), pr.score("bad"))
+	assert.Equal(t, int32(20), pr.score("good"))
+	now = now.Add(reputationHalfLife * 10)
+	assert.Equal(t, int32(0), pr.score("bad"))
+
+	// the least recently updated is forgotten over the cap
+	pr.record("other", -10)
+	assert.Equal(t, int32(-10), pr.score("other"))
+	assert.Equal(t, 2, len(pr.entries))
+	assert.Equal(t, int32(0), pr.score("bad"))
+
+	// neutral score is not kept
+	pr.record("other", 0)
+	assert.Equal(t, 1, len(pr.entries))
+}
+
+func TestPeerManager_reputationSurvivesRestart(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "reputation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+	newPM := func() *peerManager {
+		conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+		conf.DataDir = dataDir
+		conf.P2P.NetProtocolAddr = "127.0.0.1"
+		return NewPeerManager(&MockActorService{}, conf, new(MockReconnectManager), logger).(*peerManager)
+	}
+	badMeta := PeerMeta{ID: dummyPeerID2, IPAddress: "172.21.11.12", Port: 7846}
+
+	first := newPM()
+	// evicted by low score
+	first.reputations.record(badMeta.ID, -90)
+	first.reputations.record(dummyPeerID3, -200)
+	first.addrBlacklist.ban(badMeta)
+	assert.Nil(t, first.Stop())
+	_, err = os.Stat(filepath.Join(dataDir, DefaultPeerReputationFile))
+	assert.Nil(t, err)
+
+	second := newPM()
+	defer second.Stop()
+	score := second.reputations.score(badMeta.ID)
+	assert.True(t, score < 0 && score >= -90, "score %d", score)
+	assert.True(t, second.addrBlacklist.banned(badMeta))
+	assert.Equal(t, int32(0), second.reputations.score(peer.ID("unknown")))
+	// the peer restored below the minimum score is refused
+	assert.False(t, second.lowReputation(badMeta.ID))
+	assert.True(t, second.lowReputation(dummyPeerID3))
+	assert.False(t, second.tryAddInboundPeer(PeerMeta{ID: dummyPeerID3}, nil, aergoP2PSub))
+}
+
+func TestPeerManager_saveReputations(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "reputation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+	path := filepath.Join(dataDir, DefaultPeerReputationFile)
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, remotePeers: make(map[peer.ID]*RemotePeer),
+		addrBlacklist: newAddrBlacklist(handshakeFailThreshold), reputations: newPeerReputations(path, maxStoredReputations)}
+	connected := newRemotePeer(PeerMeta{ID: dummyPeerID}, pm, &MockActorService{}, logger)
+	connected.score = -50
+	pm.remotePeers[dummyPeerID] = connected
+
+	// the scores of connected peers are saved without stopping, so that they are not lost by crash
+	pm.saveReputations()
+	restored := newPeerReputations(path, maxStoredReputations)
+	assert.Nil(t, restored.load(newAddrBlacklist(handshakeFailThreshold)))
+	score := restored.score(dummyPeerID)
+	assert.True(t, score < -40 && score >= -50, "score %d", score)
+}
+
+func TestPeerManager_reputationWithoutDataDir(t *testing.T) {
+	conf := cfg.NewServerContext("", "").GetDefaultConfig().(*cfg.Config)
+	conf.DataDir = ""
+	conf.P2P.NetProtocolAddr = "127.0.0.1"
+	pm := NewPeerManager(&MockActorService{}, conf, new(MockReconnectManager), logger).(*peerManager)
+
+	// score of disconnected peer is kept in memory, so that it does not start over by reconnecting
+	pm.reputations.record(dummyPeerID2, -200)
+	assert.True(t, pm.lowReputation(dummyPeerID2))
+	assert.Nil(t, pm.reputations.save(pm.addrBlacklist))
+}
@@ -0,0 +1,56 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"sync/atomic"
+)
+
+// DefaultMinPeerScore is the default score under which peer is disconnected.
+const DefaultMinPeerScore = -100
+
+// score of peer starts from zero, and changes by the behavior of peer.
+const (
+	maxPeerScore = 100
+
+	usefulResponseReward   = 1
+	authFailurePenalty     = -10
+	pingTimeoutPenalty     = -10
+	invalidResponsePenalty = -20
+	invalidNoticePenalty   = -20
+)
+
+// Score returns current score of peer.
+func (p *RemotePeer) Score() int32 {
+	return atomic.LoadInt32(&p.score)
+}
+
+// adjustScore adds delta to the score of peer, and disconnects the peer with reason if the score is below the
+// minimum, even if it was already below, such as restored from the previous run. It returns the updated score.
+// The peer is disconnected asynchronously, since it is called also from the run loop of peer, which the peer
+// manager may be waiting for to stop the peer.
+func (p *RemotePeer) adjustScore(delta int32, reason DisconnectReason) int32 {
+	for {
+		old := atomic.LoadInt32(&p.score)
+		updated := old + delta
+		if updated > maxPeerScore {
+			updated = maxPeerScore
+		}
+		if !atomic.CompareAndSwapInt32(&p.score, old, updated) {
+			continue
+		}
+		if updated < p.minScore {
+			p.log.Info().Str(LogPeerID, p.meta.ID.Pretty()).Int32("score", updated).Msg("Evicting peer by low score")
+			go p.ps.DisconnectPeer(p.meta.ID, reason)
+		}
+		return updated
+	}
+}
+
+// evicted returns true if the peer is disconnected by low score.
+func (p *RemotePeer) evicted() bool {
+	return p.Score() < p.minScore
+}
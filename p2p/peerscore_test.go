@@ -0,0 +1,151 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/types"
+	"github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRemotePeer_adjustScore(t *testing.T) {
+	mockPM := &MockP2PService{}
+	disconnected := make(chan DisconnectReason, 10)
+	mockPM.On("DisconnectPeer", dummyPeerID, mock.AnythingOfType("DisconnectReason")).Run(
+		func(args mock.Arguments) { disconnected <- args.Get(1).(DisconnectReason) }).Return()
+	waitDisconnected := func() DisconnectReason {
+		select {
+		case reason := <-disconnected:
+			return reason
+		case <-time.After(time.Second):
+			t.Fatal("peer is not disconnected")
+			return 0
+		}
+	}
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, &MockActorService{}, logger)
+	target.minScore = -25
+
+	// score is capped
+	for i := 0; i < maxPeerScore+10; i++ {
+		target.adjustScore(usefulResponseReward, 0)
+	}
+	assert.Equal(t, int32(maxPeerScore), target.Score())
+
+	target.score = 0
+	assert.Equal(t, int32(-10), target.adjustScore(pingTimeoutPenalty, Timeout))
+	assert.Equal(t, int32(-20), target.adjustScore(authFailurePenalty, ProtocolViolation))
+	mockPM.AssertNotCalled(t, "DisconnectPeer", mock.Anything, mock.Anything)
+	assert.False(t, target.evicted())
+
+	assert.Equal(t, int32(-30), target.adjustScore(pingTimeoutPenalty, Timeout))
+	assert.True(t, target.evicted())
+	assert.Equal(t, Timeout, waitDisconnected())
+	// disconnected again while the score is below the minimum, even if it is increased
+	target.adjustScore(usefulResponseReward, 0)
+	waitDisconnected()
+
+	// so is the peer whose score was restored below the minimum
+	target.score = -200
+	target.adjustScore(usefulResponseReward, 0)
+	waitDisconnected()
+	mockPM.AssertNumberOfCalls(t, "DisconnectPeer", 3)
+}
+
+func TestRemotePeer_evictOnPingWhileRemoving(t *testing.T) {
+	mockActorServ := new(MockActorService)
+	mockActorServ.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock"), mock.Anything).
+		Return(message.GetBestBlockRsp{Block: &types.Block{Header: &types.BlockHeader{}}}, nil)
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, remotePeers: make(map[peer.ID]*RemotePeer),
+		removePeerChannel: make(chan peer.ID), finishChannel: make(chan struct{})}
+	defer close(pm.finishChannel)
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, pm, mockActorServ, logger)
+	target.minScore = pingTimeoutPenalty + 1
+	pm.remotePeers[dummyPeerID] = target
+	go func() {
+		for range target.write {
+		}
+	}()
+
+	// the peer manager removing the peer holds its mutex until the run loop of peer takes the stop signal
+	pm.mutex.Lock()
+	removed := make(chan struct{})
+	go func() {
+		defer close(removed)
+		defer pm.mutex.Unlock()
+		target.stop()
+	}()
+	// meanwhile the run loop evicts the peer by ping timeout, before it takes the stop signal
+	go func() {
+		atomic.StoreInt64(&target.pingSentAt, 1)
+		target.sendPing()
+		<-target.stopChan
+	}()
+	select {
+	case <-removed:
+	case <-time.After(time.Second * 3):
+		t.Fatal("eviction deadlocks with removal of the peer")
+	}
+	assert.True(t, target.evicted())
+}
+
+func TestPeerManager_afterPeerRemoved(t *testing.T) {
+	designated := PeerMeta{ID: dummyPeerID, IPAddress: "192.168.0.1", Port: 7846, Designated: true}
+	discovered := PeerMeta{ID: dummyPeerID2, IPAddress: "192.168.0.2", Port: 7846}
+	tests := []struct {
+		name      string
+		meta      PeerMeta
+		score     int32
+		reason    DisconnectReason
+		reconnect bool
+		banned    bool
+	}{
+		{"TDiscovered", discovered, 0, BrokenConnection, true, false},
+		{"TEvicted", discovered, -200, ProtocolViolation, false, true},
+		{"TViolation", discovered, 0, ProtocolViolation, false, true},
+		{"TDesignatedEvicted", designated, -200, ProtocolViolation, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRM := new(MockReconnectManager)
+			mockRM.On("AddJob", mock.AnythingOfType("PeerMeta")).Return()
+			pm := &peerManager{log: logger, mutex: &sync.Mutex{}, rm: mockRM, addrBlacklist: newAddrBlacklist(handshakeFailThreshold),
+				designatedPeers: map[peer.ID]PeerMeta{designated.ID: designated}}
+			target := newRemotePeer(tt.meta, pm, &MockActorService{}, logger)
+			target.score = tt.score
+			target.setDisconnectReason(tt.reason)
+
+			pm.afterPeerRemoved(target)
+			if tt.reconnect {
+				mockRM.AssertCalled(t, "AddJob", tt.meta)
+			} else {
+				mockRM.AssertNotCalled(t, "AddJob", mock.Anything)
+			}
+			assert.Equal(t, tt.banned, pm.addrBlacklist.banned(tt.meta))
+		})
+	}
+}
+
+func TestRemotePeer_consumeRequest(t *testing.T) {
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, &MockP2PService{}, &MockActorService{}, logger)
+	req := newPbMsgRequestOrder(true, false, addressesRequest, &types.AddressesRequest{MessageData: &types.MessageData{}})
+	target.requests[req.GetRequestID()] = req
+
+	// unsolicited response earns nothing
+	assert.Nil(t, target.consumeRequest("unknown"))
+	assert.Equal(t, int32(0), target.Score())
+
+	assert.Equal(t, req, target.consumeRequest(req.GetRequestID()))
+	assert.Equal(t, int32(usefulResponseReward), target.Score())
+	// nor does duplicated one
+	assert.Nil(t, target.consumeRequest(req.GetRequestID()))
+	assert.Equal(t, int32(usefulResponseReward), target.Score())
+}
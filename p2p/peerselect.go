@@ -0,0 +1,64 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/aergoio/aergo/types"
+)
+
+const (
+	// latencyTolerance is the ratio of latency difference, in which peers are regarded as equally fast.
+	latencyTolerance = 0.2
+	// unmeasuredLatency is used for the peer which latency is not measured yet.
+	unmeasuredLatency = defaultPingInterval
+)
+
+type peerCost struct {
+	peer *RemotePeer
+	cost time.Duration
+}
+
+// requestCost estimates how long the peer will take to respond. Latency is penalized by fail count of the peer.
+func (p *RemotePeer) requestCost() time.Duration {
+	latency := p.Latency()
+	if latency <= 0 {
+		latency = unmeasuredLatency
+	}
+	return latency * time.Duration(1+atomic.LoadUint32(&p.failCounter))
+}
+
+// SelectPeerForRequest selects a running peer which has lowest latency and fail count.
+// Peers which cost is within tolerance of the best one are selected in round-robin manner.
+func (ps *peerManager) SelectPeerForRequest() (*RemotePeer, bool) {
+	peers := ps.GetPeers()
+	candidates := make([]peerCost, 0, len(peers))
+	for _, aPeer := range peers {
+		if aPeer.State() == types.RUNNING {
+			candidates = append(candidates, peerCost{peer: aPeer, cost: aPeer.requestCost()})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].cost != candidates[j].cost {
+			return candidates[i].cost < candidates[j].cost
+		}
+		return ComparePeerID(candidates[i].peer.ID(), candidates[j].peer.ID()) < 0
+	})
+
+	limit := candidates[0].cost + time.Duration(float64(candidates[0].cost)*latencyTolerance)
+	bestCnt := 1
+	for bestCnt < len(candidates) && candidates[bestCnt].cost <= limit {
+		bestCnt++
+	}
+	idx := atomic.AddUint32(&ps.selectCounter, 1) % uint32(bestCnt)
+	return candidates[idx].peer, true
+}
@@ -0,0 +1,78 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/types"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerManager_SelectPeerForRequest(t *testing.T) {
+	mockActorServ := &MockActorService{}
+	newTestPeer := func(id string, latency time.Duration, state types.PeerState) *RemotePeer {
+		p := newRemotePeer(PeerMeta{ID: peer.ID(id)}, nil, mockActorServ, logger)
+		p.latency = int64(latency)
+		p.setState(state)
+		return p
+	}
+	tests := []struct {
+		name     string
+		peers    []*RemotePeer
+		expected []peer.ID
+	}{
+		{"TEmpty", []*RemotePeer{}, nil},
+		{"TNotRunning", []*RemotePeer{newTestPeer("slow", time.Millisecond*100, types.HANDSHAKING)}, nil},
+		{"TFastest", []*RemotePeer{newTestPeer("slow", time.Millisecond*300, types.RUNNING),
+			newTestPeer("fast", time.Millisecond*10, types.RUNNING),
+			newTestPeer("mid", time.Millisecond*100, types.RUNNING)}, []peer.ID{"fast"}},
+		{"TSkipStopped", []*RemotePeer{newTestPeer("slow", time.Millisecond*300, types.RUNNING),
+			newTestPeer("fast", time.Millisecond*10, types.STOPPED)}, []peer.ID{"slow"}},
+		{"TUnmeasured", []*RemotePeer{newTestPeer("unknown", 0, types.RUNNING),
+			newTestPeer("slow", time.Millisecond*300, types.RUNNING)}, []peer.ID{"slow"}},
+		{"TRoundRobin", []*RemotePeer{newTestPeer("slow", time.Millisecond*300, types.RUNNING),
+			newTestPeer("fast1", time.Millisecond*10, types.RUNNING),
+			newTestPeer("fast2", time.Millisecond*11, types.RUNNING)}, []peer.ID{"fast1", "fast2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := &peerManager{mutex: &sync.Mutex{}, peerCache: tt.peers}
+			if len(tt.expected) == 0 {
+				_, found := pm.SelectPeerForRequest()
+				assert.False(t, found)
+				return
+			}
+			selected := make(map[peer.ID]int)
+			for i := 0; i < len(tt.expected)*10; i++ {
+				actual, found := pm.SelectPeerForRequest()
+				assert.True(t, found)
+				selected[actual.ID()]++
+			}
+			assert.Equal(t, len(tt.expected), len(selected))
+			for _, id := range tt.expected {
+				assert.Equal(t, 10, selected[id])
+			}
+		})
+	}
+}
+
+func TestPeerManager_SelectPeerForRequestFailCount(t *testing.T) {
+	fast := newRemotePeer(PeerMeta{ID: peer.ID("fast")}, nil, &MockActorService{}, logger)
+	fast.latency = int64(time.Millisecond * 10)
+	fast.failCounter = 100
+	fast.setState(types.RUNNING)
+	slow := newRemotePeer(PeerMeta{ID: peer.ID("slow")}, nil, &MockActorService{}, logger)
+	slow.latency = int64(time.Millisecond * 100)
+	slow.setState(types.RUNNING)
+
+	pm := &peerManager{mutex: &sync.Mutex{}, peerCache: []*RemotePeer{fast, slow}}
+	actual, found := pm.SelectPeerForRequest()
+	assert.True(t, found)
+	assert.Equal(t, slow.ID(), actual.ID())
+}
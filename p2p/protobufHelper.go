@@ -118,6 +118,8 @@ func (pr *pbMessageOrder) SignWith(ps PeerManager) error {
 	messageData := pr.message.GetMessageData()
 	messageData.PeerID = peer.IDB58Encode(ps.SelfNodeID())
 	messageData.NodePubKey, _ = ps.PublicKey().Bytes()
+	// signature of previous sending must not be signed again
+	messageData.Sign = nil
 	signature, err := ps.SignProtoMessage(pr.message)
 	if err != nil {
 		return err
@@ -130,11 +132,7 @@ func (pr *pbMessageOrder) SignWith(ps PeerManager) error {
 
 // SendOver is send itself over the writer rw.
 func (pr *pbMessageOrder) SendOver(rw *bufio.ReadWriter) error {
-	err := SendProtoMessage(pr.message, rw)
-	if err == nil {
-		rw.Flush()
-	}
-	return err
+	return SendProtoMessage(pr.message, rw)
 }
 
 // NewMessageData is helper method - generate message data shared between all node's p2p protocols
@@ -158,8 +156,8 @@ func SendProtoMessage(data proto.Message, rw *bufio.ReadWriter) error {
 	if err != nil {
 		return err
 	}
-	rw.Flush()
-	return nil
+	// small messages are written to the connection only on flush, so that a broken connection is detected by it
+	return rw.Flush()
 }
 
 // SignProtoMessage sign protocol buffer messge by privKey
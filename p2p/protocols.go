@@ -26,6 +26,8 @@ const (
 	getMissingRequest
 	getMissingResponse
 	newBlockNotice
+	getBlockRangeRequest
+	getBlockRangeResponse
 )
 const (
 	getTXsRequest SubProtocol = 0x020 + iota
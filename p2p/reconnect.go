@@ -12,17 +12,38 @@ import (
 	"github.com/aergoio/aergo-lib/log"
 )
 
-var durations []time.Duration
+// Default backoff schedule of reconnecting. The intervals are
+// [20s 36s 1m6s 2m1s 3m40s 6m42s 12m12s 22m14s 40m30s 1h13m48s 2h14m29s 4h5m2s 7h26m29s 13h33m32s 24h42m21s]
+const (
+	DefaultReconnectInitialSec = 20
+	DefaultReconnectIncrease   = 0.6
+	DefaultReconnectMaxTrial   = 15
+)
 
-var maxTrial = 15
+// reconnectPolicy is the policy of reconnecting to the disconnected peer.
+type reconnectPolicy struct {
+	// maxTrials is the number of trials before giving up. Negative value means retrying indefinitely.
+	maxTrials int
+	// intervals is the backoff schedule of trials. The last interval is used for trials after the schedule.
+	intervals []time.Duration
+}
 
-func init() {
-	// It will get [20s 36s 1m6s 2m1s 3m40s 6m42s 12m12s 22m14s 40m30s 1h13m48s 2h14m29s 4h5m2s 7h26m29s 13h33m32s 24h42m21s]
-	durations = generateExpDuration(20, 0.6, maxTrial)
+// exhausted returns true if no more trial is allowed after trial times of trials.
+func (p reconnectPolicy) exhausted(trial int) bool {
+	return p.maxTrials >= 0 && trial >= p.maxTrials
+}
+
+// interval returns the waiting time before the next trial, after trial times of trials.
+func (p reconnectPolicy) interval(trial int) time.Duration {
+	if trial < len(p.intervals) {
+		return p.intervals[trial]
+	}
+	return p.intervals[len(p.intervals)-1]
 }
 
 type reconnectJob struct {
 	meta   PeerMeta
+	policy reconnectPolicy
 	trial  int
 	rm     ReconnectManager
 	pm     PeerManager
@@ -31,11 +52,11 @@ type reconnectJob struct {
 	cancel chan struct{}
 }
 
-func newReconnectRunner(meta PeerMeta, rm ReconnectManager, pm PeerManager, logger *log.Logger) *reconnectJob {
-	return &reconnectJob{meta: meta, trial: 0, rm: rm, pm: pm, cancel: make(chan struct{}, 1), logger: logger}
+func newReconnectRunner(meta PeerMeta, policy reconnectPolicy, rm ReconnectManager, pm PeerManager, logger *log.Logger) *reconnectJob {
+	return &reconnectJob{meta: meta, policy: policy, trial: 0, rm: rm, pm: pm, cancel: make(chan struct{}, 1), logger: logger}
 }
 func (rr *reconnectJob) runJob() {
-	timer := time.NewTimer(getNextInterval(rr.trial))
+	timer := time.NewTimer(rr.policy.interval(rr.trial))
 RETRYLOOP:
 	for {
 		// wait for duration
@@ -45,10 +66,15 @@ RETRYLOOP:
 			if found {
 				break RETRYLOOP
 			}
+			if rr.policy.exhausted(rr.trial) {
+				rr.logger.Info().Str(LogPeerID, rr.meta.ID.Pretty()).Int("trial", rr.trial).Msg("Giving up reconnecting to peer")
+				rr.pm.RemovePeerFromPool(rr.meta.ID)
+				break RETRYLOOP
+			}
 			rr.logger.Debug().Str(LogPeerID, rr.meta.ID.Pretty()).Int("trial", rr.trial).Msg("Trying to connect")
 			rr.pm.AddNewPeer(rr.meta)
 			rr.trial++
-			timer.Reset(getNextInterval(rr.trial))
+			timer.Reset(rr.policy.interval(rr.trial))
 		case <-rr.cancel:
 			break RETRYLOOP
 		}
@@ -56,13 +82,6 @@ RETRYLOOP:
 	rr.rm.jobFinished(rr.meta.ID)
 }
 
-func getNextInterval(trial int) time.Duration {
-	if trial < maxTrial {
-		return durations[trial]
-	}
-	return durations[maxTrial-1]
-}
-
 func generateExpDuration(initSecs int, inc float64, count int) []time.Duration {
 	durations := make([]time.Duration, 0, count)
 	num := float64(0)
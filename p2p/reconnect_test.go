@@ -26,15 +26,12 @@ func init() {
 }
 func Test_reconnectRunner_runReconnect(t *testing.T) {
 	logger := log.NewLogger("test.p2p")
-	// TODO: is it ok that this global var can be changed.
-	durations = []time.Duration{
+	policy := reconnectPolicy{maxTrials: -1, intervals: []time.Duration{
 		time.Millisecond * 100,
 		time.Millisecond * 120,
 		time.Millisecond * 130,
 		time.Millisecond * 150,
-	}
-	trials := len(durations)
-	maxTrial = trials
+	}}
 	mockPm := &MockP2PService{}
 	dummyPeer := &RemotePeer{}
 	mockPm.On("GetPeer", mock.MatchedBy(func(ID peer.ID) bool { return ID == dummyPeerID })).Return(nil, false)
@@ -62,7 +59,7 @@ func Test_reconnectRunner_runReconnect(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			rr := newReconnectRunner(tt.meta, dummyRM, tt.pm, logger)
+			rr := newReconnectRunner(tt.meta, policy, dummyRM, tt.pm, logger)
 			rr.runJob()
 			tt.pm.AssertNumberOfCalls(t, "GetPeer", tt.lookupCount)
 			tt.pm.AssertNumberOfCalls(t, "AddNewPeer", tt.addCount)
@@ -71,7 +68,7 @@ func Test_reconnectRunner_runReconnect(t *testing.T) {
 	}
 
 	// testb infinity
-	rr := newReconnectRunner(PeerMeta{ID: dummyPeerID}, dummyRM, mockPm, logger)
+	rr := newReconnectRunner(PeerMeta{ID: dummyPeerID}, policy, dummyRM, mockPm, logger)
 	dummyRM.jobs[dummyPeerID] = rr
 	go func() {
 		time.Sleep(time.Second)
@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"sync"
+	"time"
 
 	"github.com/aergoio/aergo-lib/log"
 	peer "github.com/libp2p/go-libp2p-peer"
@@ -12,24 +13,62 @@ type ReconnectManager interface {
 	AddJob(meta PeerMeta)
 	// CancelJob cancel from outer module to reconnectRunner
 	CancelJob(pid peer.ID)
+	// Jobs returns metas of peers which are waiting to be reconnected.
+	Jobs() []PeerMeta
 	// jobFinished remove reconnectRunner, which finish job for itself.
 	jobFinished(pid peer.ID)
 
 	Stop()
 }
 
+// DefaultDiscoveredRetry is the default number of reconnect trials to the peer discovered from other peers.
+const DefaultDiscoveredRetry = 3
+
 type reconnectManager struct {
 	pm     PeerManager
 	logger *log.Logger
 	mutex  *sync.Mutex
 
 	jobs map[peer.ID]*reconnectJob
+
+	// designatedPolicy is applied to designated peers, and discoveredPolicy is to other peers.
+	designatedPolicy reconnectPolicy
+	discoveredPolicy reconnectPolicy
+	// intervals is the backoff schedule shared by both policies
+	intervals []time.Duration
 }
 
 // NewReconnectManager create partial-inited manager for reconnect peer.
 // Note: it returns incomplete object, caller should set peerManager before using this.
 func NewReconnectManager(logger *log.Logger) *reconnectManager {
-	return &reconnectManager{mutex: &sync.Mutex{}, jobs: make(map[peer.ID]*reconnectJob), logger: logger}
+	return &reconnectManager{mutex: &sync.Mutex{}, jobs: make(map[peer.ID]*reconnectJob), logger: logger,
+		designatedPolicy: reconnectPolicy{maxTrials: -1}, discoveredPolicy: reconnectPolicy{maxTrials: DefaultDiscoveredRetry},
+		intervals: generateExpDuration(DefaultReconnectInitialSec, DefaultReconnectIncrease, DefaultReconnectMaxTrial)}
+}
+
+// setPolicies sets the numbers of reconnect trials of designated and discovered peers.
+func (rm *reconnectManager) setPolicies(designatedTrials, discoveredTrials int) {
+	rm.designatedPolicy = reconnectPolicy{maxTrials: designatedTrials}
+	rm.discoveredPolicy = reconnectPolicy{maxTrials: discoveredTrials}
+}
+
+// setBackoff sets the backoff schedule of reconnecting, which starts from initSecs and increases exponentially
+// by the rate of inc until count times of trials. Invalid values are replaced by the default.
+func (rm *reconnectManager) setBackoff(initSecs int, inc float64, count int) {
+	if initSecs <= 0 || inc < 0 || count <= 0 {
+		rm.logger.Warn().Int("initsec", initSecs).Float64("increase", inc).Int("maxtrial", count).Msg("Invalid reconnect backoff, using default")
+		initSecs, inc, count = DefaultReconnectInitialSec, DefaultReconnectIncrease, DefaultReconnectMaxTrial
+	}
+	rm.intervals = generateExpDuration(initSecs, inc, count)
+}
+
+func (rm *reconnectManager) policyOf(meta PeerMeta) reconnectPolicy {
+	policy := rm.discoveredPolicy
+	if meta.Designated {
+		policy = rm.designatedPolicy
+	}
+	policy.intervals = rm.intervals
+	return policy
 }
 
 func (rm *reconnectManager) AddJob(meta PeerMeta) {
@@ -38,8 +77,12 @@ func (rm *reconnectManager) AddJob(meta PeerMeta) {
 	if _, exist := rm.jobs[meta.ID]; exist {
 		return
 	}
-	rm.logger.Debug().Str(LogPeerID, meta.ID.Pretty()).Msg("Starting reconnect job")
-	jobRunner := newReconnectRunner(meta, rm, rm.pm, rm.logger)
+	policy := rm.policyOf(meta)
+	if policy.maxTrials == 0 {
+		return
+	}
+	rm.logger.Debug().Str(LogPeerID, meta.ID.Pretty()).Bool("designated", meta.Designated).Msg("Starting reconnect job")
+	jobRunner := newReconnectRunner(meta, policy, rm, rm.pm, rm.logger)
 	go jobRunner.runJob()
 	rm.jobs[meta.ID] = jobRunner
 }
@@ -57,6 +100,16 @@ func (rm *reconnectManager) CancelJob(pid peer.ID) {
 	job.cancel <- struct{}{}
 }
 
+func (rm *reconnectManager) Jobs() []PeerMeta {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	metas := make([]PeerMeta, 0, len(rm.jobs))
+	for _, job := range rm.jobs {
+		metas = append(metas, job.meta)
+	}
+	return metas
+}
+
 func (rm *reconnectManager) Stop() {
 	rm.mutex.Lock()
 	keys := make([]peer.ID, len(rm.jobs))
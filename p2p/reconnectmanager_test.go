@@ -12,15 +12,12 @@ import (
 
 func Test_reconnectManager_AddJob(t *testing.T) {
 	logger := log.NewLogger("test.p2p")
-	// TODO: is it ok that this global var can be changed.
-	durations = []time.Duration{
+	intervals := []time.Duration{
 		time.Millisecond * 100,
 		time.Millisecond * 120,
 		time.Millisecond * 130,
 		time.Millisecond * 150,
 	}
-	trials := len(durations)
-	maxTrial = trials
 
 	dummyMeta := PeerMeta{ID: dummyPeerID}
 	dummyMeta2 := PeerMeta{ID: dummyPeerID2}
@@ -51,6 +48,7 @@ func Test_reconnectManager_AddJob(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			rm := NewReconnectManager(logger)
 			rm.pm = mockPm
+			rm.intervals = intervals
 			rm.AddJob(dummyMeta)
 			rm.AddJob(dummyMeta)
 			rm.AddJob(dummyMeta)
@@ -70,3 +68,80 @@ func Test_reconnectManager_AddJob(t *testing.T) {
 		})
 	}
 }
+
+func Test_reconnectManager_policy(t *testing.T) {
+	logger := log.NewLogger("test.p2p")
+
+	mockPm := &MockP2PService{}
+	mockPm.On("GetPeer", mock.AnythingOfType("peer.ID")).Return(nil, false)
+	mockPm.On("AddNewPeer", mock.AnythingOfType("p2p.PeerMeta"))
+	mockPm.On("RemovePeerFromPool", mock.AnythingOfType("peer.ID"))
+
+	rm := NewReconnectManager(logger)
+	rm.pm = mockPm
+	rm.setPolicies(-1, 2)
+	rm.intervals = []time.Duration{
+		time.Millisecond * 20,
+		time.Millisecond * 30,
+	}
+	designated := PeerMeta{ID: dummyPeerID, Designated: true}
+	discovered := PeerMeta{ID: dummyPeerID2}
+	rm.AddJob(designated)
+	rm.AddJob(discovered)
+
+	// discovered peer is dropped after bounded retries
+	assert.True(t, waitUntil(func() bool {
+		rm.mutex.Lock()
+		defer rm.mutex.Unlock()
+		_, exist := rm.jobs[discovered.ID]
+		return !exist
+	}, time.Second))
+	// while designated peer keeps retrying
+	time.Sleep(time.Millisecond * 200)
+	rm.mutex.Lock()
+	_, exist := rm.jobs[designated.ID]
+	rm.mutex.Unlock()
+	assert.True(t, exist)
+	rm.Stop()
+	time.Sleep(time.Millisecond * 50)
+
+	addCount := make(map[peer.ID]int)
+	for _, call := range mockPm.Calls {
+		if call.Method == "AddNewPeer" {
+			addCount[call.Arguments.Get(0).(PeerMeta).ID]++
+		}
+	}
+	assert.Equal(t, 2, addCount[discovered.ID])
+	assert.True(t, addCount[designated.ID] > 2, "designated peer retried %d times", addCount[designated.ID])
+	mockPm.AssertCalled(t, "RemovePeerFromPool", discovered.ID)
+	mockPm.AssertNotCalled(t, "RemovePeerFromPool", designated.ID)
+
+	// reconnecting to discovered peer can be disabled
+	rm.setPolicies(-1, 0)
+	rm.AddJob(discovered)
+	assert.Equal(t, 0, len(rm.jobs))
+}
+
+func Test_reconnectManager_setBackoff(t *testing.T) {
+	logger := log.NewLogger("test.p2p")
+	rm := NewReconnectManager(logger)
+	assert.Equal(t, DefaultReconnectMaxTrial, len(rm.intervals))
+	assert.Equal(t, time.Second*DefaultReconnectInitialSec, rm.intervals[0])
+
+	rm.setBackoff(2, 0, 3)
+	assert.Equal(t, []time.Duration{time.Second * 2, time.Second * 2, time.Second * 2}, rm.intervals)
+	rm.setBackoff(1, 0.6, 4)
+	policy := rm.policyOf(PeerMeta{ID: dummyPeerID, Designated: true})
+	assert.Equal(t, -1, policy.maxTrials)
+	assert.Equal(t, rm.intervals, policy.intervals)
+	// interval stops increasing after the schedule
+	assert.Equal(t, rm.intervals[3], policy.interval(3))
+	assert.Equal(t, rm.intervals[3], policy.interval(100))
+	assert.True(t, policy.interval(2) < policy.interval(3))
+
+	// invalid values are replaced by default
+	rm.setBackoff(0, 0.6, 4)
+	assert.Equal(t, DefaultReconnectMaxTrial, len(rm.intervals))
+	rm.setBackoff(1, 0.6, 0)
+	assert.Equal(t, DefaultReconnectMaxTrial, len(rm.intervals))
+}
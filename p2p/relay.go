@@ -0,0 +1,89 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	libp2p "github.com/libp2p/go-libp2p"
+	circuit "github.com/libp2p/go-libp2p-circuit"
+	inet "github.com/libp2p/go-libp2p-net"
+	peer "github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// relayOptions returns libp2p host options about circuit relay.
+// If EnableRelay is set, this node relays connections of other peers (hop).
+// If only relay addresses are set, this node just can dial to or be dialed from other peers via relays.
+func (ps *peerManager) relayOptions() []libp2p.Option {
+	if ps.conf.EnableRelay {
+		return []libp2p.Option{libp2p.EnableRelay(circuit.OptHop)}
+	} else if len(ps.relayPeers) > 0 {
+		return []libp2p.Option{libp2p.EnableRelay()}
+	}
+	return nil
+}
+
+// addRelayPeers parses relay addresses in config. The address must be full multiaddr which contains peer id,
+// such as /ip4/172.21.11.12/tcp/7846/p2p/16Uiu2HAmHuBgtnisgPLbujFvxPNZw3Qvpk3VLUwTzh5C67LAZSFh
+func (ps *peerManager) addRelayPeers() {
+	for _, target := range ps.conf.RelayAddrs {
+		targetAddr, err := ma.NewMultiaddr(normalizeAddrString(target))
+		if err != nil {
+			ps.log.Warn().Err(err).Str("target", target).Msg("invalid relay address")
+			continue
+		}
+		relayInfo, err := pstore.InfoFromP2pAddr(targetAddr)
+		if err != nil {
+			ps.log.Warn().Err(err).Str("target", target).Msg("invalid relay address")
+			continue
+		}
+		ps.log.Info().Str(LogPeerID, relayInfo.ID.Pretty()).Str("addr", target).Msg("Adding relay peer")
+		ps.relayPeers = append(ps.relayPeers, *relayInfo)
+	}
+}
+
+// newStreamViaRelay try to open a stream to peer through relay peers, in order of config.
+// It is used as fallback when the peer cannot be dialed directly.
+func (ps *peerManager) newStreamViaRelay(ctx context.Context, peerID peer.ID, pids ...protocol.ID) (inet.Stream, error) {
+	if len(ps.relayPeers) == 0 {
+		return nil, fmt.Errorf("no relay peer")
+	}
+	var lastErr error
+	for _, relayInfo := range ps.relayPeers {
+		if relayInfo.ID == peerID {
+			continue
+		}
+		if err := ps.Connect(ctx, relayInfo); err != nil {
+			ps.log.Debug().Err(err).Str("relay", relayInfo.ID.Pretty()).Msg("Failed to connect relay peer")
+			lastErr = err
+			continue
+		}
+		circuitAddr, err := ma.NewMultiaddr(fmt.Sprintf("/p2p-circuit/ipfs/%s", peerID.Pretty()))
+		if err != nil {
+			return nil, err
+		}
+		relayAddr, err := ma.NewMultiaddr(fmt.Sprintf("/ipfs/%s", relayInfo.ID.Pretty()))
+		if err != nil {
+			return nil, err
+		}
+		ps.Peerstore().AddAddr(peerID, relayAddr.Encapsulate(circuitAddr), ps.addrTTL)
+		s, err := ps.NewStream(ctx, peerID, pids...)
+		if err != nil {
+			ps.log.Debug().Err(err).Str("relay", relayInfo.ID.Pretty()).Str(LogPeerID, peerID.Pretty()).Msg("Failed to open stream via relay")
+			lastErr = err
+			continue
+		}
+		return s, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no available relay peer")
+	}
+	return nil, lastErr
+}
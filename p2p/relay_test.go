@@ -0,0 +1,70 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	cfg "github.com/aergoio/aergo/config"
+	libp2p "github.com/libp2p/go-libp2p"
+	circuit "github.com/libp2p/go-libp2p-circuit"
+	"github.com/libp2p/go-libp2p-host"
+	inet "github.com/libp2p/go-libp2p-net"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHost(t *testing.T, opts ...libp2p.Option) host.Host {
+	listen, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/0")
+	opts = append(opts, libp2p.ListenAddrs(listen))
+	h, err := libp2p.New(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create host: %s", err.Error())
+	}
+	return h
+}
+
+func TestPeerManager_newStreamViaRelay(t *testing.T) {
+	relayHost := newTestHost(t, libp2p.EnableRelay(circuit.OptHop))
+	defer relayHost.Close()
+	targetHost := newTestHost(t, libp2p.EnableRelay())
+	defer targetHost.Close()
+	targetHost.SetStreamHandler(aergoP2PSub, func(s inet.Stream) {
+		s.Close()
+	})
+	relayInfo := pstore.PeerInfo{ID: relayHost.ID(), Addrs: relayHost.Addrs()}
+	if err := targetHost.Connect(context.Background(), relayInfo); err != nil {
+		t.Fatalf("Failed to connect relay: %s", err.Error())
+	}
+
+	conf := cfg.NewServerContext("", "").GetDefaultP2PConfig()
+	conf.RelayAddrs = []string{fmt.Sprintf("%s/p2p/%s", relayHost.Addrs()[0].String(), relayHost.ID().Pretty())}
+	target := &peerManager{conf: conf, log: logger, mutex: &sync.Mutex{}, addrTTL: DefaultNodeTTL}
+	target.addRelayPeers()
+	assert.Equal(t, 1, len(target.relayPeers))
+	target.Host = newTestHost(t, target.relayOptions()...)
+	defer target.Host.Close()
+
+	// target host is reachable only via relay
+	unreachable, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1")
+	target.Peerstore().AddAddr(targetHost.ID(), unreachable, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	_, err := target.NewStream(ctx, targetHost.ID(), aergoP2PSub)
+	cancel()
+	assert.NotNil(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	s, err := target.newStreamViaRelay(ctx, targetHost.ID(), aergoP2PSub)
+	assert.Nil(t, err)
+	if assert.NotNil(t, s) {
+		s.Close()
+	}
+}
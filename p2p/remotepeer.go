@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/golang-lru"
@@ -46,17 +47,34 @@ type RemotePeer struct {
 	readLock dummyMutex
 
 	// used to access request data from response handlers
+	requestLock sync.Mutex
 	requests    map[string]msgOrder
-	consumeChan chan string
 
 	handlers map[SubProtocol]MessageHandler
+	// protocolID is the version of p2p protocol negotiated with remote peer. It is set before the peer runs.
+	protocolID protocol.ID
 
 	sentStatus, gotStatus bool
 	failCounter           uint32
 
+	// pingSentAt and latency are unix nano time and nanoseconds respectively, and must be accessed atomically
+	pingSentAt int64
+	latency    int64
+	// bestHeight is the best block number reported by remote peer, and must be accessed atomically
+	bestHeight uint64
+	// disconnectReason is DisconnectReason why this peer is being disconnected, and must be accessed atomically
+	disconnectReason int32
+	// score is the quality of peer and must be accessed atomically. peer is evicted if it drops below minScore.
+	score    int32
+	minScore int32
+
 	blkHashCache *lru.Cache
+	// txHashCache has hashes of txs which remote peer already knows, either announced by or sent to it.
+	txHashCache *lru.Cache
 
 	rw *bufio.ReadWriter
+	// writeBroken is set when writing to peer failed, and accessed only in runWrite.
+	writeBroken bool
 }
 
 type dummyMutex struct{}
@@ -110,6 +128,7 @@ func newRemotePeer(meta PeerMeta, p2ps PeerManager, iServ ActorService, log *log
 		meta: meta, ps: p2ps, actorServ: iServ, log: log,
 		pingDuration: defaultPingInterval,
 		state:        types.STARTING,
+		minScore:     DefaultMinPeerScore,
 
 		stopChan:   make(chan struct{}),
 		write:      make(chan msgOrder),
@@ -117,8 +136,7 @@ func newRemotePeer(meta PeerMeta, p2ps PeerManager, iServ ActorService, log *log
 		hsLock:     &sync.Mutex{},
 		op:         make(chan OpOrder, 20),
 
-		requests:    make(map[string]msgOrder),
-		consumeChan: make(chan string, 10),
+		requests: make(map[string]msgOrder),
 
 		handlers: make(map[SubProtocol]MessageHandler),
 	}
@@ -128,6 +146,10 @@ func newRemotePeer(meta PeerMeta, p2ps PeerManager, iServ ActorService, log *log
 	if err != nil {
 		panic("Failed to create remotepeer " + err.Error())
 	}
+	peer.txHashCache, err = lru.New(DefaultPeerInvCacheSize)
+	if err != nil {
+		panic("Failed to create remotepeer " + err.Error())
+	}
 	return peer
 }
 
@@ -197,8 +219,6 @@ WRITELOOP:
 		select {
 		case m := <-p.write:
 			p.writeToPeer(m)
-		case rID := <-p.consumeChan:
-			delete(p.requests, rID)
 		case <-cleanupTicker.C:
 			p.pruneRequests()
 		case <-p.closeWrite:
@@ -210,7 +230,6 @@ WRITELOOP:
 
 	// closing channel is to golang runtime
 	// close(p.write)
-	// close(p.consumeChan)
 }
 
 func (p *RemotePeer) runRead() {
@@ -224,14 +243,13 @@ func (p *RemotePeer) runRead() {
 
 		if err = p.handleMsg(msg); err != nil {
 			p.log.Error().Err(err).Msg("Failed to handle message")
-			p.ps.RemovePeer(p.ID())
+			p.ps.DisconnectPeer(p.ID(), ProtocolViolation)
 			return
 		}
 	}
 
 	// closing channel is to golang runtime
 	// close(p.write)
-	// close(p.consumeChan)
 }
 
 func (p *RemotePeer) readMsg() (*types.P2PMessage, error) {
@@ -245,23 +263,28 @@ func (p *RemotePeer) readMsg() (*types.P2PMessage, error) {
 	return data, nil
 }
 
-func (p *RemotePeer) handleMsg(msg *types.P2PMessage) error {
-	var err error
+// handleMsg dispatches msg to the handler of its subprotocol. A panic in the handler is recovered and returned as an
+// error, like an unknown subprotocol, so that the peer is disconnected for protocol violation.
+func (p *RemotePeer) handleMsg(msg *types.P2PMessage) (err error) {
 	proto := SubProtocol(msg.Header.Subprotocol)
-	defer func() {
-		if r := recover(); r != nil {
-			p.log.Warn().Str("panic", fmt.Sprint(r)).Msg("There were panic in handler")
-			err = fmt.Errorf("internal error")
-		}
-	}()
 	p.log.Debug().Str(LogPeerID, p.ID().Pretty()).Str("protocol", proto.String()).Msg("Handling messge")
 
 	handler, found := p.handlers[proto]
 	if !found {
 		return fmt.Errorf("invalid protocol %s", proto)
 	}
+	// message failed to be authenticated in handler is also counted as an error of handler
+	failures := atomic.LoadUint32(&p.failCounter)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			p.log.Warn().Str("panic", fmt.Sprint(r)).Msg("There were panic in handler")
+			err = fmt.Errorf("internal error")
+		}
+		handlerStats.record(proto, time.Since(start), err != nil || atomic.LoadUint32(&p.failCounter) != failures)
+	}()
 	handler(msg)
-	return err
+	return nil
 }
 
 func (p *RemotePeer) processOp(op OpOrder) {
@@ -291,9 +314,27 @@ func (p *RemotePeer) sendMessage(msg msgOrder) {
 	}
 }
 
-// consumeRequest remove request from request history.
-func (p *RemotePeer) consumeRequest(requestID string) {
-	p.consumeChan <- requestID
+// pendingRequest returns the request of requestID which is waiting for the response, without consuming it. It
+// returns nil if no request is pending for requestID.
+func (p *RemotePeer) pendingRequest(requestID string) msgOrder {
+	p.requestLock.Lock()
+	defer p.requestLock.Unlock()
+	return p.requests[requestID]
+}
+
+// consumeRequest remove request from request history, and returns the request. It returns nil if no request is
+// pending for requestID, such as unsolicited or duplicated response.
+// The score of peer is increased only if the response matches a request it was sent.
+func (p *RemotePeer) consumeRequest(requestID string) msgOrder {
+	p.requestLock.Lock()
+	req, found := p.requests[requestID]
+	delete(p.requests, requestID)
+	p.requestLock.Unlock()
+	if !found {
+		return nil
+	}
+	p.adjustScore(usefulResponseReward, 0)
+	return req
 }
 
 func (p *RemotePeer) initiateHandshake() {
@@ -312,7 +353,10 @@ func (p *RemotePeer) initiateHandshake() {
 func (p *RemotePeer) updateMetaInfo(statusMsg *types.Status) {
 	// check address. and apply current
 	receivedMeta := FromPeerAddress(statusMsg.Sender)
-	p.meta.IPAddress = receivedMeta.IPAddress
+	// hostname which the peer is dialed by is kept for reconnecting, rather than the address it reports
+	if !p.meta.IsHostname() {
+		p.meta.IPAddress = receivedMeta.IPAddress
+	}
 	p.meta.Port = receivedMeta.Port
 }
 
@@ -344,6 +388,7 @@ func (p *RemotePeer) handleHandshake(statusMsg *types.Status) {
 
 	// If all checked and validated. it's now handshaked. and then run sync.
 	p.log.Info().Str(LogPeerID, p.meta.ID.Pretty()).Msg("peer is handshaked and now running status")
+	p.updateBestHeight(statusMsg.BestHeight)
 	p.setState(types.RUNNING)
 
 	// notice to p2pmanager that handshaking is finished
@@ -371,17 +416,34 @@ func (p *RemotePeer) writeToPeer(m msgOrder) {
 		}
 	}
 
+	if p.writeBroken {
+		p.log.Debug().Str(LogPeerID, p.meta.ID.Pretty()).Str(LogProtoID, m.GetProtocolID().String()).
+			Str(LogMsgID, m.GetRequestID()).Msg("Cancel sending message, since connection is broken")
+		return
+	}
+	// request is registered before sending, since the response can be received before SendOver returns.
+	if m.ResponseExpected() {
+		p.requestLock.Lock()
+		p.requests[m.GetRequestID()] = m
+		p.requestLock.Unlock()
+	}
 	err := m.SendOver(p.rw)
 	if err != nil {
-		p.log.Warn().Err(err).Msg("fail to SendOver")
+		if m.ResponseExpected() {
+			p.requestLock.Lock()
+			delete(p.requests, m.GetRequestID())
+			p.requestLock.Unlock()
+		}
+		// the connection is half-open or closed, such as when the host of peer crashed. the peer is removed
+		// promptly instead of being kept as a live peer.
+		p.log.Warn().Err(err).Str(LogPeerID, p.meta.ID.Pretty()).Msg("fail to SendOver, removing peer")
+		p.writeBroken = true
+		go p.ps.DisconnectPeer(p.ID(), BrokenConnection)
 		return
 	}
 	p.log.Debug().Str(LogPeerID, p.meta.ID.Pretty()).Str(LogProtoID, m.GetProtocolID().String()).
 		Str(LogMsgID, m.GetRequestID()).Msg("Send message")
 	//p.log.Debugf("Sent message %v:%v to peer %s", m.GetProtocolID(), m.GetRequestID(), p.meta.ID.Pretty())
-	if m.ResponseExpected() {
-		p.requests[m.GetRequestID()] = m
-	}
 }
 
 const getStreamTimeout = time.Second * 30
@@ -412,7 +474,7 @@ func (p *RemotePeer) getStreamForWriting(msgID string, protocol protocol.ID, sch
 // this method MUST be called in same go routine as AergoPeer.RunPeer()
 func (p *RemotePeer) sendPing() {
 	// find my best block
-	bestBlock, err := extractBlockFromRequest(p.actorServ.CallRequest(message.ChainSvc, &message.GetBestBlock{}))
+	bestBlock, err := extractBlockFromRequest(callRequestWithin(p.actorServ, message.ChainSvc, &message.GetBestBlock{}))
 	if err != nil {
 		p.log.Error().Err(err).Msg("Failed to get best block")
 		return
@@ -424,9 +486,53 @@ func (p *RemotePeer) sendPing() {
 		BestHeight:    bestBlock.GetHeader().GetBlockNo(),
 	}
 
+	if atomic.SwapInt64(&p.pingSentAt, time.Now().UnixNano()) != 0 {
+		// no response until next ping
+		p.adjustScore(pingTimeoutPenalty, Timeout)
+	}
 	p.sendMessage(newPbMsgRequestOrder(true, false, pingRequest, pingMsg))
 }
 
+// updateLatency measures round trip time from the last ping request.
+func (p *RemotePeer) updateLatency() {
+	sentAt := atomic.SwapInt64(&p.pingSentAt, 0)
+	if sentAt == 0 {
+		return
+	}
+	atomic.StoreInt64(&p.latency, time.Now().UnixNano()-sentAt)
+}
+
+// ProtocolID returns the version of p2p protocol negotiated with remote peer.
+func (p *RemotePeer) ProtocolID() protocol.ID {
+	return p.protocolID
+}
+
+// Latency returns round trip time measured by ping. It returns zero if not measured yet.
+func (p *RemotePeer) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.latency))
+}
+
+// updateBestHeight keeps the best block number which remote peer reported lastly.
+func (p *RemotePeer) updateBestHeight(blockNo types.BlockNo) {
+	atomic.StoreUint64(&p.bestHeight, blockNo)
+}
+
+// BestHeight returns the best block number reported by remote peer.
+func (p *RemotePeer) BestHeight() types.BlockNo {
+	return atomic.LoadUint64(&p.bestHeight)
+}
+
+// setDisconnectReason records the reason of disconnection. Only the first reason is kept, and it returns
+// false if the peer was already being disconnected.
+func (p *RemotePeer) setDisconnectReason(reason DisconnectReason) bool {
+	return atomic.CompareAndSwapInt32(&p.disconnectReason, 0, int32(reason))
+}
+
+// DisconnectReason returns the reason why the peer is disconnected, or zero if it is not.
+func (p *RemotePeer) DisconnectReason() DisconnectReason {
+	return DisconnectReason(atomic.LoadInt32(&p.disconnectReason))
+}
+
 // sendStatus is called once when a peer is added.()
 func (p *RemotePeer) sendStatus() {
 	p.log.Debug().Str(LogPeerID, p.meta.ID.Pretty()).Msg("Sending status message for handshaking")
@@ -444,6 +550,9 @@ func (p *RemotePeer) sendStatus() {
 // send notice message and then disconnect. this routine should only run in RunPeer go routine
 func (p *RemotePeer) goAwayMsg(msg string) {
 	p.log.Info().Str(LogPeerID, p.meta.ID.Pretty()).Str("msg", msg).Msg("Peer is closing")
+	p.setDisconnectReason(ProtocolViolation)
+	// the peer failed handshake is not scored, since it may not be added yet. its address is blacklisted instead.
+	p.ps.ReportHandshakeFailure(p.meta)
 	p.sendMessage(newPbMsgRequestOrder(false, true, goAway, &types.GoAwayNotice{MessageData: &types.MessageData{}, Message: msg}))
 	p.ps.RemovePeer(p.meta.ID)
 }
@@ -458,6 +567,7 @@ func (p *RemotePeer) pruneRequests() {
 	deletedCnt := 0
 	var deletedReqs []string
 	expireTime := time.Now().Add(-1 * time.Hour).Unix()
+	p.requestLock.Lock()
 	for key, m := range p.requests {
 		if m.Timestamp() < expireTime {
 			delete(p.requests, key)
@@ -467,6 +577,7 @@ func (p *RemotePeer) pruneRequests() {
 			deletedCnt++
 		}
 	}
+	p.requestLock.Unlock()
 	//p.log.Infof("Pruned %d requests but no response to peer %s until %v", deletedCnt, p.meta.ID.Pretty(), time.Unix(expireTime, 0))
 	p.log.Info().Int("count", deletedCnt).Str(LogPeerID, p.meta.ID.Pretty()).
 		Time("until", time.Unix(expireTime, 0)).Msg("Pruned requests, but no response to peer")
@@ -482,9 +593,30 @@ func (p *RemotePeer) handleNewBlockNotice(data *types.NewBlockNotice) {
 	b64hash := enc.ToString(data.BlockHash)
 
 	p.blkHashCache.Add(b64hash, data.BlockHash)
+	if data.BlockNo > p.BestHeight() {
+		p.updateBestHeight(data.BlockNo)
+	}
 	p.ps.HandleNewBlockNotice(p.meta.ID, b64hash, data)
 }
 
+// updateTxCache marks txs as known to remote peer.
+func (p *RemotePeer) updateTxCache(hashes [][]byte) {
+	for _, hash := range hashes {
+		p.txHashCache.Add(enc.ToString(hash), hash)
+	}
+}
+
+// filterNewTxs returns hashes of txs which remote peer does not know yet, and marks them as known.
+func (p *RemotePeer) filterNewTxs(hashes [][]byte) [][]byte {
+	newHashes := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		if ok, _ := p.txHashCache.ContainsOrAdd(enc.ToString(hash), hash); !ok {
+			newHashes = append(newHashes, hash)
+		}
+	}
+	return newHashes
+}
+
 func (p *RemotePeer) sendGoAway(msg string) {
 	// TODO: send goaway message and close connection
 }
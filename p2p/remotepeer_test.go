@@ -100,6 +100,7 @@ func TestAergoPeer_writeToPeer(t *testing.T) {
 			mockOrder.On("GetProtocolID").Return(pingRequest)
 			mockOrder.On("GetRequestID").Return("test_req")
 			mockOrder.On("ResponseExpected").Return(tt.args.needResponse)
+			mockPeerManager.On("DisconnectPeer", samplePeerID, BrokenConnection)
 
 			p := newRemotePeer(sampleMeta, mockPeerManager, mockActorServ, logger)
 			p.rw = dummyRW
@@ -117,6 +118,41 @@ func TestAergoPeer_writeToPeer(t *testing.T) {
 	}
 }
 
+// brokenWriter fails every write, like the connection to a crashed host
+type brokenWriter struct{}
+
+func (brokenWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("broken pipe")
+}
+
+func TestRemotePeer_writeToBrokenConnection(t *testing.T) {
+	sampleMeta := PeerMeta{ID: samplePeerID, IPAddress: "192.168.1.2", Port: 7845}
+	mockPeerManager := new(MockP2PService)
+	removed := make(chan DisconnectReason, 2)
+	mockPeerManager.On("DisconnectPeer", samplePeerID, mock.AnythingOfType("p2p.DisconnectReason")).Run(
+		func(args mock.Arguments) { removed <- args.Get(1).(DisconnectReason) })
+
+	p := newRemotePeer(sampleMeta, mockPeerManager, new(MockActorService), logger)
+	p.rw = &bufio.ReadWriter{Reader: &bufio.Reader{}, Writer: bufio.NewWriter(brokenWriter{})}
+	p.setState(types.RUNNING)
+
+	ping := &types.Ping{MessageData: &types.MessageData{}, BestHeight: 1}
+	p.writeToPeer(newPbMsgRequestOrder(true, false, pingRequest, ping))
+	select {
+	case reason := <-removed:
+		assert.Equal(t, BrokenConnection, reason)
+	case <-time.After(time.Second):
+		t.Fatal("peer of broken connection is not removed")
+	}
+	assert.True(t, p.writeBroken)
+	assert.Empty(t, p.requests)
+
+	// no more writes to the broken connection, nor removing peer again
+	p.writeToPeer(newPbMsgRequestOrder(true, false, pingRequest, ping))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, removed)
+}
+
 func TestRemotePeer_sendPing(t *testing.T) {
 	selfPeerID, _ := peer.IDB58Decode("16Uiu2HAmFqptXPfcdaCdwipB2fhHATgKGVFVPehDAPZsDKSU7jRm")
 	sampleSelf := PeerMeta{ID: selfPeerID, IPAddress: "192.168.1.1", Port: 6845}
@@ -140,7 +176,7 @@ func TestRemotePeer_sendPing(t *testing.T) {
 			mockActorServ := new(MockActorService)
 			mockPeerManager := new(MockP2PService)
 
-			mockActorServ.On("CallRequest", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock")).Return(dummyBestBlockRsp, tt.getBlockErr)
+			mockActorServ.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock"), mock.Anything).Return(dummyBestBlockRsp, tt.getBlockErr)
 			mockPeerManager.On("SelfMeta").Return(sampleSelf)
 
 			p := newRemotePeer(sampleMeta, mockPeerManager, mockActorServ, logger)
@@ -157,7 +193,7 @@ func TestRemotePeer_sendPing(t *testing.T) {
 			}
 			assert.Equal(t, tt.wants.wantWrite, actualWrite)
 			mockPeerManager.AssertNotCalled(t, "SelfMeta")
-			mockActorServ.AssertNumberOfCalls(t, "CallRequest", 1)
+			mockActorServ.AssertNumberOfCalls(t, "CallRequestWithTimeout", 1)
 		})
 	}
 }
@@ -186,7 +222,7 @@ func IgnoreTestRemotePeer_sendStatus(t *testing.T) {
 			mockActorServ := new(MockActorService)
 			mockPeerManager := new(MockP2PService)
 
-			mockActorServ.On("CallRequest", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock")).Return(dummyBestBlockRsp, tt.getBlockErr)
+			mockActorServ.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock"), mock.Anything).Return(dummyBestBlockRsp, tt.getBlockErr)
 			mockPeerManager.On("SelfMeta").Return(sampleSelf)
 
 			p := newRemotePeer(sampleMeta, mockPeerManager, mockActorServ, logger)
@@ -202,7 +238,7 @@ func IgnoreTestRemotePeer_sendStatus(t *testing.T) {
 			default:
 			}
 			assert.Equal(t, tt.wants.wantWrite, actualWrite)
-			mockActorServ.AssertNumberOfCalls(t, "CallRequest", 1)
+			mockActorServ.AssertNumberOfCalls(t, "CallRequestWithTimeout", 1)
 		})
 	}
 }
@@ -315,3 +351,17 @@ func TestRemotePeer_sendMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestRemotePeer_filterNewTxs(t *testing.T) {
+	sampleMeta := PeerMeta{ID: samplePeerID, IPAddress: "192.168.1.2", Port: 7845}
+	p := newRemotePeer(sampleMeta, new(MockP2PService), new(MockActorService), logger)
+
+	announced := [][]byte{[]byte("tx1"), []byte("tx2")}
+	p.updateTxCache(announced)
+
+	hashes := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3"), []byte("tx4")}
+	assert.Equal(t, hashes[2:], p.filterNewTxs(hashes))
+	// txs once sent are not sent again
+	assert.Empty(t, p.filterNewTxs(hashes))
+	assert.Equal(t, [][]byte{[]byte("tx5")}, p.filterNewTxs([][]byte{[]byte("tx3"), []byte("tx5")}))
+}
@@ -0,0 +1,117 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"sync"
+	"sync/atomic"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	peer "github.com/libp2p/go-libp2p-peer"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+)
+
+// streamLimiter caps the number of streams concurrently handled for each remote peer, so that
+// a peer cannot amplify load by opening many streams on a connection.
+type streamLimiter struct {
+	mutex  sync.Mutex
+	max    int
+	counts map[peer.ID]int
+}
+
+// newStreamLimiter creates limiter. Streams are not limited if max is not positive, nor by nil limiter.
+func newStreamLimiter(max int) *streamLimiter {
+	return &streamLimiter{max: max, counts: make(map[peer.ID]int)}
+}
+
+// acquire returns false if streams of peer already reach the max.
+func (l *streamLimiter) acquire(peerID peer.ID) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.max > 0 && l.counts[peerID] >= l.max {
+		return false
+	}
+	l.counts[peerID]++
+	return true
+}
+
+func (l *streamLimiter) release(peerID peer.ID) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.counts[peerID] <= 1 {
+		delete(l.counts, peerID)
+	} else {
+		l.counts[peerID]--
+	}
+}
+
+// SetStreamHandler sets handler of streams of pid, which is limited by the streams of each remote peer. Every
+// stream handler of peer manager, such as of handshake and of subprotocols, is set by this, so that no stream
+// escapes the limit.
+func (ps *peerManager) SetStreamHandler(pid protocol.ID, handler inet.StreamHandler) {
+	ps.Host.SetStreamHandler(pid, ps.limitStreams(handler))
+}
+
+// SetStreamHandlerMatch is SetStreamHandler with the matching function of protocol.
+func (ps *peerManager) SetStreamHandlerMatch(pid protocol.ID, match func(string) bool, handler inet.StreamHandler) {
+	ps.Host.SetStreamHandlerMatch(pid, match, ps.limitStreams(handler))
+}
+
+// limitStreams wraps stream handler to refuse streams over the limit of remote peer.
+func (ps *peerManager) limitStreams(handler inet.StreamHandler) inet.StreamHandler {
+	return func(s inet.Stream) {
+		peerID := s.Conn().RemotePeer()
+		if !ps.streamLimiter.acquire(peerID) {
+			ps.log.Warn().Str(LogPeerID, peerID.Pretty()).Str(LogProtoID, string(s.Protocol())).
+				Msg("Refusing stream: too many concurrent streams from peer")
+			// penalize the peer for selection of request target
+			if remotePeer, found := ps.GetPeer(peerID); found {
+				atomic.AddUint32(&remotePeer.failCounter, 1)
+			}
+			s.Reset()
+			return
+		}
+		defer ps.streamLimiter.release(peerID)
+		handler(s)
+	}
+}
+
+// newHandshakeSlots creates the semaphore of max inbound handshakes. Handshakes are not limited if max is not
+// positive.
+func newHandshakeSlots(max int) chan struct{} {
+	if max <= 0 {
+		return nil
+	}
+	return make(chan struct{}, max)
+}
+
+// limitHandshakes wraps handshake handler to refuse inbound handshakes over the limit of ones in progress, so
+// that a burst of connections cannot spawn unbounded work. The handler must return once handshake is done, or
+// once its time limit is over, like onHandshake.
+func (ps *peerManager) limitHandshakes(handler inet.StreamHandler) inet.StreamHandler {
+	return func(s inet.Stream) {
+		if ps.handshakeSlots == nil {
+			handler(s)
+			return
+		}
+		select {
+		case ps.handshakeSlots <- struct{}{}:
+		default:
+			ps.log.Warn().Str(LogPeerID, s.Conn().RemotePeer().Pretty()).Int("inProgress", len(ps.handshakeSlots)).
+				Msg("Refusing handshake: too many concurrent handshakes")
+			s.Reset()
+			return
+		}
+		defer func() { <-ps.handshakeSlots }()
+		handler(s)
+	}
+}
@@ -0,0 +1,169 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	peer "github.com/libp2p/go-libp2p-peer"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPeerManager_limitStreams(t *testing.T) {
+	const maxStreams = 3
+	peerID := peer.ID("remote")
+	mockConn := &MockConn{}
+	mockConn.On("RemotePeer").Return(peerID)
+
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, remotePeers: make(map[peer.ID]*RemotePeer),
+		streamLimiter: newStreamLimiter(maxStreams)}
+	release := make(chan struct{})
+	var handled sync.WaitGroup
+	handler := pm.limitStreams(func(s inet.Stream) {
+		handled.Done()
+		<-release
+	})
+
+	// streams within the cap are handled, and blocked until released
+	streams := make([]*MockStream, maxStreams+2)
+	for i := range streams {
+		streams[i] = &MockStream{}
+		streams[i].On("Conn").Return(mockConn)
+		streams[i].On("Protocol").Return(aergoP2PSub)
+		streams[i].On("Reset").Return(nil)
+	}
+	handled.Add(maxStreams)
+	for _, s := range streams[:maxStreams] {
+		go handler(s)
+	}
+	handled.Wait()
+
+	// excess streams are refused
+	for _, s := range streams[maxStreams:] {
+		handler(s)
+		s.AssertCalled(t, "Reset")
+	}
+	for _, s := range streams[:maxStreams] {
+		s.AssertNotCalled(t, "Reset")
+	}
+
+	// stream is accepted again after handlers finish
+	close(release)
+	assert.True(t, waitUntil(func() bool { return pm.streamLimiter.acquire(peerID) }, time.Second))
+}
+
+func TestPeerManager_limitHandshakes(t *testing.T) {
+	const maxHandshakes = 4
+	const connections = 50
+	pm := &peerManager{log: logger, handshakeSlots: newHandshakeSlots(maxHandshakes)}
+
+	var inProgress, maxInProgress, handshaked, refused int32
+	release := make(chan struct{})
+	handler := pm.limitHandshakes(func(s inet.Stream) {
+		n := atomic.AddInt32(&inProgress, 1)
+		for {
+			max := atomic.LoadInt32(&maxInProgress)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInProgress, max, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inProgress, -1)
+		atomic.AddInt32(&handshaked, 1)
+	})
+
+	// many connections are opened at once, and the ones over the cap are refused
+	streams := make([]*MockStream, connections)
+	var wg sync.WaitGroup
+	for i := range streams {
+		mockConn := &MockConn{}
+		mockConn.On("RemotePeer").Return(peer.ID(fmt.Sprintf("remote%d", i)))
+		streams[i] = &MockStream{}
+		streams[i].On("Conn").Return(mockConn)
+		streams[i].On("Reset").Return(nil).Run(func(mock.Arguments) { atomic.AddInt32(&refused, 1) })
+		wg.Add(1)
+		go func(s *MockStream) {
+			defer wg.Done()
+			handler(s)
+		}(streams[i])
+	}
+	assert.True(t, waitUntil(func() bool {
+		return atomic.LoadInt32(&refused) == connections-maxHandshakes
+	}, time.Second))
+	assert.Equal(t, int32(maxHandshakes), atomic.LoadInt32(&inProgress))
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(maxHandshakes), maxInProgress)
+	assert.Equal(t, int32(maxHandshakes), handshaked)
+	assert.Empty(t, pm.handshakeSlots)
+
+	// handshakes are not limited without the cap
+	pm.handshakeSlots = newHandshakeSlots(0)
+	s := &MockStream{}
+	called := false
+	pm.limitHandshakes(func(inet.Stream) { called = true })(s)
+	assert.True(t, called)
+}
+
+func waitUntil(cond func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// handlerHost keeps the stream handlers set to it
+type handlerHost struct {
+	mockHost
+	handlers map[protocol.ID]inet.StreamHandler
+}
+
+func (h *handlerHost) SetStreamHandler(pid protocol.ID, handler inet.StreamHandler) {
+	h.handlers[pid] = handler
+}
+
+func TestPeerManager_SetStreamHandler(t *testing.T) {
+	peerID := peer.ID("remote")
+	mockConn := &MockConn{}
+	mockConn.On("RemotePeer").Return(peerID)
+	host := &handlerHost{handlers: make(map[protocol.ID]inet.StreamHandler)}
+	pm := &peerManager{Host: host, log: logger, mutex: &sync.Mutex{}, remotePeers: make(map[peer.ID]*RemotePeer),
+		streamLimiter: newStreamLimiter(1)}
+
+	// handler of any protocol, not only of handshake, is limited
+	const subProtocolID = protocol.ID("/aergo/sub/test")
+	release := make(chan struct{})
+	handling := make(chan struct{})
+	pm.SetStreamHandler(subProtocolID, func(s inet.Stream) {
+		close(handling)
+		<-release
+	})
+	newStream := func() *MockStream {
+		s := &MockStream{}
+		s.On("Conn").Return(mockConn)
+		s.On("Protocol").Return(subProtocolID)
+		s.On("Reset").Return(nil)
+		return s
+	}
+	first, second := newStream(), newStream()
+	go host.handlers[subProtocolID](first)
+	<-handling
+	host.handlers[subProtocolID](second)
+	second.AssertCalled(t, "Reset")
+	close(release)
+	first.AssertNotCalled(t, "Reset")
+}
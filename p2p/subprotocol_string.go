@@ -6,13 +6,13 @@ import "strconv"
 
 const (
 	_SubProtocol_name_0 = "statusRequestpingRequestpingResponsegoAwayaddressesRequestaddressesResponse"
-	_SubProtocol_name_1 = "getBlocksRequestgetBlocksResponsegetBlockHeadersRequestgetBlockHeadersResponsegetMissingRequestgetMissingResponsenewBlockNotice"
+	_SubProtocol_name_1 = "getBlocksRequestgetBlocksResponsegetBlockHeadersRequestgetBlockHeadersResponsegetMissingRequestgetMissingResponsenewBlockNoticegetBlockRangeRequestgetBlockRangeResponse"
 	_SubProtocol_name_2 = "getTXsRequestgetTxsResponsenewTxNotice"
 )
 
 var (
 	_SubProtocol_index_0 = [...]uint8{0, 13, 24, 36, 42, 58, 75}
-	_SubProtocol_index_1 = [...]uint8{0, 16, 33, 55, 78, 95, 113, 127}
+	_SubProtocol_index_1 = [...]uint8{0, 16, 33, 55, 78, 95, 113, 127, 147, 168}
 	_SubProtocol_index_2 = [...]uint8{0, 13, 27, 38}
 )
 
@@ -21,7 +21,7 @@ func (i SubProtocol) String() string {
 	case 1 <= i && i <= 6:
 		i -= 1
 		return _SubProtocol_name_0[_SubProtocol_index_0[i]:_SubProtocol_index_0[i+1]]
-	case 16 <= i && i <= 22:
+	case 16 <= i && i <= 24:
 		i -= 16
 		return _SubProtocol_name_1[_SubProtocol_index_1[i]:_SubProtocol_index_1[i+1]]
 	case 32 <= i && i <= 34:
@@ -16,6 +16,9 @@ import (
 	"github.com/libp2p/go-libp2p-peer"
 )
 
+// DefaultMaxAddressesPerResponse is the default maximum number of addresses in a response to addresses request.
+const DefaultMaxAddressesPerResponse = 50
+
 // AddressesProtocol type
 type AddressesProtocol struct {
 	log *log.Logger
@@ -55,10 +58,22 @@ func (p *PingHandler) handleAddressesRequest(msg *types.P2PMessage) {
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, p.peer.ID(), nil)
 
+	// response is capped regardless of the requested size
+	limit := p.maxAddrs
+	if limit <= 0 {
+		limit = DefaultMaxAddressesPerResponse
+	}
+	if data.MaxSize > 0 && int(data.MaxSize) < limit {
+		limit = int(data.MaxSize)
+	}
+
 	// generate response message
 	resp := &types.AddressesResponse{MessageData: &types.MessageData{}}
-	var addrList = make([]*types.PeerAddress, 0, len(p.pm.GetPeers()))
+	var addrList = make([]*types.PeerAddress, 0, limit)
 	for _, aPeer := range p.pm.GetPeers() {
+		if len(addrList) >= limit {
+			break
+		}
 		// exclude not running peer and requesting peer itself
 		// TODO: apply peer status after fix status management bug
 		if aPeer.meta.ID == peerID {
@@ -81,6 +96,10 @@ func (p *PingHandler) checkAndAddPeerAddresses(peers []*types.PeerAddress) {
 			continue
 		}
 		meta := FromPeerAddress(rPeerAddr)
+		// hostname is resolved only for designated peers, so it is useless here.
+		if meta.IsHostname() {
+			continue
+		}
 		peerMetas = append(peerMetas, meta)
 	}
 	if len(peerMetas) > 0 {
@@ -99,9 +118,7 @@ func (p *PingHandler) handleAddressesResponse(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.GetPeers()))
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
@@ -118,6 +135,9 @@ func AddressesToStringMap(addrs []*types.PeerAddress) []map[string]string {
 	for i, addr := range addrs {
 		vMap := make(map[string]string)
 		vMap["address"] = net.IP(addr.Address).String()
+		if len(addr.Host) > 0 {
+			vMap["address"] = addr.Host
+		}
 		vMap["port"] = strconv.Itoa(int(addr.Port))
 		vMap["peerId"] = peer.ID(addr.PeerID).Pretty()
 		arr[i] = vMap
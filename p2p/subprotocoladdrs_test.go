@@ -0,0 +1,55 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aergoio/aergo/types"
+	"github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingHandler_handleAddressesRequest(t *testing.T) {
+	mockPM := &MockP2PService{}
+	peers := make([]*RemotePeer, 0, 10)
+	for i := 0; i < 10; i++ {
+		meta := PeerMeta{ID: peer.ID(fmt.Sprintf("peer%d", i)), IPAddress: fmt.Sprintf("192.168.0.%d", i+1), Port: 7846}
+		peers = append(peers, newRemotePeer(meta, mockPM, &MockActorService{}, logger))
+	}
+	mockPM.On("GetPeers").Return(peers)
+
+	tests := []struct {
+		name     string
+		maxAddrs int
+		maxSize  uint32
+		expected int
+	}{
+		{"TCapped", 3, 50, 3},
+		{"TRequestedSmaller", 3, 2, 2},
+		{"TNoRequestedSize", 3, 0, 3},
+		{"TDefaultCap", 0, 50, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requester := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, &MockActorService{}, logger)
+			handler := NewPingHandler(mockPM, requester, logger)
+			handler.maxAddrs = tt.maxAddrs
+
+			req := &types.AddressesRequest{MessageData: &types.MessageData{Id: "req"}, MaxSize: tt.maxSize}
+			data, _ := marshalMessage(req)
+			sent := make(chan msgOrder, 1)
+			go func() { sent <- <-requester.write }()
+			handler.handleAddressesRequest(&types.P2PMessage{Header: &types.MessageData{Id: "req", Subprotocol: addressesRequest.Uint32()}, Data: data})
+
+			order := (<-sent).(*pbMessageOrder)
+			assert.Equal(t, addressesResponse, order.GetProtocolID())
+			resp := &types.AddressesResponse{}
+			assert.Nil(t, unmarshalMessage(order.message.(*types.P2PMessage).Data, resp))
+			assert.Len(t, resp.Peers, tt.expected)
+		})
+	}
+}
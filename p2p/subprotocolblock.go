@@ -6,11 +6,18 @@
 package p2p
 
 import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"time"
+
 	"github.com/aergoio/aergo-lib/log"
 	"github.com/aergoio/aergo/blockchain"
+	"github.com/aergoio/aergo/consensus"
 	"github.com/aergoio/aergo/internal/enc"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
+	"github.com/golang/protobuf/proto"
 )
 
 func (sp SubProtocol) Uint32() uint32 {
@@ -21,8 +28,27 @@ func (sp SubProtocol) Uint32() uint32 {
 // Relaying is not implemented yet.
 type BlockProtocol struct {
 	BaseMsgHandler
+	// maxHeaders is the maximum number of headers in a response to block headers request.
+	// DefaultMaxHeadersPerResponse is used if it is not positive.
+	maxHeaders int
+	// chunkSize and sizeBudget are the bytes of blocks in a response message and in all the messages for a
+	// request. defaultBlockChunkSize and defaultBlockSizeBudget are used if they are not positive.
+	chunkSize  int
+	sizeBudget int
 }
 
+// DefaultMaxHeadersPerResponse is the default maximum number of headers in a response to block headers request.
+const DefaultMaxHeadersPerResponse = 1000
+
+const (
+	// defaultBlockChunkSize is the default bytes of blocks in a response message. A block larger than it is sent
+	// alone.
+	defaultBlockChunkSize = 1 << 20
+	// defaultBlockSizeBudget is the default bytes of blocks sent for a request. The requester gets the rest by
+	// further requests.
+	defaultBlockSizeBudget = 16 << 20
+)
+
 // NewBlockProtocol create block subprotocol
 func NewBlockProtocol(logger *log.Logger, chainsvc *blockchain.ChainService) *BlockProtocol {
 	p := &BlockProtocol{}
@@ -61,35 +87,63 @@ func (p *BlockProtocol) handleBlockRequest(msg *types.P2PMessage) {
 
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.Hashes))
 
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
-	// find block info from chainservice
-	idx := 0
-	blockInfos := make([]*types.Block, 0, len(data.Hashes))
-	for _, hash := range data.Hashes {
-		foundBlock, err := extractBlockFromRequest(p.actor.CallRequest(message.ChainSvc,
+	p.sendBlocks(remotePeer, data.MessageData.Id, data.Hashes)
+}
+
+// sendBlocks sends the blocks of hashes, as many as the size budget allows, in the responses of requestID. The
+// blocks are fetched one by one and sent in chunks, so that only a chunk of blocks is kept in memory at once.
+func (p *BlockProtocol) sendBlocks(remotePeer *RemotePeer, requestID string, hashes [][]byte) {
+	chunkSize, sizeBudget := p.chunkSize, p.sizeBudget
+	if chunkSize <= 0 {
+		chunkSize = defaultBlockChunkSize
+	}
+	if sizeBudget <= 0 {
+		sizeBudget = defaultBlockSizeBudget
+	}
+	blocks := make([]*types.Block, 0)
+	chunkBytes, totalBytes, sent := 0, 0, 0
+	deadline := p.callDeadline()
+	for _, hash := range hashes {
+		foundBlock, err := extractBlockFromRequest(callRequestUntil(p.actor, deadline, message.ChainSvc,
 			&message.GetBlock{BlockHash: hash}))
+		if err == errCallTimeout {
+			p.logSlowResponse(remotePeer, requestID)
+			break
+		}
 		if err != nil || foundBlock == nil {
 			continue
 		}
-		blockInfos = append(blockInfos, foundBlock)
-		idx++
+		size := proto.Size(foundBlock)
+		if totalBytes+size > sizeBudget && totalBytes > 0 {
+			break
+		}
+		if chunkBytes+size > chunkSize && len(blocks) > 0 {
+			remotePeer.sendMessage(newPbMsgResponseOrder(requestID, true, getBlocksResponse,
+				&types.GetBlockResponse{MessageData: &types.MessageData{}, Status: types.ResultStatus_OK,
+					Blocks: blocks, HasNext: true}))
+			sent += len(blocks)
+			blocks = make([]*types.Block, 0)
+			chunkBytes = 0
+		}
+		blocks = append(blocks, foundBlock)
+		chunkBytes += size
+		totalBytes += size
 	}
 	status := types.ResultStatus_OK
-	if 0 == len(blockInfos) {
+	if 0 == sent+len(blocks) {
 		status = types.ResultStatus_NOT_FOUND
 	}
 
 	// generate response message
 	resp := &types.GetBlockResponse{MessageData: &types.MessageData{},
 		Status: status,
-		Blocks: blockInfos}
+		Blocks: blocks}
 
-	remotePeer.sendMessage(newPbMsgResponseOrder(data.MessageData.Id, true, getBlocksResponse, resp))
+	remotePeer.sendMessage(newPbMsgResponseOrder(requestID, true, getBlocksResponse, resp))
 }
 
 // remote GetBlock response handler
@@ -103,13 +157,13 @@ func (p *BlockProtocol) handleGetBlockResponse(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.Blocks))
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
-	// locate request data and remove it if found
-	remotePeer.consumeRequest(data.MessageData.Id)
+	// locate request data and remove it if found, when the last response of the request is received
+	if !data.HasNext {
+		remotePeer.consumeRequest(data.MessageData.Id)
+	}
 
 	// got block
 	p.logger.Debug().Int("block_cnt", len(data.Blocks)).Msg("Request chainservice to add blocks")
@@ -133,22 +187,28 @@ func (p *BlockProtocol) handleGetBlockHeadersRequest(msg *types.P2PMessage) {
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, data)
 
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
-	// find block info from chainservice
-	maxFetchSize := min(1000, data.Size)
+	// response is capped regardless of the requested size, and the requester gets the rest by further requests
+	limit := uint32(DefaultMaxHeadersPerResponse)
+	if p.maxHeaders > 0 {
+		limit = uint32(p.maxHeaders)
+	}
+	maxFetchSize := min(limit, data.Size)
 	idx := uint32(0)
-	hashes := make([][]byte, 0, data.Size)
-	headers := make([]*types.BlockHeader, 0, data.Size)
+	hashes := make([][]byte, 0, maxFetchSize)
+	headers := make([]*types.BlockHeader, 0, maxFetchSize)
+	deadline := p.callDeadline()
 	if len(data.Hash) > 0 {
 		hash := data.Hash
 		for idx < maxFetchSize {
-			foundBlock, err := extractBlockFromRequest(p.actor.CallRequest(message.ChainSvc,
+			foundBlock, err := extractBlockFromRequest(callRequestUntil(p.actor, deadline, message.ChainSvc,
 				&message.GetBlock{BlockHash: hash}))
+			if err == errCallTimeout {
+				p.logSlowResponse(remotePeer, data.MessageData.Id)
+			}
 			if err != nil || foundBlock == nil {
 				break
 			}
@@ -166,8 +226,11 @@ func (p *BlockProtocol) handleGetBlockHeadersRequest(msg *types.P2PMessage) {
 			end = types.BlockNo(data.Height - uint64(maxFetchSize-1))
 		}
 		for i := types.BlockNo(data.Height); i >= end; i-- {
-			foundBlock, err := extractBlockFromRequest(p.actor.CallRequest(message.ChainSvc,
+			foundBlock, err := extractBlockFromRequest(callRequestUntil(p.actor, deadline, message.ChainSvc,
 				&message.GetBlockByNo{BlockNo: i}))
+			if err == errCallTimeout {
+				p.logSlowResponse(remotePeer, data.MessageData.Id)
+			}
 			if err != nil || foundBlock == nil {
 				break
 			}
@@ -184,6 +247,144 @@ func (p *BlockProtocol) handleGetBlockHeadersRequest(msg *types.P2PMessage) {
 	remotePeer.sendMessage(newPbMsgResponseOrder(data.MessageData.Id, true, getBlockHeadersResponse, resp))
 }
 
+// maxBlockRangeSize is the max number of blocks in a response of block range request.
+const maxBlockRangeSize = 100
+
+// remote peer requests handler
+func (p *BlockProtocol) handleGetBlockRangeRequest(msg *types.P2PMessage) {
+	peerID := p.peer.ID()
+	remotePeer := p.peer
+
+	// get request data
+	data := &types.GetBlockRangeRequest{}
+	err := unmarshalMessage(msg.Data, data)
+	if err != nil {
+		p.logger.Info().Err(err).Msg("fail to decode")
+		return
+	}
+	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, data)
+
+	if !p.authenticate(msg) {
+		return
+	}
+
+	// find blocks in ascending order from chainservice, until the first missing block
+	size := min(maxBlockRangeSize, data.Count)
+	blocks := make([]*types.Block, 0, size)
+	deadline := p.callDeadline()
+	for i := uint32(0); i < size; i++ {
+		foundBlock, err := extractBlockFromRequest(callRequestUntil(p.actor, deadline, message.ChainSvc,
+			&message.GetBlockByNo{BlockNo: types.BlockNo(data.StartNo + uint64(i))}))
+		if err == errCallTimeout {
+			p.logSlowResponse(remotePeer, data.MessageData.Id)
+		}
+		if err != nil || foundBlock == nil {
+			break
+		}
+		blocks = append(blocks, foundBlock)
+	}
+	status := types.ResultStatus_OK
+	if 0 == len(blocks) {
+		status = types.ResultStatus_NOT_FOUND
+	}
+
+	// generate response message
+	resp := &types.GetBlockResponse{MessageData: &types.MessageData{},
+		Status: status,
+		Blocks: blocks}
+
+	remotePeer.sendMessage(newPbMsgResponseOrder(data.MessageData.Id, true, getBlockRangeResponse, resp))
+}
+
+// remote GetBlockRange response handler
+func (p *BlockProtocol) handleGetBlockRangeResponse(msg *types.P2PMessage) {
+	peerID := p.peer.ID()
+	remotePeer := p.peer
+
+	data := &types.GetBlockResponse{}
+	err := unmarshalMessage(msg.Data, data)
+	if err != nil {
+		return
+	}
+	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.Blocks))
+	if !p.authenticate(msg) {
+		return
+	}
+	// the response must answer a block range request pending for this peer
+	req, ok := requestedRange(remotePeer.pendingRequest(data.MessageData.Id))
+	if !ok {
+		p.logger.Info().Str(LogPeerID, peerID.Pretty()).Str(LogMsgID, data.MessageData.Id).Msg("Dropping unsolicited block range response")
+		remotePeer.adjustScore(invalidResponsePenalty, ProtocolViolation)
+		return
+	}
+	if err := checkBlockRangeResponse(req, data.Blocks); err != nil {
+		p.logger.Info().Err(err).Str(LogPeerID, peerID.Pretty()).Str(LogMsgID, data.MessageData.Id).Msg("Dropping invalid block range response")
+		remotePeer.adjustScore(invalidResponsePenalty, ProtocolViolation)
+		return
+	}
+	// remove request data, since it is answered
+	remotePeer.consumeRequest(data.MessageData.Id)
+
+	// got blocks
+	p.logger.Debug().Int("block_cnt", len(data.Blocks)).Msg("Request chainservice to add blocks")
+	for _, block := range data.Blocks {
+		p.actor.SendRequest(message.ChainSvc, &message.AddBlock{PeerID: peerID, Block: block})
+	}
+}
+
+// requestedRange returns the block range request sent as req. ok is false if req is not a block range request,
+// including nil.
+func requestedRange(req msgOrder) (data *types.GetBlockRangeRequest, ok bool) {
+	order, ok := req.(*pbMessageOrder)
+	if !ok || order.GetProtocolID() != getBlockRangeRequest {
+		return nil, false
+	}
+	msg, ok := order.message.(*types.P2PMessage)
+	if !ok {
+		return nil, false
+	}
+	data = &types.GetBlockRangeRequest{}
+	if err := unmarshalMessage(msg.Data, data); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// checkBlockRangeResponse checks if blocks are the ones requested by req: they must start from StartNo of req, be
+// no more than Count of req, and form a contiguous chain.
+func checkBlockRangeResponse(req *types.GetBlockRangeRequest, blocks []*types.Block) error {
+	if len(blocks) > int(req.Count) {
+		return fmt.Errorf("%d blocks are more than requested %d", len(blocks), req.Count)
+	}
+	if err := checkBlockRange(blocks); err != nil {
+		return err
+	}
+	if len(blocks) > 0 && blocks[0].Header.BlockNo != req.StartNo {
+		return fmt.Errorf("first block %d is not requested %d", blocks[0].Header.BlockNo, req.StartNo)
+	}
+	return nil
+}
+
+// checkBlockRange checks if blocks form a contiguous chain in ascending order.
+func checkBlockRange(blocks []*types.Block) error {
+	for i, block := range blocks {
+		if block.GetHeader() == nil {
+			return fmt.Errorf("block at %d has no header", i)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := blocks[i-1]
+		if block.Header.BlockNo != prev.Header.BlockNo+1 {
+			return fmt.Errorf("gap between block %d and %d", prev.Header.BlockNo, block.Header.BlockNo)
+		}
+		if !bytes.Equal(block.Header.PrevBlockHash, prev.BlockHash()) {
+			return fmt.Errorf("block %d is not a child of previous block", block.Header.BlockNo)
+		}
+	}
+	return nil
+}
+
 func getBlockHeader(blk *types.Block) *types.BlockHeader {
 	return blk.Header
 }
@@ -199,9 +400,7 @@ func (p *BlockProtocol) handleGetBlockHeadersResponse(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, nil)
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
@@ -222,10 +421,83 @@ func (p *BlockProtocol) handleNewBlockNotice(msg *types.P2PMessage) {
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID,
 		log.DoLazyEval(func() string { return enc.ToString(data.BlockHash) }))
 
+	if len(data.BlockHash) != len(types.HashID{}) {
+		p.logger.Info().Str(LogPeerID, peerID.Pretty()).Int("size", len(data.BlockHash)).Msg("Ignoring block notice of malformed hash")
+		remotePeer.adjustScore(invalidNoticePenalty, ProtocolViolation)
+		return
+	}
+	// the block number is not checked against the local chain if the best block is not available. The notice
+	// ahead of it is ignored but not penalized, since blocks can be produced faster than the block interval, such
+	// as by GenerateBlock in development.
+	if err := checkBlockNoticeNo(data, p.localBest(), time.Now()); err != nil {
+		p.logger.Debug().Str(LogPeerID, peerID.Pretty()).Err(err).Msg("Ignoring implausible block notice")
+		return
+	}
 	remotePeer.handleNewBlockNotice(data)
 
 }
 
+// localBest returns the best block of this node cached by the new blocks notified by chain service, or from chain
+// service if none is notified yet.
+func (p *BlockProtocol) localBest() *types.Block {
+	if best := localBestBlock.get(); best != nil {
+		return best
+	}
+	best, _ := extractBlockFromRequest(p.callRequest(message.ChainSvc, &message.GetBestBlock{}))
+	localBestBlock.update(best)
+	return best
+}
+
+// localBestBlock caches the header of the best block of this node, so that block notices from peers are checked
+// without asking chain service each time.
+var localBestBlock = &bestBlockCache{}
+
+type bestBlockCache struct {
+	value atomic.Value
+}
+
+// update caches the header of block. nil block is ignored.
+func (c *bestBlockCache) update(block *types.Block) {
+	if block.GetHeader() == nil {
+		return
+	}
+	c.value.Store(&types.Block{Header: block.GetHeader()})
+}
+
+// get returns the cached block, which has only header, or nil if nothing is cached.
+func (c *bestBlockCache) get() *types.Block {
+	block, _ := c.value.Load().(*types.Block)
+	return block
+}
+
+// maxBlockNoticeAhead is the number of blocks which a notified block can be ahead of the highest block number
+// possible by now, for the difference of clocks between nodes.
+const maxBlockNoticeAhead = 100
+
+// checkBlockNoticeNo returns an error if notice is not of a plausible block number. It must not exceed the number
+// of blocks which could be produced after best by now, by more than maxBlockNoticeAhead. The block number is not
+// checked if best is nil.
+func checkBlockNoticeNo(notice *types.NewBlockNotice, best *types.Block, now time.Time) error {
+	if best == nil {
+		return nil
+	}
+	limit := best.GetHeader().GetBlockNo() + maxBlockNoticeAhead
+	if elapsed := now.Sub(time.Unix(0, best.GetHeader().GetTimestamp())); elapsed > 0 {
+		limit += uint64(elapsed / consensus.BlockInterval)
+	}
+	if notice.BlockNo > limit {
+		return fmt.Errorf("block number %d is ahead of the possible %d", notice.BlockNo, limit)
+	}
+	return nil
+}
+
+// logSlowResponse logs that the response to the request of requestID is cut short, since chain service doesn't
+// respond by the deadline.
+func (p *BlockProtocol) logSlowResponse(remotePeer *RemotePeer, requestID string) {
+	p.logger.Info().Str(LogPeerID, remotePeer.ID().Pretty()).Str(LogMsgID, requestID).
+		Msg("Chain service is too slow, responding with the blocks found so far")
+}
+
 func max(a, b uint32) uint32 {
 	if a > b {
 		return a
@@ -254,28 +526,12 @@ func (p *BlockProtocol) NotifyBranchBlock(peer *RemotePeer, hash message.BlockHa
 
 // replying chain tree
 func (p *BlockProtocol) sendMissingResp(remotePeer *RemotePeer, requestID string, missing []message.BlockHash) {
-	// find block info from chainservice
-	blockInfos := make([]*types.Block, 0, len(missing))
-	for _, hash := range missing {
-		foundBlock, err := extractBlockFromRequest(p.actor.CallRequest(message.ChainSvc,
-			&message.GetBlock{BlockHash: hash}))
-		if err != nil || foundBlock == nil {
-			continue
-		}
-		blockInfos = append(blockInfos, foundBlock)
-	}
-	status := types.ResultStatus_OK
-	if 0 == len(blockInfos) {
-		status = types.ResultStatus_NOT_FOUND
+	hashes := make([][]byte, len(missing))
+	for i, hash := range missing {
+		hashes[i] = hash
 	}
-
-	// generate response message
-	resp := &types.GetBlockResponse{MessageData: &types.MessageData{},
-		Status: status,
-		Blocks: blockInfos}
-
 	// ???: have to check arguments
-	remotePeer.sendMessage(newPbMsgResponseOrder(requestID, true, getBlocksResponse, resp))
+	p.sendBlocks(remotePeer, requestID, hashes)
 }
 
 // remote peer requests handler
@@ -293,15 +549,13 @@ func (p *BlockProtocol) handleGetMissingRequest(msg *types.P2PMessage) {
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, log.DoLazyEval(func() string {
 		return bytesArrToString(data.Hashes)
 	}))
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
 	// send to ChainSvc
 	// find block info from chainservice
-	rawResponse, err := p.actor.CallRequest(
+	rawResponse, err := p.callRequest(
 		message.ChainSvc, &message.GetMissing{Hashes: data.Hashes, StopHash: data.Stophash})
 	if err != nil {
 		p.logger.Warn().Err(err).Msg("failed to get missing")
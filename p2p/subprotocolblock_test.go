@@ -0,0 +1,415 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/pkg/component"
+	"github.com/aergoio/aergo/types"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// makeTestChain makes chain of blocks from block number 0 to size-1
+func makeTestChain(size int) []*types.Block {
+	blocks := make([]*types.Block, size)
+	var prevHash []byte
+	for i := range blocks {
+		blocks[i] = &types.Block{Header: &types.BlockHeader{BlockNo: uint64(i), PrevBlockHash: prevHash,
+			Timestamp: int64(i)}}
+		prevHash = blocks[i].BlockHash()
+	}
+	return blocks
+}
+
+func TestCheckBlockRange(t *testing.T) {
+	chain := makeTestChain(5)
+	forked := &types.Block{Header: &types.BlockHeader{BlockNo: 3, PrevBlockHash: []byte("other")}}
+	tests := []struct {
+		name    string
+		blocks  []*types.Block
+		wantErr bool
+	}{
+		{"TEmpty", []*types.Block{}, false},
+		{"TSingle", chain[2:3], false},
+		{"TContiguous", chain, false},
+		{"TGap", []*types.Block{chain[0], chain[1], chain[3]}, true},
+		{"TDescending", []*types.Block{chain[2], chain[1]}, true},
+		{"TNotChild", []*types.Block{chain[1], chain[2], forked}, true},
+		{"TNoHeader", []*types.Block{chain[0], &types.Block{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBlockRange(tt.blocks)
+			assert.Equal(t, tt.wantErr, err != nil, fmt.Sprintf("err %v", err))
+		})
+	}
+}
+
+func TestBlockProtocol_handleGetBlockRangeRequest(t *testing.T) {
+	chain := makeTestChain(150)
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	mockActor := new(MockActorService)
+	mockActor.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBlockByNo"), mock.Anything).Return(
+		func(_ string, msg interface{}, _ time.Duration) interface{} {
+			no := msg.(*message.GetBlockByNo).BlockNo
+			if no >= uint64(len(chain)) {
+				return message.GetBlockByNoRsp{Err: fmt.Errorf("not found")}
+			}
+			return message.GetBlockByNoRsp{Block: chain[no]}
+		}, nil)
+
+	tests := []struct {
+		name       string
+		startNo    uint64
+		count      uint32
+		wantStatus types.ResultStatus
+		wantCnt    int
+	}{
+		{"TNormal", 5, 10, types.ResultStatus_OK, 10},
+		{"TCapped", 0, 500, types.ResultStatus_OK, maxBlockRangeSize},
+		{"TPartial", 140, 20, types.ResultStatus_OK, 10},
+		{"TNotFound", 200, 10, types.ResultStatus_NOT_FOUND, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requester := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+			handler := NewBlockHandler(mockPM, requester, logger)
+
+			req := &types.GetBlockRangeRequest{MessageData: &types.MessageData{Id: "req"}, StartNo: tt.startNo, Count: tt.count}
+			data, _ := marshalMessage(req)
+			sent := make(chan msgOrder, 1)
+			go func() { sent <- <-requester.write }()
+			handler.handleGetBlockRangeRequest(&types.P2PMessage{Header: &types.MessageData{Id: "req", Subprotocol: getBlockRangeRequest.Uint32()}, Data: data})
+
+			order := (<-sent).(*pbMessageOrder)
+			assert.Equal(t, getBlockRangeResponse, order.GetProtocolID())
+			resp := &types.GetBlockResponse{}
+			assert.Nil(t, unmarshalMessage(order.message.(*types.P2PMessage).Data, resp))
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Len(t, resp.Blocks, tt.wantCnt)
+			for i, block := range resp.Blocks {
+				assert.Equal(t, tt.startNo+uint64(i), block.Header.BlockNo)
+			}
+		})
+	}
+}
+
+func TestBlockProtocol_slowChainService(t *testing.T) {
+	chain := makeTestChain(10)
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	mockActor := new(MockActorService)
+	// the slow call is given up by the future of the request, within the time limit of the handler
+	withinLimit := mock.MatchedBy(func(timeout time.Duration) bool { return timeout > 0 && timeout <= 50*time.Millisecond })
+	mockActor.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBlockByNo"), withinLimit).Return(
+		nil, component.ErrComponentTimeout)
+	requester := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+	handler := NewBlockHandler(mockPM, requester, logger)
+	handler.callTimeout = 50 * time.Millisecond
+
+	req := &types.GetBlockRangeRequest{MessageData: &types.MessageData{Id: "req"}, StartNo: 0, Count: 5}
+	data, _ := marshalMessage(req)
+	sent := make(chan msgOrder, 1)
+	go func() { sent <- <-requester.write }()
+	start := time.Now()
+	handler.handleGetBlockRangeRequest(&types.P2PMessage{Header: &types.MessageData{Id: "req", Subprotocol: getBlockRangeRequest.Uint32()}, Data: data})
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "handler blocked for %v", time.Since(start))
+	mockActor.AssertNumberOfCalls(t, "CallRequestWithTimeout", 1)
+
+	order := (<-sent).(*pbMessageOrder)
+	resp := &types.GetBlockResponse{}
+	assert.Nil(t, unmarshalMessage(order.message.(*types.P2PMessage).Data, resp))
+	assert.Equal(t, types.ResultStatus_NOT_FOUND, resp.Status)
+	assert.Empty(t, resp.Blocks)
+	assert.Equal(t, int32(0), requester.Score())
+}
+
+func TestBlockProtocol_handleGetBlockHeadersRequest(t *testing.T) {
+	chain := makeTestChain(50)
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	mockActor := new(MockActorService)
+	mockActor.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBlockByNo"), mock.Anything).Return(
+		func(_ string, msg interface{}, _ time.Duration) interface{} {
+			no := msg.(*message.GetBlockByNo).BlockNo
+			if no >= uint64(len(chain)) {
+				return message.GetBlockByNoRsp{Err: fmt.Errorf("not found")}
+			}
+			return message.GetBlockByNoRsp{Block: chain[no]}
+		}, nil)
+
+	tests := []struct {
+		name       string
+		maxHeaders int
+		height     uint64
+		size       uint32
+		wantCnt    int
+	}{
+		{"TCapped", 10, 40, 30, 10},
+		{"TRequestedSmaller", 10, 40, 5, 5},
+		{"TDefaultCap", 0, 40, 30, 30},
+		{"TMassiveRange", 10, 49, 1 << 31, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requester := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+			handler := NewBlockHandler(mockPM, requester, logger)
+			handler.maxHeaders = tt.maxHeaders
+
+			req := &types.GetBlockHeadersRequest{MessageData: &types.MessageData{Id: "req"}, Height: tt.height, Size: tt.size}
+			data, _ := marshalMessage(req)
+			sent := make(chan msgOrder, 1)
+			go func() { sent <- <-requester.write }()
+			handler.handleGetBlockHeadersRequest(&types.P2PMessage{Header: &types.MessageData{Id: "req", Subprotocol: getBlockHeadersRequest.Uint32()}, Data: data})
+
+			order := (<-sent).(*pbMessageOrder)
+			assert.Equal(t, getBlockHeadersResponse, order.GetProtocolID())
+			resp := &types.GetBlockHeadersResponse{}
+			assert.Nil(t, unmarshalMessage(order.message.(*types.P2PMessage).Data, resp))
+			assert.Len(t, resp.Headers, tt.wantCnt)
+			assert.Len(t, resp.Hashes, tt.wantCnt)
+			// headers are from the requested height downward, so the requester continues below the last one
+			for i, header := range resp.Headers {
+				assert.Equal(t, tt.height-uint64(i), header.BlockNo)
+			}
+		})
+	}
+}
+
+func TestBlockProtocol_handleGetBlockRangeResponse(t *testing.T) {
+	chain := makeTestChain(5)
+	tests := []struct {
+		name      string
+		requested bool
+		blocks    []*types.Block
+		wantAdded int
+		wantScore int32
+	}{
+		{"TValid", true, chain[1:4], 3, usefulResponseReward},
+		{"TNotFound", true, nil, 0, usefulResponseReward},
+		{"TGap", true, []*types.Block{chain[1], chain[3]}, 0, invalidResponsePenalty},
+		{"TWrongStart", true, chain[2:4], 0, invalidResponsePenalty},
+		{"TTooMany", true, chain[1:5], 0, invalidResponsePenalty},
+		{"TUnsolicited", false, chain[1:4], 0, invalidResponsePenalty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPM := new(MockP2PService)
+			mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+			mockActor := new(MockActorService)
+			mockActor.On("SendRequest", message.ChainSvc, mock.AnythingOfType("*message.AddBlock"))
+			target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+			handler := NewBlockHandler(mockPM, target, logger)
+
+			reqID := "unknown"
+			if tt.requested {
+				order := newPbMsgRequestOrder(true, true, getBlockRangeRequest,
+					&types.GetBlockRangeRequest{MessageData: &types.MessageData{}, StartNo: 1, Count: 3})
+				reqID = order.GetRequestID()
+				target.requests[reqID] = order
+			}
+
+			resp := &types.GetBlockResponse{MessageData: &types.MessageData{Id: reqID}, Status: types.ResultStatus_OK, Blocks: tt.blocks}
+			data, _ := marshalMessage(resp)
+			handler.handleGetBlockRangeResponse(&types.P2PMessage{Header: &types.MessageData{Id: reqID, Subprotocol: getBlockRangeResponse.Uint32()}, Data: data})
+
+			mockActor.AssertNumberOfCalls(t, "SendRequest", tt.wantAdded)
+			assert.Equal(t, tt.wantScore, target.Score())
+			if tt.wantScore > 0 {
+				assert.Nil(t, target.pendingRequest(reqID))
+			}
+		})
+	}
+
+	// a request of the other protocol is not answered by a block range response
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, new(MockActorService), logger)
+	order := newPbMsgRequestOrder(true, true, getBlocksRequest, &types.GetBlockRequest{MessageData: &types.MessageData{}})
+	target.requests[order.GetRequestID()] = order
+	_, ok := requestedRange(target.pendingRequest(order.GetRequestID()))
+	assert.False(t, ok)
+}
+
+func TestBlockProtocol_handleNewBlockNotice(t *testing.T) {
+	best := &types.Block{Header: &types.BlockHeader{BlockNo: 1000, Timestamp: time.Now().UnixNano()}}
+	hash := make([]byte, len(types.HashID{}))
+	tests := []struct {
+		name       string
+		hash       []byte
+		blockNo    uint64
+		wantHeight uint64
+		wantScore  int32
+	}{
+		{"TNext", hash, 1001, 1001, 0},
+		{"TOld", hash, 10, 10, 0},
+		{"TAheadInSlack", hash, 1000 + maxBlockNoticeAhead, 1000 + maxBlockNoticeAhead, 0},
+		// ignored, but not penalized
+		{"TImplausible", hash, 1 << 40, 0, 0},
+		{"TMalformedHash", hash[:10], 1001, 0, invalidNoticePenalty},
+	}
+	defer func() { localBestBlock = &bestBlockCache{} }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localBestBlock = &bestBlockCache{}
+			mockPM := new(MockP2PService)
+			mockPM.On("HandleNewBlockNotice", dummyPeerID, mock.Anything, mock.AnythingOfType("*types.NewBlockNotice"))
+			mockActor := new(MockActorService)
+			mockActor.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock"), mock.Anything).Return(
+				message.GetBestBlockRsp{Block: best}, nil)
+			target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+			handler := NewBlockHandler(mockPM, target, logger)
+
+			notice := &types.NewBlockNotice{MessageData: &types.MessageData{Id: "notice"}, BlockHash: tt.hash, BlockNo: tt.blockNo}
+			data, _ := marshalMessage(notice)
+			handler.handleNewBlockNotice(&types.P2PMessage{Header: &types.MessageData{Id: "notice", Subprotocol: newBlockNotice.Uint32()}, Data: data})
+
+			if tt.wantHeight == 0 {
+				mockPM.AssertNotCalled(t, "HandleNewBlockNotice", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mockPM.AssertNumberOfCalls(t, "HandleNewBlockNotice", 1)
+			}
+			assert.Equal(t, tt.wantHeight, target.BestHeight())
+			assert.Equal(t, tt.wantScore, target.Score())
+		})
+	}
+
+	// the best block notified by chain service is used without asking chain service
+	localBestBlock = &bestBlockCache{}
+	newBest := &types.Block{Header: &types.BlockHeader{BlockNo: 5000, Timestamp: time.Now().UnixNano()}, Hash: hash}
+	peerMan := new(MockP2PService)
+	peerMan.On("GetPeers").Return([]*RemotePeer{})
+	(&P2P{pm: peerMan}).NotifyNewBlock(message.NotifyNewBlock{BlockNo: 5000, Block: newBest})
+	mockPM := new(MockP2PService)
+	mockPM.On("HandleNewBlockNotice", dummyPeerID, mock.Anything, mock.AnythingOfType("*types.NewBlockNotice"))
+	mockActor := new(MockActorService)
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+	handler := NewBlockHandler(mockPM, target, logger)
+	notice := &types.NewBlockNotice{MessageData: &types.MessageData{Id: "notice"}, BlockHash: hash, BlockNo: 5001}
+	data, _ := marshalMessage(notice)
+	handler.handleNewBlockNotice(&types.P2PMessage{Header: &types.MessageData{Id: "notice", Subprotocol: newBlockNotice.Uint32()}, Data: data})
+	assert.Equal(t, uint64(5001), target.BestHeight())
+	mockActor.AssertNotCalled(t, "CallRequestWithTimeout", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBlockProtocol_handleBlockRequest(t *testing.T) {
+	// blocks of about 100KB
+	chain := makeTestChain(20)
+	found := make(map[string]*types.Block)
+	for _, block := range chain {
+		block.Body = &types.BlockBody{Txs: []*types.Tx{{Body: &types.TxBody{Payload: make([]byte, 100000)}}}}
+		found[string(block.BlockHash())] = block
+	}
+	// the largest one, since the genesis block has no previous hash
+	blockSize := proto.Size(chain[1])
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	mockActor := new(MockActorService)
+	mockActor.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBlock"), mock.Anything).Return(
+		func(_ string, msg interface{}, _ time.Duration) interface{} {
+			block, exists := found[string(msg.(*message.GetBlock).BlockHash)]
+			if !exists {
+				return message.GetBlockRsp{Err: fmt.Errorf("not found")}
+			}
+			return message.GetBlockRsp{Block: block}
+		}, nil)
+	hashes := make([][]byte, len(chain))
+	for i, block := range chain {
+		hashes[i] = block.BlockHash()
+	}
+
+	tests := []struct {
+		name       string
+		hashes     [][]byte
+		chunkSize  int
+		sizeBudget int
+		wantStatus types.ResultStatus
+		wantCnt    int
+		wantMsgs   int
+	}{
+		{"TChunked", hashes, 3 * blockSize, 0, types.ResultStatus_OK, 20, 7},
+		{"TBudget", hashes, 3 * blockSize, 10*blockSize - 100, types.ResultStatus_OK, 9, 3},
+		{"TLargerThanChunk", hashes[:3], blockSize / 2, 0, types.ResultStatus_OK, 3, 3},
+		{"TNotFound", [][]byte{[]byte("unknown")}, 0, 0, types.ResultStatus_NOT_FOUND, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requester := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+			handler := NewBlockHandler(mockPM, requester, logger)
+			handler.chunkSize, handler.sizeBudget = tt.chunkSize, tt.sizeBudget
+			chunkSize := tt.chunkSize
+			if chunkSize <= 0 {
+				chunkSize = defaultBlockChunkSize
+			}
+
+			req := &types.GetBlockRequest{MessageData: &types.MessageData{Id: "req"}, Hashes: tt.hashes}
+			data, _ := marshalMessage(req)
+			sent := make(chan []*types.GetBlockResponse, 1)
+			go func() {
+				var resps []*types.GetBlockResponse
+				for order := range requester.write {
+					resp := &types.GetBlockResponse{}
+					assert.Nil(t, unmarshalMessage(order.(*pbMessageOrder).message.(*types.P2PMessage).Data, resp))
+					resps = append(resps, resp)
+					if !resp.HasNext {
+						break
+					}
+				}
+				sent <- resps
+			}()
+			handler.handleBlockRequest(&types.P2PMessage{Header: &types.MessageData{Id: "req", Subprotocol: getBlocksRequest.Uint32()}, Data: data})
+
+			resps := <-sent
+			assert.Len(t, resps, tt.wantMsgs)
+			var blocks []*types.Block
+			for i, resp := range resps {
+				assert.Equal(t, tt.wantStatus, resp.Status)
+				assert.Equal(t, i < len(resps)-1, resp.HasNext)
+				// a message is bounded by the chunk size, unless it has a single block
+				size := 0
+				for _, block := range resp.Blocks {
+					size += proto.Size(block)
+				}
+				assert.True(t, size <= chunkSize || len(resp.Blocks) == 1, "message %d has %d bytes", i, size)
+				blocks = append(blocks, resp.Blocks...)
+			}
+			if assert.Len(t, blocks, tt.wantCnt) {
+				for i, block := range blocks {
+					assert.Equal(t, chain[i].BlockHash(), block.BlockHash())
+				}
+			}
+		})
+	}
+}
+
+func TestBlockProtocol_handleGetBlockResponse(t *testing.T) {
+	chain := makeTestChain(4)
+	mockPM := new(MockP2PService)
+	mockPM.On("AuthenticateMessage", mock.Anything, mock.Anything).Return(true)
+	mockActor := new(MockActorService)
+	mockActor.On("SendRequest", message.ChainSvc, mock.AnythingOfType("*message.AddBlock"))
+	target := newRemotePeer(PeerMeta{ID: dummyPeerID}, mockPM, mockActor, logger)
+	handler := NewBlockHandler(mockPM, target, logger)
+
+	// the request is consumed by its last response
+	for i, hasNext := range []bool{true, false} {
+		resp := &types.GetBlockResponse{MessageData: &types.MessageData{Id: "resp"}, Status: types.ResultStatus_OK,
+			Blocks: chain[i*2 : i*2+2], HasNext: hasNext}
+		data, _ := marshalMessage(resp)
+		handler.handleGetBlockResponse(&types.P2PMessage{Header: &types.MessageData{Id: "resp", Subprotocol: getBlocksResponse.Uint32()}, Data: data})
+		if hasNext {
+			assert.Equal(t, int32(0), target.Score())
+		}
+	}
+	mockActor.AssertNumberOfCalls(t, "SendRequest", 4)
+	assert.Equal(t, int32(usefulResponseReward), target.Score())
+}
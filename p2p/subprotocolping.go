@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
 )
 
@@ -25,6 +26,9 @@ type PingProtocol struct {
 // PingHandler handle pingRequest message
 type PingHandler struct {
 	BaseMsgHandler
+	// maxAddrs is the maximum number of addresses in a response to addresses request.
+	// DefaultMaxAddressesPerResponse is used if it is not positive.
+	maxAddrs int
 }
 
 // NewPingHandler create handler about ping protocol for a peer
@@ -50,11 +54,18 @@ func (p *PingHandler) handlePing(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), msg.Header.Id, peerID, nil)
+	remotePeer.updateBestHeight(pingMsg.BestHeight)
 
 	// generate response message
 	p.logger.Debug().Str(LogPeerID, peerID.Pretty()).Str(LogMsgID, msg.Header.Id).Msg("Sending ping response")
-	resp := &types.Pong{MessageData: &types.MessageData{}} // BestBlockHash: bestBlock.GetHash(),
-	// BestHeight:    bestBlock.GetHeader().GetBlockNo(),
+	resp := &types.Pong{MessageData: &types.MessageData{}}
+	bestBlock, err := extractBlockFromRequest(p.callRequest(message.ChainSvc, &message.GetBestBlock{}))
+	if err != nil {
+		p.logger.Warn().Err(err).Msg("Failed to get best block")
+	} else {
+		resp.BestBlockHash = bestBlock.BlockHash()
+		resp.BestHeight = bestBlock.GetHeader().GetBlockNo()
+	}
 
 	remotePeer.sendMessage(newPbMsgResponseOrder(msg.Header.Id, false, pingResponse, resp))
 }
@@ -71,6 +82,10 @@ func (p *PingHandler) handlePingResponse(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), msg.Header.Id, peerID, nil)
+	remotePeer.updateLatency()
+	if pingRspMsg.BestHeight > 0 {
+		remotePeer.updateBestHeight(pingRspMsg.BestHeight)
+	}
 	remotePeer.consumeRequest(msg.Header.Id)
 }
 
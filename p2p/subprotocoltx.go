@@ -55,9 +55,7 @@ func (p *TxProtocol) handleGetTXsRequest(msg *types.P2PMessage) {
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.Hashes))
 
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
@@ -70,7 +68,7 @@ func (p *TxProtocol) handleGetTXsRequest(msg *types.P2PMessage) {
 	hashes := make([][]byte, 0, len(data.Hashes))
 	txInfos := make([]*types.Tx, 0, len(data.Hashes))
 	// FIXME: chain에 들어간 트랜잭션을 볼 방법이 없다. 멤풀도 검색이 안 되서 전체를 다 본 다음에 그중에 매칭이 되는 것을 추출하는 방식으로 처리한다.
-	txs, _ := extractTXsFromRequest(p.actor.CallRequest(message.MemPoolSvc,
+	txs, _ := extractTXsFromRequest(p.callRequest(message.MemPoolSvc,
 		&message.MemPoolGet{}))
 	for _, tx := range txs {
 		hash, found := hashesMap[enc.ToString(tx.Hash)]
@@ -102,9 +100,7 @@ func (p *TxProtocol) handleGetTXsResponse(msg *types.P2PMessage) {
 		return
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID, len(data.Txs))
-	valid := p.pm.AuthenticateMessage(data, data.MessageData)
-	if !valid {
-		p.logger.Info().Msg("Failed to authenticate message")
+	if !p.authenticate(msg) {
 		return
 	}
 
@@ -128,11 +124,20 @@ func (p *TxProtocol) handleNewTXsNotice(msg *types.P2PMessage) {
 	}
 	debugLogReceiveMsg(p.logger, SubProtocol(msg.Header.Subprotocol), data.MessageData.Id, peerID,
 		log.DoLazyEval(func() string { return bytesArrToString(data.TxHashes) }))
+	p.peer.updateTxCache(data.TxHashes)
 	// TODO: check myself and request txs which this node don't have.
-	toGet := make([]message.TXHash, len(data.TxHashes))
+	toGet := make([]message.TXHash, 0, len(data.TxHashes))
 	// 임시조치로 일단 다 가져온다.
-	for i, hashByte := range data.TxHashes {
-		toGet[i] = message.TXHash(hashByte)
+	for _, hashByte := range data.TxHashes {
+		// drop txs which are confirmed already
+		if p.pm.IsConfirmedTx(hashByte) {
+			continue
+		}
+		toGet = append(toGet, message.TXHash(hashByte))
+	}
+	if len(toGet) == 0 {
+		p.logger.Debug().Str(LogPeerID, peerID.Pretty()).Msg("All txs in notice are confirmed already")
+		return
 	}
 	// create message data
 	p.actor.SendRequest(message.P2PSvc, &message.GetTransactions{ToWhom: peerID, Hashes: toGet})
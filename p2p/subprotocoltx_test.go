@@ -6,6 +6,7 @@ package p2p
 
 import (
 	"testing"
+	"time"
 
 	"github.com/aergoio/aergo-actor/actor"
 )
@@ -22,6 +23,9 @@ func (m mockIServ) SendRequest(actor string, msg interface{}) {
 func (m mockIServ) CallRequest(actor string, msg interface{}) (interface{}, error) {
 	return nil, nil
 }
+func (m mockIServ) CallRequestWithTimeout(actor string, msg interface{}, timeout time.Duration) (interface{}, error) {
+	return nil, nil
+}
 func (m mockIServ) FutureRequest(actor string, msg interface{}) *actor.Future {
 	return nil
 }
@@ -0,0 +1,239 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	ss "github.com/libp2p/go-conn-security"
+	libp2p "github.com/libp2p/go-libp2p"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/libp2p/go-libp2p-peer"
+	secio "github.com/libp2p/go-libp2p-secio"
+)
+
+// tlsID is the id of security transport, which is negotiated when connecting to peer.
+const tlsID = "/aergo/tls/1.0.0"
+
+const (
+	// tlsBindingLabel prefixes the certificates of tls session, which are signed by node key of each peer.
+	tlsBindingLabel   = "aergo-p2p-identity"
+	maxIdentityLength = 4096
+)
+
+// tlsTransport is libp2p security transport which encrypts connections by TLS with the certificate in config.
+// Certificates of peers are not verified, since peers authenticate each other by their node key instead,
+// with the signature of the certificates of both sides, which are bound to the tls session by their keys.
+type tlsTransport struct {
+	privKey crypto.PrivKey
+	localID peer.ID
+	config  *tls.Config
+}
+
+var _ ss.Transport = (*tlsTransport)(nil)
+
+// tlsConn is the connection secured by tlsTransport
+type tlsConn struct {
+	*tls.Conn
+	transport *tlsTransport
+	remoteID  peer.ID
+	remoteKey crypto.PubKey
+}
+
+var _ ss.Conn = (*tlsConn)(nil)
+
+// securityOptions returns libp2p options of TLS if it is enabled in config, or secio otherwise. Both of them
+// enable TCP keep-alive on the connections if it is configured.
+func (ps *peerManager) securityOptions() []libp2p.Option {
+	var id string
+	var transport ss.Transport
+	if ps.tlsTransport != nil {
+		id, transport = tlsID, ps.tlsTransport
+	} else if ps.keepAlive > 0 {
+		secioTransport, err := secio.New(ps.privateKey)
+		if err != nil {
+			ps.log.Warn().Err(err).Msg("Failed to create secio transport, TCP keep-alive is disabled")
+			return nil
+		}
+		id, transport = secio.ID, secioTransport
+	} else {
+		// default security transport
+		return nil
+	}
+	if ps.keepAlive > 0 {
+		transport = &keepAliveTransport{Transport: transport, period: ps.keepAlive}
+	}
+	return []libp2p.Option{libp2p.Security(id, transport)}
+}
+
+func newTLSTransport(certFile, keyFile string, privKey crypto.PrivKey) (*tlsTransport, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both of certificate and key file must be set")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate %s and key %s: %s", certFile, keyFile, err.Error())
+	}
+	localID, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true,
+		// client must present its certificate, which is signed as a part of its identity
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	return &tlsTransport{privKey: privKey, localID: localID, config: config}, nil
+}
+
+func (t *tlsTransport) SecureInbound(ctx context.Context, insecure net.Conn) (ss.Conn, error) {
+	return t.secure(ctx, tls.Server(insecure, t.config), true, "")
+}
+
+func (t *tlsTransport) SecureOutbound(ctx context.Context, insecure net.Conn, p peer.ID) (ss.Conn, error) {
+	return t.secure(ctx, tls.Client(insecure, t.config), false, p)
+}
+
+// secure does tls handshake and then authenticates remote peer. expected is empty for inbound connection.
+func (t *tlsTransport) secure(ctx context.Context, conn *tls.Conn, server bool, expected peer.ID) (ss.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := conn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	remoteKey, err := t.exchangeIdentity(conn, server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	remoteID, err := peer.IDFromPublicKey(remoteKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if remoteID == t.localID || (expected != "" && remoteID != expected) {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected peer %s", remoteID.Pretty())
+	}
+	return &tlsConn{Conn: conn, transport: t, remoteID: remoteID, remoteKey: remoteKey}, nil
+}
+
+// exchangeIdentity sends public key of local node and the signature of the certificates of tls session,
+// and then receives and verifies those of remote peer.
+func (t *tlsTransport) exchangeIdentity(conn *tls.Conn, server bool) (crypto.PubKey, error) {
+	material, err := t.sessionBinding(conn, server)
+	if err != nil {
+		return nil, err
+	}
+	sign, err := t.privKey.Sign(material)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := t.privKey.GetPublic().Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// both sides send first, so that sending is done in another goroutine
+	sent := make(chan error, 1)
+	go func() {
+		sent <- writeFrames(conn, pubKey, sign)
+	}()
+	remotePubKey, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	remoteSign, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	if err = <-sent; err != nil {
+		return nil, err
+	}
+
+	remoteKey, err := crypto.UnmarshalPublicKey(remotePubKey)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := remoteKey.Verify(material, remoteSign)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("invalid signature of remote peer")
+	}
+	return remoteKey, nil
+}
+
+// sessionBinding returns the digest of the certificates of server and client of conn, which are used in the
+// session, so a man in the middle who doesn't have their keys can't relay the signature of it.
+func (t *tlsTransport) sessionBinding(conn *tls.Conn, server bool) ([]byte, error) {
+	peerCerts := conn.ConnectionState().PeerCertificates
+	if len(peerCerts) == 0 {
+		return nil, fmt.Errorf("no certificate of remote peer")
+	}
+	localCert, remoteCert := t.config.Certificates[0].Certificate[0], peerCerts[0].Raw
+	serverCert, clientCert := remoteCert, localCert
+	if server {
+		serverCert, clientCert = localCert, remoteCert
+	}
+	digest := sha256.New()
+	digest.Write([]byte(tlsBindingLabel))
+	writeFrames(digest, serverCert, clientCert)
+	return digest.Sum(nil), nil
+}
+
+func writeFrames(w io.Writer, frames ...[]byte) error {
+	for _, frame := range frames {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(frame)))
+		if _, err := w.Write(append(length[:], frame...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > maxIdentityLength {
+		return nil, fmt.Errorf("too large identity frame %d", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (c *tlsConn) LocalPeer() peer.ID {
+	return c.transport.localID
+}
+
+func (c *tlsConn) LocalPrivateKey() crypto.PrivKey {
+	return c.transport.privKey
+}
+
+func (c *tlsConn) RemotePeer() peer.ID {
+	return c.remoteID
+}
+
+func (c *tlsConn) RemotePublicKey() crypto.PubKey {
+	return c.remoteKey
+}
@@ -0,0 +1,141 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo/message"
+	"github.com/aergoio/aergo/types"
+	libp2p "github.com/libp2p/go-libp2p"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	inet "github.com/libp2p/go-libp2p-net"
+	peer "github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/libp2p/go-libp2p-peerstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// writeTestCert writes self-signed certificate and its key in PEM format to dir
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err.Error())
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "aergo"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err.Error())
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	certFile, keyFile := filepath.Join(dir, "n2n.crt"), filepath.Join(dir, "n2n.key")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+// newTLSPeerManager returns peerManager of which host is secured by TLS
+func newTLSPeerManager(t *testing.T, certFile, keyFile string) *peerManager {
+	priv, pub, _ := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+	pid, _ := peer.IDFromPublicKey(pub)
+	transport, err := newTLSTransport(certFile, keyFile, priv)
+	if err != nil {
+		t.Fatalf("failed to create tls transport: %s", err.Error())
+	}
+	mockActorServ := &MockActorService{}
+	mockActorServ.On("CallRequestWithTimeout", message.ChainSvc, mock.AnythingOfType("*message.GetBestBlock"), mock.Anything).
+		Return(message.GetBestBlockRsp{Block: types.NewBlock(nil, make([]*types.Tx, 0), 0)}, nil)
+	pm := &peerManager{log: logger, mutex: &sync.Mutex{}, iServ: mockActorServ, privateKey: priv, publicKey: pub,
+		selfMeta: PeerMeta{ID: pid, IPAddress: "127.0.0.1"}, remotePeers: make(map[peer.ID]*RemotePeer), tlsTransport: transport}
+	pm.Host = newTestHost(t, append(pm.securityOptions(), libp2p.Identity(priv))...)
+	return pm
+}
+
+func TestNewTLSTransport(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "n2ntls")
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCert(t, dir)
+	priv, _, _ := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{"TValid", certFile, keyFile, false},
+		{"TNoCert", "", keyFile, true},
+		{"TNoKey", certFile, "", true},
+		{"TMissingCert", filepath.Join(dir, "missing.crt"), keyFile, true},
+		{"TNotKey", certFile, certFile, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newTLSTransport(tt.certFile, tt.keyFile, priv)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				assert.Nil(t, transport)
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, transport)
+			}
+		})
+	}
+}
+
+func TestTLSTransport_doHandshake(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "n2ntls")
+	defer os.RemoveAll(dir)
+	// each peer can have its own certificate
+	remoteCert, remoteKey := writeTestCert(t, dir)
+	remote := newTLSPeerManager(t, remoteCert, remoteKey)
+	defer remote.Host.Close()
+	localDir := filepath.Join(dir, "local")
+	os.Mkdir(localDir, 0700)
+	localCert, localKey := writeTestCert(t, localDir)
+	local := newTLSPeerManager(t, localCert, localKey)
+	defer local.Host.Close()
+
+	remoteResult := make(chan bool, 1)
+	remote.setProtocolHandlers(func(s inet.Stream) {
+		defer s.Close()
+		rw := bufio.NewReadWriter(bufio.NewReader(s), bufio.NewWriter(s))
+		remoteResult <- doHandshake(remote, s.Conn().RemotePeer(), rw)
+	})
+	local.Peerstore().AddAddrs(remote.ID(), remote.Addrs(), pstore.TempAddrTTL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	s, err := local.newP2PStream(ctx, remote.ID())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(s), bufio.NewWriter(s))
+	assert.True(t, doHandshake(local, remote.ID(), rw))
+	assert.True(t, <-remoteResult)
+	assert.Equal(t, remote.ID(), s.Conn().RemotePeer())
+
+	// peer without TLS can't connect to the TLS peer
+	plain := newTestHost(t)
+	defer plain.Close()
+	err = plain.Connect(ctx, pstore.PeerInfo{ID: remote.ID(), Addrs: remote.Addrs()})
+	assert.NotNil(t, err)
+}
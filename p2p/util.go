@@ -10,12 +10,17 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aergoio/aergo-actor/actor"
 	"github.com/aergoio/aergo-lib/log"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/types"
 	peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -35,6 +40,8 @@ type ActorService interface {
 	// CallReqeust send actor request and wait the handling of that message to finished,
 	// and get return value.
 	CallRequest(actor string, msg interface{}) (interface{}, error)
+	// CallRequestWithTimeout is the same as CallRequest, but waits for the return value only for timeout.
+	CallRequestWithTimeout(actor string, msg interface{}, timeout time.Duration) (interface{}, error)
 	// FutureRequest send actor reqeust and get the Future object to get the state and return value of message
 	FutureRequest(actor string, msg interface{}) *actor.Future
 }
@@ -96,6 +103,48 @@ func setIP(a *types.PeerAddress, ipAddress net.IP) {
 	a.Address = []byte(ipAddress)
 }
 
+// ipProtocol returns multiaddr protocol name of the address family of ip.
+func ipProtocol(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ip4"
+	}
+	return "ip6"
+}
+
+// toMultiAddr make tcp multiaddr of ip address and port, such as /ip4/172.21.11.12/tcp/7846 or
+// /ip6/2001:db8::1/tcp/7846 according to the address family.
+func toMultiAddr(ipAddress string, port uint32) (ma.Multiaddr, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address %s", ipAddress)
+	}
+	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", ipProtocol(ip), ip.String(), port))
+}
+
+// toPeerMultiAddr makes tcp multiaddr of host and port to dial peer. It is same as toMultiAddr if host is
+// ip address, or dns multiaddr such as /dns4/node1.example.com/tcp/7846 if host is hostname, which
+// is resolved just before dialing.
+func toPeerMultiAddr(host string, port uint32) (ma.Multiaddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return toMultiAddr(host, port)
+	}
+	if !validHostname.MatchString(host) {
+		return nil, fmt.Errorf("invalid hostname %s", host)
+	}
+	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", madns.Dns4Protocol.Name, host, port))
+}
+
+// bracketedIP6 matches ipv6 literal in brackets, such as /ip6/[2001:db8::1]/tcp/7846
+var bracketedIP6 = regexp.MustCompile(`/ip6/\[([0-9a-fA-F:.]+)\]`)
+
+// normalizeAddrString converts address string in config to the form which go-multiaddr can parse.
+func normalizeAddrString(target string) string {
+	// go-multiaddr pinned in glide.lock knows the protocol of peer id only by the deprecated name ipfs, so the
+	// recent name p2p is translated. Both names are accepted in config, and /ipfs/ is kept working after upgrade.
+	target = strings.Replace(target, "/p2p/", "/ipfs/", 1)
+	return bracketedIP6.ReplaceAllString(target, "/ip6/$1")
+}
+
 // RandomUUID generate random UUID and return in form of string
 func RandomUUID() string {
 	return uuid.Must(uuid.NewV4()).String()
@@ -106,6 +155,7 @@ func externalIP() (net.IP, error) {
 	if err != nil {
 		return nil, err
 	}
+	var ip6 net.IP
 	for _, iface := range ifaces {
 		if iface.Flags&net.FlagUp == 0 {
 			continue // interface down
@@ -128,13 +178,19 @@ func externalIP() (net.IP, error) {
 			if ip == nil || ip.IsLoopback() {
 				continue
 			}
-			ip = ip.To4()
-			if ip == nil {
-				continue // not an ipv4 address
+			if ip.To4() == nil {
+				// ipv4 address is preferred, and ipv6 one is used only in ipv6 only host
+				if ip6 == nil && ip.IsGlobalUnicast() {
+					ip6 = ip
+				}
+				continue
 			}
-			return ip, nil
+			return ip.To4(), nil
 		}
 	}
+	if ip6 != nil {
+		return ip6, nil
+	}
 	return nil, errors.New("no external ip address found")
 }
 
@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -39,6 +40,79 @@ func TestGetIP(t *testing.T) {
 	}
 
 }
+func TestToMultiAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       string
+		port     uint32
+		expected string
+		wantErr  bool
+	}{
+		{"TIP4", "172.21.11.12", 3456, "/ip4/172.21.11.12/tcp/3456", false},
+		{"TIP6", "2001:0db8:85a3:08d3:1319:8a2e:0370:7334", 3456, "/ip6/2001:db8:85a3:8d3:1319:8a2e:370:7334/tcp/3456", false},
+		{"TIP6Loopback", "::1", 7846, "/ip6/::1/tcp/7846", false},
+		{"TIP4Mapped", "::ffff:192.0.1.2", 7846, "/ip4/192.0.1.2/tcp/7846", false},
+		{"TUnspecified6", "::", 7846, "/ip6/::/tcp/7846", false},
+		{"TInvalid", "172.21.11", 3456, "", true},
+		{"THostname", "localhost", 3456, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := toMultiAddr(tt.ip, tt.port)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, actual.String())
+
+			// round trip
+			parsed, err := ma.NewMultiaddr(actual.String())
+			assert.Nil(t, err)
+			assert.True(t, actual.Equal(parsed))
+			netAddr, err := mnet.ToNetAddr(parsed)
+			assert.Nil(t, err)
+			assert.True(t, net.ParseIP(tt.ip).Equal(netAddr.(*net.TCPAddr).IP))
+			assert.Equal(t, int(tt.port), netAddr.(*net.TCPAddr).Port)
+		})
+	}
+}
+
+func TestToPeerMultiAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		port     uint32
+		expected string
+		wantErr  bool
+	}{
+		{"TIP4", "172.21.11.12", 3456, "/ip4/172.21.11.12/tcp/3456", false},
+		{"TIP6", "2001:db8::1", 3456, "/ip6/2001:db8::1/tcp/3456", false},
+		{"THostname", "node1.example.com", 7846, "/dns4/node1.example.com/tcp/7846", false},
+		{"TLocalhost", "localhost", 7846, "/dns4/localhost/tcp/7846", false},
+		{"TInvalidHostname", "node1/example.com", 7846, "", true},
+		{"TEmpty", "", 7846, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := toPeerMultiAddr(tt.host, tt.port)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, actual.String())
+		})
+	}
+}
+
+func TestUnspecifiedAddrs(t *testing.T) {
+	addrs := unspecifiedAddrs(7846, "ip4", "ip6")
+	assert.Equal(t, 2, len(addrs))
+	assert.Equal(t, "/ip4/0.0.0.0/tcp/7846", addrs[0].String())
+	assert.Equal(t, "/ip6/::/tcp/7846", addrs[1].String())
+}
+
 func TestLookupAddress(t *testing.T) {
 	ip, err := externalIP()
 	if err != nil {
@@ -6,6 +6,7 @@
 package component
 
 import (
+	"errors"
 	"sync/atomic"
 	"time"
 
@@ -16,15 +17,19 @@ import (
 
 var _ IComponent = (*BaseComponent)(nil)
 
+// ErrComponentTimeout is the error of a request, to which the target component doesn't respond in time.
+var ErrComponentTimeout = errors.New("component request timeout")
+
 // BaseComponent provides a basic implementations for IComponent interface
 type BaseComponent struct {
 	*log.Logger
 	IActor
-	name            string
-	pid             *actor.PID
-	status          Status
-	hub             *ComponentHub
-	accQueuedMsg    uint64
+	name   string
+	pid    *actor.PID
+	status Status
+	hub    *ComponentHub
+	// queuedMsg is the number of messages posted but not received yet, and must be accessed atomically
+	queuedMsg       uint64
 	accProcessedMsg uint64
 }
 
@@ -40,7 +45,7 @@ func NewBaseComponent(name string, actor IActor, logger *log.Logger) *BaseCompon
 		pid:             nil,
 		status:          StoppedStatus,
 		hub:             nil,
-		accQueuedMsg:    0,
+		queuedMsg:       0,
 		accProcessedMsg: 0,
 	}
 }
@@ -90,6 +95,27 @@ func (base *BaseComponent) Stop() {
 	base.pid = nil
 }
 
+// drainRequest is queued at the mailbox by StopGraceful, and done is closed when it's received, which means all
+// the messages queued before are handled
+type drainRequest struct {
+	done chan struct{}
+}
+
+// StopGraceful is similar with Stop, but handles the messages queued at the mailbox before stopping
+// It waits for them up to timeout, and then stops this component anyway
+// The messages queued after StopGraceful is called are discarded
+func (base *BaseComponent) StopGraceful(timeout time.Duration) {
+	drain := &drainRequest{done: make(chan struct{})}
+	base.pid.Tell(drain)
+	select {
+	case <-drain.done:
+	case <-time.After(timeout):
+		base.Warn().Dur("timeout", timeout).Uint64("queued", atomic.LoadUint64(&base.queuedMsg)).
+			Msg("failed to handle all the queued messages before stop")
+	}
+	base.Stop()
+}
+
 // Tell passes a given message to this component and forgets
 func (base *BaseComponent) Tell(message interface{}) {
 	if base.pid == nil {
@@ -149,6 +175,26 @@ func (base *BaseComponent) RequestToFuture(targetCompName string, message interf
 	return base.hub.RequestFuture(targetCompName, message, timeout, base.name)
 }
 
+// RequestToFutureResult is similar with RequestToFuture, but waits for the result of the future.
+// It returns ErrComponentTimeout if the target component doesn't respond within timeout, and the response as
+// the error if the target component responds with an error
+func (base *BaseComponent) RequestToFutureResult(targetCompName string, message interface{}, timeout time.Duration) (interface{}, error) {
+	return futureResult(base.RequestToFuture(targetCompName, message, timeout))
+}
+
+func futureResult(future *actor.Future) (interface{}, error) {
+	result, err := future.Result()
+	if err == actor.ErrTimeout {
+		return nil, ErrComponentTimeout
+	} else if err != nil {
+		return nil, err
+	}
+	if rspErr, ok := result.(error); ok {
+		return nil, rspErr
+	}
+	return result, nil
+}
+
 // SetHub assigns a component hub to be used internally
 func (base *BaseComponent) SetHub(hub *ComponentHub) {
 	base.hub = hub
@@ -180,6 +226,10 @@ func (base *BaseComponent) Receive(context actor.Context) {
 
 	case *CompStatReq:
 		context.Respond(base.statics(msg))
+
+	case *drainRequest:
+		close(msg.done)
+		return
 	}
 
 	base.IActor.Receive(context)
@@ -197,21 +247,28 @@ func (base *BaseComponent) statics(req *CompStatReq) *CompStatRsp {
 	return &CompStatRsp{
 		Status:            StatusToString(base.status),
 		ProcessedMsg:      base.accProcessedMsg,
-		QueuedMsg:         base.accQueuedMsg,
+		QueuedMsg:         atomic.LoadUint64(&base.queuedMsg),
 		MsgProcessLatency: thisMsgLatency.String(),
 		Actor:             base.IActor.Statics(),
 	}
 }
 
 // MessagePosted is called when a msg is inserted at a mailbox (or queue) of this component
-// At this time, BaseComponent accumulates its counter to get a number of queued msgs
+// At this time, BaseComponent increases its counter to get a number of queued msgs
+// System messages are not counted
 func (base *BaseComponent) MessagePosted(message interface{}) {
-	base.accQueuedMsg++
+	if _, ok := message.(actor.SystemMessage); !ok {
+		atomic.AddUint64(&base.queuedMsg, 1)
+	}
 }
 
 // MessageReceived is called when msg is handled by the Receive func
-// This does nothing, but needs to implement Mailbox Statics interface
-func (base *BaseComponent) MessageReceived(message interface{}) {}
+// At this time, BaseComponent decreases its counter of queued msgs
+func (base *BaseComponent) MessageReceived(message interface{}) {
+	if _, ok := message.(actor.SystemMessage); !ok {
+		atomic.AddUint64(&base.queuedMsg, ^uint64(0))
+	}
+}
 
 // MailboxStarted does nothing, but needs to implement Mailbox Statics interface
 func (base *BaseComponent) MailboxStarted() {}
@@ -0,0 +1,129 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package component
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo-actor/actor"
+	"github.com/aergoio/aergo-lib/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// testActor responds to string requests with respond, or never responds if respond is nil.
+type testActor struct {
+	respond func(request string) interface{}
+}
+
+func (a *testActor) BeforeStart() {}
+func (a *testActor) BeforeStop()  {}
+func (a *testActor) Receive(context actor.Context) {
+	if request, ok := context.Message().(string); ok && a.respond != nil {
+		context.Respond(a.respond(request))
+	}
+}
+func (a *testActor) Statics() *map[string]interface{} { return nil }
+
+func TestBaseComponent_RequestToFutureResult(t *testing.T) {
+	logger := log.NewLogger("test")
+	errRsp := errors.New("response error")
+	hub := NewComponentHub()
+	requester := NewBaseComponent("testRequester", &testActor{}, logger)
+	hub.Register(requester)
+	hub.Register(NewBaseComponent("testSilent", &testActor{}, logger))
+	hub.Register(NewBaseComponent("testEcho", &testActor{respond: func(request string) interface{} {
+		if request == "fail" {
+			return errRsp
+		}
+		return request
+	}}, logger))
+	hub.Start()
+	defer hub.Stop()
+
+	result, err := requester.RequestToFutureResult("testSilent", "ping", 50*time.Millisecond)
+	assert.Equal(t, ErrComponentTimeout, err)
+	assert.Nil(t, result)
+
+	result, err = requester.RequestToFutureResult("testEcho", "ping", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "ping", result)
+
+	result, err = requester.RequestToFutureResult("testEcho", "fail", time.Second)
+	assert.Equal(t, errRsp, err)
+	assert.Nil(t, result)
+}
+
+func TestBaseComponent_queuedMsg(t *testing.T) {
+	const n = 10
+	gate := make(chan struct{})
+	hub := NewComponentHub()
+	comp := NewBaseComponent("testQueued", &testActor{respond: func(request string) interface{} {
+		<-gate
+		return nil
+	}}, log.NewLogger("test"))
+	hub.Register(comp)
+	hub.Start()
+	defer hub.Stop()
+
+	for i := 0; i < n; i++ {
+		comp.Tell("block")
+	}
+	// the first message is not received until it's handled
+	assert.Equal(t, uint64(n), comp.statics(&CompStatReq{time.Now()}).QueuedMsg)
+
+	close(gate)
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadUint64(&comp.queuedMsg) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, uint64(0), comp.statics(&CompStatReq{time.Now()}).QueuedMsg)
+}
+
+func TestBaseComponent_StopGraceful(t *testing.T) {
+	const n = 10
+	var handled uint32
+	gate := make(chan struct{})
+	hub := NewComponentHub()
+	comp := NewBaseComponent("testGraceful", &testActor{respond: func(request string) interface{} {
+		<-gate
+		atomic.AddUint32(&handled, 1)
+		return nil
+	}}, log.NewLogger("test"))
+	hub.Register(comp)
+	hub.Start()
+
+	for i := 0; i < n; i++ {
+		comp.Tell("queued")
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(gate)
+	}()
+	comp.StopGraceful(time.Second)
+	// all the messages queued before the stop are handled
+	assert.Equal(t, uint32(n), atomic.LoadUint32(&handled))
+	assert.True(t, waitStopped(comp, time.Second))
+}
+
+func TestBaseComponent_StopGracefulTimeout(t *testing.T) {
+	gate := make(chan struct{})
+	defer close(gate)
+	hub := NewComponentHub()
+	comp := NewBaseComponent("testGracefulTimeout", &testActor{respond: func(request string) interface{} {
+		<-gate
+		return nil
+	}}, log.NewLogger("test"))
+	hub.Register(comp)
+	hub.Start()
+
+	comp.Tell("stuck")
+	start := time.Now()
+	comp.StopGraceful(50 * time.Millisecond)
+	assert.True(t, time.Since(start) < time.Second, "graceful stop blocked for %v", time.Since(start))
+}
@@ -6,12 +6,23 @@
 package component
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/aergoio/aergo-actor/actor"
+	"github.com/aergoio/aergo-lib/log"
 )
 
+var logger = log.NewLogger("component")
+
+// stopTimeout is the time to wait for a component to reach StoppedStatus before stopping the next one.
+var stopTimeout = 10 * time.Second
+
+// stopPollInterval is the interval to check the status of a stopping component.
+const stopPollInterval = 10 * time.Millisecond
+
 // ICompSyncRequester is the interface that wraps the RequestFuture method.
 type ICompSyncRequester interface {
 	RequestFuture(targetName string, message interface{}, timeout time.Duration, tip string) *actor.Future
@@ -20,6 +31,8 @@ type ICompSyncRequester interface {
 // ComponentHub keeps a list of registerd components
 type ComponentHub struct {
 	components map[string]IComponent
+	// order is the components in the order of registration
+	order []IComponent
 }
 
 type hubInitSync struct {
@@ -61,16 +74,53 @@ func (hub *ComponentHub) Start() {
 	hubInit.end()
 }
 
-// Stop invokes stop funcs of registered components at this hub
-func (hub *ComponentHub) Stop() {
-	for _, comp := range hub.components {
+// Stop invokes stop funcs of registered components at this hub, in the reverse order of registration. Each
+// component is stopped after the previous one reaches StoppedStatus, so a component must be registered after the
+// components which it depends on. It returns an error naming the components which are not stopped in time, after
+// all of them are stopped.
+func (hub *ComponentHub) Stop() error {
+	var notStopped []string
+	for i := len(hub.order) - 1; i >= 0; i-- {
+		comp := hub.order[i]
 		comp.Stop()
+		if !waitStopped(comp, stopTimeout) {
+			logger.Warn().Str("component", comp.GetName()).Dur("timeout", stopTimeout).
+				Msg("component is not stopped in time, stopping the next one")
+			notStopped = append(notStopped, comp.GetName())
+		}
+	}
+	if len(notStopped) > 0 {
+		return fmt.Errorf("components not stopped in time: %s", strings.Join(notStopped, ", "))
 	}
+	return nil
 }
 
-// Register assigns a component to this hub for management
+// waitStopped waits until comp reaches StoppedStatus. It returns false if comp is not stopped within timeout.
+func waitStopped(comp IComponent, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for comp.Status() != StoppedStatus {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(stopPollInterval)
+	}
+	return true
+}
+
+// Register assigns a component to this hub for management. A component registered again with the same name
+// replaces the previous one, keeping its place in the stop order.
 func (hub *ComponentHub) Register(component IComponent) {
-	hub.components[component.GetName()] = component
+	name := component.GetName()
+	if _, exists := hub.components[name]; exists {
+		for i, comp := range hub.order {
+			if comp.GetName() == name {
+				hub.order[i] = component
+			}
+		}
+	} else {
+		hub.order = append(hub.order, component)
+	}
+	hub.components[name] = component
 	component.SetHub(hub)
 }
 
@@ -0,0 +1,116 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package component
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo-actor/actor"
+	"github.com/stretchr/testify/assert"
+)
+
+// testComponent reaches StoppedStatus some time after Stop is called, like an actor stopping asynchronously.
+type testComponent struct {
+	name   string
+	status Status
+	hub    *ComponentHub
+	// onStop is called with the name of component when Stop is called
+	onStop func(name string)
+}
+
+func (c *testComponent) GetName() string { return c.name }
+func (c *testComponent) Start()          { atomic.StoreUint32(&c.status, StartedStatus) }
+func (c *testComponent) Stop() {
+	c.onStop(c.name)
+	atomic.StoreUint32(&c.status, StoppingStatus)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreUint32(&c.status, StoppedStatus)
+	}()
+}
+func (c *testComponent) Status() Status                                 { return atomic.LoadUint32(&c.status) }
+func (c *testComponent) SetHub(hub *ComponentHub)                       { c.hub = hub }
+func (c *testComponent) Hub() *ComponentHub                             { return c.hub }
+func (c *testComponent) Tell(message interface{})                       {}
+func (c *testComponent) Request(message interface{}, sender *actor.PID) {}
+func (c *testComponent) RequestFuture(message interface{}, timeout time.Duration, tip string) *actor.Future {
+	return nil
+}
+func (c *testComponent) Receive(actor.Context) {}
+
+func TestComponentHub_StopOrder(t *testing.T) {
+	var mutex sync.Mutex
+	var stopped []string
+	hub := NewComponentHub()
+	chain := &testComponent{name: "chain", status: StartedStatus}
+	p2p := &testComponent{name: "p2p", status: StartedStatus}
+	// p2p depends on chain, so it is registered later
+	chain.onStop = func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		stopped = append(stopped, name)
+		assert.Equal(t, StoppedStatus, p2p.Status(), "chain is stopped before p2p is stopped")
+	}
+	p2p.onStop = func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		stopped = append(stopped, name)
+		assert.Equal(t, StartedStatus, chain.Status())
+	}
+	hub.Register(chain)
+	hub.Register(p2p)
+
+	assert.Nil(t, hub.Stop())
+	assert.Equal(t, []string{"p2p", "chain"}, stopped)
+	assert.Equal(t, StoppedStatus, chain.Status())
+}
+
+func TestComponentHub_RegisterAgain(t *testing.T) {
+	var stopped []string
+	onStop := func(name string) { stopped = append(stopped, name) }
+	hub := NewComponentHub()
+	hub.Register(&testComponent{name: "a", status: StartedStatus, onStop: onStop})
+	hub.Register(&testComponent{name: "b", status: StartedStatus, onStop: onStop})
+	replaced := &testComponent{name: "a", status: StartedStatus, onStop: onStop}
+	hub.Register(replaced)
+
+	assert.Nil(t, hub.Stop())
+	assert.Equal(t, []string{"b", "a"}, stopped)
+	assert.Equal(t, StoppedStatus, replaced.Status())
+	assert.Equal(t, replaced, hub.Get("a"))
+}
+
+// stuckComponent never reaches StoppedStatus.
+type stuckComponent struct {
+	testComponent
+}
+
+func (c *stuckComponent) Stop() {
+	c.onStop(c.name)
+	atomic.StoreUint32(&c.status, StoppingStatus)
+}
+
+func TestComponentHub_StopTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { stopTimeout = timeout }(stopTimeout)
+	stopTimeout = 50 * time.Millisecond
+
+	var stopped []string
+	onStop := func(name string) { stopped = append(stopped, name) }
+	hub := NewComponentHub()
+	hub.Register(&testComponent{name: "chain", status: StartedStatus, onStop: onStop})
+	hub.Register(&stuckComponent{testComponent{name: "p2p", status: StartedStatus, onStop: onStop}})
+
+	// the components after the stuck one are still stopped, and the stuck one is reported
+	err := hub.Stop()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "p2p")
+		assert.NotContains(t, err.Error(), "chain")
+	}
+	assert.Equal(t, []string{"p2p", "chain"}, stopped)
+}
@@ -17,7 +17,7 @@ type CompStatReq struct {
 // CompStatRsp contains component's internal info, used to help debugging
 // - Status is a string representation of a component's status
 // - ProcessedMsg is an accumulated number of message that this component processes
-// - QueuedMsg is an accumulated number of message at this component's mailbox
+// - QueuedMsg is the number of messages waiting at this component's mailbox
 // - MsgProcessLatency is an estimated latency to process a msg
 // - Error is an error msg when a requester fails to get statics
 // - Actor is a reserved field to get component's internal debug info
@@ -171,3 +171,46 @@ func (s *Trie) VerifyMerkleProofCompressedEmpty(bitmap []byte, ap [][]byte, leng
 	// this key is not included in the trie
 	return true
 }
+
+// VerifyInclusion verifies that key/value is included in the trie of root, without the trie itself. It is for
+// those who know only the root, such as light clients. The trie height is the bit length of key.
+func VerifyInclusion(root []byte, ap [][]byte, key, value []byte) bool {
+	leafHash := hash(key, value, []byte{1})
+	return bytes.Equal(root, verifyAuditPath(ap, key, leafHash))
+}
+
+// VerifyNonInclusion verifies that key is not included in the trie of root, without the trie itself. Like
+// VerifyMerkleProofEmpty, proofKey and proofValue are of the leaf on the path of key, if the path ends at a leaf.
+func VerifyNonInclusion(root []byte, ap [][]byte, key, proofKey, proofValue []byte) bool {
+	if uint64(len(ap)) == uint64(len(key))*8 {
+		// if the proof goes down to the DefaultLeaf, then there is no shortcut on the way
+		return bytes.Equal(root, verifyAuditPath(ap, key, DefaultLeaf))
+	}
+	if bytes.Equal(key, proofKey) || !VerifyInclusion(root, ap, proofKey, proofValue) {
+		return false
+	}
+	for b := 0; b < len(ap); b++ {
+		if bitIsSet(key, uint64(b)) != bitIsSet(proofKey, uint64(b)) {
+			// the proofKey leaf node is not on the path of the key
+			return false
+		}
+	}
+	return true
+}
+
+// verifyAuditPath returns the root computed from the leaf hash up along the audit path, of which the first node
+// is the sibling nearest to the leaf.
+func verifyAuditPath(ap [][]byte, key, leafHash []byte) []byte {
+	if uint64(len(ap)) > uint64(len(key))*8 {
+		return nil
+	}
+	node := leafHash
+	for i, sibling := range ap {
+		if bitIsSet(key, uint64(len(ap)-1-i)) {
+			node = hash(sibling, node)
+		} else {
+			node = hash(node, sibling)
+		}
+	}
+	return node
+}
@@ -235,6 +235,33 @@ func TestTrieMerkleProof(t *testing.T) {
 	}
 }
 
+func TestTrieVerifyWithRoot(t *testing.T) {
+	smt := NewTrie(32, hash, nil)
+	keys := getFreshData(10, 32)
+	values := getFreshData(10, 32)
+	smt.Update(keys, values)
+	root := smt.Root
+
+	for i, key := range keys {
+		ap, _, _, _, _ := smt.MerkleProof(key)
+		if !VerifyInclusion(root, ap, key, values[i]) {
+			t.Fatalf("failed to verify inclusion proof")
+		}
+		if VerifyInclusion(root, ap, key, values[(i+1)%len(values)]) {
+			t.Fatalf("verified inclusion proof of wrong value")
+		}
+	}
+	emptyKey := hash([]byte("non-member"))
+	ap, _, proofKey, proofValue, _ := smt.MerkleProof(emptyKey)
+	if !VerifyNonInclusion(root, ap, emptyKey, proofKey, proofValue) {
+		t.Fatalf("failed to verify non inclusion proof")
+	}
+	ap, _, proofKey, proofValue, _ = smt.MerkleProof(keys[0])
+	if VerifyNonInclusion(root, ap, keys[0], proofKey, proofValue) {
+		t.Fatalf("verified non inclusion proof of included key")
+	}
+}
+
 func TestTrieMerkleProofCompressed(t *testing.T) {
 	smt := NewTrie(32, hash, nil)
 	// Add data to empty trie
@@ -0,0 +1,44 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package rpc
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// checkAdmin returns an error unless the rpc is called from the host of this node. Admin rpcs, which expose the
+// internals of the node or change its behavior, are served to local callers only.
+func checkAdmin(ctx context.Context) error {
+	caller, ok := peer.FromContext(ctx)
+	if !ok || caller.Addr == nil {
+		return status.Error(codes.PermissionDenied, "admin rpc is allowed only from localhost")
+	}
+	if !isLoopback(caller.Addr) {
+		return status.Errorf(codes.PermissionDenied, "admin rpc is allowed only from localhost, not %s", caller.Addr)
+	}
+	return nil
+}
+
+// isLoopback reports whether addr is a loopback or unix socket address.
+func isLoopback(addr net.Addr) bool {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP.IsLoopback()
+	case *net.UnixAddr:
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
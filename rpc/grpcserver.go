@@ -11,11 +11,13 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/aergoio/aergo-actor/actor"
 
 	"github.com/aergoio/aergo-lib/log"
+	"github.com/aergoio/aergo/consensus"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/p2p"
 	"github.com/aergoio/aergo/pkg/component"
@@ -35,6 +37,9 @@ type AergoRPCService struct {
 	hub         *component.ComponentHub
 	actorHelper p2p.ActorService
 	msgHelper   message.Helper
+
+	consensusMutex sync.RWMutex
+	consensus      consensus.ChainConsensus
 }
 
 // FIXME remove redundant constants
@@ -59,10 +64,38 @@ func (rpc *AergoRPCService) Blockchain(ctx context.Context, in *types.Empty) (*t
 		return nil, rsp.Err
 	}
 	last := rsp.Block
-	return &types.BlockchainStatus{
+	bcStatus := &types.BlockchainStatus{
 		BestBlockHash: last.BlockHash(),
 		BestHeight:    last.GetHeader().GetBlockNo(),
-	}, nil
+	}
+
+	// the sync state depends on the heights of peers, so the best block is responded even without it
+	syncState, err := rpc.syncState()
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to get sync state")
+		return bcStatus, nil
+	}
+	bcStatus.Syncing = syncState.Syncing
+	bcStatus.SyncProgress = syncState.Progress
+	bcStatus.TargetHeight = syncState.TargetHeight
+	return bcStatus, nil
+}
+
+// syncState returns the sync state of the chain service.
+func (rpc *AergoRPCService) syncState() (*types.SyncState, error) {
+	result, err := rpc.hub.RequestFuture(message.ChainSvc, &message.GetSyncState{}, defaultActorTimeout,
+		"rpc.(*AergoRPCService).Blockchain").Result()
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := result.(message.GetSyncStateRsp)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "internal type error")
+	}
+	if rsp.Err != nil {
+		return nil, rsp.Err
+	}
+	return &rsp.State, nil
 }
 
 // ListBlockHeaders handle rpc request listblocks
@@ -249,16 +282,9 @@ func (rpc *AergoRPCService) CommitTX(ctx context.Context, in *types.TxList) (*ty
 			}
 
 			for j, err := range rsp.Err {
-				switch err {
-				case nil:
-					results.Results[start+j].Error = types.CommitStatus_COMMIT_STATUS_OK
-				case message.ErrTxNonceTooLow:
-					results.Results[start+j].Error = types.CommitStatus_COMMIT_STATUS_NONCE_TOO_LOW
-				case message.ErrTxAlreadyInMempool:
-					results.Results[start+j].Error = types.CommitStatus_COMMIT_STATUS_TX_ALREADY_EXISTS
-				default:
-					results.Results[start+j].Error = types.CommitStatus_COMMIT_STATUS_TX_INTERNAL_ERROR
-
+				results.Results[start+j].Error = commitStatus(err)
+				if err != nil {
+					results.Results[start+j].Detail = err.Error()
 				}
 			}
 			start += cnt
@@ -270,10 +296,34 @@ func (rpc *AergoRPCService) CommitTX(ctx context.Context, in *types.TxList) (*ty
 	return results, nil
 }
 
+// commitStatus converts the error of tx validation to the status code, which clients can handle
+// programmatically.
+func commitStatus(err error) types.CommitStatus {
+	switch err {
+	case nil:
+		return types.CommitStatus_COMMIT_STATUS_OK
+	case message.ErrTxNonceTooLow:
+		return types.CommitStatus_COMMIT_STATUS_NONCE_TOO_LOW
+	case message.ErrTxAlreadyInMempool:
+		return types.CommitStatus_COMMIT_STATUS_TX_ALREADY_EXISTS
+	case message.ErrTxFormatInvalid, message.ErrTxHasInvalidHash:
+		return types.CommitStatus_COMMIT_STATUS_INVALID_ARGUMENT
+	case message.ErrSignNotMatch:
+		return types.CommitStatus_COMMIT_STATUS_INVALID_SIGNATURE
+	case message.ErrInsufficientBalance:
+		return types.CommitStatus_COMMIT_STATUS_INSUFFICIENT_BALANCE
+	case message.ErrTxTooLarge:
+		return types.CommitStatus_COMMIT_STATUS_TX_TOO_LARGE
+	default:
+		return types.CommitStatus_COMMIT_STATUS_TX_INTERNAL_ERROR
+	}
+}
+
 // GetState handle rpc request getstate
-func (rpc *AergoRPCService) GetState(ctx context.Context, in *types.SingleBytes) (*types.State, error) {
+func (rpc *AergoRPCService) GetState(ctx context.Context, in *types.StateQuery) (*types.State, error) {
 	result, err := rpc.hub.RequestFuture(message.ChainSvc,
-		&message.GetState{Account: in.Value}, defaultActorTimeout, "rpc.(*AergoRPCService).GetState").Result()
+		&message.GetState{Account: in.Account, Confirmations: in.Confirmations},
+		defaultActorTimeout, "rpc.(*AergoRPCService).GetState").Result()
 	if err != nil {
 		return nil, err
 	}
@@ -315,7 +365,7 @@ func (rpc *AergoRPCService) GetAccounts(ctx context.Context, in *types.Empty) (*
 // LockAccount handle rpc request lockaccount
 func (rpc *AergoRPCService) LockAccount(ctx context.Context, in *types.Personal) (*types.Account, error) {
 	result, err := rpc.hub.RequestFuture(message.AccountsSvc,
-		&message.LockAccount{Account: in.Account, Passphrase: in.Passphrase},
+		&message.LockAccount{Account: in.Account},
 		defaultActorTimeout, "rpc.(*AergoRPCService).LockAccount").Result()
 	if err != nil {
 		return nil, err
@@ -330,7 +380,8 @@ func (rpc *AergoRPCService) LockAccount(ctx context.Context, in *types.Personal)
 // UnlockAccount handle rpc request unlockaccount
 func (rpc *AergoRPCService) UnlockAccount(ctx context.Context, in *types.Personal) (*types.Account, error) {
 	result, err := rpc.hub.RequestFuture(message.AccountsSvc,
-		&message.UnlockAccount{Account: in.Account, Passphrase: in.Passphrase},
+		&message.UnlockAccount{Account: in.Account, Passphrase: in.Passphrase,
+			Duration: time.Duration(in.Duration) * time.Second},
 		defaultActorTimeout, "rpc.(*AergoRPCService).UnlockAccount").Result()
 	if err != nil {
 		return nil, err
@@ -356,6 +407,20 @@ func (rpc *AergoRPCService) SignTX(ctx context.Context, in *types.Tx) (*types.Tx
 	return rsp.Tx, rsp.Err
 }
 
+// SignTransaction handle rpc request signtransaction
+func (rpc *AergoRPCService) SignTransaction(ctx context.Context, in *types.SignTxRequest) (*types.Tx, error) {
+	result, err := rpc.hub.RequestFuture(message.AccountsSvc,
+		&message.SignTxFrom{Tx: in.Tx, From: in.From}, defaultActorTimeout, "rpc.(*AergoRPCService).SignTransaction").Result()
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := result.(*message.SignTxRsp)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "internal type (%v) error", reflect.TypeOf(result))
+	}
+	return rsp.Tx, rsp.Err
+}
+
 // VerifyTX handle rpc request verifytx
 func (rpc *AergoRPCService) VerifyTX(ctx context.Context, in *types.Tx) (*types.VerifyResult, error) {
 	result, err := rpc.hub.RequestFuture(message.AccountsSvc,
@@ -392,6 +457,58 @@ func (rpc *AergoRPCService) GetPeers(ctx context.Context, in *types.Empty) (*typ
 	return &types.PeerList{Peers: rsp.Peers, States: states}, nil
 }
 
+// DumpP2PState handle rpc request dumpp2pstate. It returns the snapshot of p2p subsystem in json. It is an admin
+// rpc, allowed only from localhost.
+func (rpc *AergoRPCService) DumpP2PState(ctx context.Context, in *types.Empty) (*types.SingleBytes, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	result, err := rpc.hub.RequestFuture(message.P2PSvc,
+		&message.DumpP2PState{}, defaultActorTimeout, "rpc.(*AergoRPCService).DumpP2PState").Result()
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := result.(*message.DumpP2PStateRsp)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "internal type (%v) error", reflect.TypeOf(result))
+	}
+	data, err := json.MarshalIndent(rsp, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return &types.SingleBytes{Value: data}, nil
+}
+
+func (rpc *AergoRPCService) setConsensus(c consensus.ChainConsensus) {
+	rpc.consensusMutex.Lock()
+	defer rpc.consensusMutex.Unlock()
+	rpc.consensus = c
+}
+
+// GenerateBlock handle rpc request generateblock. It produces a block immediately and returns its hash. It is
+// available only for the consensus which allows manual block production, such as sbp for development. It is an admin
+// rpc, allowed only from localhost.
+func (rpc *AergoRPCService) GenerateBlock(ctx context.Context, in *types.Empty) (*types.SingleBytes, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	rpc.consensusMutex.RLock()
+	c := rpc.consensus
+	rpc.consensusMutex.RUnlock()
+
+	producer, ok := c.(consensus.ManualBlockProducer)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "manual block production is not allowed by consensus")
+	}
+	ctx, cancel := context.WithTimeout(ctx, halfMinute)
+	defer cancel()
+	block, err := producer.ProduceBlock(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to produce block: %s", err.Error())
+	}
+	return &types.SingleBytes{Value: block.BlockHash()}, nil
+}
+
 // NodeState handle rpc request nodestate
 func (rpc *AergoRPCService) NodeState(ctx context.Context, in *types.SingleBytes) (*types.SingleBytes, error) {
 	timeout := int64(binary.LittleEndian.Uint64(in.Value))
@@ -416,6 +533,23 @@ func (rpc *AergoRPCService) GetReceipt(ctx context.Context, in *types.SingleByte
 	return rsp.Receipt, nil
 }
 
+// GetLogs handle rpc request getlogs. It returns the events of contract emitted in the range of blocks.
+func (rpc *AergoRPCService) GetLogs(ctx context.Context, in *types.FilterInfo) (*types.EventList, error) {
+	result, err := rpc.hub.RequestFuture(message.ChainSvc,
+		&message.GetLogs{Filter: in}, defaultActorTimeout, "rpc.(*AergoRPCService).GetLogs").Result()
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := result.(message.GetLogsRsp)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "internal type (%v) error", reflect.TypeOf(result))
+	}
+	if rsp.Err != nil {
+		return nil, status.Error(codes.InvalidArgument, rsp.Err.Error())
+	}
+	return &types.EventList{Events: rsp.Events}, nil
+}
+
 func toTimestamp(time time.Time) *timestamp.Timestamp {
 	return &timestamp.Timestamp{
 		Seconds: time.Unix(),
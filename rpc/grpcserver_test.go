@@ -8,9 +8,14 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"net"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/consensus/impl/sbp"
 	"github.com/aergoio/aergo/internal/enc"
 	"github.com/aergoio/aergo/p2p"
 
@@ -20,7 +25,11 @@ import (
 	"github.com/aergoio/aergo/pkg/component"
 	"github.com/aergoio/aergo/types"
 	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestAergoRPCService_dummys(t *testing.T) {
@@ -124,3 +133,131 @@ func (fs *FutureStub) Result() interface{} {
 func NewFutureStub(result interface{}) FutureStub {
 	return FutureStub{dumbResult: result}
 }
+
+func TestCommitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want types.CommitStatus
+	}{
+		{"ok", nil, types.CommitStatus_COMMIT_STATUS_OK},
+		{"nonceTooLow", message.ErrTxNonceTooLow, types.CommitStatus_COMMIT_STATUS_NONCE_TOO_LOW},
+		{"duplicate", message.ErrTxAlreadyInMempool, types.CommitStatus_COMMIT_STATUS_TX_ALREADY_EXISTS},
+		{"invalidFormat", message.ErrTxFormatInvalid, types.CommitStatus_COMMIT_STATUS_INVALID_ARGUMENT},
+		{"invalidHash", message.ErrTxHasInvalidHash, types.CommitStatus_COMMIT_STATUS_INVALID_ARGUMENT},
+		{"badSignature", message.ErrSignNotMatch, types.CommitStatus_COMMIT_STATUS_INVALID_SIGNATURE},
+		{"insufficientFunds", message.ErrInsufficientBalance, types.CommitStatus_COMMIT_STATUS_INSUFFICIENT_BALANCE},
+		{"tooLarge", message.ErrTxTooLarge, types.CommitStatus_COMMIT_STATUS_TX_TOO_LARGE},
+		{"unknown", fmt.Errorf("db failure"), types.CommitStatus_COMMIT_STATUS_TX_INTERNAL_ERROR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitStatus(tt.err); got != tt.want {
+				t.Errorf("commitStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// devChain responds to the requests of block production as the chain and the
+// mempool services, without actors.
+type devChain struct {
+	sync.Mutex
+	best    *types.Block
+	pending []*types.Tx
+}
+
+func (c *devChain) RequestFuture(targetName string, msg interface{}, timeout time.Duration, tip string) *actor.Future {
+	c.Lock()
+	defer c.Unlock()
+
+	future := actor.NewFuture(timeout)
+	switch m := msg.(type) {
+	case *message.GetBestBlock:
+		future.PID().Tell(message.GetBestBlockRsp{Block: c.best})
+	case *message.MemPoolGet:
+		future.PID().Tell(&message.MemPoolGetRsp{Txs: c.pending})
+	case *message.ComputeStateRoot:
+		future.PID().Tell(message.ComputeStateRootRsp{Root: []byte("root")})
+	case *message.AddBlock:
+		c.best, c.pending = m.Block, nil
+		future.PID().Tell(message.AddBlockRsp{BlockNo: m.Block.GetHeader().GetBlockNo(), BlockHash: m.Block.BlockHash()})
+	}
+	return future
+}
+
+// noManualConsensus is a consensus which doesn't allow manual block production, like DPoS.
+type noManualConsensus struct{}
+
+func (noManualConsensus) IsTransactionValid(tx *types.Tx) bool                          { return true }
+func (noManualConsensus) IsBlockValid(block *types.Block, bestBlock *types.Block) error { return nil }
+func (noManualConsensus) StatusUpdate()                                                 {}
+
+func TestAergoRPCService_GenerateBlock(t *testing.T) {
+	conf := config.NewServerContext("", "").GetDefaultConfig().(*config.Config)
+	bf, err := sbp.New(conf, nil)
+	assert.Nil(t, err)
+	newTx := func(nonce uint64) *types.Tx {
+		tx := &types.Tx{Body: &types.TxBody{Nonce: nonce}}
+		tx.Hash = tx.CalculateTxHash()
+		return tx
+	}
+	pending := []*types.Tx{newTx(1), newTx(2)}
+	chain := &devChain{best: types.NewBlock(nil, nil, 0), pending: pending}
+	bf.ICompSyncRequester = chain
+	go bf.Start()
+	defer close(bf.QuitChan())
+
+	local := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 50000}})
+	rpc := &AergoRPCService{hub: hubStub, actorHelper: mockActorHelper, msgHelper: mockMsgHelper}
+	rpc.setConsensus(bf)
+	got, err := rpc.GenerateBlock(local, &types.Empty{})
+	assert.Nil(t, err)
+	assert.Equal(t, chain.best.BlockHash(), got.GetValue())
+	assert.Equal(t, types.BlockNo(1), chain.best.GetHeader().GetBlockNo())
+	assert.Equal(t, pending, chain.best.GetBody().GetTxs())
+
+	// the next one is produced on top of the previous one, even with no tx
+	prev := chain.best
+	_, err = rpc.GenerateBlock(local, &types.Empty{})
+	assert.Nil(t, err)
+	assert.Equal(t, prev.BlockHash(), chain.best.GetHeader().GetPrevBlockHash())
+	assert.Empty(t, chain.best.GetBody().GetTxs())
+
+	// queued jobs are produced on top of the best block at the time of
+	// production, not of queueing
+	bf.QueueJob(time.Now(), bf.JobQueue())
+	bf.QueueJob(time.Now(), bf.JobQueue())
+	_, err = rpc.GenerateBlock(local, &types.Empty{})
+	assert.Nil(t, err)
+	assert.Equal(t, types.BlockNo(5), chain.best.GetHeader().GetBlockNo())
+
+	// not allowed from a remote caller
+	_, err = rpc.GenerateBlock(context.Background(), &types.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// not allowed in production consensus
+	rpc.setConsensus(noManualConsensus{})
+	_, err = rpc.GenerateBlock(local, &types.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestCheckAdmin(t *testing.T) {
+	withCaller := func(addr net.Addr) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	}
+	assert.Nil(t, checkAdmin(withCaller(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 50000})))
+	assert.Nil(t, checkAdmin(withCaller(&net.TCPAddr{IP: net.ParseIP("::1"), Port: 50000})))
+	assert.Nil(t, checkAdmin(withCaller(&net.UnixAddr{Name: "/tmp/aergo.sock", Net: "unix"})))
+
+	for _, ctx := range []context.Context{
+		withCaller(&net.TCPAddr{IP: net.ParseIP("172.21.11.12"), Port: 50000}),
+		context.Background(),
+	} {
+		assert.Equal(t, codes.PermissionDenied, status.Code(checkAdmin(ctx)))
+	}
+
+	rpc := &AergoRPCService{hub: hubStub, actorHelper: mockActorHelper, msgHelper: mockMsgHelper}
+	_, err := rpc.DumpP2PState(withCaller(&net.TCPAddr{IP: net.ParseIP("172.21.11.12"), Port: 50000}), &types.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
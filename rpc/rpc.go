@@ -14,6 +14,7 @@ import (
 
 	"github.com/aergoio/aergo-actor/actor"
 	"github.com/aergoio/aergo/config"
+	"github.com/aergoio/aergo/consensus"
 	"github.com/aergoio/aergo/message"
 	"github.com/aergoio/aergo/pkg/component"
 	aergorpc "github.com/aergoio/aergo/types"
@@ -33,7 +34,7 @@ type RPC struct {
 
 	grpcServer    *grpc.Server
 	grpcWebServer *grpcweb.WrappedGrpcServer
-	actualServer  aergorpc.AergoRPCServiceServer
+	actualServer  *AergoRPCService
 	httpServer    *http.Server
 }
 
@@ -79,6 +80,11 @@ func (ns *RPC) BeforeStart() {
 	go ns.serve()
 }
 
+// SetConsensus sets the consensus which serves the consensus related requests, such as manual block production.
+func (ns *RPC) SetConsensus(c consensus.ChainConsensus) {
+	ns.actualServer.setConsensus(c)
+}
+
 // Stop stops rpc service.
 func (ns *RPC) BeforeStop() {
 	ns.httpServer.Close()
@@ -164,7 +170,5 @@ func (ns *RPC) FutureRequest(actor string, msg interface{}) *actor.Future {
 
 // CallRequest implement interface method of ActorService
 func (ns *RPC) CallRequest(actor string, msg interface{}) (interface{}, error) {
-	future := ns.RequestToFuture(actor, msg, defaultTTL)
-
-	return future.Result()
+	return ns.RequestToFutureResult(actor, msg, defaultTTL)
 }
@@ -0,0 +1,124 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aergoio/aergo-lib/db"
+)
+
+// ValueLogGC is implemented by DBs that reclaim the space of their value log
+// only when asked to, as badger does.
+type ValueLogGC interface {
+	RunValueLogGC(discardRatio float64) error
+}
+
+// DBGC runs value log GC of a DB periodically.
+type DBGC struct {
+	name   string
+	dbPath string
+	gc     ValueLogGC
+	ratio  float64
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// StartDBGC starts GC of store every interval, rewriting value log files of which
+// discardable portion is over ratio. It returns nil if interval is not positive or
+// store does not support value log GC. Stop of nil DBGC is allowed.
+func StartDBGC(name, dbPath string, store db.DB, interval time.Duration, ratio float64) *DBGC {
+	if interval <= 0 {
+		return nil
+	}
+	gc := valueLogGCOf(store)
+	if gc == nil {
+		logger.Warn().Str("db", name).Msg("db does not support value log gc")
+		return nil
+	}
+	ticker := time.NewTicker(interval)
+	dbgc := newDBGC(name, dbPath, gc, ratio)
+	go func() {
+		defer ticker.Stop()
+		dbgc.run(ticker.C)
+	}()
+	return dbgc
+}
+
+func newDBGC(name, dbPath string, gc ValueLogGC, ratio float64) *DBGC {
+	return &DBGC{
+		name:   name,
+		dbPath: dbPath,
+		gc:     gc,
+		ratio:  ratio,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// valueLogGCOf returns the value log GC of store, or nil if store has none. It relies on the badger DB of aergo-lib
+// exposing RunValueLogGC of the badger.DB it wraps.
+func valueLogGCOf(store db.DB) ValueLogGC {
+	if gc, ok := store.(ValueLogGC); ok {
+		return gc
+	}
+	return nil
+}
+
+// run collects garbage every time tick fires, until stopped.
+func (dbgc *DBGC) run(tick <-chan time.Time) {
+	defer close(dbgc.done)
+	for {
+		select {
+		case <-tick:
+			dbgc.collect()
+		case <-dbgc.stop:
+			return
+		}
+	}
+}
+
+func (dbgc *DBGC) collect() {
+	before := dirSize(dbgc.dbPath)
+	count := 0
+	// each run rewrites at most one file, so repeat until nothing is left to rewrite
+	for {
+		select {
+		case <-dbgc.stop:
+			return
+		default:
+		}
+		if err := dbgc.gc.RunValueLogGC(dbgc.ratio); err != nil {
+			break
+		}
+		count++
+	}
+	after := dirSize(dbgc.dbPath)
+	logger.Info().Str("db", dbgc.name).Int("rewritten", count).Int64("reclaimed", before-after).
+		Int64("size", after).Msg("value log gc done")
+}
+
+// Stop stops GC and waits for the running one to finish, so that the DB can be closed.
+func (dbgc *DBGC) Stop() {
+	if dbgc == nil {
+		return
+	}
+	close(dbgc.stop)
+	<-dbgc.done
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aergoio/aergo-lib/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// testGCDB counts value log GC runs of a real db
+type testGCDB struct {
+	db.DB
+	runs int32
+}
+
+func (tdb *testGCDB) RunValueLogGC(discardRatio float64) error {
+	atomic.AddInt32(&tdb.runs, 1)
+	return errors.New("no rewrite")
+}
+
+func TestDBGC(t *testing.T) {
+	dbPath, _ := ioutil.TempDir("", "dbgc")
+	defer os.RemoveAll(dbPath)
+	store := &testGCDB{DB: db.NewDB(db.BadgerImpl, dbPath)}
+	defer store.Close()
+
+	store.Set([]byte("key"), []byte("value"))
+	gc := newDBGC("test", dbPath, store, 0.5)
+	tick := make(chan time.Time)
+	go gc.run(tick)
+
+	// tick is received only after the previous collection is done
+	for i := 0; i < 3; i++ {
+		tick <- time.Now()
+	}
+	gc.Stop()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&store.runs))
+
+	// no more gc after stop
+	select {
+	case tick <- time.Now():
+		t.Fatal("gc is running after stop")
+	default:
+	}
+	assert.Equal(t, []byte("value"), store.Get([]byte("key")))
+}
+
+func TestDBGCOfBadger(t *testing.T) {
+	dbPath, _ := ioutil.TempDir("", "dbgc")
+	defer os.RemoveAll(dbPath)
+	// the db of state and contract is created like this
+	store := db.NewDB(db.BadgerImpl, dbPath)
+	defer store.Close()
+
+	assert.NotNil(t, valueLogGCOf(store), "gc must be supported by badger db")
+	gc := StartDBGC("test", dbPath, store, time.Hour, 0.5)
+	assert.NotNil(t, gc)
+	gc.Stop()
+}
+
+func TestDBGCDisabled(t *testing.T) {
+	dbPath, _ := ioutil.TempDir("", "dbgc")
+	defer os.RemoveAll(dbPath)
+	store := &testGCDB{DB: db.NewDB(db.BadgerImpl, dbPath)}
+	defer store.Close()
+
+	gc := StartDBGC("test", dbPath, store, 0, 0.5)
+	assert.Nil(t, gc)
+	// stop of disabled gc is allowed
+	gc.Stop()
+}
@@ -0,0 +1,78 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/aergoio/aergo/pkg/trie"
+	"github.com/aergoio/aergo/types"
+)
+
+// StateProof is a merkle proof of the state of an account against a state root. It proves either that the
+// account has the state, or that the account doesn't exist.
+type StateProof struct {
+	Account types.AccountID
+	// State is the state of account, which is empty if the account doesn't exist
+	State    *types.State
+	Included bool
+	// AuditPath is the sibling nodes on the path of account, from the nearest to the leaf
+	AuditPath [][]byte
+	// ProofKey and ProofValue are of the leaf on the path of the excluded account, if the path ends at a leaf
+	ProofKey   []byte
+	ProofValue []byte
+}
+
+// GetProof returns the proof of the current state of account against the current state root.
+func (sdb *ChainStateDB) GetProof(aid types.AccountID) (*StateProof, error) {
+	if aid == emptyAccountID {
+		return nil, fmt.Errorf("Failed to get proof: invalid account id")
+	}
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	ap, included, proofKey, proofValue, err := sdb.trie.MerkleProof(aid[:])
+	if err != nil {
+		return nil, err
+	}
+	proof := &StateProof{
+		Account:    aid,
+		State:      types.NewState(),
+		Included:   included,
+		AuditPath:  ap,
+		ProofKey:   proofKey,
+		ProofValue: proofValue,
+	}
+	if included {
+		state, err := sdb.getAccountState(aid)
+		if err != nil {
+			return nil, err
+		}
+		if isEmptyState(state) {
+			return nil, fmt.Errorf("Failed to get proof: account %v is in trie but has no state", aid)
+		}
+		res := types.Clone(*state).(types.State)
+		proof.State = &res
+	}
+	return proof, nil
+}
+
+// VerifyStateProof reports whether proof is valid against the state root.
+func VerifyStateProof(root []byte, proof *StateProof) bool {
+	if proof == nil {
+		return false
+	}
+	if proof.Included {
+		if isEmptyState(proof.State) {
+			return false
+		}
+		return trie.VerifyInclusion(root, proof.AuditPath, proof.Account[:], proof.State.GetHash())
+	}
+	if !isEmptyState(proof.State) {
+		return false
+	}
+	return trie.VerifyNonInclusion(root, proof.AuditPath, proof.Account[:], proof.ProofKey, proof.ProofValue)
+}
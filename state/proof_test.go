@@ -0,0 +1,74 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package state
+
+import (
+	"testing"
+
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainStateDB_GetProof(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 2, 5)
+	assert.Nil(t, sdb.Apply(bstates[0]))
+	root := append([]byte{}, sdb.GetHash()...)
+
+	aid := types.ToAccountID([]byte{byte(0), byte(1), byte(0)})
+	proof, err := sdb.GetProof(aid)
+	assert.Nil(t, err)
+	assert.True(t, proof.Included)
+	assert.Equal(t, uint64(1000), proof.State.Balance)
+	assert.True(t, VerifyStateProof(root, proof))
+
+	// forged state does not verify
+	forged := *proof
+	forged.State = &types.State{Nonce: 1, Balance: 1000000}
+	assert.False(t, VerifyStateProof(root, &forged))
+
+	missing := types.ToAccountID([]byte("missing"))
+	exclusion, err := sdb.GetProof(missing)
+	assert.Nil(t, err)
+	assert.False(t, exclusion.Included)
+	assert.True(t, exclusion.State.IsEmpty())
+	assert.True(t, VerifyStateProof(root, exclusion))
+	// an exclusion proof can't be turned into an inclusion proof
+	exclusion.Included = true
+	exclusion.State = &types.State{Balance: 1}
+	assert.False(t, VerifyStateProof(root, exclusion))
+
+	// the old proof fails against the root after the trie is changed
+	bs := NewBlockState(2, bstates[1].BlockHash, bstates[1].PrevHash)
+	prev, err := sdb.GetBlockAccountClone(bs, aid)
+	assert.Nil(t, err)
+	bs.PutAccount(aid, prev, &types.State{Nonce: 2, Balance: 500})
+	assert.Nil(t, sdb.Apply(bs))
+	newRoot := sdb.GetHash()
+	assert.NotEqual(t, root, newRoot)
+	assert.False(t, VerifyStateProof(newRoot, proof))
+
+	updated, err := sdb.GetProof(aid)
+	assert.Nil(t, err)
+	assert.True(t, VerifyStateProof(newRoot, updated))
+	assert.False(t, VerifyStateProof(root, updated))
+}
+
+func TestChainStateDB_GetProofEmpty(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	proof, err := sdb.GetProof(types.ToAccountID([]byte("missing")))
+	assert.Nil(t, err)
+	assert.False(t, proof.Included)
+	assert.True(t, VerifyStateProof(EmptyRoot, proof))
+	assert.False(t, VerifyStateProof(nil, proof))
+
+	_, err = sdb.GetProof(types.AccountID{})
+	assert.NotNil(t, err)
+}
@@ -14,6 +14,16 @@ func saveData(store *db.DB, key []byte, data interface{}) error {
 	if key == nil {
 		return fmt.Errorf("Failed to set data: key is nil")
 	}
+	raw, err := encodeData(data)
+	if err != nil {
+		return err
+	}
+	// logger.Debug().Str("key", enc.ToString(key)).Int("size", len(raw)).Msg("saveData")
+	(*store).Set(key, raw)
+	return nil
+}
+
+func encodeData(data interface{}) ([]byte, error) {
 	var err error
 	var raw []byte
 	switch data.(type) {
@@ -22,23 +32,18 @@ func saveData(store *db.DB, key []byte, data interface{}) error {
 	case proto.Message:
 		raw, err = proto.Marshal(data.(proto.Message))
 		if err != nil {
-			return err
+			return nil, err
 		}
 	default:
 		buffer := &bytes.Buffer{}
 		enc := gob.NewEncoder(buffer)
 		err = enc.Encode(data)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		raw = buffer.Bytes()
-		if err != nil {
-			return err
-		}
 	}
-	// logger.Debug().Str("key", enc.ToString(key)).Int("size", len(raw)).Msg("saveData")
-	(*store).Set(key, raw)
-	return nil
+	return raw, nil
 }
 
 func loadData(store *db.DB, key []byte, data interface{}) error {
@@ -68,17 +73,43 @@ func loadData(store *db.DB, key []byte, data interface{}) error {
 	return err
 }
 
+// saveStateDB writes the states of accounts changed since the last save and latest block info to db in a
+// transaction, so that the accounts on disk are always the ones as of latest on disk.
 func (sdb *ChainStateDB) saveStateDB() error {
+	if sdb.latest == nil {
+		// no block has been applied to the fresh db yet
+		return nil
+	}
 	// logger.Debug().Int("blockNo", int(sdb.latest.BlockNo)).Str("blockHash", sdb.latest.BlockHash.String()).Msg("saveStateDB.latest")
-	// logger.Debug().Int("size", len(sdb.accounts)).Msg("saveStateDB.accounts")
-	err := saveData(sdb.statedb, []byte(stateAccounts), sdb.accounts)
+	// logger.Debug().Int("size", len(sdb.dirty)).Msg("saveStateDB.accounts")
+	latest, err := encodeData(sdb.latest)
 	if err != nil {
 		return err
 	}
-	err = saveData(sdb.statedb, []byte(stateLatest), sdb.latest)
-	if err != nil {
-		return err
+	raws := make(map[types.AccountID][]byte, len(sdb.dirty))
+	for aid, state := range sdb.dirty {
+		if state == nil {
+			raws[aid] = nil
+			continue
+		}
+		if raws[aid], err = encodeData(state); err != nil {
+			return err
+		}
+	}
+	tx := (*sdb.statedb).NewTx(true)
+	for aid, raw := range raws {
+		if raw == nil {
+			tx.Delete(accountKey(aid))
+		} else {
+			tx.Set(accountKey(aid), raw)
+		}
 	}
+	if (*sdb.statedb).Exist([]byte(stateAccounts)) {
+		tx.Delete([]byte(stateAccounts))
+	}
+	tx.Set([]byte(stateLatest), latest)
+	tx.Commit()
+	sdb.dirty = make(map[types.AccountID]*types.State)
 	return nil
 }
 
@@ -88,30 +119,139 @@ func (sdb *ChainStateDB) loadStateDB() error {
 		return err
 	}
 	// logger.Debug().Int("blockNo", int(sdb.latest.BlockNo)).Str("blockHash", sdb.latest.BlockHash.String()).Msg("loadStateDB.latest")
-	err = loadData(sdb.statedb, []byte(stateAccounts), &sdb.accounts)
+	// the accounts saved at once by old versions are saved by their own keys on the next save
+	var accounts map[types.AccountID]*types.State
+	err = loadData(sdb.statedb, []byte(stateAccounts), &accounts)
 	if err != nil {
 		return err
 	}
-	// logger.Debug().Int("size", len(sdb.accounts)).Msg("loadStateDB.accounts")
+	for aid, state := range accounts {
+		sdb.putAccount(aid, state)
+	}
+	// logger.Debug().Int("size", len(accounts)).Msg("loadStateDB.accounts")
 	return nil
 }
 
+func accountKey(aid types.AccountID) []byte {
+	return append([]byte(stateAccount), aid[:]...)
+}
+
+// loadAccount returns the state of account saved in db, which is empty if the account doesn't exist.
+func (sdb *ChainStateDB) loadAccount(aid types.AccountID) (*types.State, error) {
+	state := types.NewState()
+	if err := loadData(sdb.statedb, accountKey(aid), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// blockStateData is the stored form of BlockState, which exports the account entries and the state root.
+type blockStateData struct {
+	BlockInfo
+	Accounts map[types.AccountID]*StateEntry
+	Root     []byte
+}
+
 func (sdb *ChainStateDB) saveBlockState(data *BlockState) error {
 	bid := data.BlockHash
 	if bid == emptyBlockID {
 		return fmt.Errorf("Invalid ID to save BlockState: empty")
 	}
-	err := saveData(sdb.statedb, bid[:], data)
+	if sdb.blockStates != nil {
+		// replaces the stale one, if the block is applied again
+		sdb.blockStates.Add(bid, data)
+	}
+	if sdb.buffer != nil {
+		sdb.buffer.blockStates[bid] = data
+		return nil
+	}
+	err := saveData(sdb.statedb, bid[:], &blockStateData{
+		BlockInfo: data.BlockInfo,
+		Accounts:  data.accounts,
+		Root:      data.root,
+	})
 	return err
 }
 func (sdb *ChainStateDB) loadBlockState(bid types.BlockID) (*BlockState, error) {
 	if bid == emptyBlockID {
 		return nil, fmt.Errorf("Invalid ID to load BlockState: empty")
 	}
-	data := &BlockState{}
+	if sdb.buffer != nil {
+		if bs, ok := sdb.buffer.blockStates[bid]; ok {
+			return bs, nil
+		}
+	}
+	if sdb.blockStates != nil {
+		if bs, ok := sdb.blockStates.Get(bid); ok {
+			return bs.(*BlockState), nil
+		}
+	}
+	data := &blockStateData{}
 	err := loadData(sdb.statedb, bid[:], data)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	bs := NewBlockState(data.BlockNo, data.BlockHash, data.PrevHash)
+	for k, v := range data.Accounts {
+		bs.accounts[k] = v
+	}
+	bs.root = data.Root
+	// the one not found is not cached, since it may be saved later
+	if sdb.blockStates != nil && bs.BlockHash == bid {
+		sdb.blockStates.Add(bid, bs)
+	}
+	return bs, nil
+}
+
+func stateRootKey(blockNo types.BlockNo) []byte {
+	return append([]byte(stateRoot), types.BlockNoToBytes(blockNo)...)
+}
+
+func (sdb *ChainStateDB) saveStateRoot(blockNo types.BlockNo) error {
+	if sdb.buffer != nil {
+		sdb.buffer.roots[blockNo] = sdb.root()
+		return nil
+	}
+	return saveData(sdb.statedb, stateRootKey(blockNo), sdb.root())
+}
+func (sdb *ChainStateDB) loadStateRoot(blockNo types.BlockNo) ([]byte, error) {
+	if sdb.buffer != nil {
+		if root, ok := sdb.buffer.roots[blockNo]; ok {
+			return root, nil
+		}
+	}
+	key := stateRootKey(blockNo)
+	if !(*sdb.statedb).Exist(key) {
+		return nil, fmt.Errorf("Failed to load state root: not found for block no %v", blockNo)
+	}
+	return (*sdb.statedb).Get(key), nil
+}
+
+// flushBuffer writes the buffered block states and state roots to db in a transaction, and empties the buffer.
+func (sdb *ChainStateDB) flushBuffer() error {
+	if sdb.buffer == nil || sdb.buffer.len() == 0 {
+		return nil
+	}
+	raws := make(map[types.BlockID][]byte, len(sdb.buffer.blockStates))
+	for bid, bs := range sdb.buffer.blockStates {
+		raw, err := encodeData(&blockStateData{
+			BlockInfo: bs.BlockInfo,
+			Accounts:  bs.accounts,
+			Root:      bs.root,
+		})
+		if err != nil {
+			return err
+		}
+		raws[bid] = raw
+	}
+	tx := (*sdb.statedb).NewTx(true)
+	for bid, raw := range raws {
+		tx.Set(bid[:], raw)
+	}
+	for blockNo, root := range sdb.buffer.roots {
+		tx.Set(stateRootKey(blockNo), root)
+	}
+	tx.Commit()
+	sdb.buffer = newStateBuffer()
+	return nil
 }
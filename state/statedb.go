@@ -12,17 +12,31 @@ import (
 	"path"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/aergoio/aergo-lib/db"
 	"github.com/aergoio/aergo-lib/log"
 	"github.com/aergoio/aergo/pkg/trie"
 	"github.com/aergoio/aergo/types"
+	lru "github.com/hashicorp/golang-lru"
 )
 
 const (
-	stateName     = "state"
+	stateName = "state"
+	// stateAccounts is the key of all the account states saved at once, by the versions before each account is
+	// saved by its own key. It is migrated on loading.
 	stateAccounts = stateName + ".accounts"
+	stateAccount  = stateName + ".account."
 	stateLatest   = stateName + ".latest"
+	stateRoot     = stateName + ".root."
+	stateGenesis  = stateName + ".genesis"
+
+	// defaultAccountCacheSize is the number of accounts cached in memory unless SetAccountCacheSize is called.
+	defaultAccountCacheSize = 65536
+
+	// MaxUndoDepth is the maximum number of blocks below the latest one, as of which GetAccountStateAt rebuilds an
+	// account state. Each of them is loaded to undo its changes.
+	MaxUndoDepth = 1024
 )
 
 var (
@@ -34,6 +48,42 @@ var (
 	emptyAccountID = types.AccountID{}
 )
 
+// EmptyRoot is the root hash of the state trie which has no account. It is the state root of a block after which
+// no account exists.
+var EmptyRoot = emptyTrieRoot()
+
+// emptyTrieRoot returns the root of a trie of 32 bytes keys whose leaves are all default.
+func emptyTrieRoot() []byte {
+	hasher := types.GetTrieHasher()
+	root := trie.DefaultLeaf
+	for i := 0; i < 32*8; i++ {
+		root = hasher(root, root)
+	}
+	return root
+}
+
+// ErrBlockGap reports the block to apply doesn't follow the latest block applied to the state, so that the
+// state must catch up with the blocks between them first.
+type ErrBlockGap struct {
+	Latest  types.BlockNo
+	BlockNo types.BlockNo
+}
+
+func (e *ErrBlockGap) Error() string {
+	return fmt.Sprintf("Failed to apply: invalid block no - latest=%v, this=%v", e.Latest, e.BlockNo)
+}
+
+// ErrPrevHashMismatch reports the block to apply is not the child of the latest block applied to the state,
+// which means it is of a fork.
+type ErrPrevHashMismatch struct {
+	Latest   types.BlockID
+	PrevHash types.BlockID
+}
+
+func (e *ErrPrevHashMismatch) Error() string {
+	return fmt.Sprintf("Failed to apply: invalid previous block latest=%v, bstate=%v", e.Latest, e.PrevHash)
+}
+
 type BlockInfo struct {
 	BlockNo   types.BlockNo
 	BlockHash types.BlockID
@@ -47,6 +97,8 @@ type StateEntry struct {
 type BlockState struct {
 	BlockInfo
 	accounts map[types.AccountID]*StateEntry
+	// root is the root hash of state trie after the block is applied
+	root []byte
 }
 
 func NewStateEntry(state, undo *types.State) *StateEntry {
@@ -80,15 +132,51 @@ func (bs *BlockState) PutAccount(aid types.AccountID, state, change *types.State
 
 type ChainStateDB struct {
 	sync.RWMutex
-	accounts map[types.AccountID]*types.State
-	trie     *trie.Trie
-	latest   *BlockInfo
-	statedb  *db.DB
+	// accounts caches the states of accounts recently read or changed, evicting the least recently used ones.
+	// The state of an account missing in it is loaded from db. nil disables the cache.
+	accounts *lru.Cache
+	// dirty keeps the states of accounts changed after the last saveStateDB, and nil for the deleted ones. They are
+	// never evicted, since db doesn't have them yet.
+	dirty   map[types.AccountID]*types.State
+	trie    *trie.Trie
+	latest  *BlockInfo
+	statedb *db.DB
+	dbPath  string
+	gc      *DBGC
+
+	// batchMode defers trie commits and saving latest info until FlushState
+	batchMode bool
+	// buffer keeps the states of applied blocks in memory until bufferSize blocks are accumulated
+	buffer     *stateBuffer
+	bufferSize int
+	// blockStates caches the block states recently loaded or saved, which are read again by successive rollbacks
+	// and reorgs. The cached block states must not be modified.
+	blockStates *lru.Cache
+}
+
+// stateBuffer is a write-ahead buffer of the block states and state roots, which are applied but not written
+// to db yet.
+type stateBuffer struct {
+	blockStates map[types.BlockID]*BlockState
+	roots       map[types.BlockNo][]byte
+}
+
+func newStateBuffer() *stateBuffer {
+	return &stateBuffer{
+		blockStates: make(map[types.BlockID]*BlockState),
+		roots:       make(map[types.BlockNo][]byte),
+	}
+}
+
+func (buf *stateBuffer) len() int {
+	return len(buf.roots)
 }
 
 func NewStateDB() *ChainStateDB {
+	accounts, _ := lru.New(defaultAccountCacheSize)
 	return &ChainStateDB{
-		accounts: make(map[types.AccountID]*types.State),
+		accounts: accounts,
+		dirty:    make(map[types.AccountID]*types.State),
 	}
 }
 
@@ -108,6 +196,7 @@ func (sdb *ChainStateDB) Init(dataDir string) error {
 	// init db
 	if sdb.statedb == nil {
 		sdb.statedb = InitDB(dataDir, stateName)
+		sdb.dbPath = path.Join(dataDir, stateName)
 	}
 
 	// init trie
@@ -115,55 +204,233 @@ func (sdb *ChainStateDB) Init(dataDir string) error {
 	sdb.trie = trie.NewTrie(32, hasher, *sdb.statedb)
 
 	// load data from db
-	err := sdb.loadStateDB()
-	return err
+	if err := sdb.loadStateDB(); err != nil {
+		return err
+	}
+	// restore the root of trie as of the latest block
+	if sdb.latest != nil {
+		if root, err := sdb.loadStateRoot(sdb.latest.BlockNo); err == nil {
+			sdb.trie.Root = root
+		}
+	}
+	return nil
 }
 
+// Close saves the data to db and closes it. The db is closed even if the data fails to be saved.
 func (sdb *ChainStateDB) Close() error {
 	sdb.Lock()
 	defer sdb.Unlock()
 
+	if sdb.statedb == nil {
+		return nil
+	}
+	defer func() {
+		sdb.gc.Stop()
+		sdb.gc = nil
+		(*sdb.statedb).Close()
+		sdb.statedb = nil
+	}()
+
 	// save data to db
-	err := sdb.saveStateDB()
+	return sdb.flush()
+}
+
+// StartGC runs value log GC of the state db every interval. See StartDBGC.
+func (sdb *ChainStateDB) StartGC(interval time.Duration, ratio float64) {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if sdb.statedb == nil || sdb.gc != nil {
+		return
+	}
+	sdb.gc = StartDBGC(stateName, sdb.dbPath, *sdb.statedb, interval, ratio)
+}
+
+// SetBufferSize makes the states of applied blocks buffered in memory, and written to db at once for every size
+// blocks. The trie commit and saving latest block info are deferred together, so the state on disk is always the
+// one of a block written to db. Reads see the buffered states. 0 disables buffering after writing buffered states.
+func (sdb *ChainStateDB) SetBufferSize(size int) error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if size <= 0 {
+		if err := sdb.checkpoint(); err != nil {
+			return err
+		}
+		sdb.buffer = nil
+		sdb.bufferSize = 0
+		return nil
+	}
+	if sdb.buffer == nil {
+		sdb.buffer = newStateBuffer()
+	}
+	sdb.bufferSize = size
+	return nil
+}
+
+// SetBlockStateCacheSize makes up to size block states recently loaded or saved kept in memory, so that they are
+// not read from db again. 0 disables the cache.
+func (sdb *ChainStateDB) SetBlockStateCacheSize(size int) error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if size <= 0 {
+		sdb.blockStates = nil
+		return nil
+	}
+	cache, err := lru.New(size)
 	if err != nil {
 		return err
 	}
+	sdb.blockStates = cache
+	return nil
+}
 
-	// close db
-	if sdb.statedb != nil {
-		(*sdb.statedb).Close()
+// SetAccountCacheSize makes up to size account states recently read or changed kept in memory. The accounts
+// changed but not yet written to db are kept regardless of size. 0 disables the cache.
+func (sdb *ChainStateDB) SetAccountCacheSize(size int) error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if size <= 0 {
+		sdb.accounts = nil
+		return nil
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		return err
 	}
+	sdb.accounts = cache
 	return nil
 }
 
+// GetLatestBlockNo returns the number of the latest block applied to the state.
+func (sdb *ChainStateDB) GetLatestBlockNo() types.BlockNo {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	if sdb.latest == nil {
+		return 0
+	}
+	return sdb.latest.BlockNo
+}
+
 func (sdb *ChainStateDB) SetGenesis(genesisBlock *types.Block) error {
+	return sdb.SetGenesisWithStates(genesisBlock, nil)
+}
+
+// SetGenesisWithStates sets genesis block as the latest, with initial states of accounts. If the state is already
+// initialized, it does nothing for the same genesis block, and fails for another one.
+func (sdb *ChainStateDB) SetGenesisWithStates(genesisBlock *types.Block, states map[types.AccountID]*types.State) error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
 	gbInfo := &BlockInfo{
 		BlockNo:   0,
 		BlockHash: types.ToBlockID(genesisBlock.Hash),
 	}
+	if sdb.latest != nil {
+		same, err := sdb.isGenesis(gbInfo.BlockHash)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return fmt.Errorf("Failed to set genesis: state is already initialized with another genesis block")
+		}
+		return nil
+	}
 	sdb.latest = gbInfo
 
 	// save state of genesis block
 	bstate := NewBlockState(gbInfo.BlockNo, gbInfo.BlockHash, types.BlockID{})
-	sdb.saveBlockState(bstate)
+	for aid, state := range states {
+		if isEmptyState(state) {
+			continue
+		}
+		bstate.PutAccount(aid, nil, state)
+		sdb.putAccount(aid, state)
+	}
+	if err := sdb.updateTrie(bstate); err != nil {
+		return err
+	}
+	bstate.root = sdb.root()
+	if err := sdb.saveBlockState(bstate); err != nil {
+		return err
+	}
+	if err := sdb.saveStateRoot(gbInfo.BlockNo); err != nil {
+		return err
+	}
+	if err := saveData(sdb.statedb, []byte(stateGenesis), gbInfo.BlockHash[:]); err != nil {
+		return err
+	}
 
-	// TODO: process initial coin tx
-	err := sdb.saveStateDB()
-	return err
+	return sdb.checkpoint()
 }
 
+// isGenesis reports whether the state is initialized with the genesis block of bid.
+func (sdb *ChainStateDB) isGenesis(bid types.BlockID) (bool, error) {
+	if stored := (*sdb.statedb).Get([]byte(stateGenesis)); len(stored) > 0 {
+		return bytes.Equal(stored, bid[:]), nil
+	}
+	// initialized before the genesis hash is saved, which has the block state of genesis block
+	bs, err := sdb.loadBlockState(bid)
+	if err != nil {
+		return false, err
+	}
+	return bs.BlockHash == bid && bs.BlockNo == 0, nil
+}
+
+// getAccountState returns the latest state of account. It never modifies sdb; the state of account which doesn't
+// exist is returned empty without being kept. It must be called with the lock held.
 func (sdb *ChainStateDB) getAccountState(aid types.AccountID) (*types.State, error) {
 	if aid == emptyAccountID {
 		return nil, fmt.Errorf("Failed to get block account: invalid account id")
 	}
-	if state, ok := sdb.accounts[aid]; ok {
+	if state, ok := sdb.dirty[aid]; ok {
+		if state == nil {
+			// deleted, but not yet from db
+			return types.NewState(), nil
+		}
 		return state, nil
 	}
-	state := types.NewState()
-	sdb.accounts[aid] = state
+	if sdb.accounts != nil {
+		if cached, ok := sdb.accounts.Get(aid); ok {
+			return cached.(*types.State), nil
+		}
+	}
+	state, err := sdb.loadAccount(aid)
+	if err != nil {
+		return nil, err
+	}
+	// the cache is safe to update with the read lock only, and the account missing in db is not kept
+	if sdb.accounts != nil && !isEmptyState(state) {
+		sdb.accounts.Add(aid, state)
+	}
 	return state, nil
 }
+
+// putAccount sets the latest state of account, which is written to db by saveStateDB. The account is deleted if
+// state is empty.
+func (sdb *ChainStateDB) putAccount(aid types.AccountID, state *types.State) {
+	if isEmptyState(state) {
+		if sdb.accounts != nil {
+			sdb.accounts.Remove(aid)
+		}
+		sdb.dirty[aid] = nil
+		return
+	}
+	if sdb.accounts != nil {
+		sdb.accounts.Add(aid, state)
+	}
+	sdb.dirty[aid] = state
+}
+
+// GetAccountStateClone returns a clone of the latest state of account. It is safe to call concurrently with the
+// changes of state.
 func (sdb *ChainStateDB) GetAccountStateClone(aid types.AccountID) (*types.State, error) {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
 	state, err := sdb.getAccountState(aid)
 	if err != nil {
 		return nil, err
@@ -171,6 +438,52 @@ func (sdb *ChainStateDB) GetAccountStateClone(aid types.AccountID) (*types.State
 	res := types.Clone(*state).(types.State)
 	return &res, nil
 }
+
+// GetAccountStateAt returns a clone of the account state right after the block of blockNo was
+// applied. It is rebuilt from the latest state by undoing the block states above blockNo, which must be
+// at most MaxUndoDepth blocks.
+func (sdb *ChainStateDB) GetAccountStateAt(aid types.AccountID, blockNo types.BlockNo) (*types.State, error) {
+	if aid == emptyAccountID {
+		return nil, fmt.Errorf("Failed to get account state: invalid account id")
+	}
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	if sdb.latest == nil || sdb.latest.BlockNo < blockNo {
+		return nil, fmt.Errorf("Failed to get account state: block no %v is higher than latest", blockNo)
+	}
+	if sdb.latest.BlockNo-blockNo > MaxUndoDepth {
+		return nil, fmt.Errorf("Failed to get account state: block no %v is more than %d blocks below latest",
+			blockNo, MaxUndoDepth)
+	}
+	state, err := sdb.getAccountState(aid)
+	if err != nil {
+		return nil, err
+	}
+	target := *sdb.latest
+	for target.BlockNo > blockNo {
+		bs, err := sdb.loadBlockState(target.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := bs.accounts[aid]; ok {
+			state = entry.Undo
+			if state == nil {
+				// the account didn't exist before the block
+				state = types.NewState()
+			}
+		}
+		target = BlockInfo{
+			BlockNo:   target.BlockNo - 1,
+			BlockHash: bs.PrevHash,
+		}
+	}
+	res := types.Clone(*state).(types.State)
+	return &res, nil
+}
+
+// getBlockAccount returns the state of account changed in bs, or the latest one if bs doesn't change it. It must
+// be called with the lock held.
 func (sdb *ChainStateDB) getBlockAccount(bs *BlockState, aid types.AccountID) (*types.State, error) {
 	if aid == emptyAccountID {
 		return nil, fmt.Errorf("Failed to get block account: invalid account id")
@@ -181,7 +494,13 @@ func (sdb *ChainStateDB) getBlockAccount(bs *BlockState, aid types.AccountID) (*
 	}
 	return sdb.getAccountState(aid)
 }
+
+// GetBlockAccountClone returns a clone of the state of account in bs. It is safe to call concurrently with the
+// changes of state.
 func (sdb *ChainStateDB) GetBlockAccountClone(bs *BlockState, aid types.AccountID) (*types.State, error) {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
 	state, err := sdb.getBlockAccount(bs, aid)
 	if err != nil {
 		return nil, err
@@ -190,12 +509,37 @@ func (sdb *ChainStateDB) GetBlockAccountClone(bs *BlockState, aid types.AccountI
 	return &res, nil
 }
 
-func (sdb *ChainStateDB) updateTrie(bstate *BlockState, undo bool) error {
+func (sdb *ChainStateDB) updateTrie(bstate *BlockState) error {
 	size := len(bstate.accounts)
 	if size <= 0 {
 		// do nothing
 		return nil
 	}
+	keys, vals := trieData(bstate)
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := sdb.trie.Update(keys, vals)
+	if err != nil {
+		return err
+	}
+	if sdb.deferred() {
+		// commit is deferred until FlushState or flushing buffer
+		return nil
+	}
+	return sdb.trie.Commit()
+}
+
+// isEmptyState reports whether the state is of an account which doesn't exist. Empty accounts are
+// never stored in the trie, so that they don't affect the root hash.
+func isEmptyState(st *types.State) bool {
+	return st == nil || st.IsEmpty()
+}
+
+// trieData returns sorted keys and values of accounts in block state, to update trie.
+// An account which becomes empty is deleted from the trie by DefaultLeaf value.
+func trieData(bstate *BlockState) (trie.DataArray, trie.DataArray) {
+	size := len(bstate.accounts)
 	accs := make([]types.AccountID, 0, size)
 	for k := range bstate.accounts {
 		accs = append(accs, k)
@@ -203,54 +547,171 @@ func (sdb *ChainStateDB) updateTrie(bstate *BlockState, undo bool) error {
 	sort.Slice(accs, func(i, j int) bool {
 		return bytes.Compare(accs[i][:], accs[j][:]) == -1
 	})
-	keys := make(trie.DataArray, size)
-	vals := make(trie.DataArray, size)
-	for i, v := range accs {
-		keys[i] = v[:]
-		if undo {
-			vals[i] = bstate.accounts[v].Undo.GetHash()
+	keys := make(trie.DataArray, 0, size)
+	vals := make(trie.DataArray, 0, size)
+	for _, v := range accs {
+		from, to := bstate.accounts[v].Undo, bstate.accounts[v].State
+		if isEmptyState(to) {
+			if isEmptyState(from) {
+				// not in the trie, and deleting a missing key makes the root invalid
+				continue
+			}
+			keys = append(keys, v[:])
+			vals = append(vals, trie.DefaultLeaf)
 		} else {
-			vals[i] = bstate.accounts[v].State.GetHash()
+			keys = append(keys, v[:])
+			vals = append(vals, to.GetHash())
 		}
 	}
-	if size > 0 {
-		_, err := sdb.trie.Update(keys, vals)
-		return err
+	return keys, vals
+}
+
+// ComputeRoot returns the root hash of state trie, which would be after the block state is applied.
+// The state itself is not changed.
+func (sdb *ChainStateDB) ComputeRoot(bstate *BlockState) ([]byte, error) {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	if len(bstate.accounts) == 0 {
+		return sdb.root(), nil
 	}
-	sdb.trie.Commit()
-	return nil
+	oldRoot := sdb.trie.Root
+	keys, vals := trieData(bstate)
+	if len(keys) == 0 {
+		return sdb.root(), nil
+	}
+	root, err := sdb.trie.Update(keys, vals)
+	// restore the root. updated nodes are just garbage, since they are not reachable from the root.
+	sdb.trie.Root = oldRoot
+	if err != nil {
+		return nil, err
+	}
+	if len(root) == 0 {
+		return EmptyRoot, nil
+	}
+	return root, nil
 }
 
+// revertTrie restores the state trie to the root right after bstate was applied. The trie nodes of past roots are
+// never deleted, so the trie is restored just by the root, without undoing the changes of the blocks above.
 func (sdb *ChainStateDB) revertTrie(bstate *BlockState) error {
-	// TODO: Use root hash of previous block state to revert instead of manual update
-	return sdb.updateTrie(bstate, true)
+	root := bstate.root
+	if len(root) == 0 {
+		// block state saved before the root was kept in it
+		var err error
+		if root, err = sdb.loadStateRoot(bstate.BlockNo); err != nil {
+			return err
+		}
+	}
+	sdb.trie.Root = root
+	return nil
 }
 
-func (sdb *ChainStateDB) Apply(bstate *BlockState) error {
-	if sdb.latest.BlockNo+1 != bstate.BlockNo {
-		return fmt.Errorf("Failed to apply: invalid block no - latest=%v, this=%v", sdb.latest.BlockNo, bstate.BlockNo)
+// CheckNext returns ErrBlockGap or ErrPrevHashMismatch if the block of blockNo and prevHash can't be applied next
+// to the latest state.
+func (sdb *ChainStateDB) CheckNext(blockNo types.BlockNo, prevHash types.BlockID) error {
+	if sdb.latest.BlockNo+1 != blockNo {
+		return &ErrBlockGap{Latest: sdb.latest.BlockNo, BlockNo: blockNo}
+	}
+	if sdb.latest.BlockHash != prevHash {
+		return &ErrPrevHashMismatch{Latest: sdb.latest.BlockHash, PrevHash: prevHash}
 	}
-	if sdb.latest.BlockHash != bstate.PrevHash {
-		return fmt.Errorf("Failed to apply: invalid previous block latest=%v, bstate=%v",
-			sdb.latest.BlockHash, bstate.PrevHash)
+	return nil
+}
+
+func (sdb *ChainStateDB) Apply(bstate *BlockState) error {
+	if err := sdb.CheckNext(bstate.BlockNo, bstate.PrevHash); err != nil {
+		return err
 	}
 	sdb.Lock()
 	defer sdb.Unlock()
 
-	sdb.saveBlockState(bstate)
 	for k, v := range bstate.accounts {
-		sdb.accounts[k] = v.State
+		sdb.putAccount(k, v.State)
 	}
-	err := sdb.updateTrie(bstate, false)
+	err := sdb.updateTrie(bstate)
 	if err != nil {
 		return err
 	}
+	bstate.root = sdb.root()
+	if err = sdb.saveBlockState(bstate); err != nil {
+		return err
+	}
 	// logger.Debugf("- trie.root: %v", base64.StdEncoding.EncodeToString(sdb.GetHash()))
 	sdb.latest = &bstate.BlockInfo
+	err = sdb.saveStateRoot(bstate.BlockNo)
+	if err != nil {
+		return err
+	}
+	if sdb.buffer != nil && sdb.buffer.len() >= sdb.bufferSize {
+		if sdb.batchMode {
+			// latest on disk is not advanced until FlushState, but the buffer must not grow during an import
+			return sdb.flushBuffer()
+		}
+		return sdb.checkpoint()
+	}
+	if sdb.deferred() {
+		// latest on disk is not advanced until FlushState or flushing buffer, to keep crash consistency
+		return nil
+	}
 	err = sdb.saveStateDB()
 	return err
 }
 
+// BeginBatch makes following Apply calls defer trie commits and saving latest block info
+// until FlushState is called. It is used to import many blocks at once.
+func (sdb *ChainStateDB) BeginBatch() {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	sdb.batchMode = true
+}
+
+// FlushState commits the trie and saves latest block info, which are deferred in batch mode,
+// and then ends batch mode.
+func (sdb *ChainStateDB) FlushState() error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	return sdb.flush()
+}
+
+// Flush writes the trie, accounts and latest block info to db, so that the current state is recovered
+// after restart. Unlike FlushState, batch mode is kept, so it can be called periodically during an import
+// as a checkpoint, as well as before shutdown.
+func (sdb *ChainStateDB) Flush() error {
+	sdb.Lock()
+	defer sdb.Unlock()
+
+	return sdb.checkpoint()
+}
+
+func (sdb *ChainStateDB) flush() error {
+	if err := sdb.checkpoint(); err != nil {
+		return err
+	}
+	sdb.batchMode = false
+	return nil
+}
+
+// deferred reports whether trie commits are deferred by batch mode or buffering.
+func (sdb *ChainStateDB) deferred() bool {
+	return sdb.batchMode || sdb.buffer != nil
+}
+
+func (sdb *ChainStateDB) checkpoint() error {
+	// the block states must be on disk before latest refers to them
+	if err := sdb.flushBuffer(); err != nil {
+		return err
+	}
+	if sdb.deferred() {
+		if err := sdb.trie.Commit(); err != nil {
+			return err
+		}
+	}
+	return sdb.saveStateDB()
+}
+
 func (sdb *ChainStateDB) Rollback(blockNo types.BlockNo) error {
 	if sdb.latest.BlockNo <= blockNo {
 		return fmt.Errorf("Failed to rollback: invalid block no")
@@ -258,6 +719,10 @@ func (sdb *ChainStateDB) Rollback(blockNo types.BlockNo) error {
 	sdb.Lock()
 	defer sdb.Unlock()
 
+	if sdb.batchMode {
+		return fmt.Errorf("Failed to rollback: state is not flushed")
+	}
+
 	target := sdb.latest
 	for target.BlockNo >= blockNo {
 		bs, err := sdb.loadBlockState(target.BlockHash)
@@ -267,27 +732,62 @@ func (sdb *ChainStateDB) Rollback(blockNo types.BlockNo) error {
 		sdb.latest = &bs.BlockInfo
 
 		if target.BlockNo == blockNo {
+			if err := sdb.revertTrie(bs); err != nil {
+				return err
+			}
 			break
 		}
 
 		for k, v := range bs.accounts {
-			sdb.accounts[k] = v.Undo
+			sdb.putAccount(k, v.Undo)
 		}
-		err = sdb.revertTrie(bs)
-		if err != nil {
-			return err
-		}
-		// logger.Debugf("- trie.root: %v", base64.StdEncoding.EncodeToString(sdb.GetHash()))
 
 		target = &BlockInfo{
 			BlockNo:   sdb.latest.BlockNo - 1,
 			BlockHash: sdb.latest.PrevHash,
 		}
 	}
-	err := sdb.saveStateDB()
-	return err
+	return sdb.checkpoint()
 }
 
+// GetHash returns the current root hash of state trie. It is EmptyRoot, never nil, if no account exists.
 func (sdb *ChainStateDB) GetHash() []byte {
+	return sdb.root()
+}
+
+func (sdb *ChainStateDB) root() []byte {
+	if len(sdb.trie.Root) == 0 {
+		return EmptyRoot
+	}
 	return sdb.trie.Root
 }
+
+// GetStateRootAt returns the root hash of state trie right after the block of blockNo was applied.
+func (sdb *ChainStateDB) GetStateRootAt(blockNo types.BlockNo) ([]byte, error) {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	if sdb.latest == nil || sdb.latest.BlockNo < blockNo {
+		return nil, fmt.Errorf("Failed to get state root: block no %v is higher than latest", blockNo)
+	}
+	return sdb.loadStateRoot(blockNo)
+}
+
+// GetStateRoot returns the root hash of state trie right after the block of blockHash was applied. Unlike
+// GetStateRootAt, it finds the root of a block which is not in the main chain, such as one rolled back by reorg.
+func (sdb *ChainStateDB) GetStateRoot(blockHash types.BlockID) ([]byte, error) {
+	sdb.RLock()
+	defer sdb.RUnlock()
+
+	bs, err := sdb.loadBlockState(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if bs.BlockHash != blockHash {
+		return nil, fmt.Errorf("Failed to get state root: unknown block %v", blockHash)
+	}
+	if len(bs.root) == 0 {
+		return nil, fmt.Errorf("Failed to get state root: not recorded for block %v", blockHash)
+	}
+	return bs.root, nil
+}
@@ -0,0 +1,788 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/aergoio/aergo/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStateDB(t testing.TB) (*ChainStateDB, string) {
+	dataDir, err := ioutil.TempDir("", "statedb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdb := NewStateDB()
+	if err = sdb.Init(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	genesis := types.NewBlock(nil, nil, 0)
+	genesis.BlockHash()
+	if err = sdb.SetGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	return sdb, dataDir
+}
+
+func closeTestStateDB(sdb *ChainStateDB, dataDir string) {
+	sdb.Close()
+	os.RemoveAll(dataDir)
+}
+
+func testBlockID(blockNo types.BlockNo) types.BlockID {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, blockNo)
+	return types.BlockID(sha256.Sum256(buf))
+}
+
+// newTestBlockStates makes block states following genesis, each of them changes accPerBlock accounts
+func newTestBlockStates(genesis types.BlockID, blockCnt, accPerBlock int) []*BlockState {
+	bstates := make([]*BlockState, 0, blockCnt)
+	prevHash := genesis
+	for i := 1; i <= blockCnt; i++ {
+		blockNo := types.BlockNo(i)
+		blockHash := testBlockID(blockNo)
+		bs := NewBlockState(blockNo, blockHash, prevHash)
+		for j := 0; j < accPerBlock; j++ {
+			aid := types.ToAccountID([]byte{byte(j), byte(i), byte(i >> 8)})
+			bs.PutAccount(aid, types.NewState(), &types.State{Nonce: uint64(i), Balance: uint64(i*1000 + j)})
+		}
+		bstates = append(bstates, bs)
+		prevHash = blockHash
+	}
+	return bstates
+}
+
+func TestChainStateDB_FlushState(t *testing.T) {
+	perBlock, perBlockDir := newTestStateDB(t)
+	defer closeTestStateDB(perBlock, perBlockDir)
+	batched, batchedDir := newTestStateDB(t)
+	defer closeTestStateDB(batched, batchedDir)
+
+	bstates := newTestBlockStates(perBlock.latest.BlockHash, 10, 10)
+	for _, bs := range bstates {
+		assert.Nil(t, perBlock.Apply(bs))
+	}
+
+	batched.BeginBatch()
+	for _, bs := range bstates {
+		assert.Nil(t, batched.Apply(bs))
+	}
+	assert.Equal(t, types.BlockNo(10), batched.latest.BlockNo)
+	// latest on disk must not be advanced before flush
+	var saved *BlockInfo
+	assert.Nil(t, loadData(batched.statedb, []byte(stateLatest), &saved))
+	assert.Equal(t, types.BlockNo(0), saved.BlockNo)
+	assert.NotNil(t, batched.Rollback(5))
+
+	assert.Nil(t, batched.FlushState())
+	assert.Nil(t, loadData(batched.statedb, []byte(stateLatest), &saved))
+	assert.Equal(t, types.BlockNo(10), saved.BlockNo)
+	assert.Equal(t, perBlock.GetHash(), batched.GetHash())
+}
+
+func TestChainStateDB_Flush(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer os.RemoveAll(dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 10, 5)
+	sdb.BeginBatch()
+	for _, bs := range bstates[:6] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	assert.Nil(t, sdb.Flush())
+	// flush is a checkpoint, which does not end batch mode
+	assert.True(t, sdb.batchMode)
+	root := append([]byte{}, sdb.GetHash()...)
+	accounts := make(map[types.AccountID]types.State, sdb.accounts.Len())
+	for _, key := range sdb.accounts.Keys() {
+		state, _ := sdb.accounts.Peek(key)
+		accounts[key.(types.AccountID)] = *state.(*types.State)
+	}
+	// blocks after the checkpoint are lost by the crash
+	for _, bs := range bstates[6:] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	expected := append([]byte{}, sdb.GetHash()...)
+	sdb.gc.Stop()
+	(*sdb.statedb).Close()
+
+	reopened := NewStateDB()
+	assert.Nil(t, reopened.Init(dataDir))
+	defer reopened.Close()
+	assert.Equal(t, bstates[5].BlockInfo, *reopened.latest)
+	assert.Equal(t, root, reopened.GetHash())
+	for aid, state := range accounts {
+		actual, err := reopened.GetAccountStateClone(aid)
+		assert.Nil(t, err)
+		assert.Equal(t, state.GetHash(), actual.GetHash())
+	}
+
+	// the reopened state continues from the checkpoint
+	for _, bs := range bstates[6:] {
+		assert.Nil(t, reopened.Apply(bs))
+	}
+	assert.Equal(t, expected, reopened.GetHash())
+}
+
+func TestChainStateDB_Buffer(t *testing.T) {
+	perBlock, perBlockDir := newTestStateDB(t)
+	defer closeTestStateDB(perBlock, perBlockDir)
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+	assert.Nil(t, sdb.SetBufferSize(4))
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 6, 5)
+	for _, bs := range bstates {
+		assert.Nil(t, perBlock.Apply(bs))
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	assert.Equal(t, perBlock.GetHash(), sdb.GetHash())
+	// the buffer is written at block 4, and has blocks 5 and 6
+	assert.Equal(t, 2, sdb.buffer.len())
+	var saved *BlockInfo
+	assert.Nil(t, loadData(sdb.statedb, []byte(stateLatest), &saved))
+	assert.Equal(t, types.BlockNo(4), saved.BlockNo)
+	assert.False(t, (*sdb.statedb).Exist(bstates[5].BlockHash[:]))
+
+	// reads see the buffered states
+	for i := range bstates {
+		blockNo := types.BlockNo(i + 1)
+		expected, err := perBlock.GetStateRootAt(blockNo)
+		assert.Nil(t, err)
+		actual, err := sdb.GetStateRootAt(blockNo)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	aid := types.ToAccountID([]byte{byte(0), byte(5), byte(0)})
+	st, err := sdb.GetAccountStateAt(aid, 4)
+	assert.Nil(t, err)
+	assert.True(t, st.IsEmpty())
+	st, err = sdb.GetAccountStateAt(aid, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5000), st.Balance)
+
+	// rollback into the buffered blocks
+	assert.Nil(t, sdb.Rollback(5))
+	assert.Nil(t, perBlock.Rollback(5))
+	assert.Equal(t, perBlock.GetHash(), sdb.GetHash())
+	assert.Equal(t, 0, sdb.buffer.len())
+	assert.Nil(t, loadData(sdb.statedb, []byte(stateLatest), &saved))
+	assert.Equal(t, types.BlockNo(5), saved.BlockNo)
+}
+
+func TestChainStateDB_BufferRecovery(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer os.RemoveAll(dataDir)
+	assert.Nil(t, sdb.SetBufferSize(4))
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 10, 5)
+	for _, bs := range bstates[:8] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	flushed := append([]byte{}, sdb.GetHash()...)
+	for _, bs := range bstates[8:] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	expected := append([]byte{}, sdb.GetHash()...)
+	// crash with blocks 9 and 10 in the buffer
+	sdb.gc.Stop()
+	(*sdb.statedb).Close()
+
+	reopened := NewStateDB()
+	assert.Nil(t, reopened.Init(dataDir))
+	defer reopened.Close()
+	// the state is the one of the last written block
+	assert.Equal(t, bstates[7].BlockInfo, *reopened.latest)
+	assert.Equal(t, flushed, reopened.GetHash())
+	assert.False(t, (*reopened.statedb).Exist(bstates[8].BlockHash[:]))
+
+	// the lost blocks are applied again
+	assert.Nil(t, reopened.SetBufferSize(4))
+	for _, bs := range bstates[8:] {
+		assert.Nil(t, reopened.Apply(bs))
+	}
+	assert.Nil(t, reopened.Flush())
+	assert.Equal(t, expected, reopened.GetHash())
+	root, err := reopened.GetStateRootAt(10)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, root)
+}
+
+func TestChainStateDB_GetStateRootAt(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	genesisRoot, err := sdb.GetStateRootAt(0)
+	assert.Nil(t, err)
+	assert.Equal(t, sdb.GetHash(), genesisRoot)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 5, 3)
+	roots := make([][]byte, 0, len(bstates))
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+		roots = append(roots, append([]byte{}, sdb.GetHash()...))
+	}
+	for i, expected := range roots {
+		actual, err := sdb.GetStateRootAt(types.BlockNo(i + 1))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	_, err = sdb.GetStateRootAt(types.BlockNo(len(bstates) + 1))
+	assert.NotNil(t, err)
+}
+
+func TestChainStateDB_GetStateRoot(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	genesisRoot, err := sdb.GetStateRoot(sdb.latest.BlockHash)
+	assert.Nil(t, err)
+	assert.Equal(t, sdb.GetHash(), genesisRoot)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 5, 3)
+	roots := make([][]byte, 0, len(bstates))
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+		roots = append(roots, append([]byte{}, sdb.GetHash()...))
+	}
+	// the roots of rolled back blocks are kept
+	assert.Nil(t, sdb.Rollback(2))
+	for i, bs := range bstates {
+		actual, err := sdb.GetStateRoot(bs.BlockHash)
+		assert.Nil(t, err)
+		assert.Equal(t, roots[i], actual)
+	}
+
+	_, err = sdb.GetStateRoot(testBlockID(100))
+	assert.NotNil(t, err)
+	_, err = sdb.GetStateRoot(types.BlockID{})
+	assert.NotNil(t, err)
+}
+
+func TestChainStateDB_GetAccountStateAt(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	aid := types.ToAccountID([]byte("account"))
+	prevHash := sdb.latest.BlockHash
+	for i := 1; i <= 5; i++ {
+		blockNo := types.BlockNo(i)
+		bs := NewBlockState(blockNo, testBlockID(blockNo), prevHash)
+		prev, err := sdb.GetBlockAccountClone(bs, aid)
+		assert.Nil(t, err)
+		bs.PutAccount(aid, prev, &types.State{Nonce: uint64(i), Balance: uint64(i * 100)})
+		assert.Nil(t, sdb.Apply(bs))
+		prevHash = bs.BlockHash
+	}
+
+	head, err := sdb.GetAccountStateAt(aid, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), head.Nonce)
+	assert.Equal(t, uint64(500), head.Balance)
+
+	// 3 confirmations back from head
+	confirmed, err := sdb.GetAccountStateAt(aid, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), confirmed.Nonce)
+	assert.Equal(t, uint64(200), confirmed.Balance)
+
+	// the account didn't exist at genesis
+	genesis, err := sdb.GetAccountStateAt(aid, 0)
+	assert.Nil(t, err)
+	assert.True(t, genesis.IsEmpty())
+
+	_, err = sdb.GetAccountStateAt(aid, 6)
+	assert.NotNil(t, err)
+}
+
+func TestChainStateDB_GetAccountStateAtDepth(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	aid := types.ToAccountID([]byte("account"))
+	prevHash := sdb.latest.BlockHash
+	for i := 1; i <= MaxUndoDepth+1; i++ {
+		blockNo := types.BlockNo(i)
+		bs := NewBlockState(blockNo, testBlockID(blockNo), prevHash)
+		assert.Nil(t, sdb.Apply(bs))
+		prevHash = bs.BlockHash
+	}
+
+	_, err := sdb.GetAccountStateAt(aid, 1)
+	assert.Nil(t, err)
+	// the blocks to undo are bounded
+	_, err = sdb.GetAccountStateAt(aid, 0)
+	assert.NotNil(t, err)
+}
+
+func TestChainStateDB_EmptyAccount(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	emptyRoot := append([]byte{}, sdb.GetHash()...)
+	aid := types.ToAccountID([]byte("account"))
+	other := types.ToAccountID([]byte("other"))
+	putAndApply := func(blockNo types.BlockNo, aid types.AccountID, change *types.State) {
+		bs := NewBlockState(blockNo, testBlockID(blockNo), sdb.latest.BlockHash)
+		prev, err := sdb.GetBlockAccountClone(bs, aid)
+		assert.Nil(t, err)
+		bs.PutAccount(aid, prev, change)
+		assert.Nil(t, sdb.Apply(bs))
+	}
+
+	// an account which stays empty is not stored
+	putAndApply(1, other, types.NewState())
+	assert.Equal(t, emptyRoot, sdb.GetHash())
+
+	putAndApply(2, aid, &types.State{Nonce: 1, Balance: 100})
+	assert.NotEqual(t, emptyRoot, sdb.GetHash())
+	value, err := sdb.trie.Get(aid[:])
+	assert.Nil(t, err)
+	assert.NotEmpty(t, value)
+
+	// the account ending a block empty is removed from trie
+	putAndApply(3, aid, types.NewState())
+	assert.Equal(t, emptyRoot, sdb.GetHash())
+	value, err = sdb.trie.Get(aid[:])
+	assert.Nil(t, err)
+	assert.Empty(t, value)
+	assert.False(t, sdb.accounts.Contains(aid))
+
+	// rollback restores the removed account
+	assert.Nil(t, sdb.Rollback(2))
+	st, err := sdb.GetAccountStateClone(aid)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), st.Balance)
+	assert.NotEqual(t, emptyRoot, sdb.GetHash())
+}
+
+func TestChainStateDB_ConcurrentRead(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 20, 10)
+	aids := make([]types.AccountID, 0, 20)
+	for i := 1; i <= 20; i++ {
+		aids = append(aids, types.ToAccountID([]byte{byte(0), byte(i), byte(i >> 8)}))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 50; round++ {
+				for _, aid := range aids {
+					_, err := sdb.GetAccountStateClone(aid)
+					assert.Nil(t, err)
+					_, err = sdb.GetBlockAccountClone(bstates[0], aid)
+					assert.Nil(t, err)
+				}
+			}
+		}()
+	}
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	wg.Wait()
+
+	// reading the missing account doesn't keep it
+	missing := types.ToAccountID([]byte("missing"))
+	st, err := sdb.GetAccountStateClone(missing)
+	assert.Nil(t, err)
+	assert.True(t, isEmptyState(st))
+	assert.False(t, sdb.accounts.Contains(missing))
+	assert.Equal(t, len(aids)*10, sdb.accounts.Len())
+}
+
+func TestChainStateDB_EmptyRoot(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	assert.NotEmpty(t, EmptyRoot)
+	assert.Equal(t, EmptyRoot, sdb.GetHash())
+	root, err := sdb.GetStateRootAt(0)
+	assert.Nil(t, err)
+	assert.Equal(t, EmptyRoot, root)
+
+	// a block without any account change keeps the empty root
+	bs := NewBlockState(1, testBlockID(1), sdb.latest.BlockHash)
+	computed, err := sdb.ComputeRoot(bs)
+	assert.Nil(t, err)
+	assert.Equal(t, EmptyRoot, computed)
+}
+
+func TestChainStateDB_ComputeRoot(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 3, 5)
+	for _, bs := range bstates {
+		prevRoot := append([]byte{}, sdb.GetHash()...)
+		computed, err := sdb.ComputeRoot(bs)
+		assert.Nil(t, err)
+		// computing root must not change state
+		assert.Equal(t, prevRoot, sdb.GetHash())
+
+		assert.Nil(t, sdb.Apply(bs))
+		assert.Equal(t, computed, sdb.GetHash())
+	}
+}
+
+func benchmarkApply(b *testing.B, batch bool, bufferSize int) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		sdb, dataDir := newTestStateDB(b)
+		bstates := newTestBlockStates(sdb.latest.BlockHash, 100, 100)
+		if err := sdb.SetBufferSize(bufferSize); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if batch {
+			sdb.BeginBatch()
+		}
+		for _, bs := range bstates {
+			if err := sdb.Apply(bs); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := sdb.FlushState(); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		closeTestStateDB(sdb, dataDir)
+	}
+}
+
+func BenchmarkApplyPerBlock(b *testing.B) {
+	benchmarkApply(b, false, 0)
+}
+
+func BenchmarkApplyBatch(b *testing.B) {
+	benchmarkApply(b, true, 0)
+}
+
+func BenchmarkApplyBuffered(b *testing.B) {
+	benchmarkApply(b, false, 32)
+}
+
+func TestChainStateDB_RollbackRoot(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	roots := [][]byte{append([]byte{}, sdb.GetHash()...)}
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 8, 4)
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+		roots = append(roots, append([]byte{}, sdb.GetHash()...))
+	}
+
+	// the trie is restored to the exact historical root at each rollback
+	for _, blockNo := range []types.BlockNo{6, 3, 1, 0} {
+		assert.Nil(t, sdb.Rollback(blockNo))
+		assert.Equal(t, blockNo, sdb.latest.BlockNo)
+		assert.Equal(t, roots[blockNo], sdb.GetHash())
+		actual, err := sdb.GetStateRootAt(blockNo)
+		assert.Nil(t, err)
+		assert.Equal(t, roots[blockNo], actual)
+	}
+
+	// the restored trie keeps on updating as before
+	for i, bs := range bstates[:3] {
+		assert.Nil(t, sdb.Apply(bs))
+		assert.Equal(t, roots[i+1], sdb.GetHash())
+	}
+}
+
+func TestChainStateDB_ReloadAccounts(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer os.RemoveAll(dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 5, 3)
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	// an account deleted by the last block
+	deleted := types.ToAccountID([]byte{byte(0), byte(1), byte(0)})
+	bs := NewBlockState(6, testBlockID(6), sdb.latest.BlockHash)
+	bs.PutAccount(deleted, bstates[0].accounts[deleted].State, types.NewState())
+	assert.Nil(t, sdb.Apply(bs))
+	assert.Nil(t, sdb.Close())
+
+	reopened := NewStateDB()
+	assert.Nil(t, reopened.Init(dataDir))
+	defer reopened.Close()
+	// accounts are loaded on reading them, not at start
+	assert.Equal(t, 0, reopened.accounts.Len())
+	for _, bs := range bstates {
+		for aid, entry := range bs.accounts {
+			st, err := reopened.GetAccountStateClone(aid)
+			assert.Nil(t, err)
+			if aid == deleted {
+				assert.True(t, isEmptyState(st))
+			} else {
+				assert.Equal(t, entry.State.GetHash(), st.GetHash())
+			}
+		}
+	}
+	st, err := reopened.GetAccountStateClone(types.ToAccountID([]byte("missing")))
+	assert.Nil(t, err)
+	assert.True(t, isEmptyState(st))
+
+	// account states at past blocks are rebuilt from the loaded ones
+	st, err = reopened.GetAccountStateAt(deleted, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, bstates[0].accounts[deleted].State.GetHash(), st.GetHash())
+}
+
+func TestChainStateDB_MigrateAccounts(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer os.RemoveAll(dataDir)
+
+	// accounts saved at once by old versions
+	aid := types.ToAccountID([]byte("legacy"))
+	legacy := map[types.AccountID]*types.State{aid: {Nonce: 1, Balance: 100}}
+	assert.Nil(t, saveData(sdb.statedb, []byte(stateAccounts), legacy))
+	sdb.gc.Stop()
+	(*sdb.statedb).Close()
+
+	reopened := NewStateDB()
+	assert.Nil(t, reopened.Init(dataDir))
+	defer reopened.Close()
+	assert.Nil(t, reopened.Flush())
+	assert.False(t, (*reopened.statedb).Exist([]byte(stateAccounts)))
+	st, err := reopened.loadAccount(aid)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), st.Balance)
+}
+
+func TestChainStateDB_ApplyNonSequential(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	genesis := sdb.latest.BlockHash
+	bstates := newTestBlockStates(genesis, 3, 1)
+	assert.Nil(t, sdb.Apply(bstates[0]))
+
+	// block 3 is ahead of block 2 to apply next
+	err := sdb.Apply(bstates[2])
+	if assert.IsType(t, &ErrBlockGap{}, err) {
+		assert.Equal(t, types.BlockNo(1), err.(*ErrBlockGap).Latest)
+		assert.Equal(t, types.BlockNo(3), err.(*ErrBlockGap).BlockNo)
+	}
+	// block 1 is already applied
+	assert.IsType(t, &ErrBlockGap{}, sdb.Apply(bstates[0]))
+
+	// block 2 of a fork, whose parent is not block 1
+	fork := NewBlockState(2, testBlockID(100), genesis)
+	err = sdb.Apply(fork)
+	if assert.IsType(t, &ErrPrevHashMismatch{}, err) {
+		assert.Equal(t, bstates[0].BlockHash, err.(*ErrPrevHashMismatch).Latest)
+		assert.Equal(t, genesis, err.(*ErrPrevHashMismatch).PrevHash)
+	}
+
+	// nothing is applied by the rejected blocks
+	assert.Equal(t, types.BlockNo(1), sdb.latest.BlockNo)
+	assert.Nil(t, sdb.Apply(bstates[1]))
+}
+
+func assertBlockStateEqual(t *testing.T, expected, actual *BlockState) {
+	assert.Equal(t, expected.BlockInfo, actual.BlockInfo)
+	assert.Equal(t, expected.root, actual.root)
+	if assert.Equal(t, len(expected.accounts), len(actual.accounts)) {
+		for aid, entry := range expected.accounts {
+			assert.Equal(t, entry.State.GetBalance(), actual.accounts[aid].State.GetBalance())
+			assert.Equal(t, entry.State.GetNonce(), actual.accounts[aid].State.GetNonce())
+			assert.Equal(t, entry.Undo == nil, actual.accounts[aid].Undo == nil)
+		}
+	}
+}
+
+func TestChainStateDB_BlockStateCache(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	assert.Nil(t, sdb.SetBlockStateCacheSize(4))
+	roots := [][]byte{append([]byte{}, sdb.GetHash()...)}
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 8, 4)
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+		roots = append(roots, append([]byte{}, sdb.GetHash()...))
+	}
+
+	// the saved block states are cached, and the cached ones are same as the ones on disk
+	for i := len(bstates) - 1; i >= 0; i-- {
+		bs := bstates[i]
+		loaded, err := sdb.loadBlockState(bs.BlockHash)
+		assert.Nil(t, err)
+		if i >= 4 {
+			assert.True(t, loaded == bs, "block state %v must be loaded from cache", bs.BlockNo)
+		}
+		again, err := sdb.loadBlockState(bs.BlockHash)
+		assert.Nil(t, err)
+		assert.True(t, loaded == again, "block state %v must be cached on loading", bs.BlockNo)
+
+		sdb.blockStates.Remove(bs.BlockHash)
+		onDisk, err := sdb.loadBlockState(bs.BlockHash)
+		assert.Nil(t, err)
+		assertBlockStateEqual(t, onDisk, loaded)
+	}
+	assert.Equal(t, 4, sdb.blockStates.Len())
+
+	// a missing block state is not cached
+	_, err := sdb.loadBlockState(testBlockID(100))
+	assert.Nil(t, err)
+	assert.False(t, sdb.blockStates.Contains(testBlockID(100)))
+
+	// successive rollbacks through the cache give the same states as without it
+	for _, blockNo := range []types.BlockNo{6, 2} {
+		assert.Nil(t, sdb.Rollback(blockNo))
+		assert.Equal(t, roots[blockNo], sdb.GetHash())
+	}
+	for _, bs := range bstates[2:] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	assert.Nil(t, sdb.SetBlockStateCacheSize(0))
+	assert.Nil(t, sdb.Rollback(2))
+	assert.Equal(t, roots[2], sdb.GetHash())
+}
+
+func TestChainStateDB_AccountCache(t *testing.T) {
+	sdb, dataDir := newTestStateDB(t)
+	defer closeTestStateDB(sdb, dataDir)
+
+	assert.Nil(t, sdb.SetAccountCacheSize(10))
+	assert.Nil(t, sdb.SetBufferSize(4))
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 3, 10)
+	assertAccounts := func() {
+		for _, bs := range bstates {
+			for aid, entry := range bs.accounts {
+				st, err := sdb.GetAccountStateClone(aid)
+				assert.Nil(t, err)
+				assert.Equal(t, entry.State.GetHash(), st.GetHash())
+			}
+		}
+	}
+
+	// the accounts of the buffered blocks are read even if evicted, since they are not written to db yet
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	assert.Equal(t, 10, sdb.accounts.Len())
+	assert.Len(t, sdb.dirty, 30)
+	assertAccounts()
+
+	// after written to db, the cold accounts are evicted and loaded from db again
+	assert.Nil(t, sdb.Flush())
+	assert.Empty(t, sdb.dirty)
+	for aid := range bstates[0].accounts {
+		assert.False(t, sdb.accounts.Contains(aid))
+	}
+	assertAccounts()
+	assert.Equal(t, 10, sdb.accounts.Len())
+	for aid := range bstates[0].accounts {
+		assert.False(t, sdb.accounts.Contains(aid))
+	}
+
+	// the state is same as without the cache
+	root := append([]byte{}, sdb.GetHash()...)
+	assert.Nil(t, sdb.SetAccountCacheSize(0))
+	assertAccounts()
+	assert.Nil(t, sdb.Rollback(1))
+	for _, bs := range bstates[1:] {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	assert.Equal(t, root, sdb.GetHash())
+}
+
+func benchmarkRollback(b *testing.B, cacheSize int) {
+	sdb, dataDir := newTestStateDB(b)
+	defer closeTestStateDB(sdb, dataDir)
+
+	bstates := newTestBlockStates(sdb.latest.BlockHash, 64, 100)
+	for _, bs := range bstates {
+		if err := sdb.Apply(bs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := sdb.SetBlockStateCacheSize(cacheSize); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		// every rollback reads the block states of the 32 blocks above the target, which are read again next time
+		if err := sdb.Rollback(32); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		for _, bs := range bstates[32:] {
+			if err := sdb.Apply(bs); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StartTimer()
+	}
+}
+
+func BenchmarkRollback(b *testing.B) {
+	benchmarkRollback(b, 0)
+}
+
+func BenchmarkRollbackCached(b *testing.B) {
+	benchmarkRollback(b, 64)
+}
+
+func TestChainStateDB_SetGenesis(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "statedb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dataDir)
+
+	alice := types.ToAccountID([]byte("alice"))
+	genesis := types.NewBlock(nil, nil, 0)
+	genesis.BlockHash()
+	other := types.NewBlock(nil, nil, 1)
+	other.BlockHash()
+
+	// first time
+	sdb := NewStateDB()
+	assert.Nil(t, sdb.Init(dataDir))
+	states := map[types.AccountID]*types.State{alice: {Balance: 100}}
+	assert.Nil(t, sdb.SetGenesisWithStates(genesis, states))
+	root := append([]byte{}, sdb.GetHash()...)
+	bstates := newTestBlockStates(types.ToBlockID(genesis.Hash), 2, 1)
+	for _, bs := range bstates {
+		assert.Nil(t, sdb.Apply(bs))
+	}
+	latest := sdb.GetHash()
+	sdb.Close()
+
+	// the same genesis is not applied again over the existing chain
+	sdb = NewStateDB()
+	assert.Nil(t, sdb.Init(dataDir))
+	assert.Nil(t, sdb.SetGenesisWithStates(genesis, map[types.AccountID]*types.State{alice: {Balance: 1}}))
+	assert.Equal(t, types.BlockNo(2), sdb.GetLatestBlockNo())
+	assert.Equal(t, latest, sdb.GetHash())
+	state, err := sdb.GetAccountStateClone(alice)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), state.Balance)
+	actual, err := sdb.GetStateRootAt(0)
+	assert.Nil(t, err)
+	assert.Equal(t, root, actual)
+
+	// another genesis is rejected
+	assert.NotNil(t, sdb.SetGenesis(other))
+	assert.Equal(t, types.BlockNo(2), sdb.GetLatestBlockNo())
+	assert.Equal(t, latest, sdb.GetHash())
+	sdb.Close()
+}
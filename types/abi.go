@@ -1,6 +1,20 @@
 package types
 
+import "github.com/mr-tron/base58/base58"
+
+// ABIBytesKey is the key of the JSON object which encodes a byte array argument, such as {"_bytes": "<base58>"}.
+// The byte array is passed to the contract as a Lua string, which may have any bytes.
+const ABIBytesKey = "_bytes"
+
 type ABI struct {
 	Name string
+	// Args are the arguments of the call. Decoded from JSON by the contract, they are of json.Number, string,
+	// bool, nil, []interface{} and map[string]interface{}, which are all passed to the contract as is, except the
+	// objects of ABIBytesKey passed as byte arrays.
 	Args []interface{}
 }
+
+// ABIBytes returns the argument of b, which is encoded in JSON as the object of ABIBytesKey.
+func ABIBytes(b []byte) map[string]interface{} {
+	return map[string]interface{}{ABIBytesKey: base58.Encode(b)}
+}
@@ -7,21 +7,200 @@ package types
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"sort"
 
 	"github.com/aergoio/aergo/internal/enc"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/golang/protobuf/proto"
 	crypto "github.com/libp2p/go-libp2p-crypto"
 	peer "github.com/libp2p/go-libp2p-peer"
 )
 
 // Genesis represents genesis block
 type Genesis struct {
-	Header    *BlockHeader      `json:"header"`
+	// ChainID is the id of the chain, which makes the genesis of a network distinct from the others
+	ChainID string       `json:"chain_id,omitempty"`
+	Header  *BlockHeader `json:"header"`
+	// Balance is the initial states of accounts, keyed by base64 encoded address
 	Balance   map[string]*State `json:"alloc"`
 	Timestamp int64             `json:"timestamp,omitempty"`
-	// TODO: bp Peer info
+	// BPs is the IDs of the initial block producers in base58. Their order decides the slots assigned to them.
+	BPs []string `json:"bps,omitempty"`
+}
+
+// AccountStates returns the initial states of accounts in genesis, keyed by account id.
+func (g *Genesis) AccountStates() (map[AccountID]*State, error) {
+	states := make(map[AccountID]*State, len(g.Balance))
+	for addr, state := range g.Balance {
+		raw, err := enc.ToBytes(addr)
+		if err != nil || len(raw) == 0 {
+			return nil, fmt.Errorf("invalid address in genesis alloc: %s", addr)
+		}
+		if state == nil {
+			return nil, fmt.Errorf("no state of address %s in genesis alloc", addr)
+		}
+		states[ToAccountID(raw)] = state
+	}
+	return states, nil
+}
+
+// UnmarshalJSON decodes genesis in json. The balance of an account in alloc is either a number or a decimal string,
+// so that a large amount is kept exact even if the json is produced by a library keeping numbers in float64.
+func (g *Genesis) UnmarshalJSON(data []byte) error {
+	type plainGenesis Genesis
+	aux := &struct {
+		*plainGenesis
+		Balance map[string]*genesisState `json:"alloc"`
+	}{plainGenesis: (*plainGenesis)(g)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	if aux.Balance == nil {
+		g.Balance = nil
+		return nil
+	}
+	g.Balance = make(map[string]*State, len(aux.Balance))
+	for addr, gs := range aux.Balance {
+		if gs == nil {
+			// reported by AccountStates
+			g.Balance[addr] = nil
+			continue
+		}
+		state := gs.State
+		if state == nil {
+			state = &State{}
+		}
+		balance, err := parseGenesisBalance(gs.Balance)
+		if err != nil {
+			return fmt.Errorf("invalid balance of %s in genesis alloc: %s", addr, err.Error())
+		}
+		state.Balance = balance
+		g.Balance[addr] = state
+	}
+	return nil
+}
+
+// genesisState is the state of an account in genesis alloc, whose balance is decoded by parseGenesisBalance.
+type genesisState struct {
+	*State
+	Balance json.RawMessage `json:"balance,omitempty"`
+}
+
+// parseGenesisBalance parses a balance in json, which is either a number or a decimal string. It fails if the
+// balance is negative, fractional, or larger than the maximum balance. The maximum is that of uint64, since
+// the balance of account state is uint64; a larger one is refused rather than truncated.
+func parseGenesisBalance(raw json.RawMessage) (uint64, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	var s string
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, err
+		}
+	} else {
+		s = string(raw)
+	}
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return 0, fmt.Errorf("%s is not a decimal integer", s)
+	}
+	if amount.Sign() < 0 {
+		return 0, fmt.Errorf("negative balance %s", s)
+	}
+	if !amount.IsUint64() {
+		return 0, fmt.Errorf("balance %s exceeds the maximum balance of account %d", s, uint64(math.MaxUint64))
+	}
+	return amount.Uint64(), nil
+}
+
+// BlockProducers returns the IDs of the initial block producers in genesis, in the order of BPs. It fails if any
+// of them is not a valid peer ID, or listed more than once.
+func (g *Genesis) BlockProducers() ([]peer.ID, error) {
+	return DecodePeerIDs("bps", g.BPs)
+}
+
+// Digest returns the hash of the chain id, initial block producers and account states in genesis, which is
+// committed to the genesis block. It is nil if genesis has none of them, so that a genesis of a seed only keeps
+// its hash.
+func (g *Genesis) Digest() ([]byte, error) {
+	if g.ChainID == "" && len(g.BPs) == 0 && len(g.Balance) == 0 {
+		return nil, nil
+	}
+	bps, err := g.BlockProducers()
+	if err != nil {
+		return nil, err
+	}
+	states, err := g.AccountStates()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]AccountID, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	digest := sha256.New()
+	binary.Write(digest, binary.LittleEndian, uint64(len(g.ChainID)))
+	digest.Write([]byte(g.ChainID))
+	binary.Write(digest, binary.LittleEndian, uint64(len(bps)))
+	for _, bp := range bps {
+		binary.Write(digest, binary.LittleEndian, uint64(len(bp)))
+		digest.Write([]byte(bp))
+	}
+	binary.Write(digest, binary.LittleEndian, uint64(len(ids)))
+	for _, id := range ids {
+		state := states[id]
+		digest.Write(id[:])
+		binary.Write(digest, binary.LittleEndian, state.Nonce)
+		binary.Write(digest, binary.LittleEndian, state.Balance)
+		for _, b := range [][]byte{state.CodeHash, state.StorageRoot} {
+			binary.Write(digest, binary.LittleEndian, uint64(len(b)))
+			digest.Write(b)
+		}
+	}
+	return digest.Sum(nil), nil
+}
+
+// Block returns the genesis block of g. The genesis block has no transaction, so its TxsRootHash holds the digest
+// of genesis instead, and the hash of the block changes with the chain id, initial block producers and account
+// states.
+func (g *Genesis) Block() (*Block, error) {
+	digest, err := g.Digest()
+	if err != nil {
+		return nil, err
+	}
+	block := NewBlock(nil, nil, g.Timestamp)
+	block.Header.TxsRootHash = digest
+	return block, nil
+}
+
+// DecodePeerIDs decodes the base58 peer IDs listed in the field name of a config or genesis. The error names the
+// first entry which is not a valid ID or is listed more than once.
+func DecodePeerIDs(name string, ids []string) ([]peer.ID, error) {
+	peerIDs := make([]peer.ID, len(ids))
+	index := make(map[peer.ID]int, len(ids))
+	for i, id := range ids {
+		peerID, err := peer.IDB58Decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d] %q: %s", name, i, id, err.Error())
+		}
+		if prev, exist := index[peerID]; exist {
+			return nil, fmt.Errorf("duplicate %s[%d] %q (same as %s[%d])", name, i, id, name, prev)
+		}
+		index[peerID] = i
+		peerIDs[i] = peerID
+	}
+	return peerIDs, nil
 }
 
 // BlockNo is the height of a block, which starts from 0 (genesis block).
@@ -87,6 +266,12 @@ func (block *Block) BlockHash() []byte {
 	return block.GetHash()
 }
 
+// SetStateRoot sets the state root of block. The hash of block is cleared, since the state root is a part of it.
+func (block *Block) SetStateRoot(root []byte) {
+	block.Header.StateRootHash = root
+	block.Hash = nil
+}
+
 // Sign adds a pubkey and a block signature to block.
 func (block *Block) Sign(privKey crypto.PrivKey) error {
 	var err error
@@ -182,6 +367,8 @@ func (block *Block) setPubKey(pubKey crypto.PubKey) error {
 		return err
 	}
 	block.Header.PubKey = pk
+	// the pubkey is a part of the block hash
+	block.Hash = nil
 
 	return nil
 }
@@ -207,15 +394,8 @@ func (header *BlockHeader) Clone() *BlockHeader {
 	if header == nil {
 		return nil
 	}
-	// XXX Very risky code. This part below must be rewritten whenever a new
-	// XXX struct member is addded. Need to rewritten in a more robust way.
-	return &BlockHeader{
-		PrevBlockHash: Clone(header.PrevBlockHash).([]byte),
-		BlockNo:       header.BlockNo,
-		Timestamp:     header.Timestamp,
-		PubKey:        Clone(header.PubKey).([]byte),
-		Sign:          Clone(header.Sign).([]byte),
-	}
+	// proto.Clone deeply copies all the fields, including the ones added later.
+	return proto.Clone(header).(*BlockHeader)
 }
 func (body *BlockBody) Clone() *BlockBody {
 	if body == nil {
@@ -236,6 +416,7 @@ func writeBlockHeader(w io.Writer, bh *BlockHeader) error {
 		bh.BlockNo,
 		bh.Timestamp,
 		bh.TxsRootHash,
+		bh.StateRootHash,
 		bh.Confirms,
 		bh.PubKey,
 	} {
@@ -265,6 +446,8 @@ func NewTx() *Tx {
 	return tx
 }
 
+// CalculateTxHash returns the hash identifying tx. It covers the whole body including the signature, so the hash of
+// a tx changes when it is signed. The signature itself is made over hashForSigning, which excludes the signature.
 func (tx *Tx) CalculateTxHash() []byte {
 	txBody := tx.Body
 	digest := sha256.New()
@@ -280,6 +463,65 @@ func (tx *Tx) CalculateTxHash() []byte {
 	return digest.Sum(nil)
 }
 
+// hashForSigning returns the hash of txBody excluding its signature, which is the preimage covered by the
+// signature. Unlike CalculateTxHash, it doesn't change when the signature is set.
+func (txBody *TxBody) hashForSigning() []byte {
+	h := sha256.New()
+	binary.Write(h, binary.LittleEndian, txBody.Nonce)
+	h.Write(txBody.Account)
+	h.Write(txBody.Recipient)
+	binary.Write(h, binary.LittleEndian, txBody.Amount)
+	binary.Write(h, binary.LittleEndian, txBody.Limit)
+	binary.Write(h, binary.LittleEndian, txBody.Price)
+	h.Write(txBody.Payload)
+	binary.Write(h, binary.LittleEndian, txBody.Type)
+	return h.Sum(nil)
+}
+
+// RecoverAccount returns the address of the account whose key made the signature of txBody.
+func (txBody *TxBody) RecoverAccount() ([]byte, error) {
+	pubkey, _, err := btcec.RecoverCompact(btcec.S256(), txBody.Sign, txBody.hashForSigning())
+	if err != nil {
+		return nil, err
+	}
+	return AddressFromPubKey(pubkey.ToECDSA()), nil
+}
+
+// Sign adds to tx the signature made by privKey. The signature is made over the hash of the body excluding the
+// signature itself, and then the hash of tx, which includes the signature, is updated.
+func (tx *Tx) Sign(privKey *btcec.PrivateKey) error {
+	if tx.Body == nil {
+		return fmt.Errorf("tx has no body to sign")
+	}
+	sign, err := btcec.SignCompact(btcec.S256(), privKey, tx.Body.hashForSigning(), true)
+	if err != nil {
+		return err
+	}
+	tx.Body.Sign = sign
+	tx.Hash = tx.CalculateTxHash()
+	return nil
+}
+
+// VerifySign verifies that the signature of tx is made by the key of its account.
+func (tx *Tx) VerifySign() (valid bool, err error) {
+	if tx.Body == nil {
+		return false, fmt.Errorf("tx has no body to verify")
+	}
+	var account []byte
+	if account, err = tx.Body.RecoverAccount(); err != nil {
+		return false, err
+	}
+	return bytes.Equal(account, tx.Body.Account), nil
+}
+
+// AddressFromPubKey returns the account address of pubkey.
+func AddressFromPubKey(pubkey *ecdsa.PublicKey) []byte {
+	addr := new(bytes.Buffer)
+	binary.Write(addr, binary.LittleEndian, pubkey.X.Bytes())
+	binary.Write(addr, binary.LittleEndian, pubkey.Y.Bytes())
+	return addr.Bytes()[:20] //TODO: ADDRESSLENGTH ?
+}
+
 func (tx *Tx) Clone() *Tx {
 	if tx == nil {
 		return nil
@@ -301,6 +543,6 @@ func (tx *Tx) Clone() *Tx {
 	res := &Tx{
 		Body: body,
 	}
-	res.Hash = tx.CalculateTxHash()
+	res.Hash = res.CalculateTxHash()
 	return res
 }
@@ -38,7 +38,7 @@ func (x TxType) String() string {
 	return proto.EnumName(TxType_name, int32(x))
 }
 func (TxType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{0}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{0}
 }
 
 type Block struct {
@@ -54,7 +54,7 @@ func (m *Block) Reset()         { *m = Block{} }
 func (m *Block) String() string { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()    {}
 func (*Block) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{0}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{0}
 }
 func (m *Block) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Block.Unmarshal(m, b)
@@ -101,6 +101,7 @@ type BlockHeader struct {
 	Timestamp            int64    `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	BlocksRootHash       []byte   `protobuf:"bytes,4,opt,name=blocksRootHash,proto3" json:"blocksRootHash,omitempty"`
 	TxsRootHash          []byte   `protobuf:"bytes,5,opt,name=txsRootHash,proto3" json:"txsRootHash,omitempty"`
+	StateRootHash        []byte   `protobuf:"bytes,9,opt,name=stateRootHash,proto3" json:"stateRootHash,omitempty"`
 	Confirms             uint64   `protobuf:"varint,6,opt,name=confirms,proto3" json:"confirms,omitempty"`
 	PubKey               []byte   `protobuf:"bytes,7,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
 	Sign                 []byte   `protobuf:"bytes,8,opt,name=sign,proto3" json:"sign,omitempty"`
@@ -113,7 +114,7 @@ func (m *BlockHeader) Reset()         { *m = BlockHeader{} }
 func (m *BlockHeader) String() string { return proto.CompactTextString(m) }
 func (*BlockHeader) ProtoMessage()    {}
 func (*BlockHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{1}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{1}
 }
 func (m *BlockHeader) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockHeader.Unmarshal(m, b)
@@ -168,6 +169,13 @@ func (m *BlockHeader) GetTxsRootHash() []byte {
 	return nil
 }
 
+func (m *BlockHeader) GetStateRootHash() []byte {
+	if m != nil {
+		return m.StateRootHash
+	}
+	return nil
+}
+
 func (m *BlockHeader) GetConfirms() uint64 {
 	if m != nil {
 		return m.Confirms
@@ -200,7 +208,7 @@ func (m *BlockBody) Reset()         { *m = BlockBody{} }
 func (m *BlockBody) String() string { return proto.CompactTextString(m) }
 func (*BlockBody) ProtoMessage()    {}
 func (*BlockBody) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{2}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{2}
 }
 func (m *BlockBody) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockBody.Unmarshal(m, b)
@@ -238,7 +246,7 @@ func (m *TxList) Reset()         { *m = TxList{} }
 func (m *TxList) String() string { return proto.CompactTextString(m) }
 func (*TxList) ProtoMessage()    {}
 func (*TxList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{3}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{3}
 }
 func (m *TxList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TxList.Unmarshal(m, b)
@@ -277,7 +285,7 @@ func (m *Tx) Reset()         { *m = Tx{} }
 func (m *Tx) String() string { return proto.CompactTextString(m) }
 func (*Tx) ProtoMessage()    {}
 func (*Tx) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{4}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{4}
 }
 func (m *Tx) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Tx.Unmarshal(m, b)
@@ -330,7 +338,7 @@ func (m *TxBody) Reset()         { *m = TxBody{} }
 func (m *TxBody) String() string { return proto.CompactTextString(m) }
 func (*TxBody) ProtoMessage()    {}
 func (*TxBody) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{5}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{5}
 }
 func (m *TxBody) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TxBody.Unmarshal(m, b)
@@ -425,7 +433,7 @@ func (m *TxIdx) Reset()         { *m = TxIdx{} }
 func (m *TxIdx) String() string { return proto.CompactTextString(m) }
 func (*TxIdx) ProtoMessage()    {}
 func (*TxIdx) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{6}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{6}
 }
 func (m *TxIdx) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TxIdx.Unmarshal(m, b)
@@ -471,7 +479,7 @@ func (m *TxInBlock) Reset()         { *m = TxInBlock{} }
 func (m *TxInBlock) String() string { return proto.CompactTextString(m) }
 func (*TxInBlock) ProtoMessage()    {}
 func (*TxInBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{7}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{7}
 }
 func (m *TxInBlock) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TxInBlock.Unmarshal(m, b)
@@ -519,7 +527,7 @@ func (m *State) Reset()         { *m = State{} }
 func (m *State) String() string { return proto.CompactTextString(m) }
 func (*State) ProtoMessage()    {}
 func (*State) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{8}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{8}
 }
 func (m *State) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_State.Unmarshal(m, b)
@@ -571,6 +579,8 @@ type Receipt struct {
 	ContractAddress      []byte   `protobuf:"bytes,1,opt,name=contractAddress,proto3" json:"contractAddress,omitempty"`
 	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
 	Ret                  string   `protobuf:"bytes,3,opt,name=ret,proto3" json:"ret,omitempty"`
+	GasUsed              uint64   `protobuf:"varint,4,opt,name=gasUsed,proto3" json:"gasUsed,omitempty"`
+	Events               []*Event `protobuf:"bytes,5,rep,name=events,proto3" json:"events,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -580,7 +590,7 @@ func (m *Receipt) Reset()         { *m = Receipt{} }
 func (m *Receipt) String() string { return proto.CompactTextString(m) }
 func (*Receipt) ProtoMessage()    {}
 func (*Receipt) Descriptor() ([]byte, []int) {
-	return fileDescriptor_blockchain_93630d4dcaaaa6a7, []int{9}
+	return fileDescriptor_blockchain_be3895391f9da275, []int{9}
 }
 func (m *Receipt) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Receipt.Unmarshal(m, b)
@@ -621,6 +631,216 @@ func (m *Receipt) GetRet() string {
 	return ""
 }
 
+func (m *Receipt) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *Receipt) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// Event is emitted by a contract call. Its location in chain is filled when it is queried.
+type Event struct {
+	ContractAddress      []byte   `protobuf:"bytes,1,opt,name=contractAddress,proto3" json:"contractAddress,omitempty"`
+	EventName            string   `protobuf:"bytes,2,opt,name=eventName,proto3" json:"eventName,omitempty"`
+	JsonArgs             string   `protobuf:"bytes,3,opt,name=jsonArgs,proto3" json:"jsonArgs,omitempty"`
+	EventIdx             int32    `protobuf:"varint,4,opt,name=eventIdx,proto3" json:"eventIdx,omitempty"`
+	TxHash               []byte   `protobuf:"bytes,5,opt,name=txHash,proto3" json:"txHash,omitempty"`
+	BlockHash            []byte   `protobuf:"bytes,6,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	BlockNo              uint64   `protobuf:"varint,7,opt,name=blockNo,proto3" json:"blockNo,omitempty"`
+	TxIndex              int32    `protobuf:"varint,8,opt,name=txIndex,proto3" json:"txIndex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blockchain_be3895391f9da275, []int{10}
+}
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (dst *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(dst, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *Event) GetEventName() string {
+	if m != nil {
+		return m.EventName
+	}
+	return ""
+}
+
+func (m *Event) GetJsonArgs() string {
+	if m != nil {
+		return m.JsonArgs
+	}
+	return ""
+}
+
+func (m *Event) GetEventIdx() int32 {
+	if m != nil {
+		return m.EventIdx
+	}
+	return 0
+}
+
+func (m *Event) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Event) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *Event) GetBlockNo() uint64 {
+	if m != nil {
+		return m.BlockNo
+	}
+	return 0
+}
+
+func (m *Event) GetTxIndex() int32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+type EventList struct {
+	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventList) Reset()         { *m = EventList{} }
+func (m *EventList) String() string { return proto.CompactTextString(m) }
+func (*EventList) ProtoMessage()    {}
+func (*EventList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blockchain_be3895391f9da275, []int{11}
+}
+func (m *EventList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventList.Unmarshal(m, b)
+}
+func (m *EventList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventList.Marshal(b, m, deterministic)
+}
+func (dst *EventList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventList.Merge(dst, src)
+}
+func (m *EventList) XXX_Size() int {
+	return xxx_messageInfo_EventList.Size(m)
+}
+func (m *EventList) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventList proto.InternalMessageInfo
+
+func (m *EventList) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// FilterInfo selects the events of contract, optionally with eventName, emitted in the blocks from blockfrom to blockto.
+type FilterInfo struct {
+	ContractAddress      []byte   `protobuf:"bytes,1,opt,name=contractAddress,proto3" json:"contractAddress,omitempty"`
+	EventName            string   `protobuf:"bytes,2,opt,name=eventName,proto3" json:"eventName,omitempty"`
+	Blockfrom            uint64   `protobuf:"varint,3,opt,name=blockfrom,proto3" json:"blockfrom,omitempty"`
+	Blockto              uint64   `protobuf:"varint,4,opt,name=blockto,proto3" json:"blockto,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FilterInfo) Reset()         { *m = FilterInfo{} }
+func (m *FilterInfo) String() string { return proto.CompactTextString(m) }
+func (*FilterInfo) ProtoMessage()    {}
+func (*FilterInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blockchain_be3895391f9da275, []int{12}
+}
+func (m *FilterInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FilterInfo.Unmarshal(m, b)
+}
+func (m *FilterInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FilterInfo.Marshal(b, m, deterministic)
+}
+func (dst *FilterInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FilterInfo.Merge(dst, src)
+}
+func (m *FilterInfo) XXX_Size() int {
+	return xxx_messageInfo_FilterInfo.Size(m)
+}
+func (m *FilterInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_FilterInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FilterInfo proto.InternalMessageInfo
+
+func (m *FilterInfo) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *FilterInfo) GetEventName() string {
+	if m != nil {
+		return m.EventName
+	}
+	return ""
+}
+
+func (m *FilterInfo) GetBlockfrom() uint64 {
+	if m != nil {
+		return m.Blockfrom
+	}
+	return 0
+}
+
+func (m *FilterInfo) GetBlockto() uint64 {
+	if m != nil {
+		return m.Blockto
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Block)(nil), "types.Block")
 	proto.RegisterType((*BlockHeader)(nil), "types.BlockHeader")
@@ -632,50 +852,62 @@ func init() {
 	proto.RegisterType((*TxInBlock)(nil), "types.TxInBlock")
 	proto.RegisterType((*State)(nil), "types.State")
 	proto.RegisterType((*Receipt)(nil), "types.Receipt")
+	proto.RegisterType((*Event)(nil), "types.Event")
+	proto.RegisterType((*EventList)(nil), "types.EventList")
+	proto.RegisterType((*FilterInfo)(nil), "types.FilterInfo")
 	proto.RegisterEnum("types.TxType", TxType_name, TxType_value)
 }
 
-func init() { proto.RegisterFile("blockchain.proto", fileDescriptor_blockchain_93630d4dcaaaa6a7) }
-
-var fileDescriptor_blockchain_93630d4dcaaaa6a7 = []byte{
-	// 609 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xc5, 0x89, 0x9d, 0xd6, 0x93, 0xb4, 0x44, 0x2b, 0x84, 0xcc, 0x87, 0x50, 0xb0, 0x0a, 0x8a,
-	0x7a, 0x48, 0xa5, 0x72, 0xe0, 0xc0, 0xa9, 0x45, 0x15, 0x14, 0x4a, 0x2a, 0x2d, 0x11, 0x07, 0x24,
-	0x0e, 0x6b, 0x7b, 0x49, 0x56, 0xc4, 0x5e, 0xcb, 0xbb, 0x41, 0xce, 0x6f, 0xe6, 0x37, 0x20, 0xa1,
-	0x99, 0x75, 0x12, 0x13, 0x15, 0x4e, 0xd9, 0xf7, 0xe6, 0xfb, 0xcd, 0xc4, 0x30, 0x4c, 0x96, 0x3a,
-	0xfd, 0x91, 0x2e, 0x84, 0x2a, 0x26, 0x65, 0xa5, 0xad, 0x66, 0x81, 0x5d, 0x97, 0xd2, 0xc4, 0x39,
-	0x04, 0x97, 0x68, 0x62, 0x0c, 0xfc, 0x85, 0x30, 0x8b, 0xc8, 0x1b, 0x79, 0xe3, 0x01, 0xa7, 0x37,
-	0x3b, 0x85, 0xde, 0x42, 0x8a, 0x4c, 0x56, 0x51, 0x67, 0xe4, 0x8d, 0xfb, 0xe7, 0x6c, 0x42, 0x41,
-	0x13, 0x8a, 0x78, 0x4f, 0x16, 0xde, 0x78, 0xb0, 0x13, 0xf0, 0x13, 0x9d, 0xad, 0xa3, 0x2e, 0x79,
-	0x0e, 0xdb, 0x9e, 0x97, 0x3a, 0x5b, 0x73, 0xb2, 0xc6, 0xbf, 0x3d, 0xe8, 0xb7, 0xa2, 0xd9, 0x09,
-	0x1c, 0x95, 0x95, 0xfc, 0xe9, 0xa8, 0x5d, 0xf9, 0xbf, 0x49, 0x16, 0xc1, 0x01, 0xf5, 0x3f, 0xd5,
-	0xd4, 0x88, 0xcf, 0x37, 0x90, 0x3d, 0x85, 0xd0, 0xaa, 0x5c, 0x1a, 0x2b, 0xf2, 0x92, 0x4a, 0x77,
-	0xf9, 0x8e, 0x60, 0x2f, 0xe1, 0x98, 0x1c, 0x0d, 0xd7, 0xda, 0x52, 0x7a, 0x9f, 0xd2, 0xef, 0xb1,
-	0x6c, 0x04, 0x7d, 0x5b, 0xef, 0x9c, 0x02, 0x72, 0x6a, 0x53, 0xec, 0x31, 0x1c, 0xa6, 0xba, 0xf8,
-	0xae, 0xaa, 0xdc, 0x44, 0x3d, 0x6a, 0x61, 0x8b, 0xd9, 0x43, 0xe8, 0x95, 0xab, 0xe4, 0xa3, 0x5c,
-	0x47, 0x07, 0x14, 0xd8, 0x20, 0x54, 0xd4, 0xa8, 0x79, 0x11, 0x1d, 0x3a, 0x45, 0xf1, 0x1d, 0x8f,
-	0x21, 0xdc, 0x4a, 0xc2, 0x9e, 0x40, 0xd7, 0xd6, 0x26, 0xf2, 0x46, 0xdd, 0x71, 0xff, 0x3c, 0x6c,
-	0x14, 0x9b, 0xd5, 0x1c, 0xd9, 0xf8, 0x05, 0xf4, 0x66, 0xf5, 0x8d, 0x32, 0xf6, 0xff, 0x6e, 0x6f,
-	0xa0, 0x33, 0xab, 0xef, 0x5c, 0xde, 0xf3, 0x66, 0x21, 0x6e, 0x75, 0x47, 0xdb, 0xb8, 0xd6, 0x36,
-	0x7e, 0x79, 0x58, 0x84, 0x7a, 0x79, 0x00, 0x41, 0xa1, 0x8b, 0x54, 0x52, 0x0a, 0x9f, 0x3b, 0x80,
-	0xc2, 0x8b, 0x34, 0xd5, 0xab, 0xc2, 0x52, 0x9a, 0x01, 0xdf, 0x40, 0x14, 0xbe, 0x92, 0xa9, 0x2a,
-	0x95, 0x2c, 0x2c, 0x09, 0x3f, 0xe0, 0x3b, 0x02, 0x25, 0x11, 0x39, 0x85, 0xf9, 0x94, 0xae, 0x41,
-	0x98, 0xaf, 0x14, 0xeb, 0xa5, 0x16, 0x59, 0x23, 0xf2, 0x06, 0x62, 0xfd, 0xa5, 0xca, 0x95, 0x6d,
-	0xd4, 0x75, 0x00, 0xd9, 0xb2, 0x52, 0xa9, 0x24, 0x65, 0x7d, 0xee, 0xc0, 0x5d, 0xc2, 0xe2, 0xb4,
-	0x38, 0x60, 0x14, 0x8e, 0xbc, 0xf1, 0x71, 0x6b, 0xda, 0xd9, 0xba, 0x94, 0x9c, 0x4c, 0xf1, 0x6b,
-	0x08, 0x66, 0xf5, 0x75, 0x56, 0x63, 0xef, 0xc9, 0xde, 0xc1, 0xed, 0x08, 0x36, 0x84, 0xae, 0xca,
-	0x6a, 0x9a, 0x37, 0xe0, 0xf8, 0x8c, 0x3f, 0x40, 0x38, 0xab, 0xaf, 0x0b, 0xf7, 0x3f, 0x89, 0x21,
-	0xb0, 0x98, 0x85, 0x02, 0xfb, 0xe7, 0x83, 0x6d, 0xa5, 0xeb, 0xac, 0xe6, 0xce, 0xc4, 0x1e, 0x41,
-	0xc7, 0xd6, 0x8d, 0xf0, 0xad, 0x85, 0x75, 0x6c, 0x1d, 0xaf, 0x20, 0xf8, 0x6c, 0x85, 0x95, 0xff,
-	0x16, 0x3c, 0x11, 0x4b, 0x81, 0xfc, 0xe6, 0xd2, 0x1d, 0x74, 0x17, 0x98, 0x49, 0xea, 0xd9, 0xe9,
-	0xbd, 0xc5, 0x78, 0xbf, 0xc6, 0xea, 0x4a, 0xcc, 0x25, 0x1e, 0x6c, 0x73, 0xe4, 0x6d, 0x2a, 0xfe,
-	0x06, 0x07, 0x5c, 0xa6, 0x52, 0x95, 0x96, 0x8d, 0xe1, 0x7e, 0xaa, 0x0b, 0x5b, 0x89, 0xd4, 0x5e,
-	0x64, 0x59, 0x25, 0x8d, 0x69, 0x34, 0xd8, 0xa7, 0x71, 0x8b, 0xc6, 0x0a, 0xbb, 0x32, 0xd4, 0x4b,
-	0xc8, 0x1b, 0x84, 0x0a, 0x55, 0xd2, 0x6d, 0x3d, 0xe4, 0xf8, 0x3c, 0x3d, 0xc1, 0x3b, 0x42, 0xa9,
-	0x19, 0x40, 0x6f, 0x7a, 0xcb, 0x3f, 0x5d, 0xdc, 0x0c, 0xef, 0xb1, 0x63, 0x80, 0x77, 0xb7, 0x5f,
-	0xae, 0xf8, 0xf4, 0x62, 0xfa, 0xf6, 0x6a, 0xe8, 0x5d, 0x8e, 0xbe, 0x3e, 0x9b, 0x2b, 0xbb, 0x58,
-	0x25, 0x93, 0x54, 0xe7, 0x67, 0x42, 0x56, 0x73, 0xad, 0xb4, 0xfb, 0x3d, 0x23, 0x91, 0x92, 0x1e,
-	0x7d, 0x9b, 0x5e, 0xfd, 0x09, 0x00, 0x00, 0xff, 0xff, 0xe1, 0x0b, 0x80, 0x81, 0xaf, 0x04, 0x00,
-	0x00,
+func init() { proto.RegisterFile("blockchain.proto", fileDescriptor_blockchain_be3895391f9da275) }
+
+var fileDescriptor_blockchain_be3895391f9da275 = []byte{
+	// 768 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xcf, 0x8e, 0xfb, 0x34,
+	0x10, 0x26, 0x6d, 0x92, 0xfe, 0x32, 0xed, 0x2e, 0x95, 0x85, 0x50, 0x80, 0x15, 0x2a, 0x51, 0x41,
+	0xd5, 0x1e, 0xba, 0x62, 0x39, 0x70, 0xe0, 0xd4, 0x45, 0x0b, 0x14, 0x96, 0xae, 0x64, 0x0a, 0x07,
+	0x6e, 0x6e, 0xe2, 0x6d, 0x03, 0x4d, 0x1c, 0xc5, 0xee, 0x2a, 0x7d, 0x07, 0x5e, 0x81, 0x13, 0x8f,
+	0xc7, 0x89, 0x37, 0x40, 0x1e, 0x3b, 0x7f, 0x5a, 0x2d, 0x2b, 0x21, 0xfd, 0x4e, 0xf5, 0xf7, 0x79,
+	0xc6, 0x9e, 0xf9, 0xbe, 0x71, 0x0a, 0xe3, 0xcd, 0x5e, 0xc4, 0xbf, 0xc7, 0x3b, 0x96, 0xe6, 0xf3,
+	0xa2, 0x14, 0x4a, 0x10, 0x4f, 0x1d, 0x0b, 0x2e, 0xa3, 0x0c, 0xbc, 0x3b, 0xbd, 0x45, 0x08, 0xb8,
+	0x3b, 0x26, 0x77, 0xa1, 0x33, 0x71, 0x66, 0x23, 0x8a, 0x6b, 0x72, 0x0d, 0xfe, 0x8e, 0xb3, 0x84,
+	0x97, 0x61, 0x6f, 0xe2, 0xcc, 0x86, 0xb7, 0x64, 0x8e, 0x49, 0x73, 0xcc, 0xf8, 0x0e, 0x77, 0xa8,
+	0x8d, 0x20, 0x53, 0x70, 0x37, 0x22, 0x39, 0x86, 0x7d, 0x8c, 0x1c, 0x77, 0x23, 0xef, 0x44, 0x72,
+	0xa4, 0xb8, 0x1b, 0xfd, 0xd5, 0x83, 0x61, 0x27, 0x9b, 0x4c, 0xe1, 0xa2, 0x28, 0xf9, 0xb3, 0xa1,
+	0xda, 0xeb, 0x4f, 0x49, 0x12, 0xc2, 0x00, 0xeb, 0x5f, 0x09, 0x2c, 0xc4, 0xa5, 0x35, 0x24, 0x57,
+	0x10, 0xa8, 0x34, 0xe3, 0x52, 0xb1, 0xac, 0xc0, 0xab, 0xfb, 0xb4, 0x25, 0xc8, 0x67, 0x70, 0x89,
+	0x81, 0x92, 0x0a, 0xa1, 0xf0, 0x78, 0x17, 0x8f, 0x3f, 0x63, 0xc9, 0x04, 0x86, 0xaa, 0x6a, 0x83,
+	0x3c, 0x0c, 0xea, 0x52, 0xba, 0x4e, 0xa9, 0x98, 0xe2, 0x4d, 0x4c, 0x60, 0xea, 0x3c, 0x21, 0xc9,
+	0x87, 0xf0, 0x26, 0x16, 0xf9, 0x53, 0x5a, 0x66, 0x32, 0xf4, 0xb1, 0xd0, 0x06, 0x93, 0xf7, 0xc1,
+	0x2f, 0x0e, 0x9b, 0x1f, 0xf8, 0x31, 0x1c, 0x60, 0xaa, 0x45, 0x5a, 0x77, 0x99, 0x6e, 0xf3, 0xf0,
+	0x8d, 0xd1, 0x5d, 0xaf, 0xa3, 0x19, 0x04, 0x8d, 0x70, 0xe4, 0x23, 0xe8, 0xab, 0x4a, 0x86, 0xce,
+	0xa4, 0x3f, 0x1b, 0xde, 0x06, 0x56, 0xd7, 0x75, 0x45, 0x35, 0x1b, 0x7d, 0x0a, 0xfe, 0xba, 0x7a,
+	0x48, 0xa5, 0x7a, 0x3d, 0xec, 0x2b, 0xe8, 0xad, 0xab, 0x17, 0x2d, 0xfe, 0xc4, 0xda, 0x66, 0x0c,
+	0xbe, 0x68, 0xf2, 0x3a, 0x9e, 0xfd, 0xed, 0x80, 0x6f, 0x08, 0xf2, 0x1e, 0x78, 0xb9, 0xc8, 0x63,
+	0x8e, 0x47, 0xb8, 0xd4, 0x00, 0x6d, 0x0f, 0x8b, 0x63, 0x71, 0xc8, 0x15, 0x1e, 0x33, 0xa2, 0x35,
+	0xd4, 0xf6, 0x94, 0x3c, 0x4e, 0x8b, 0x94, 0xe7, 0x0a, 0xed, 0x19, 0xd1, 0x96, 0xd0, 0x92, 0xb0,
+	0x0c, 0xd3, 0x5c, 0x3c, 0xce, 0x22, 0x7d, 0x5e, 0xc1, 0x8e, 0x7b, 0xc1, 0x12, 0x6b, 0x45, 0x0d,
+	0xf5, 0xfd, 0xfb, 0x34, 0x4b, 0x95, 0x55, 0xd7, 0x00, 0xcd, 0x16, 0x65, 0x1a, 0x73, 0x54, 0xd6,
+	0xa5, 0x06, 0xbc, 0x24, 0xac, 0xee, 0x56, 0x37, 0x88, 0xee, 0x5d, 0x76, 0xba, 0x5d, 0x1f, 0x0b,
+	0x4e, 0x71, 0x2b, 0xfa, 0x12, 0xbc, 0x75, 0xb5, 0x4c, 0x2a, 0x5d, 0xfb, 0xe6, 0x6c, 0x2c, 0x5b,
+	0x82, 0x8c, 0xa1, 0x9f, 0x26, 0x15, 0xf6, 0xeb, 0x51, 0xbd, 0x8c, 0xbe, 0x87, 0x60, 0x5d, 0x2d,
+	0x73, 0xf3, 0x9a, 0x22, 0xf0, 0x94, 0x3e, 0x05, 0x13, 0x87, 0xb7, 0xa3, 0xe6, 0xa6, 0x65, 0x52,
+	0x51, 0xb3, 0x45, 0x3e, 0x80, 0x9e, 0xaa, 0xac, 0xf0, 0x1d, 0xc3, 0x7a, 0xaa, 0x8a, 0x0e, 0xe0,
+	0xfd, 0xa4, 0x27, 0xeb, 0xbf, 0x05, 0xdf, 0xb0, 0x3d, 0xd3, 0x7c, 0xfd, 0x1e, 0x0c, 0x34, 0x13,
+	0x98, 0x70, 0xac, 0xd9, 0xe8, 0xdd, 0x60, 0x3d, 0xe5, 0x52, 0x89, 0x92, 0x6d, 0x71, 0x60, 0xed,
+	0x53, 0xe8, 0x52, 0xd1, 0x9f, 0x0e, 0x0c, 0x28, 0x8f, 0x79, 0x5a, 0x28, 0x32, 0x83, 0x77, 0x63,
+	0x91, 0xab, 0x92, 0xc5, 0x6a, 0x91, 0x24, 0x25, 0x97, 0xd2, 0x8a, 0x70, 0x4e, 0x6b, 0x1b, 0xf5,
+	0x33, 0x38, 0x48, 0x2c, 0x26, 0xa0, 0x16, 0x69, 0x89, 0x4a, 0x6e, 0x6c, 0x0f, 0xa8, 0x5e, 0xea,
+	0xba, 0xb7, 0x4c, 0xfe, 0x2c, 0x79, 0x62, 0x1d, 0xaf, 0x21, 0x99, 0x82, 0xcf, 0x9f, 0x79, 0xae,
+	0x64, 0xe8, 0x4d, 0xfa, 0x1d, 0xc1, 0xee, 0x35, 0x49, 0xed, 0x5e, 0xf4, 0x8f, 0x03, 0x1e, 0x32,
+	0xff, 0xa3, 0xba, 0x2b, 0x08, 0x30, 0x7b, 0xc5, 0x32, 0x6e, 0x0b, 0x6c, 0x09, 0xad, 0xd7, 0x6f,
+	0x52, 0xe4, 0x8b, 0x72, 0x2b, 0x6d, 0xa1, 0x0d, 0xd6, 0x7b, 0x18, 0xa8, 0x6d, 0x74, 0xd1, 0xe7,
+	0x06, 0xeb, 0x9e, 0x55, 0xd5, 0xf9, 0x58, 0x58, 0x74, 0x3a, 0x34, 0xfe, 0xf9, 0xd0, 0x74, 0xbe,
+	0x63, 0x83, 0xd3, 0xef, 0x58, 0x08, 0x03, 0x55, 0x2d, 0xf3, 0x84, 0x57, 0x38, 0xaf, 0x1e, 0xad,
+	0x61, 0xf4, 0x39, 0x04, 0xd8, 0x32, 0x3e, 0xf2, 0x56, 0x26, 0xe7, 0x15, 0x99, 0xfe, 0x70, 0x00,
+	0xbe, 0x49, 0xf7, 0x8a, 0x97, 0xcb, 0xfc, 0x49, 0xbc, 0x35, 0xad, 0xea, 0xde, 0x9e, 0x4a, 0x91,
+	0xa1, 0x58, 0x2e, 0x6d, 0x89, 0xa6, 0x37, 0x25, 0x6a, 0x6f, 0x2d, 0xbc, 0x9e, 0x82, 0x6f, 0x5e,
+	0x18, 0x01, 0xf0, 0x57, 0x8f, 0xf4, 0xc7, 0xc5, 0xc3, 0xf8, 0x1d, 0x72, 0x09, 0xf0, 0xed, 0xe3,
+	0x2f, 0xf7, 0x74, 0xb5, 0x58, 0x7d, 0x7d, 0x3f, 0x76, 0xee, 0x26, 0xbf, 0x7e, 0xbc, 0x4d, 0xd5,
+	0xee, 0xb0, 0x99, 0xc7, 0x22, 0xbb, 0x61, 0xbc, 0xdc, 0x8a, 0x54, 0x98, 0xdf, 0x1b, 0x6c, 0x72,
+	0xe3, 0xe3, 0x1f, 0xd7, 0x17, 0xff, 0x0e, 0x00, 0x4d, 0x45, 0xfe, 0x1f, 0xcc, 0x06, 0x00, 0x00,
 }
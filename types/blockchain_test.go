@@ -3,8 +3,17 @@ package types
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/aergoio/aergo/internal/enc"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/golang/protobuf/proto"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/libp2p/go-libp2p-peer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +37,211 @@ func TestBlockHashOldNew(t *testing.T) {
 
 	assert.Equal(t, h1, h2)
 }
+
+func TestTxSignVerify(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	account := AddressFromPubKey(&privKey.PublicKey)
+
+	tx := &Tx{Body: &TxBody{Nonce: 1, Account: account, Recipient: []byte("recipient"), Amount: 10}}
+	digest := tx.Body.hashForSigning()
+	assert.Nil(t, tx.Sign(privKey))
+	assert.NotEmpty(t, tx.Body.Sign)
+	// the signed digest excludes the signature, while the tx hash includes it
+	assert.Equal(t, digest, tx.Body.hashForSigning())
+	assert.Equal(t, tx.CalculateTxHash(), tx.Hash)
+
+	valid, err := tx.VerifySign()
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	// re-signing gives the same hash
+	hash := tx.Hash
+	assert.Nil(t, tx.Sign(privKey))
+	assert.Equal(t, hash, tx.Hash)
+
+	// tampered body
+	tx.Body.Amount = 20
+	valid, err = tx.VerifySign()
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// signed by the key of other account
+	otherKey, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Sign(otherKey))
+	valid, err = tx.VerifySign()
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// unsigned
+	tx.Body.Sign = nil
+	_, err = tx.VerifySign()
+	assert.NotNil(t, err)
+}
+
+func TestTxHashForSigning(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+
+	tx := &Tx{Body: &TxBody{Nonce: 1, Account: AddressFromPubKey(&privKey.PublicKey), Payload: []byte("payload")}}
+	preimage := tx.Body.hashForSigning()
+	unsignedHash := tx.CalculateTxHash()
+
+	assert.Nil(t, tx.Sign(privKey))
+	// the signing preimage is stable, while the identity hash covers the signature
+	assert.Equal(t, preimage, tx.Body.hashForSigning())
+	assert.NotEqual(t, unsignedHash, tx.Hash)
+	assert.Equal(t, tx.CalculateTxHash(), tx.Hash)
+
+	// a clone has the same identity
+	assert.Equal(t, tx.Hash, tx.Clone().Hash)
+	// other fields than the signature are covered by both
+	tx.Body.Nonce = 2
+	assert.NotEqual(t, preimage, tx.Body.hashForSigning())
+	assert.NotEqual(t, tx.Hash, tx.CalculateTxHash())
+}
+
+func TestBlockHeaderClone(t *testing.T) {
+	header := &BlockHeader{
+		PrevBlockHash:  []byte("prevBlockHash"),
+		BlockNo:        1,
+		Timestamp:      2,
+		BlocksRootHash: []byte("blocksRootHash"),
+		TxsRootHash:    []byte("txsRootHash"),
+		StateRootHash:  []byte("stateRootHash"),
+		Confirms:       3,
+		PubKey:         []byte("pubKey"),
+		Sign:           []byte("sign"),
+	}
+	// all the fields must be populated, so that a field added later is not missed
+	v := reflect.ValueOf(header).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if name := v.Type().Field(i).Name; !strings.HasPrefix(name, "XXX_") {
+			assert.False(t, reflect.DeepEqual(v.Field(i).Interface(), reflect.Zero(v.Field(i).Type()).Interface()),
+				"field %s of header is not populated", name)
+		}
+	}
+
+	clone := header.Clone()
+	assert.Equal(t, header, clone)
+	assert.True(t, proto.Equal(header, clone))
+
+	// the clone doesn't share memory with the original
+	header.StateRootHash[0] = 'x'
+	assert.Equal(t, []byte("stateRootHash"), clone.StateRootHash)
+
+	var nilHeader *BlockHeader
+	assert.Nil(t, nilHeader.Clone())
+}
+
+func TestBlockSetStateRoot(t *testing.T) {
+	block := NewBlock(nil, nil, 1)
+	hash := block.BlockHash()
+
+	// the cached hash is updated by the state root
+	block.SetStateRoot([]byte("stateRootHash"))
+	assert.NotEqual(t, hash, block.BlockHash())
+	assert.Equal(t, block.calculateBlockHash(), block.BlockHash())
+}
+
+func TestGenesisBlockProducers(t *testing.T) {
+	ids := make([]peer.ID, 3)
+	for i := range ids {
+		_, pubKey, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+		assert.Nil(t, err)
+		ids[i], err = peer.IDFromPublicKey(pubKey)
+		assert.Nil(t, err)
+	}
+	raw := `{"timestamp":1,"bps":["` + ids[2].Pretty() + `","` + ids[0].Pretty() + `","` + ids[1].Pretty() + `"]}`
+
+	genesis := new(Genesis)
+	assert.Nil(t, json.Unmarshal([]byte(raw), genesis))
+	bps, err := genesis.BlockProducers()
+	assert.Nil(t, err)
+	// the order in genesis is kept
+	assert.Equal(t, []peer.ID{ids[2], ids[0], ids[1]}, bps)
+
+	bps, err = (&Genesis{}).BlockProducers()
+	assert.Nil(t, err)
+	assert.Empty(t, bps)
+
+	for _, malformed := range [][]string{
+		{ids[0].Pretty(), "not-a-peer-id"},
+		{ids[0].Pretty(), ""},
+		{ids[0].Pretty(), ids[1].Pretty(), ids[0].Pretty()},
+	} {
+		_, err := (&Genesis{BPs: malformed}).BlockProducers()
+		assert.NotNil(t, err, "%v", malformed)
+	}
+}
+
+func TestGenesisBlock(t *testing.T) {
+	ids := make([]string, 2)
+	for i := range ids {
+		_, pubKey, err := crypto.GenerateKeyPair(crypto.Secp256k1, 256)
+		assert.Nil(t, err)
+		id, err := peer.IDFromPublicKey(pubKey)
+		assert.Nil(t, err)
+		ids[i] = id.Pretty()
+	}
+	alice := enc.ToString([]byte("alice"))
+	genesisHash := func(genesis *Genesis) string {
+		block, err := genesis.Block()
+		assert.Nil(t, err)
+		assert.Equal(t, genesis.Timestamp, block.GetHeader().GetTimestamp())
+		return enc.ToString(block.BlockHash())
+	}
+
+	// the genesis of a seed only keeps its hash
+	seedOnly := NewBlock(nil, nil, 1)
+	assert.Equal(t, enc.ToString(seedOnly.BlockHash()), genesisHash(&Genesis{Timestamp: 1}))
+
+	hashes := map[string]string{}
+	for name, genesis := range map[string]*Genesis{
+		"seed":     {Timestamp: 1},
+		"chainid":  {Timestamp: 1, ChainID: "test"},
+		"bps":      {Timestamp: 1, BPs: ids},
+		"reversed": {Timestamp: 1, BPs: []string{ids[1], ids[0]}},
+		"alloc":    {Timestamp: 1, Balance: map[string]*State{alice: {Balance: 1}}},
+		"balance":  {Timestamp: 1, Balance: map[string]*State{alice: {Balance: 2}}},
+		"nonce":    {Timestamp: 1, Balance: map[string]*State{alice: {Balance: 1, Nonce: 1}}},
+	} {
+		hash := genesisHash(genesis)
+		if other, exist := hashes[hash]; exist {
+			t.Errorf("genesis %s has the same hash as %s", name, other)
+		}
+		hashes[hash] = name
+	}
+
+	_, err := (&Genesis{Timestamp: 1, BPs: []string{ids[0], ids[0]}}).Block()
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "bps[1]"), err.Error())
+	}
+}
+
+func TestGenesisBalance(t *testing.T) {
+	alice, bob, carol := enc.ToString([]byte("alice")), enc.ToString([]byte("bob")), enc.ToString([]byte("carol"))
+	raw := `{"timestamp":1,"alloc":{
+		"` + alice + `":{"balance":"18446744073709551615","nonce":2},
+		"` + bob + `":{"balance":9007199254740993},
+		"` + carol + `":{}}}`
+
+	genesis := new(Genesis)
+	assert.Nil(t, json.Unmarshal([]byte(raw), genesis))
+	assert.Equal(t, int64(1), genesis.Timestamp)
+	states, err := genesis.AccountStates()
+	assert.Nil(t, err)
+	// the balances beyond the precision of float64 are kept exact
+	assert.Equal(t, uint64(math.MaxUint64), states[ToAccountID([]byte("alice"))].Balance)
+	assert.Equal(t, uint64(2), states[ToAccountID([]byte("alice"))].Nonce)
+	assert.Equal(t, uint64(9007199254740993), states[ToAccountID([]byte("bob"))].Balance)
+	assert.Equal(t, uint64(0), states[ToAccountID([]byte("carol"))].Balance)
+
+	for _, balance := range []string{
+		`"18446744073709551616"`, `18446744073709551616`, `"-1"`, `-1`, `"1.5"`, `1e3`, `"0x10"`, `""`, `true`,
+	} {
+		err := json.Unmarshal([]byte(`{"alloc":{"`+alice+`":{"balance":`+balance+`}}}`), new(Genesis))
+		assert.NotNil(t, err, balance)
+	}
+}
@@ -0,0 +1,19 @@
+package types
+
+import peer "github.com/libp2p/go-libp2p-peer"
+
+// BpAssignment describes the block producer scheduled for a time slot and the one which actually produced the
+// block of the slot.
+type BpAssignment struct {
+	// Timestamp is the UNIX time in nanoseconds which the slot is looked up by.
+	Timestamp int64
+	// BlockNo and BlockHash identify the block produced in the slot. BlockHash is empty if the slot was skipped.
+	BlockNo   BlockNo
+	BlockHash []byte
+	// Scheduled is the producer to which the slot is assigned by the producer set active at that time.
+	Scheduled peer.ID
+	// Actual is the producer of the block, which is empty if the slot was skipped.
+	Actual peer.ID
+	// Mismatch is true if the block was produced by other than the scheduled producer.
+	Mismatch bool
+}
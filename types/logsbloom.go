@@ -0,0 +1,110 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+const (
+	// LogsBloomSize is the size in bytes of the bloom filter of the events in a block.
+	LogsBloomSize = 256
+	// logsBloomHashes is the number of bits set for each item.
+	logsBloomHashes = 3
+)
+
+// LogsBloom is a bloom filter over the events emitted in a block. The contract address of each event, and the pair
+// of it and the event name are added, so that a query by either of them can skip the blocks having no match.
+type LogsBloom [LogsBloomSize]byte
+
+// NewLogsBloom returns a bloom filter of events.
+func NewLogsBloom(events ...*Event) *LogsBloom {
+	bloom := &LogsBloom{}
+	for _, event := range events {
+		bloom.AddEvent(event)
+	}
+	return bloom
+}
+
+// LogsBloomFromBytes returns the bloom filter in b. It returns nil if b is not of a bloom filter.
+func LogsBloomFromBytes(b []byte) *LogsBloom {
+	if len(b) != LogsBloomSize {
+		return nil
+	}
+	bloom := &LogsBloom{}
+	copy(bloom[:], b)
+	return bloom
+}
+
+// AddEvent adds event to the filter.
+func (b *LogsBloom) AddEvent(event *Event) {
+	b.add(event.GetContractAddress())
+	b.add(eventBloomItem(event.GetContractAddress(), event.GetEventName()))
+}
+
+// MayMatch reports whether the events in filter may have one matching f. false means none of them surely matches.
+func (b *LogsBloom) MayMatch(f *FilterInfo) bool {
+	if len(f.GetContractAddress()) == 0 {
+		return !b.IsEmpty()
+	}
+	if f.GetEventName() == "" {
+		return b.test(f.GetContractAddress())
+	}
+	return b.test(eventBloomItem(f.GetContractAddress(), f.GetEventName()))
+}
+
+// IsEmpty reports whether no event is added.
+func (b *LogsBloom) IsEmpty() bool {
+	return *b == LogsBloom{}
+}
+
+// Bytes returns b in bytes.
+func (b *LogsBloom) Bytes() []byte {
+	return b[:]
+}
+
+func (b *LogsBloom) add(item []byte) {
+	for _, bit := range bloomBits(item) {
+		b[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+func (b *LogsBloom) test(item []byte) bool {
+	for _, bit := range bloomBits(item) {
+		if b[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomBits returns the positions of the bits set for item.
+func bloomBits(item []byte) [logsBloomHashes]uint {
+	var bits [logsBloomHashes]uint
+	h := sha256.Sum256(item)
+	for i := range bits {
+		bits[i] = (uint(h[2*i])<<8 | uint(h[2*i+1])) % (LogsBloomSize * 8)
+	}
+	return bits
+}
+
+// eventBloomItem returns the item of the pair of contract address and event name.
+func eventBloomItem(contractAddress []byte, eventName string) []byte {
+	var b bytes.Buffer
+	b.Write(contractAddress)
+	b.WriteByte(0x00)
+	b.WriteString(eventName)
+	return b.Bytes()
+}
+
+// Matches reports whether event is selected by f. The block range of f is not checked.
+func (f *FilterInfo) Matches(event *Event) bool {
+	if len(f.GetContractAddress()) > 0 && !bytes.Equal(f.GetContractAddress(), event.GetContractAddress()) {
+		return false
+	}
+	return f.GetEventName() == "" || f.GetEventName() == event.GetEventName()
+}
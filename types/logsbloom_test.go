@@ -0,0 +1,59 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogsBloomNoFalseNegative(t *testing.T) {
+	var events []*Event
+	for i := 0; i < 200; i++ {
+		events = append(events, &Event{
+			ContractAddress: []byte(fmt.Sprintf("contract%012d", i%20)),
+			EventName:       fmt.Sprintf("event%d", i%7),
+		})
+	}
+	bloom := NewLogsBloom(events...)
+	restored := LogsBloomFromBytes(bloom.Bytes())
+	assert.Equal(t, bloom, restored)
+
+	for _, event := range events {
+		for _, filter := range []*FilterInfo{
+			{ContractAddress: event.ContractAddress},
+			{ContractAddress: event.ContractAddress, EventName: event.EventName},
+			{EventName: event.EventName},
+		} {
+			assert.True(t, filter.Matches(event))
+			assert.True(t, restored.MayMatch(filter), "event %s of %s", event.EventName, event.ContractAddress)
+		}
+	}
+}
+
+func TestLogsBloomMayMatch(t *testing.T) {
+	empty := NewLogsBloom()
+	assert.True(t, empty.IsEmpty())
+	assert.False(t, empty.MayMatch(&FilterInfo{ContractAddress: []byte("contract")}))
+	assert.False(t, empty.MayMatch(&FilterInfo{}))
+	assert.Nil(t, LogsBloomFromBytes(nil))
+
+	bloom := NewLogsBloom(&Event{ContractAddress: []byte("contract"), EventName: "transfer"})
+	assert.False(t, bloom.IsEmpty())
+	assert.True(t, bloom.MayMatch(&FilterInfo{ContractAddress: []byte("contract"), EventName: "transfer"}))
+	assert.False(t, bloom.MayMatch(&FilterInfo{ContractAddress: []byte("other")}))
+	assert.False(t, bloom.MayMatch(&FilterInfo{ContractAddress: []byte("contract"), EventName: "approve"}))
+}
+
+func TestFilterInfoMatches(t *testing.T) {
+	event := &Event{ContractAddress: []byte("contract"), EventName: "transfer"}
+	assert.True(t, (&FilterInfo{}).Matches(event))
+	assert.True(t, (&FilterInfo{ContractAddress: []byte("contract")}).Matches(event))
+	assert.False(t, (&FilterInfo{ContractAddress: []byte("other")}).Matches(event))
+	assert.False(t, (&FilterInfo{ContractAddress: []byte("contract"), EventName: "approve"}).Matches(event))
+}
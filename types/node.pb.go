@@ -20,9 +20,11 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type PeerAddress struct {
 	// address is stored in form of IPv4-mapped IPv6 addresses with network byte order
-	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	Port                 uint32   `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	PeerID               []byte   `protobuf:"bytes,3,opt,name=peerID,proto3" json:"peerID,omitempty"`
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Port    uint32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	PeerID  []byte `protobuf:"bytes,3,opt,name=peerID,proto3" json:"peerID,omitempty"`
+	// host is dns hostname of the peer, which is resolved on dialing. address is empty if host is set.
+	Host                 string   `protobuf:"bytes,4,opt,name=host,proto3" json:"host,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -32,7 +34,7 @@ func (m *PeerAddress) Reset()         { *m = PeerAddress{} }
 func (m *PeerAddress) String() string { return proto.CompactTextString(m) }
 func (*PeerAddress) ProtoMessage()    {}
 func (*PeerAddress) Descriptor() ([]byte, []int) {
-	return fileDescriptor_node_36365aea966d2842, []int{0}
+	return fileDescriptor_node_f1822baf127768d6, []int{0}
 }
 func (m *PeerAddress) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PeerAddress.Unmarshal(m, b)
@@ -73,21 +75,29 @@ func (m *PeerAddress) GetPeerID() []byte {
 	return nil
 }
 
+func (m *PeerAddress) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*PeerAddress)(nil), "types.PeerAddress")
 }
 
-func init() { proto.RegisterFile("node.proto", fileDescriptor_node_36365aea966d2842) }
+func init() { proto.RegisterFile("node.proto", fileDescriptor_node_f1822baf127768d6) }
 
-var fileDescriptor_node_36365aea966d2842 = []byte{
-	// 138 bytes of a gzipped FileDescriptorProto
+var fileDescriptor_node_f1822baf127768d6 = []byte{
+	// 150 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0xcb, 0x4f, 0x49,
-	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2d, 0xa9, 0x2c, 0x48, 0x2d, 0x56, 0x0a, 0xe6,
+	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2d, 0xa9, 0x2c, 0x48, 0x2d, 0x56, 0x4a, 0xe7,
 	0xe2, 0x0e, 0x48, 0x4d, 0x2d, 0x72, 0x4c, 0x49, 0x29, 0x4a, 0x2d, 0x2e, 0x16, 0x92, 0xe0, 0x62,
 	0x4f, 0x84, 0x30, 0x25, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0x60, 0x5c, 0x21, 0x21, 0x2e, 0x96,
 	0x82, 0xfc, 0xa2, 0x12, 0x09, 0x26, 0x05, 0x46, 0x0d, 0xde, 0x20, 0x30, 0x5b, 0x48, 0x8c, 0x8b,
-	0xad, 0x20, 0x35, 0xb5, 0xc8, 0xd3, 0x45, 0x82, 0x19, 0xac, 0x18, 0xca, 0x73, 0x52, 0x88, 0x92,
-	0x4b, 0xcf, 0x2c, 0xc9, 0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0x4f, 0x4c, 0x2d, 0x4a, 0xcf,
-	0xcf, 0xcc, 0x87, 0xd0, 0xfa, 0x60, 0x6b, 0x93, 0xd8, 0xc0, 0x8e, 0x30, 0x06, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0xda, 0xa5, 0xe3, 0xd9, 0x92, 0x00, 0x00, 0x00,
+	0xad, 0x20, 0x35, 0xb5, 0xc8, 0xd3, 0x45, 0x82, 0x19, 0xac, 0x18, 0xca, 0x03, 0xa9, 0xcd, 0xc8,
+	0x2f, 0x2e, 0x91, 0x60, 0x51, 0x60, 0xd4, 0xe0, 0x0c, 0x02, 0xb3, 0x9d, 0x14, 0xa2, 0xe4, 0xd2,
+	0x33, 0x4b, 0x32, 0x4a, 0x93, 0xf4, 0x92, 0xf3, 0x73, 0xf5, 0x13, 0x53, 0x8b, 0xd2, 0xf3, 0x33,
+	0xf3, 0x21, 0xb4, 0x3e, 0xd8, 0x29, 0x49, 0x6c, 0x60, 0x87, 0x19, 0x03, 0x06, 0x00, 0x3f, 0x72,
+	0xd8, 0x34, 0xa6, 0x00, 0x00, 0x00,
 }
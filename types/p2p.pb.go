@@ -110,7 +110,7 @@ func (x ResultStatus) String() string {
 	return proto.EnumName(ResultStatus_name, int32(x))
 }
 func (ResultStatus) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{0}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{0}
 }
 
 // MessageData has datas shared between all app protocols
@@ -142,7 +142,7 @@ func (m *MessageData) Reset()         { *m = MessageData{} }
 func (m *MessageData) String() string { return proto.CompactTextString(m) }
 func (*MessageData) ProtoMessage()    {}
 func (*MessageData) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{0}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{0}
 }
 func (m *MessageData) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_MessageData.Unmarshal(m, b)
@@ -237,7 +237,7 @@ func (m *P2PMessage) Reset()         { *m = P2PMessage{} }
 func (m *P2PMessage) String() string { return proto.CompactTextString(m) }
 func (*P2PMessage) ProtoMessage()    {}
 func (*P2PMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{1}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{1}
 }
 func (m *P2PMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_P2PMessage.Unmarshal(m, b)
@@ -285,7 +285,7 @@ func (m *Ping) Reset()         { *m = Ping{} }
 func (m *Ping) String() string { return proto.CompactTextString(m) }
 func (*Ping) ProtoMessage()    {}
 func (*Ping) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{2}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{2}
 }
 func (m *Ping) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Ping.Unmarshal(m, b)
@@ -341,7 +341,7 @@ func (m *Pong) Reset()         { *m = Pong{} }
 func (m *Pong) String() string { return proto.CompactTextString(m) }
 func (*Pong) ProtoMessage()    {}
 func (*Pong) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{3}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{3}
 }
 func (m *Pong) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Pong.Unmarshal(m, b)
@@ -384,20 +384,22 @@ func (m *Pong) GetBestHeight() uint64 {
 
 // Ping request message
 type Status struct {
-	MessageData          *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
-	Sender               *PeerAddress `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
-	BestBlockHash        []byte       `protobuf:"bytes,3,opt,name=bestBlockHash,proto3" json:"bestBlockHash,omitempty"`
-	BestHeight           uint64       `protobuf:"varint,4,opt,name=bestHeight,proto3" json:"bestHeight,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	MessageData   *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
+	Sender        *PeerAddress `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	BestBlockHash []byte       `protobuf:"bytes,3,opt,name=bestBlockHash,proto3" json:"bestBlockHash,omitempty"`
+	BestHeight    uint64       `protobuf:"varint,4,opt,name=bestHeight,proto3" json:"bestHeight,omitempty"`
+	// chainID is the id of the chain which the sender joins. Peers of another chain are refused on handshake.
+	ChainID              string   `protobuf:"bytes,5,opt,name=chainID,proto3" json:"chainID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Status) Reset()         { *m = Status{} }
 func (m *Status) String() string { return proto.CompactTextString(m) }
 func (*Status) ProtoMessage()    {}
 func (*Status) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{4}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{4}
 }
 func (m *Status) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status.Unmarshal(m, b)
@@ -445,6 +447,13 @@ func (m *Status) GetBestHeight() uint64 {
 	return 0
 }
 
+func (m *Status) GetChainID() string {
+	if m != nil {
+		return m.ChainID
+	}
+	return ""
+}
+
 type GoAwayNotice struct {
 	MessageData          *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
 	Message              string       `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -457,7 +466,7 @@ func (m *GoAwayNotice) Reset()         { *m = GoAwayNotice{} }
 func (m *GoAwayNotice) String() string { return proto.CompactTextString(m) }
 func (*GoAwayNotice) ProtoMessage()    {}
 func (*GoAwayNotice) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{5}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{5}
 }
 func (m *GoAwayNotice) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GoAwayNotice.Unmarshal(m, b)
@@ -504,7 +513,7 @@ func (m *AddressesRequest) Reset()         { *m = AddressesRequest{} }
 func (m *AddressesRequest) String() string { return proto.CompactTextString(m) }
 func (*AddressesRequest) ProtoMessage()    {}
 func (*AddressesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{6}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{6}
 }
 func (m *AddressesRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AddressesRequest.Unmarshal(m, b)
@@ -557,7 +566,7 @@ func (m *AddressesResponse) Reset()         { *m = AddressesResponse{} }
 func (m *AddressesResponse) String() string { return proto.CompactTextString(m) }
 func (*AddressesResponse) ProtoMessage()    {}
 func (*AddressesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{7}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{7}
 }
 func (m *AddressesResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AddressesResponse.Unmarshal(m, b)
@@ -592,19 +601,21 @@ func (m *AddressesResponse) GetPeers() []*PeerAddress {
 }
 
 type NewBlockNotice struct {
-	MessageData          *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
-	BlockHash            []byte       `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
-	BlockNo              uint64       `protobuf:"varint,3,opt,name=blockNo,proto3" json:"blockNo,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	MessageData *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
+	BlockHash   []byte       `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	BlockNo     uint64       `protobuf:"varint,3,opt,name=blockNo,proto3" json:"blockNo,omitempty"`
+	// timestamp of block (unix nano), to measure block propagation time
+	BlockTimestamp       int64    `protobuf:"varint,4,opt,name=blockTimestamp,proto3" json:"blockTimestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *NewBlockNotice) Reset()         { *m = NewBlockNotice{} }
 func (m *NewBlockNotice) String() string { return proto.CompactTextString(m) }
 func (*NewBlockNotice) ProtoMessage()    {}
 func (*NewBlockNotice) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{8}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{8}
 }
 func (m *NewBlockNotice) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_NewBlockNotice.Unmarshal(m, b)
@@ -645,6 +656,13 @@ func (m *NewBlockNotice) GetBlockNo() uint64 {
 	return 0
 }
 
+func (m *NewBlockNotice) GetBlockTimestamp() int64 {
+	if m != nil {
+		return m.BlockTimestamp
+	}
+	return 0
+}
+
 // GetBlockHeadersRequest
 type GetBlockHeadersRequest struct {
 	MessageData *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
@@ -665,7 +683,7 @@ func (m *GetBlockHeadersRequest) Reset()         { *m = GetBlockHeadersRequest{}
 func (m *GetBlockHeadersRequest) String() string { return proto.CompactTextString(m) }
 func (*GetBlockHeadersRequest) ProtoMessage()    {}
 func (*GetBlockHeadersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{9}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{9}
 }
 func (m *GetBlockHeadersRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockHeadersRequest.Unmarshal(m, b)
@@ -742,7 +760,7 @@ func (m *GetBlockHeadersResponse) Reset()         { *m = GetBlockHeadersResponse
 func (m *GetBlockHeadersResponse) String() string { return proto.CompactTextString(m) }
 func (*GetBlockHeadersResponse) ProtoMessage()    {}
 func (*GetBlockHeadersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{10}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{10}
 }
 func (m *GetBlockHeadersResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockHeadersResponse.Unmarshal(m, b)
@@ -803,7 +821,7 @@ func (m *GetBlockRequest) Reset()         { *m = GetBlockRequest{} }
 func (m *GetBlockRequest) String() string { return proto.CompactTextString(m) }
 func (*GetBlockRequest) ProtoMessage()    {}
 func (*GetBlockRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{11}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{11}
 }
 func (m *GetBlockRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockRequest.Unmarshal(m, b)
@@ -842,6 +860,7 @@ type GetBlockResponse struct {
 	MessageData          *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
 	Status               ResultStatus `protobuf:"varint,2,opt,name=status,proto3,enum=types.ResultStatus" json:"status,omitempty"`
 	Blocks               []*Block     `protobuf:"bytes,4,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	HasNext              bool         `protobuf:"varint,5,opt,name=hasNext,proto3" json:"hasNext,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -851,7 +870,7 @@ func (m *GetBlockResponse) Reset()         { *m = GetBlockResponse{} }
 func (m *GetBlockResponse) String() string { return proto.CompactTextString(m) }
 func (*GetBlockResponse) ProtoMessage()    {}
 func (*GetBlockResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{12}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{12}
 }
 func (m *GetBlockResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetBlockResponse.Unmarshal(m, b)
@@ -892,6 +911,13 @@ func (m *GetBlockResponse) GetBlocks() []*Block {
 	return nil
 }
 
+func (m *GetBlockResponse) GetHasNext() bool {
+	if m != nil {
+		return m.HasNext
+	}
+	return false
+}
+
 type NewTransactionsNotice struct {
 	MessageData          *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
 	TxHashes             [][]byte     `protobuf:"bytes,2,rep,name=txHashes,proto3" json:"txHashes,omitempty"`
@@ -904,7 +930,7 @@ func (m *NewTransactionsNotice) Reset()         { *m = NewTransactionsNotice{} }
 func (m *NewTransactionsNotice) String() string { return proto.CompactTextString(m) }
 func (*NewTransactionsNotice) ProtoMessage()    {}
 func (*NewTransactionsNotice) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{13}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{13}
 }
 func (m *NewTransactionsNotice) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_NewTransactionsNotice.Unmarshal(m, b)
@@ -950,7 +976,7 @@ func (m *GetTransactionsRequest) Reset()         { *m = GetTransactionsRequest{}
 func (m *GetTransactionsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetTransactionsRequest) ProtoMessage()    {}
 func (*GetTransactionsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{14}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{14}
 }
 func (m *GetTransactionsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetTransactionsRequest.Unmarshal(m, b)
@@ -998,7 +1024,7 @@ func (m *GetTransactionsResponse) Reset()         { *m = GetTransactionsResponse
 func (m *GetTransactionsResponse) String() string { return proto.CompactTextString(m) }
 func (*GetTransactionsResponse) ProtoMessage()    {}
 func (*GetTransactionsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{15}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{15}
 }
 func (m *GetTransactionsResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetTransactionsResponse.Unmarshal(m, b)
@@ -1062,7 +1088,7 @@ func (m *GetMissingRequest) Reset()         { *m = GetMissingRequest{} }
 func (m *GetMissingRequest) String() string { return proto.CompactTextString(m) }
 func (*GetMissingRequest) ProtoMessage()    {}
 func (*GetMissingRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_p2p_ae92ba2cfcbab9e9, []int{16}
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{16}
 }
 func (m *GetMissingRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetMissingRequest.Unmarshal(m, b)
@@ -1103,6 +1129,62 @@ func (m *GetMissingRequest) GetStophash() []byte {
 	return nil
 }
 
+// GetBlockRangeRequest requests blocks from startNo in ascending order. Responded by GetBlockResponse.
+type GetBlockRangeRequest struct {
+	MessageData *MessageData `protobuf:"bytes,1,opt,name=messageData,proto3" json:"messageData,omitempty"`
+	StartNo     uint64       `protobuf:"varint,2,opt,name=startNo,proto3" json:"startNo,omitempty"`
+	// count is the number of requested blocks. server may respond fewer blocks than it.
+	Count                uint32   `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRangeRequest) Reset()         { *m = GetBlockRangeRequest{} }
+func (m *GetBlockRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRangeRequest) ProtoMessage()    {}
+func (*GetBlockRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_p2p_975a3a3d073dda6c, []int{17}
+}
+func (m *GetBlockRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRangeRequest.Unmarshal(m, b)
+}
+func (m *GetBlockRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRangeRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetBlockRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRangeRequest.Merge(dst, src)
+}
+func (m *GetBlockRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRangeRequest.Size(m)
+}
+func (m *GetBlockRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRangeRequest proto.InternalMessageInfo
+
+func (m *GetBlockRangeRequest) GetMessageData() *MessageData {
+	if m != nil {
+		return m.MessageData
+	}
+	return nil
+}
+
+func (m *GetBlockRangeRequest) GetStartNo() uint64 {
+	if m != nil {
+		return m.StartNo
+	}
+	return 0
+}
+
+func (m *GetBlockRangeRequest) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*MessageData)(nil), "types.MessageData")
 	proto.RegisterType((*P2PMessage)(nil), "types.P2PMessage")
@@ -1121,75 +1203,80 @@ func init() {
 	proto.RegisterType((*GetTransactionsRequest)(nil), "types.GetTransactionsRequest")
 	proto.RegisterType((*GetTransactionsResponse)(nil), "types.GetTransactionsResponse")
 	proto.RegisterType((*GetMissingRequest)(nil), "types.GetMissingRequest")
+	proto.RegisterType((*GetBlockRangeRequest)(nil), "types.GetBlockRangeRequest")
 	proto.RegisterEnum("types.ResultStatus", ResultStatus_name, ResultStatus_value)
 }
 
-func init() { proto.RegisterFile("p2p.proto", fileDescriptor_p2p_ae92ba2cfcbab9e9) }
-
-var fileDescriptor_p2p_ae92ba2cfcbab9e9 = []byte{
-	// 1023 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0xd1, 0x6e, 0xe3, 0x44,
-	0x17, 0xfe, 0x9d, 0xa4, 0x69, 0x72, 0x92, 0xb4, 0xd3, 0xe9, 0xbf, 0x5d, 0xab, 0xac, 0x4a, 0x64,
-	0x55, 0x28, 0x2a, 0xa8, 0x2b, 0x15, 0x5e, 0xc0, 0x8d, 0xa7, 0xad, 0xd5, 0x74, 0x1c, 0x4d, 0x9c,
-	0xb2, 0x70, 0x81, 0xe5, 0x24, 0xd3, 0xd8, 0xa2, 0xb5, 0x43, 0x66, 0xa2, 0xb6, 0x2b, 0xc1, 0x05,
-	0x12, 0xb7, 0xbc, 0x00, 0x2f, 0x01, 0x77, 0x5c, 0x71, 0xc1, 0x8b, 0x81, 0x66, 0xe2, 0x6c, 0x1c,
-	0xba, 0x80, 0xd4, 0x6a, 0x97, 0xab, 0xcc, 0xf9, 0xe6, 0xcc, 0x9c, 0xef, 0x7c, 0xe7, 0x9c, 0x89,
-	0xa1, 0x3a, 0x39, 0x9a, 0x1c, 0x4e, 0xa6, 0xa9, 0x4c, 0xf1, 0x9a, 0xbc, 0x9f, 0x70, 0xb1, 0x8b,
-	0x06, 0xd7, 0xe9, 0xf0, 0xeb, 0x61, 0x14, 0xc6, 0xc9, 0x7c, 0x63, 0x17, 0x92, 0x74, 0xc4, 0xe7,
-	0x6b, 0xeb, 0x0f, 0x03, 0x6a, 0x17, 0x5c, 0x88, 0x70, 0xcc, 0x9d, 0x50, 0x86, 0x78, 0x1f, 0x1a,
-	0xc3, 0xeb, 0x98, 0x27, 0xf2, 0x92, 0x4f, 0x45, 0x9c, 0x26, 0xa6, 0xd1, 0x34, 0x5a, 0x55, 0xb6,
-	0x0a, 0xe2, 0x17, 0x50, 0x95, 0xf1, 0x0d, 0x17, 0x32, 0xbc, 0x99, 0x98, 0x85, 0xa6, 0xd1, 0x2a,
-	0xb2, 0x25, 0x80, 0x37, 0xa0, 0x10, 0x8f, 0xcc, 0xa2, 0x3e, 0x58, 0x88, 0x47, 0x78, 0x07, 0xca,
-	0xe3, 0x54, 0x88, 0x78, 0x62, 0x96, 0x9a, 0x46, 0xab, 0xc2, 0x32, 0x4b, 0xe1, 0x13, 0xce, 0xa7,
-	0xae, 0x63, 0xae, 0x69, 0xdf, 0xcc, 0xc2, 0x7b, 0xa0, 0x19, 0x76, 0x67, 0x83, 0x73, 0x7e, 0x6f,
-	0x96, 0x9b, 0x46, 0xab, 0xce, 0x72, 0x08, 0xc6, 0x50, 0x12, 0xf1, 0x38, 0x31, 0xd7, 0xf5, 0x8e,
-	0x5e, 0xe3, 0x26, 0xd4, 0xc4, 0x6c, 0xa0, 0x73, 0x1a, 0xa6, 0xd7, 0x66, 0xa5, 0x69, 0xb4, 0x1a,
-	0x2c, 0x0f, 0xa9, 0x68, 0xd7, 0x3c, 0x19, 0xcb, 0xc8, 0xac, 0xea, 0xcd, 0xcc, 0xb2, 0x3a, 0x00,
-	0xdd, 0xa3, 0x6e, 0xa6, 0x01, 0x3e, 0x80, 0x72, 0xc4, 0xc3, 0x11, 0x9f, 0xea, 0xc4, 0x6b, 0x47,
-	0xf8, 0x50, 0xab, 0x78, 0x98, 0xd3, 0x88, 0x65, 0x1e, 0x8a, 0xc7, 0x28, 0x94, 0xa1, 0x16, 0xa0,
-	0xce, 0xf4, 0xda, 0xfa, 0xc1, 0x80, 0x52, 0x37, 0x4e, 0xc6, 0xf8, 0x33, 0xa8, 0xdd, 0x2c, 0xcf,
-	0xfc, 0xc3, 0x6d, 0x79, 0x37, 0xfc, 0x11, 0x6c, 0x0e, 0xb8, 0x90, 0x81, 0xae, 0x59, 0x10, 0x85,
-	0x22, 0xca, 0x6e, 0x6f, 0x28, 0xf8, 0x58, 0xa1, 0x67, 0xa1, 0x88, 0xf0, 0x87, 0x50, 0xd3, 0x7e,
-	0x11, 0x8f, 0xc7, 0x91, 0xd4, 0x5a, 0x97, 0x18, 0x28, 0xe8, 0x4c, 0x23, 0xd6, 0xf7, 0x8a, 0x47,
-	0xfa, 0x68, 0x1e, 0xfb, 0xb0, 0x1a, 0xf0, 0xed, 0x2c, 0xf6, 0x20, 0x17, 0xf2, 0x2d, 0x24, 0x7e,
-	0x36, 0xa0, 0xdc, 0x93, 0xa1, 0x9c, 0x89, 0x47, 0xd2, 0x38, 0x80, 0xb2, 0xe0, 0x89, 0xaa, 0x46,
-	0x61, 0xe5, 0x40, 0x97, 0xf3, 0xa9, 0x3d, 0x1a, 0x4d, 0xb9, 0x10, 0x2c, 0xf3, 0x78, 0x48, 0xb9,
-	0xf8, 0xef, 0x94, 0x4b, 0x0f, 0x28, 0x7f, 0x05, 0xf5, 0xd3, 0xd4, 0xbe, 0x0d, 0xef, 0x69, 0x2a,
-	0xe3, 0x21, 0x7f, 0x24, 0x6f, 0x13, 0xd6, 0x33, 0x53, 0x13, 0xaf, 0xb2, 0x85, 0x69, 0xfd, 0x68,
-	0x00, 0xca, 0x98, 0x73, 0xc1, 0xf8, 0x37, 0x33, 0x2e, 0xe4, 0x7b, 0x10, 0x47, 0x11, 0x0a, 0xef,
-	0x7a, 0xf1, 0x6b, 0xae, 0x65, 0x69, 0xb0, 0x85, 0x69, 0x09, 0xd8, 0xca, 0xf1, 0x11, 0x93, 0x34,
-	0x11, 0x8f, 0xcd, 0xba, 0x05, 0x6b, 0x6a, 0x82, 0x85, 0x59, 0x68, 0x16, 0xff, 0x86, 0xcf, 0xdc,
-	0xc1, 0xfa, 0x0e, 0x36, 0x28, 0xbf, 0xd5, 0x55, 0x79, 0x92, 0xce, 0x2f, 0xa0, 0x3a, 0xf8, 0x4b,
-	0x8b, 0x2e, 0x01, 0x95, 0xf4, 0x60, 0x1e, 0x22, 0xeb, 0xcd, 0x85, 0x69, 0xfd, 0x6a, 0xc0, 0xce,
-	0x29, 0xcf, 0xda, 0x42, 0x0f, 0xf3, 0x13, 0x6b, 0x81, 0xa1, 0x94, 0x1b, 0x56, 0xbd, 0x56, 0x0f,
-	0xce, 0xca, 0x78, 0x66, 0x96, 0xc2, 0xd3, 0xab, 0x2b, 0xc1, 0x17, 0xed, 0x97, 0x59, 0xf3, 0x67,
-	0xed, 0x35, 0xd7, 0x8f, 0x61, 0x83, 0xe9, 0x35, 0x46, 0x50, 0x0c, 0xc5, 0x50, 0xbf, 0x81, 0x15,
-	0xa6, 0x96, 0xd6, 0xef, 0x06, 0x3c, 0x7f, 0x40, 0xfd, 0x49, 0x65, 0xfb, 0x18, 0xca, 0x42, 0x0f,
-	0xa9, 0x66, 0xbf, 0x71, 0xb4, 0x9d, 0x1d, 0x60, 0x5c, 0xcc, 0xae, 0xe5, 0x7c, 0x7e, 0x59, 0xe6,
-	0xa2, 0x93, 0x0a, 0x45, 0xc4, 0x85, 0x59, 0x6c, 0x16, 0x5b, 0x75, 0x96, 0x59, 0xf8, 0x13, 0x58,
-	0x9f, 0xbf, 0x8a, 0xc2, 0x2c, 0xad, 0x54, 0x3f, 0x47, 0x94, 0x2d, 0x5c, 0xac, 0x00, 0x36, 0x17,
-	0x39, 0x3c, 0x4d, 0xf7, 0x25, 0x9d, 0x42, 0x9e, 0x8e, 0xf5, 0x93, 0x01, 0x68, 0x19, 0xe1, 0xfd,
-	0xc9, 0xb3, 0x0f, 0x65, 0xdd, 0x63, 0x0b, 0x15, 0xea, 0x79, 0x15, 0x58, 0xb6, 0x67, 0xc5, 0xf0,
-	0x8c, 0xf2, 0x5b, 0x7f, 0x1a, 0x26, 0x22, 0x1c, 0xca, 0x38, 0x4d, 0xc4, 0x93, 0xa6, 0x60, 0x17,
-	0x2a, 0xf2, 0xee, 0x2c, 0x2f, 0xc3, 0x1b, 0xdb, 0xba, 0xd2, 0x8d, 0x9e, 0x0f, 0xf5, 0x6e, 0x04,
-	0xff, 0x65, 0xde, 0x96, 0xab, 0x81, 0xfe, 0xfb, 0xb6, 0xfc, 0x00, 0x8a, 0xf2, 0x6e, 0x51, 0x8c,
-	0x6a, 0x76, 0x83, 0x7f, 0xc7, 0x14, 0x6a, 0x7d, 0x0b, 0x5b, 0xa7, 0x5c, 0x5e, 0xc4, 0x42, 0xc4,
-	0xc9, 0xf8, 0x9d, 0xc8, 0xa2, 0x4a, 0x23, 0x64, 0x3a, 0x89, 0x96, 0xff, 0x47, 0x6f, 0xec, 0x83,
-	0xdf, 0x0a, 0x50, 0xcf, 0x27, 0x83, 0xcb, 0x50, 0xf0, 0xce, 0xd1, 0xff, 0x70, 0x1d, 0x2a, 0x6d,
-	0x9b, 0xb6, 0x49, 0x87, 0x38, 0xc8, 0xc0, 0x35, 0x58, 0xef, 0xd3, 0x73, 0xea, 0x7d, 0x4e, 0x51,
-	0x01, 0xff, 0x1f, 0x90, 0x4b, 0x2f, 0xed, 0x8e, 0xeb, 0x04, 0x36, 0x3b, 0xed, 0x5f, 0x10, 0xea,
-	0xa3, 0x22, 0x7e, 0x06, 0x5b, 0x0e, 0xb1, 0x9d, 0x8e, 0x4b, 0x49, 0x40, 0x5e, 0xb5, 0x09, 0x71,
-	0x88, 0x83, 0x4a, 0xb8, 0x01, 0x55, 0xea, 0xf9, 0xc1, 0x89, 0xd7, 0xa7, 0x0e, 0x5a, 0xc3, 0x18,
-	0x36, 0xec, 0x0e, 0x23, 0xb6, 0xf3, 0x45, 0x40, 0x5e, 0xb9, 0x3d, 0xbf, 0x87, 0xca, 0xea, 0x64,
-	0x97, 0xb0, 0x0b, 0xb7, 0xd7, 0x73, 0x3d, 0x1a, 0x38, 0x84, 0xba, 0xc4, 0x41, 0xeb, 0x78, 0x07,
-	0x30, 0x23, 0x3d, 0xaf, 0xcf, 0xda, 0xea, 0xc2, 0x33, 0xbb, 0xdf, 0xf3, 0x89, 0x83, 0x2a, 0xf8,
-	0x39, 0x6c, 0x9f, 0xd8, 0x6e, 0x87, 0x38, 0x41, 0x97, 0x91, 0xb6, 0x47, 0x1d, 0xd7, 0x77, 0x3d,
-	0x8a, 0xaa, 0x8a, 0xa4, 0x7d, 0xec, 0x31, 0xe5, 0x05, 0x18, 0x41, 0xdd, 0xeb, 0xfb, 0x81, 0x77,
-	0x12, 0x30, 0x9b, 0x9e, 0x12, 0x54, 0xc3, 0x5b, 0xd0, 0xe8, 0x53, 0xf7, 0xa2, 0xdb, 0x21, 0x8a,
-	0x31, 0x71, 0x50, 0x5d, 0x25, 0xe9, 0x52, 0x9f, 0x30, 0x6a, 0x77, 0x50, 0x03, 0x6f, 0x42, 0xad,
-	0x4f, 0xed, 0x4b, 0xdb, 0xed, 0xd8, 0xc7, 0x1d, 0x82, 0x36, 0x14, 0x77, 0xc7, 0xf6, 0xed, 0xa0,
-	0xe3, 0xf5, 0x7a, 0x68, 0x13, 0x6f, 0xc3, 0x66, 0x9f, 0xda, 0x7d, 0xff, 0x8c, 0x50, 0xdf, 0x6d,
-	0xdb, 0xea, 0x0a, 0x74, 0xdc, 0xfc, 0x72, 0x6f, 0x1c, 0xcb, 0x68, 0x36, 0x38, 0x1c, 0xa6, 0x37,
-	0x2f, 0x43, 0x3e, 0x1d, 0xa7, 0x71, 0x3a, 0xff, 0x7d, 0xa9, 0xeb, 0x35, 0x28, 0xeb, 0xaf, 0xbf,
-	0x4f, 0xff, 0x0c, 0x00, 0x00, 0xff, 0xff, 0x84, 0xd4, 0xcc, 0xe9, 0x16, 0x0b, 0x00, 0x00,
+func init() { proto.RegisterFile("p2p.proto", fileDescriptor_p2p_975a3a3d073dda6c) }
+
+var fileDescriptor_p2p_975a3a3d073dda6c = []byte{
+	// 1077 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc5, 0x56, 0x4b, 0x6f, 0x23, 0x45,
+	0x10, 0xc6, 0xcf, 0xd8, 0xe5, 0x47, 0x26, 0x9d, 0x7d, 0x58, 0x01, 0x2d, 0xd1, 0x68, 0x85, 0x56,
+	0x0b, 0xca, 0x4a, 0x81, 0x3f, 0x30, 0xf1, 0x4c, 0xe2, 0x51, 0x9c, 0x19, 0xab, 0x3d, 0xce, 0x02,
+	0x07, 0x46, 0x63, 0xbb, 0x63, 0x8f, 0x70, 0x66, 0x8c, 0x7b, 0xac, 0x4d, 0x10, 0x5c, 0x90, 0xb8,
+	0xf2, 0x33, 0xb8, 0x73, 0x40, 0xe2, 0xc4, 0x81, 0x23, 0x7f, 0x0a, 0xaa, 0x7b, 0xda, 0xf1, 0x24,
+	0xbb, 0x80, 0x14, 0x6b, 0x97, 0x93, 0xab, 0xaa, 0xab, 0xab, 0xbf, 0xfa, 0xea, 0x31, 0x86, 0xea,
+	0xfc, 0x70, 0x7e, 0x30, 0x5f, 0xc4, 0x49, 0x4c, 0x4a, 0xc9, 0xf5, 0x9c, 0xf1, 0x3d, 0x6d, 0x38,
+	0x8b, 0x47, 0x5f, 0x8f, 0xa6, 0x41, 0x18, 0xa5, 0x07, 0x7b, 0x10, 0xc5, 0x63, 0x96, 0xca, 0xfa,
+	0x5f, 0x39, 0xa8, 0x9d, 0x31, 0xce, 0x83, 0x09, 0x33, 0x83, 0x24, 0x20, 0x4f, 0xa1, 0x31, 0x9a,
+	0x85, 0x2c, 0x4a, 0xce, 0xd9, 0x82, 0x87, 0x71, 0xd4, 0xca, 0xed, 0xe7, 0x9e, 0x55, 0xe9, 0x6d,
+	0x23, 0xf9, 0x00, 0xaa, 0x49, 0x78, 0xc9, 0x78, 0x12, 0x5c, 0xce, 0x5b, 0x79, 0xf4, 0x28, 0xd0,
+	0xb5, 0x81, 0x34, 0x21, 0x1f, 0x8e, 0x5b, 0x05, 0x79, 0x11, 0x25, 0xf2, 0x08, 0xca, 0x93, 0x98,
+	0xf3, 0x70, 0xde, 0x2a, 0xa2, 0xad, 0x42, 0x95, 0x26, 0xec, 0x73, 0xc6, 0x16, 0xb6, 0xd9, 0x2a,
+	0x49, 0x5f, 0xa5, 0x91, 0x27, 0x20, 0x11, 0xf6, 0x96, 0xc3, 0x53, 0x76, 0xdd, 0x2a, 0xe3, 0x59,
+	0x9d, 0x66, 0x2c, 0x84, 0x40, 0x91, 0x87, 0x93, 0xa8, 0xb5, 0x25, 0x4f, 0xa4, 0x4c, 0xf6, 0xa1,
+	0xc6, 0x97, 0x43, 0x99, 0xd3, 0x28, 0x9e, 0xb5, 0x2a, 0x78, 0xd4, 0xa0, 0x59, 0x93, 0x78, 0x6d,
+	0xc6, 0xa2, 0x49, 0x32, 0x6d, 0x55, 0xe5, 0xa1, 0xd2, 0xf4, 0x2e, 0x40, 0xef, 0xb0, 0xa7, 0x38,
+	0x20, 0xcf, 0xa1, 0x3c, 0x65, 0xc1, 0x98, 0x2d, 0x64, 0xe2, 0xb5, 0x43, 0x72, 0x20, 0x59, 0x3c,
+	0xc8, 0x70, 0x44, 0x95, 0x87, 0xc0, 0x31, 0x46, 0x5d, 0x12, 0x80, 0x38, 0x84, 0xac, 0xff, 0x98,
+	0x83, 0x62, 0x2f, 0x8c, 0x26, 0xe4, 0x33, 0xa8, 0x5d, 0xae, 0xef, 0xfc, 0x4b, 0xb4, 0xac, 0x1b,
+	0xf9, 0x08, 0xb6, 0x87, 0xc8, 0xa2, 0x2f, 0x6b, 0xe6, 0x4f, 0x03, 0x3e, 0x55, 0xd1, 0x1b, 0xc2,
+	0x7c, 0x24, 0xac, 0x1d, 0x34, 0x92, 0x0f, 0xa1, 0x26, 0xfd, 0xa6, 0x2c, 0x9c, 0x4c, 0x13, 0xc9,
+	0x75, 0x91, 0x82, 0x30, 0x75, 0xa4, 0x45, 0xff, 0x41, 0xe0, 0x88, 0xef, 0x8d, 0x03, 0xdb, 0xe0,
+	0xd6, 0x83, 0x6f, 0x46, 0x81, 0x85, 0x5a, 0x3f, 0xf9, 0x06, 0x10, 0x7f, 0xe6, 0xa0, 0xdc, 0x4f,
+	0x82, 0x64, 0xc9, 0xef, 0x09, 0x03, 0xab, 0xc1, 0x59, 0x24, 0xaa, 0x91, 0xbf, 0x75, 0xa1, 0x87,
+	0x8d, 0x62, 0x8c, 0xc7, 0x0b, 0x74, 0xa5, 0xca, 0xe3, 0x75, 0xc8, 0x85, 0xff, 0x86, 0x5c, 0xbc,
+	0x0b, 0x99, 0xb4, 0x60, 0x4b, 0x8e, 0xca, 0x4d, 0x53, 0xae, 0x54, 0xfd, 0x2b, 0xa8, 0x9f, 0xc4,
+	0xc6, 0xab, 0xe0, 0xda, 0x89, 0x93, 0x70, 0xc4, 0xee, 0x99, 0x11, 0xc6, 0x57, 0xaa, 0x4c, 0x09,
+	0xe3, 0x2b, 0x55, 0xff, 0x29, 0x07, 0x9a, 0xca, 0x89, 0x71, 0xca, 0xbe, 0x59, 0x22, 0xa8, 0x77,
+	0x40, 0x9b, 0x00, 0x14, 0x5c, 0xf5, 0xc3, 0x6f, 0x99, 0x24, 0xac, 0x41, 0x57, 0xaa, 0xce, 0x61,
+	0x27, 0x83, 0x87, 0xcf, 0xe3, 0x88, 0xdf, 0x37, 0xeb, 0x67, 0x50, 0x12, 0xb3, 0xcd, 0x11, 0x4f,
+	0xe1, 0x1f, 0xf0, 0xa4, 0x0e, 0xfa, 0xcf, 0x39, 0x68, 0x3a, 0xec, 0x95, 0x2c, 0xd8, 0x46, 0x44,
+	0xe3, 0x8a, 0x1a, 0xde, 0xe9, 0xde, 0xb5, 0x41, 0x64, 0x3d, 0x4c, 0x9f, 0x50, 0x6d, 0xbb, 0x52,
+	0x71, 0x02, 0x9b, 0x52, 0xf4, 0x6e, 0xf6, 0x5b, 0x51, 0xee, 0xb7, 0x3b, 0x56, 0xfd, 0xb7, 0x1c,
+	0x3c, 0x3a, 0x61, 0xaa, 0xb3, 0xe4, 0x3e, 0xd8, 0xb0, 0x68, 0xb8, 0x4d, 0x32, 0xf3, 0x2e, 0x65,
+	0xb1, 0xb3, 0x6e, 0x4d, 0xb8, 0xd2, 0x84, 0x3d, 0xbe, 0xb8, 0xe0, 0x6c, 0xd5, 0xc1, 0x4a, 0x4b,
+	0x37, 0x23, 0x56, 0xb2, 0x24, 0x2b, 0x29, 0x65, 0xa2, 0x41, 0x21, 0xe0, 0x23, 0xb9, 0x46, 0x2b,
+	0x54, 0x88, 0xfa, 0x1f, 0x39, 0x78, 0xfc, 0x1a, 0xf4, 0x8d, 0xea, 0xfb, 0x31, 0x36, 0x9c, 0x9c,
+	0x73, 0x89, 0xbe, 0x79, 0xb8, 0xab, 0x2e, 0x60, 0xd8, 0xe5, 0x2c, 0x49, 0x57, 0x00, 0x55, 0x2e,
+	0x32, 0x29, 0x4c, 0x8e, 0x71, 0x4c, 0xaa, 0x80, 0xa9, 0x2a, 0x8d, 0x7c, 0x02, 0x5b, 0xe9, 0x62,
+	0xe5, 0x98, 0x55, 0xb6, 0x4d, 0x32, 0x40, 0xe9, 0xca, 0x45, 0xf7, 0x61, 0x7b, 0x95, 0xc3, 0x66,
+	0xbc, 0xaf, 0xe1, 0xe4, 0xb3, 0x70, 0xf4, 0x5f, 0x71, 0x1e, 0xd7, 0x2f, 0xbc, 0x3b, 0x7a, 0x9e,
+	0x42, 0x59, 0xb6, 0xda, 0x8a, 0x85, 0x7a, 0x96, 0x05, 0xaa, 0xce, 0x44, 0x03, 0x23, 0x4e, 0x87,
+	0x5d, 0x25, 0xb2, 0xd8, 0x15, 0xba, 0x52, 0xf5, 0x10, 0x1e, 0xe2, 0x00, 0x79, 0x8b, 0x20, 0xe2,
+	0xc1, 0x28, 0xc1, 0xaf, 0x35, 0xdf, 0x68, 0x8e, 0xf6, 0xa0, 0x92, 0x5c, 0x75, 0xb2, 0x04, 0xdd,
+	0xe8, 0xfa, 0x85, 0x1c, 0x81, 0xec, 0x53, 0x6f, 0xa7, 0x14, 0xbf, 0xa4, 0x0d, 0x7b, 0xfb, 0xa1,
+	0xff, 0xbf, 0x61, 0xdf, 0x87, 0x42, 0x72, 0xb5, 0x2a, 0x53, 0x55, 0x45, 0xf0, 0xae, 0xa8, 0xb0,
+	0xea, 0xdf, 0xc3, 0x0e, 0x42, 0x3e, 0x0b, 0xf1, 0x9f, 0x4e, 0x34, 0x79, 0x2b, 0xb4, 0x88, 0xd2,
+	0xf0, 0x24, 0x9e, 0x4f, 0xd7, 0x1f, 0xbb, 0x1b, 0x5d, 0xff, 0x0e, 0x1e, 0xdc, 0x34, 0x6f, 0x10,
+	0x4d, 0xd8, 0x66, 0x08, 0xb0, 0xdb, 0x90, 0x8b, 0x45, 0x82, 0xeb, 0x32, 0x9f, 0xae, 0x4b, 0xa5,
+	0x92, 0x07, 0x50, 0x1a, 0xc5, 0xcb, 0x28, 0x51, 0x1f, 0x8f, 0x54, 0x79, 0xfe, 0x7b, 0x1e, 0xea,
+	0x59, 0x2a, 0x49, 0x19, 0xf2, 0xee, 0xa9, 0xf6, 0x1e, 0xa9, 0x43, 0xa5, 0x6d, 0x38, 0x6d, 0xab,
+	0x6b, 0x99, 0x5a, 0x8e, 0xd4, 0x60, 0x6b, 0xe0, 0x9c, 0x3a, 0xee, 0x4b, 0x47, 0xcb, 0x63, 0x24,
+	0xcd, 0x76, 0xce, 0x8d, 0xae, 0x6d, 0xfa, 0x06, 0x3d, 0x19, 0x9c, 0x59, 0x8e, 0xa7, 0x15, 0xc8,
+	0x43, 0xd8, 0x31, 0x2d, 0xc3, 0xec, 0xda, 0x8e, 0xe5, 0x5b, 0x9f, 0xb7, 0x2d, 0xcb, 0xc4, 0x9b,
+	0x45, 0xd2, 0x80, 0xaa, 0xe3, 0x7a, 0xfe, 0xb1, 0x3b, 0x70, 0x4c, 0xad, 0x84, 0x7b, 0xaf, 0x69,
+	0x74, 0x29, 0xfa, 0x7d, 0x81, 0x4e, 0x76, 0xdf, 0xeb, 0x6b, 0x65, 0x71, 0xb3, 0x67, 0xd1, 0x33,
+	0xbb, 0xdf, 0xb7, 0x5d, 0xc7, 0x37, 0x2d, 0xc7, 0xc6, 0x9b, 0x5b, 0x48, 0x26, 0xa1, 0x56, 0xdf,
+	0x1d, 0xd0, 0xb6, 0x08, 0xd8, 0x31, 0x06, 0x7d, 0x0f, 0xed, 0x15, 0xf2, 0x18, 0x76, 0x8f, 0x0d,
+	0x1b, 0x71, 0xf9, 0x3d, 0x6a, 0xb5, 0x5d, 0xc7, 0xb4, 0x3d, 0xbc, 0xa7, 0x55, 0x05, 0x48, 0xe3,
+	0xc8, 0xa5, 0xc2, 0x0b, 0x70, 0x99, 0xd6, 0xdd, 0x81, 0xe7, 0xbb, 0xc7, 0x3e, 0x35, 0x9c, 0x13,
+	0x4b, 0xab, 0x91, 0x1d, 0x68, 0x0c, 0x1c, 0xfb, 0xac, 0xd7, 0xb5, 0x04, 0x62, 0x74, 0xaa, 0x8b,
+	0x24, 0x6d, 0x14, 0xa9, 0x63, 0x74, 0xb5, 0x06, 0xd9, 0x86, 0xda, 0xc0, 0x31, 0xce, 0x31, 0xb6,
+	0x71, 0xd4, 0xb5, 0xb4, 0xa6, 0xc0, 0x6e, 0x1a, 0x9e, 0xe1, 0x77, 0xdd, 0x7e, 0x5f, 0xdb, 0x26,
+	0xbb, 0xb0, 0x8d, 0xe7, 0x03, 0xaf, 0x83, 0xd7, 0xed, 0xb6, 0x21, 0x42, 0x68, 0x47, 0xfb, 0x5f,
+	0x3e, 0x99, 0x84, 0xc9, 0x74, 0x39, 0x3c, 0x18, 0xc5, 0x97, 0x2f, 0x02, 0xb6, 0x98, 0xc4, 0x61,
+	0x9c, 0xfe, 0xbe, 0x90, 0xb5, 0x1a, 0x96, 0xe5, 0x1f, 0xdb, 0x4f, 0xff, 0x06, 0x7d, 0x49, 0xcb,
+	0x4a, 0xf1, 0x0b, 0x00, 0x00,
 }
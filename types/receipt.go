@@ -2,10 +2,30 @@ package types
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 	"github.com/mr-tron/base58/base58"
-	"strings"
+)
+
+// Receipt statuses
+const (
+	// ReceiptSuccess means the contract call completed and its state changes are kept.
+	ReceiptSuccess = "SUCCESS"
+	// ReceiptCreated means the contract was deployed.
+	ReceiptCreated = "CREATED"
+	// ReceiptReverted means the contract raised an error by itself, by error or assert. The tx is still valid,
+	// but the contract's state changes are rolled back. Ret holds the revert reason.
+	ReceiptReverted = "REVERTED"
+	// ReceiptError means the call could not be executed by the VM (e.g. load failure), or failed by a runtime
+	// error of the VM, like indexing nil.
+	ReceiptError = "ERROR"
+	// ReceiptOutOfGas means the call was aborted because it exceeded its gas limit. Running time of a call is
+	// bounded by gas only, so that every node gets the same receipt.
+	ReceiptOutOfGas = "OUT_OF_GAS"
 )
 
 func NewReceipt(contractAddress []byte, status string, jsonRet string) Receipt {
@@ -16,21 +36,108 @@ func NewReceipt(contractAddress []byte, status string, jsonRet string) Receipt {
 	}
 }
 
-func NewReceiptFromBytes(b []byte) *Receipt {
+// receiptVersion is the version of the receipt encoding written by Bytes. The versioned encoding starts with 0x00
+// and the version, while the legacy encoding starts with a contract address of legacyAddressLength bytes followed
+// by one of the statuses in legacyStatuses.
+const receiptVersion = 1
+
+// legacyStatuses are the statuses which the legacy encoding has.
+var legacyStatuses = map[string]bool{ReceiptSuccess: true, ReceiptCreated: true}
+
+const (
+	legacyAddressLength = 20
+	// receiptHeaderLength is the length of 0x00, the version and the length of the contract address.
+	receiptHeaderLength = 3
+)
+
+// ErrReceiptFormat is returned on decoding bytes which are not a receipt.
+var ErrReceiptFormat = errors.New("invalid receipt encoding")
+
+// NewReceiptFromBytes returns the receipt encoded by Bytes. The receipt saved without gas used by the previous
+// versions is decoded as well.
+func NewReceiptFromBytes(b []byte) (*Receipt, error) {
+	if len(b) < receiptHeaderLength || b[0] != 0x00 || b[1] != receiptVersion {
+		return newLegacyReceiptFromBytes(b)
+	}
+	r, err := newVersionedReceiptFromBytes(b)
+	if err != nil {
+		// a legacy address may start like the versioned encoding
+		if legacy, legacyErr := newLegacyReceiptFromBytes(b); legacyErr == nil {
+			return legacy, nil
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func newVersionedReceiptFromBytes(b []byte) (*Receipt, error) {
+	addressEnd := receiptHeaderLength + int(b[2])
+	if len(b) < addressEnd+8 {
+		return nil, ErrReceiptFormat
+	}
+	r := new(Receipt)
+	r.ContractAddress = b[receiptHeaderLength:addressEnd]
+	r.GasUsed = binary.BigEndian.Uint64(b[addressEnd : addressEnd+8])
+	rest := b[addressEnd+8:]
+	endIdx := bytes.IndexByte(rest, 0x00)
+	if endIdx < 0 {
+		return nil, ErrReceiptFormat
+	}
+	r.Status = string(rest[:endIdx])
+	ret := rest[endIdx+1:]
+	if retEnd := bytes.IndexByte(ret, 0x00); retEnd >= 0 {
+		var list EventList
+		if err := proto.Unmarshal(ret[retEnd+1:], &list); err != nil {
+			return nil, err
+		}
+		r.Events = list.Events
+		ret = ret[:retEnd]
+	}
+	r.Ret = string(ret)
+	return r, nil
+}
+
+// newLegacyReceiptFromBytes decodes the receipt of contract address, status terminated by 0x00 and then ret.
+func newLegacyReceiptFromBytes(b []byte) (*Receipt, error) {
+	if len(b) <= legacyAddressLength {
+		return nil, ErrReceiptFormat
+	}
+	endIdx := bytes.IndexByte(b[legacyAddressLength:], 0x00)
+	if endIdx < 0 {
+		return nil, ErrReceiptFormat
+	}
+	endIdx += legacyAddressLength
 	r := new(Receipt)
-	r.ContractAddress = b[:20]
-	endIdx := bytes.IndexByte(b[20:], 0x00) + 20
-	r.Status = string(b[20:endIdx])
+	r.ContractAddress = b[:legacyAddressLength]
+	r.Status = string(b[legacyAddressLength:endIdx])
+	if !legacyStatuses[r.Status] {
+		return nil, ErrReceiptFormat
+	}
 	r.Ret = string(b[endIdx+1:])
-	return r
+	return r, nil
 }
 
+// Bytes encodes r into 0x00, the version of encoding, the length of contract address in a byte, contract address,
+// gas used in 8 bytes, status terminated by 0x00 and then ret. If r has events, ret is terminated by 0x00 and
+// followed by the events encoded in EventList. The contract address must be shorter than 256 bytes.
 func (r Receipt) Bytes() []byte {
 	var b bytes.Buffer
+	b.WriteByte(0x00)
+	b.WriteByte(receiptVersion)
+	b.WriteByte(byte(len(r.ContractAddress)))
 	b.Write(r.ContractAddress)
+	var gasUsed [8]byte
+	binary.BigEndian.PutUint64(gasUsed[:], r.GasUsed)
+	b.Write(gasUsed[:])
 	b.WriteString(r.Status)
 	b.WriteByte(0x00)
 	b.WriteString(r.Ret)
+	if len(r.Events) > 0 {
+		// events are plain messages, which never fail to be encoded
+		events, _ := proto.Marshal(&EventList{Events: r.Events})
+		b.WriteByte(0x00)
+		b.Write(events)
+	}
 	return b.Bytes()
 }
 
@@ -39,13 +146,25 @@ func (r Receipt) MarshalJSONPB(*jsonpb.Marshaler) ([]byte, error) {
 }
 
 func (r Receipt) MarshalJSON() ([]byte, error) {
-	var b bytes.Buffer
-	b.WriteString(`{"contractAddress":"`)
-	b.WriteString(base58.Encode(r.ContractAddress))
-	b.WriteString(`","status":"`)
-	b.WriteString(strings.Replace(r.Status, "\"", "'", -1))
-	b.WriteString(`","ret":"`)
-	b.WriteString(strings.Replace(r.Ret, "\"", "'", -1))
-	b.WriteString(`"}`)
-	return b.Bytes(), nil
+	type eventJSON struct {
+		EventName string `json:"eventName"`
+		JsonArgs  string `json:"jsonArgs"`
+		EventIdx  int32  `json:"eventIdx"`
+	}
+	out := struct {
+		ContractAddress string      `json:"contractAddress"`
+		Status          string      `json:"status"`
+		Ret             string      `json:"ret"`
+		GasUsed         uint64      `json:"gasUsed"`
+		Events          []eventJSON `json:"events,omitempty"`
+	}{
+		ContractAddress: base58.Encode(r.ContractAddress),
+		Status:          r.Status,
+		Ret:             r.Ret,
+		GasUsed:         r.GasUsed,
+	}
+	for _, event := range r.Events {
+		out.Events = append(out.Events, eventJSON{EventName: event.EventName, JsonArgs: event.JsonArgs, EventIdx: event.EventIdx})
+	}
+	return json.Marshal(&out)
 }
@@ -0,0 +1,90 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiptBytes(t *testing.T) {
+	address := []byte("01234567890123456789")
+	withEvents := NewReceipt(address, ReceiptSuccess, `["ok"]`)
+	withEvents.GasUsed = 1234
+	withEvents.Events = []*Event{
+		{ContractAddress: address, EventName: "transfer", JsonArgs: `["bob",10]`},
+		{ContractAddress: address, EventName: "approve", JsonArgs: `[]`, EventIdx: 1},
+	}
+	noEvents := NewReceipt(address, ReceiptReverted, "revert: no")
+	noEvents.GasUsed = 10
+	// the length of address is encoded
+	shortAddress := NewReceipt([]byte("0123"), ReceiptSuccess, `[]`)
+
+	for _, receipt := range []Receipt{withEvents, noEvents, shortAddress} {
+		decoded, err := NewReceiptFromBytes(receipt.Bytes())
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, receipt.ContractAddress, decoded.ContractAddress)
+		assert.Equal(t, receipt.Status, decoded.Status)
+		assert.Equal(t, receipt.Ret, decoded.Ret)
+		assert.Equal(t, receipt.GasUsed, decoded.GasUsed)
+		if assert.Len(t, decoded.Events, len(receipt.Events)) {
+			for i, event := range receipt.Events {
+				assert.True(t, proto.Equal(event, decoded.Events[i]), "event %d", i)
+			}
+		}
+	}
+
+	// the receipt saved before having gas used is decoded as well
+	legacy := append(append([]byte{}, address...), []byte("SUCCESS\x00[1]")...)
+	decoded, err := NewReceiptFromBytes(legacy)
+	if assert.NoError(t, err) {
+		assert.Equal(t, address, decoded.ContractAddress)
+		assert.Equal(t, ReceiptSuccess, decoded.Status)
+		assert.Equal(t, "[1]", decoded.Ret)
+		assert.Zero(t, decoded.GasUsed)
+		assert.Empty(t, decoded.Events)
+	}
+
+	// truncated or unknown encodings are errors rather than panics
+	encoded := noEvents.Bytes()
+	for _, b := range [][]byte{nil, address, encoded[:receiptHeaderLength], encoded[:receiptHeaderLength+len(address)+7],
+		address[:10], append(append([]byte{}, address...), []byte("SUCCESS")...)} {
+		_, err := NewReceiptFromBytes(b)
+		assert.Error(t, err, "%x", b)
+	}
+	unknown := append([]byte{}, encoded...)
+	unknown[1] = receiptVersion + 1
+	_, err = NewReceiptFromBytes(unknown)
+	assert.Error(t, err)
+}
+
+func TestReceiptMarshalJSON(t *testing.T) {
+	address := []byte("01234567890123456789")
+	receipt := NewReceipt(address, ReceiptSuccess, `{"name":"bob\n"}`)
+	receipt.GasUsed = 1234
+	receipt.Events = []*Event{{ContractAddress: address, EventName: "transfer", JsonArgs: `["bob",10]`, EventIdx: 1}}
+
+	b, err := json.Marshal(receipt)
+	assert.Nil(t, err)
+	var got map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &got), "must be valid json: %s", b)
+	assert.Equal(t, base58.Encode(address), got["contractAddress"])
+	assert.Equal(t, `{"name":"bob\n"}`, got["ret"])
+	assert.Equal(t, float64(1234), got["gasUsed"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"eventName": "transfer", "jsonArgs": `["bob",10]`, "eventIdx": float64(1)}},
+		got["events"])
+
+	// no events field without events
+	b, err = json.Marshal(NewReceipt(address, ReceiptSuccess, ""))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "events")
+}
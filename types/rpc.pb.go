@@ -27,11 +27,14 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type CommitStatus int32
 
 const (
-	CommitStatus_COMMIT_STATUS_OK                CommitStatus = 0
-	CommitStatus_COMMIT_STATUS_NONCE_TOO_LOW     CommitStatus = 1
-	CommitStatus_COMMIT_STATUS_INVALID_ARGUMENT  CommitStatus = 2
-	CommitStatus_COMMIT_STATUS_TX_ALREADY_EXISTS CommitStatus = 3
-	CommitStatus_COMMIT_STATUS_TX_INTERNAL_ERROR CommitStatus = 4
+	CommitStatus_COMMIT_STATUS_OK                   CommitStatus = 0
+	CommitStatus_COMMIT_STATUS_NONCE_TOO_LOW        CommitStatus = 1
+	CommitStatus_COMMIT_STATUS_INVALID_ARGUMENT     CommitStatus = 2
+	CommitStatus_COMMIT_STATUS_TX_ALREADY_EXISTS    CommitStatus = 3
+	CommitStatus_COMMIT_STATUS_TX_INTERNAL_ERROR    CommitStatus = 4
+	CommitStatus_COMMIT_STATUS_INVALID_SIGNATURE    CommitStatus = 5
+	CommitStatus_COMMIT_STATUS_INSUFFICIENT_BALANCE CommitStatus = 6
+	CommitStatus_COMMIT_STATUS_TX_TOO_LARGE         CommitStatus = 7
 )
 
 var CommitStatus_name = map[int32]string{
@@ -40,20 +43,26 @@ var CommitStatus_name = map[int32]string{
 	2: "COMMIT_STATUS_INVALID_ARGUMENT",
 	3: "COMMIT_STATUS_TX_ALREADY_EXISTS",
 	4: "COMMIT_STATUS_TX_INTERNAL_ERROR",
+	5: "COMMIT_STATUS_INVALID_SIGNATURE",
+	6: "COMMIT_STATUS_INSUFFICIENT_BALANCE",
+	7: "COMMIT_STATUS_TX_TOO_LARGE",
 }
 var CommitStatus_value = map[string]int32{
-	"COMMIT_STATUS_OK":                0,
-	"COMMIT_STATUS_NONCE_TOO_LOW":     1,
-	"COMMIT_STATUS_INVALID_ARGUMENT":  2,
-	"COMMIT_STATUS_TX_ALREADY_EXISTS": 3,
-	"COMMIT_STATUS_TX_INTERNAL_ERROR": 4,
+	"COMMIT_STATUS_OK":                   0,
+	"COMMIT_STATUS_NONCE_TOO_LOW":        1,
+	"COMMIT_STATUS_INVALID_ARGUMENT":     2,
+	"COMMIT_STATUS_TX_ALREADY_EXISTS":    3,
+	"COMMIT_STATUS_TX_INTERNAL_ERROR":    4,
+	"COMMIT_STATUS_INVALID_SIGNATURE":    5,
+	"COMMIT_STATUS_INSUFFICIENT_BALANCE": 6,
+	"COMMIT_STATUS_TX_TOO_LARGE":         7,
 }
 
 func (x CommitStatus) String() string {
 	return proto.EnumName(CommitStatus_name, int32(x))
 }
 func (CommitStatus) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{0}
+	return fileDescriptor_rpc_6c671147b7647294, []int{0}
 }
 
 type VerifyStatus int32
@@ -79,13 +88,16 @@ func (x VerifyStatus) String() string {
 	return proto.EnumName(VerifyStatus_name, int32(x))
 }
 func (VerifyStatus) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{1}
+	return fileDescriptor_rpc_6c671147b7647294, []int{1}
 }
 
 // BlockchainStatus is current status of blockchain
 type BlockchainStatus struct {
 	BestBlockHash        []byte   `protobuf:"bytes,1,opt,name=best_block_hash,json=bestBlockHash,proto3" json:"best_block_hash,omitempty"`
 	BestHeight           uint64   `protobuf:"varint,2,opt,name=best_height,json=bestHeight,proto3" json:"best_height,omitempty"`
+	Syncing              bool     `protobuf:"varint,3,opt,name=syncing,proto3" json:"syncing,omitempty"`
+	SyncProgress         float64  `protobuf:"fixed64,4,opt,name=sync_progress,json=syncProgress,proto3" json:"sync_progress,omitempty"`
+	TargetHeight         uint64   `protobuf:"varint,5,opt,name=target_height,json=targetHeight,proto3" json:"target_height,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -95,7 +107,7 @@ func (m *BlockchainStatus) Reset()         { *m = BlockchainStatus{} }
 func (m *BlockchainStatus) String() string { return proto.CompactTextString(m) }
 func (*BlockchainStatus) ProtoMessage()    {}
 func (*BlockchainStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{0}
+	return fileDescriptor_rpc_6c671147b7647294, []int{0}
 }
 func (m *BlockchainStatus) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockchainStatus.Unmarshal(m, b)
@@ -129,6 +141,27 @@ func (m *BlockchainStatus) GetBestHeight() uint64 {
 	return 0
 }
 
+func (m *BlockchainStatus) GetSyncing() bool {
+	if m != nil {
+		return m.Syncing
+	}
+	return false
+}
+
+func (m *BlockchainStatus) GetSyncProgress() float64 {
+	if m != nil {
+		return m.SyncProgress
+	}
+	return 0
+}
+
+func (m *BlockchainStatus) GetTargetHeight() uint64 {
+	if m != nil {
+		return m.TargetHeight
+	}
+	return 0
+}
+
 type Input struct {
 	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	Address              [][]byte `protobuf:"bytes,2,rep,name=address,proto3" json:"address,omitempty"`
@@ -143,7 +176,7 @@ func (m *Input) Reset()         { *m = Input{} }
 func (m *Input) String() string { return proto.CompactTextString(m) }
 func (*Input) ProtoMessage()    {}
 func (*Input) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{1}
+	return fileDescriptor_rpc_6c671147b7647294, []int{1}
 }
 func (m *Input) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Input.Unmarshal(m, b)
@@ -205,7 +238,7 @@ func (m *Output) Reset()         { *m = Output{} }
 func (m *Output) String() string { return proto.CompactTextString(m) }
 func (*Output) ProtoMessage()    {}
 func (*Output) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{2}
+	return fileDescriptor_rpc_6c671147b7647294, []int{2}
 }
 func (m *Output) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Output.Unmarshal(m, b)
@@ -263,7 +296,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{3}
+	return fileDescriptor_rpc_6c671147b7647294, []int{3}
 }
 func (m *Empty) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Empty.Unmarshal(m, b)
@@ -294,7 +327,7 @@ func (m *SingleBytes) Reset()         { *m = SingleBytes{} }
 func (m *SingleBytes) String() string { return proto.CompactTextString(m) }
 func (*SingleBytes) ProtoMessage()    {}
 func (*SingleBytes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{4}
+	return fileDescriptor_rpc_6c671147b7647294, []int{4}
 }
 func (m *SingleBytes) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SingleBytes.Unmarshal(m, b)
@@ -324,6 +357,7 @@ func (m *SingleBytes) GetValue() []byte {
 type Personal struct {
 	Passphrase           string   `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
 	Account              *Account `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	Duration             uint64   `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -333,7 +367,7 @@ func (m *Personal) Reset()         { *m = Personal{} }
 func (m *Personal) String() string { return proto.CompactTextString(m) }
 func (*Personal) ProtoMessage()    {}
 func (*Personal) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{5}
+	return fileDescriptor_rpc_6c671147b7647294, []int{5}
 }
 func (m *Personal) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Personal.Unmarshal(m, b)
@@ -367,6 +401,13 @@ func (m *Personal) GetAccount() *Account {
 	return nil
 }
 
+func (m *Personal) GetDuration() uint64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
 type PeerList struct {
 	Peers                []*PeerAddress `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
 	States               []int32        `protobuf:"varint,2,rep,packed,name=states,proto3" json:"states,omitempty"`
@@ -379,7 +420,7 @@ func (m *PeerList) Reset()         { *m = PeerList{} }
 func (m *PeerList) String() string { return proto.CompactTextString(m) }
 func (*PeerList) ProtoMessage()    {}
 func (*PeerList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{6}
+	return fileDescriptor_rpc_6c671147b7647294, []int{6}
 }
 func (m *PeerList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PeerList.Unmarshal(m, b)
@@ -428,7 +469,7 @@ func (m *ListParams) Reset()         { *m = ListParams{} }
 func (m *ListParams) String() string { return proto.CompactTextString(m) }
 func (*ListParams) ProtoMessage()    {}
 func (*ListParams) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{7}
+	return fileDescriptor_rpc_6c671147b7647294, []int{7}
 }
 func (m *ListParams) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ListParams.Unmarshal(m, b)
@@ -494,7 +535,7 @@ func (m *BlockHeaderList) Reset()         { *m = BlockHeaderList{} }
 func (m *BlockHeaderList) String() string { return proto.CompactTextString(m) }
 func (*BlockHeaderList) ProtoMessage()    {}
 func (*BlockHeaderList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{8}
+	return fileDescriptor_rpc_6c671147b7647294, []int{8}
 }
 func (m *BlockHeaderList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockHeaderList.Unmarshal(m, b)
@@ -522,18 +563,20 @@ func (m *BlockHeaderList) GetBlocks() []*Block {
 }
 
 type CommitResult struct {
-	Hash                 []byte       `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
-	Error                CommitStatus `protobuf:"varint,2,opt,name=error,proto3,enum=types.CommitStatus" json:"error,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Hash  []byte       `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Error CommitStatus `protobuf:"varint,2,opt,name=error,proto3,enum=types.CommitStatus" json:"error,omitempty"`
+	// detail is human readable message of error
+	Detail               string   `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *CommitResult) Reset()         { *m = CommitResult{} }
 func (m *CommitResult) String() string { return proto.CompactTextString(m) }
 func (*CommitResult) ProtoMessage()    {}
 func (*CommitResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{9}
+	return fileDescriptor_rpc_6c671147b7647294, []int{9}
 }
 func (m *CommitResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CommitResult.Unmarshal(m, b)
@@ -567,6 +610,13 @@ func (m *CommitResult) GetError() CommitStatus {
 	return CommitStatus_COMMIT_STATUS_OK
 }
 
+func (m *CommitResult) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
 type CommitResultList struct {
 	Results              []*CommitResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
@@ -578,7 +628,7 @@ func (m *CommitResultList) Reset()         { *m = CommitResultList{} }
 func (m *CommitResultList) String() string { return proto.CompactTextString(m) }
 func (*CommitResultList) ProtoMessage()    {}
 func (*CommitResultList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{10}
+	return fileDescriptor_rpc_6c671147b7647294, []int{10}
 }
 func (m *CommitResultList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CommitResultList.Unmarshal(m, b)
@@ -617,7 +667,7 @@ func (m *VerifyResult) Reset()         { *m = VerifyResult{} }
 func (m *VerifyResult) String() string { return proto.CompactTextString(m) }
 func (*VerifyResult) ProtoMessage()    {}
 func (*VerifyResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_rpc_e6897c849d1aa08b, []int{11}
+	return fileDescriptor_rpc_6c671147b7647294, []int{11}
 }
 func (m *VerifyResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_VerifyResult.Unmarshal(m, b)
@@ -651,6 +701,100 @@ func (m *VerifyResult) GetError() VerifyStatus {
 	return VerifyStatus_VERIFY_STATUS_OK
 }
 
+// StateQuery is compatible with SingleBytes, so that confirmations is optional.
+type StateQuery struct {
+	Account              []byte   `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Confirmations        uint64   `protobuf:"varint,2,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateQuery) Reset()         { *m = StateQuery{} }
+func (m *StateQuery) String() string { return proto.CompactTextString(m) }
+func (*StateQuery) ProtoMessage()    {}
+func (*StateQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_6c671147b7647294, []int{12}
+}
+func (m *StateQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateQuery.Unmarshal(m, b)
+}
+func (m *StateQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateQuery.Marshal(b, m, deterministic)
+}
+func (dst *StateQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateQuery.Merge(dst, src)
+}
+func (m *StateQuery) XXX_Size() int {
+	return xxx_messageInfo_StateQuery.Size(m)
+}
+func (m *StateQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateQuery proto.InternalMessageInfo
+
+func (m *StateQuery) GetAccount() []byte {
+	if m != nil {
+		return m.Account
+	}
+	return nil
+}
+
+func (m *StateQuery) GetConfirmations() uint64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+// SignTxRequest asks the node to sign tx with the unlocked account from.
+type SignTxRequest struct {
+	Tx                   *Tx      `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	From                 []byte   `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignTxRequest) Reset()         { *m = SignTxRequest{} }
+func (m *SignTxRequest) String() string { return proto.CompactTextString(m) }
+func (*SignTxRequest) ProtoMessage()    {}
+func (*SignTxRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_rpc_6c671147b7647294, []int{13}
+}
+func (m *SignTxRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignTxRequest.Unmarshal(m, b)
+}
+func (m *SignTxRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignTxRequest.Marshal(b, m, deterministic)
+}
+func (dst *SignTxRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignTxRequest.Merge(dst, src)
+}
+func (m *SignTxRequest) XXX_Size() int {
+	return xxx_messageInfo_SignTxRequest.Size(m)
+}
+func (m *SignTxRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignTxRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignTxRequest proto.InternalMessageInfo
+
+func (m *SignTxRequest) GetTx() *Tx {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *SignTxRequest) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*BlockchainStatus)(nil), "types.BlockchainStatus")
 	proto.RegisterType((*Input)(nil), "types.Input")
@@ -664,6 +808,8 @@ func init() {
 	proto.RegisterType((*CommitResult)(nil), "types.CommitResult")
 	proto.RegisterType((*CommitResultList)(nil), "types.CommitResultList")
 	proto.RegisterType((*VerifyResult)(nil), "types.VerifyResult")
+	proto.RegisterType((*StateQuery)(nil), "types.StateQuery")
+	proto.RegisterType((*SignTxRequest)(nil), "types.SignTxRequest")
 	proto.RegisterEnum("types.CommitStatus", CommitStatus_name, CommitStatus_value)
 	proto.RegisterEnum("types.VerifyStatus", VerifyStatus_name, VerifyStatus_value)
 }
@@ -688,14 +834,18 @@ type AergoRPCServiceClient interface {
 	GetBlockTX(ctx context.Context, in *SingleBytes, opts ...grpc.CallOption) (*TxInBlock, error)
 	GetReceipt(ctx context.Context, in *SingleBytes, opts ...grpc.CallOption) (*Receipt, error)
 	CommitTX(ctx context.Context, in *TxList, opts ...grpc.CallOption) (*CommitResultList, error)
-	GetState(ctx context.Context, in *SingleBytes, opts ...grpc.CallOption) (*State, error)
+	GetState(ctx context.Context, in *StateQuery, opts ...grpc.CallOption) (*State, error)
 	CreateAccount(ctx context.Context, in *Personal, opts ...grpc.CallOption) (*Account, error)
 	GetAccounts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AccountList, error)
 	LockAccount(ctx context.Context, in *Personal, opts ...grpc.CallOption) (*Account, error)
 	UnlockAccount(ctx context.Context, in *Personal, opts ...grpc.CallOption) (*Account, error)
 	SignTX(ctx context.Context, in *Tx, opts ...grpc.CallOption) (*Tx, error)
+	SignTransaction(ctx context.Context, in *SignTxRequest, opts ...grpc.CallOption) (*Tx, error)
 	VerifyTX(ctx context.Context, in *Tx, opts ...grpc.CallOption) (*VerifyResult, error)
 	GetPeers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeerList, error)
+	DumpP2PState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SingleBytes, error)
+	GenerateBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SingleBytes, error)
+	GetLogs(ctx context.Context, in *FilterInfo, opts ...grpc.CallOption) (*EventList, error)
 }
 
 type aergoRPCServiceClient struct {
@@ -778,7 +928,7 @@ func (c *aergoRPCServiceClient) CommitTX(ctx context.Context, in *TxList, opts .
 	return out, nil
 }
 
-func (c *aergoRPCServiceClient) GetState(ctx context.Context, in *SingleBytes, opts ...grpc.CallOption) (*State, error) {
+func (c *aergoRPCServiceClient) GetState(ctx context.Context, in *StateQuery, opts ...grpc.CallOption) (*State, error) {
 	out := new(State)
 	err := c.cc.Invoke(ctx, "/types.AergoRPCService/GetState", in, out, opts...)
 	if err != nil {
@@ -832,6 +982,15 @@ func (c *aergoRPCServiceClient) SignTX(ctx context.Context, in *Tx, opts ...grpc
 	return out, nil
 }
 
+func (c *aergoRPCServiceClient) SignTransaction(ctx context.Context, in *SignTxRequest, opts ...grpc.CallOption) (*Tx, error) {
+	out := new(Tx)
+	err := c.cc.Invoke(ctx, "/types.AergoRPCService/SignTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aergoRPCServiceClient) VerifyTX(ctx context.Context, in *Tx, opts ...grpc.CallOption) (*VerifyResult, error) {
 	out := new(VerifyResult)
 	err := c.cc.Invoke(ctx, "/types.AergoRPCService/VerifyTX", in, out, opts...)
@@ -850,6 +1009,33 @@ func (c *aergoRPCServiceClient) GetPeers(ctx context.Context, in *Empty, opts ..
 	return out, nil
 }
 
+func (c *aergoRPCServiceClient) DumpP2PState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SingleBytes, error) {
+	out := new(SingleBytes)
+	err := c.cc.Invoke(ctx, "/types.AergoRPCService/DumpP2PState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aergoRPCServiceClient) GenerateBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SingleBytes, error) {
+	out := new(SingleBytes)
+	err := c.cc.Invoke(ctx, "/types.AergoRPCService/GenerateBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aergoRPCServiceClient) GetLogs(ctx context.Context, in *FilterInfo, opts ...grpc.CallOption) (*EventList, error) {
+	out := new(EventList)
+	err := c.cc.Invoke(ctx, "/types.AergoRPCService/GetLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AergoRPCServiceServer is the server API for AergoRPCService service.
 type AergoRPCServiceServer interface {
 	NodeState(context.Context, *SingleBytes) (*SingleBytes, error)
@@ -860,14 +1046,18 @@ type AergoRPCServiceServer interface {
 	GetBlockTX(context.Context, *SingleBytes) (*TxInBlock, error)
 	GetReceipt(context.Context, *SingleBytes) (*Receipt, error)
 	CommitTX(context.Context, *TxList) (*CommitResultList, error)
-	GetState(context.Context, *SingleBytes) (*State, error)
+	GetState(context.Context, *StateQuery) (*State, error)
 	CreateAccount(context.Context, *Personal) (*Account, error)
 	GetAccounts(context.Context, *Empty) (*AccountList, error)
 	LockAccount(context.Context, *Personal) (*Account, error)
 	UnlockAccount(context.Context, *Personal) (*Account, error)
 	SignTX(context.Context, *Tx) (*Tx, error)
+	SignTransaction(context.Context, *SignTxRequest) (*Tx, error)
 	VerifyTX(context.Context, *Tx) (*VerifyResult, error)
 	GetPeers(context.Context, *Empty) (*PeerList, error)
+	DumpP2PState(context.Context, *Empty) (*SingleBytes, error)
+	GenerateBlock(context.Context, *Empty) (*SingleBytes, error)
+	GetLogs(context.Context, *FilterInfo) (*EventList, error)
 }
 
 func RegisterAergoRPCServiceServer(s *grpc.Server, srv AergoRPCServiceServer) {
@@ -1019,7 +1209,7 @@ func _AergoRPCService_CommitTX_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _AergoRPCService_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SingleBytes)
+	in := new(StateQuery)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1031,7 +1221,7 @@ func _AergoRPCService_GetState_Handler(srv interface{}, ctx context.Context, dec
 		FullMethod: "/types.AergoRPCService/GetState",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AergoRPCServiceServer).GetState(ctx, req.(*SingleBytes))
+		return srv.(AergoRPCServiceServer).GetState(ctx, req.(*StateQuery))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1126,6 +1316,24 @@ func _AergoRPCService_SignTX_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AergoRPCService_SignTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AergoRPCServiceServer).SignTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/types.AergoRPCService/SignTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AergoRPCServiceServer).SignTransaction(ctx, req.(*SignTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AergoRPCService_VerifyTX_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Tx)
 	if err := dec(in); err != nil {
@@ -1162,6 +1370,60 @@ func _AergoRPCService_GetPeers_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AergoRPCService_DumpP2PState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AergoRPCServiceServer).DumpP2PState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/types.AergoRPCService/DumpP2PState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AergoRPCServiceServer).DumpP2PState(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AergoRPCService_GenerateBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AergoRPCServiceServer).GenerateBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/types.AergoRPCService/GenerateBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AergoRPCServiceServer).GenerateBlock(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AergoRPCService_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AergoRPCServiceServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/types.AergoRPCService/GetLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AergoRPCServiceServer).GetLogs(ctx, req.(*FilterInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _AergoRPCService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "types.AergoRPCService",
 	HandlerType: (*AergoRPCServiceServer)(nil),
@@ -1222,6 +1484,10 @@ var _AergoRPCService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SignTX",
 			Handler:    _AergoRPCService_SignTX_Handler,
 		},
+		{
+			MethodName: "SignTransaction",
+			Handler:    _AergoRPCService_SignTransaction_Handler,
+		},
 		{
 			MethodName: "VerifyTX",
 			Handler:    _AergoRPCService_VerifyTX_Handler,
@@ -1230,73 +1496,101 @@ var _AergoRPCService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPeers",
 			Handler:    _AergoRPCService_GetPeers_Handler,
 		},
+		{
+			MethodName: "DumpP2PState",
+			Handler:    _AergoRPCService_DumpP2PState_Handler,
+		},
+		{
+			MethodName: "GenerateBlock",
+			Handler:    _AergoRPCService_GenerateBlock_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _AergoRPCService_GetLogs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
 }
 
-func init() { proto.RegisterFile("rpc.proto", fileDescriptor_rpc_e6897c849d1aa08b) }
-
-var fileDescriptor_rpc_e6897c849d1aa08b = []byte{
-	// 948 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0x5f, 0x6f, 0xe2, 0x46,
-	0x10, 0xc7, 0x24, 0x90, 0x64, 0x80, 0xc3, 0xdd, 0x46, 0x29, 0xa5, 0x55, 0x82, 0xf6, 0xaa, 0x8a,
-	0x5e, 0x5b, 0xd2, 0xd2, 0x9e, 0xfa, 0x58, 0x39, 0x1c, 0x05, 0x54, 0xfe, 0x44, 0x6b, 0x27, 0xe5,
-	0xda, 0x07, 0xcb, 0x98, 0x05, 0xac, 0x03, 0xdb, 0xf2, 0x2e, 0x51, 0xd2, 0xaf, 0xd5, 0x7e, 0xc0,
-	0xca, 0xbb, 0x6b, 0xb0, 0x23, 0xf2, 0x70, 0x7d, 0xca, 0xce, 0xf8, 0x37, 0xff, 0x7f, 0x33, 0x01,
-	0xce, 0xa2, 0xd0, 0x6d, 0x85, 0x51, 0xc0, 0x03, 0x54, 0xe0, 0x4f, 0x21, 0x65, 0xf5, 0xab, 0x65,
-	0x10, 0x2c, 0xd7, 0xf4, 0x5a, 0x28, 0x67, 0xdb, 0xc5, 0x35, 0xf7, 0x36, 0x94, 0x71, 0x67, 0x13,
-	0x4a, 0x5c, 0x5d, 0x9f, 0xad, 0x03, 0xf7, 0x83, 0xbb, 0x72, 0x3c, 0x5f, 0x69, 0x2a, 0x8e, 0xeb,
-	0x06, 0x5b, 0x9f, 0x2b, 0x11, 0xfc, 0x60, 0x4e, 0xe5, 0x1b, 0xff, 0x05, 0xfa, 0xcd, 0x0e, 0x6e,
-	0x72, 0x87, 0x6f, 0x19, 0xfa, 0x1a, 0xaa, 0x33, 0xca, 0xb8, 0x2d, 0xfc, 0xd8, 0x2b, 0x87, 0xad,
-	0x6a, 0x5a, 0x43, 0x6b, 0x96, 0x49, 0x25, 0x56, 0x0b, 0x78, 0xdf, 0x61, 0x2b, 0x74, 0x05, 0x25,
-	0x81, 0x5b, 0x51, 0x6f, 0xb9, 0xe2, 0xb5, 0x7c, 0x43, 0x6b, 0x1e, 0x13, 0x88, 0x55, 0x7d, 0xa1,
-	0xc1, 0x2e, 0x14, 0x06, 0x7e, 0xb8, 0xe5, 0x08, 0xc1, 0x71, 0xca, 0x8d, 0x78, 0xa3, 0x1a, 0x9c,
-	0x38, 0xf3, 0x79, 0x44, 0x19, 0xab, 0xe5, 0x1b, 0x47, 0xcd, 0x32, 0x49, 0x44, 0x74, 0x0e, 0x85,
-	0x07, 0x67, 0xbd, 0xa5, 0xb5, 0x23, 0x01, 0x97, 0x02, 0xba, 0x80, 0x22, 0x73, 0x23, 0x2f, 0xe4,
-	0xb5, 0x63, 0xa1, 0x56, 0x12, 0x5e, 0x40, 0x71, 0xb2, 0xe5, 0x71, 0x94, 0x73, 0x28, 0x78, 0xfe,
-	0x9c, 0x3e, 0x8a, 0x30, 0x15, 0x22, 0x85, 0x6c, 0x1c, 0xed, 0xff, 0xc7, 0x39, 0x81, 0x42, 0x77,
-	0x13, 0xf2, 0x27, 0xfc, 0x1a, 0x4a, 0xa6, 0xe7, 0x2f, 0xd7, 0xf4, 0xe6, 0x89, 0xd3, 0x94, 0x17,
-	0x2d, 0xe5, 0x05, 0x5b, 0x70, 0x7a, 0x4b, 0x23, 0x16, 0xf8, 0xce, 0x1a, 0x5d, 0x02, 0x84, 0x0e,
-	0x63, 0xe1, 0x2a, 0x72, 0x98, 0x84, 0x9d, 0x91, 0x94, 0x06, 0x35, 0xe1, 0x44, 0x0d, 0x48, 0x64,
-	0x58, 0x6a, 0xbf, 0x6a, 0x89, 0x51, 0xb7, 0x0c, 0xa9, 0x25, 0xc9, 0x67, 0x3c, 0x8c, 0xbd, 0xd2,
-	0x68, 0xe8, 0x31, 0x8e, 0x9a, 0x50, 0x08, 0x29, 0x8d, 0x58, 0x4d, 0x6b, 0x1c, 0x35, 0x4b, 0x6d,
-	0xa4, 0x6c, 0xe2, 0xef, 0x86, 0x2c, 0x90, 0x48, 0x80, 0xa8, 0x88, 0x3b, 0x9c, 0xca, 0x46, 0x17,
-	0x88, 0x92, 0xf0, 0x03, 0x40, 0xec, 0xe9, 0xd6, 0x89, 0x9c, 0x0d, 0x3b, 0x38, 0xa3, 0x0b, 0x28,
-	0x66, 0x86, 0xab, 0xa4, 0x18, 0xcb, 0xbc, 0xbf, 0x65, 0xe3, 0x2a, 0x44, 0xbc, 0x63, 0x6c, 0xb0,
-	0x58, 0x30, 0x2a, 0xfb, 0x56, 0x21, 0x4a, 0x42, 0x3a, 0x1c, 0x39, 0xcc, 0xad, 0x15, 0x1a, 0x5a,
-	0xf3, 0x94, 0xc4, 0x4f, 0xfc, 0x0b, 0x54, 0x25, 0x89, 0xa8, 0x33, 0x57, 0xc5, 0x7c, 0x05, 0x45,
-	0xc1, 0xb6, 0xa4, 0x9a, 0xb2, 0xaa, 0x46, 0xe0, 0x88, 0xfa, 0x86, 0x47, 0x50, 0xee, 0x04, 0x9b,
-	0x8d, 0xc7, 0x09, 0x65, 0xdb, 0xf5, 0x61, 0x5a, 0x7d, 0x03, 0x05, 0x1a, 0x45, 0x41, 0x24, 0x32,
-	0x7e, 0xd5, 0xfe, 0x54, 0x39, 0x92, 0x76, 0x92, 0xe0, 0x44, 0x22, 0xb0, 0x01, 0x7a, 0xda, 0x9d,
-	0x48, 0xe4, 0x7b, 0x38, 0x89, 0x84, 0x94, 0x64, 0x92, 0x75, 0x20, 0x91, 0x24, 0xc1, 0x60, 0x0b,
-	0xca, 0xf7, 0x34, 0xf2, 0x16, 0x4f, 0x2a, 0xa3, 0xcf, 0x21, 0xcf, 0x25, 0xff, 0x4a, 0xed, 0x33,
-	0x65, 0x69, 0x3d, 0x92, 0x3c, 0x7f, 0x7c, 0x29, 0x31, 0x69, 0x9e, 0x49, 0xec, 0xcd, 0x3f, 0x5a,
-	0x52, 0xa8, 0xda, 0xc8, 0x73, 0xd0, 0x3b, 0x93, 0xd1, 0x68, 0x60, 0xd9, 0xa6, 0x65, 0x58, 0x77,
-	0xa6, 0x3d, 0xf9, 0x5d, 0xcf, 0xa1, 0x2b, 0xf8, 0x22, 0xab, 0x1d, 0x4f, 0xc6, 0x9d, 0xae, 0x6d,
-	0x4d, 0x26, 0xf6, 0x70, 0xf2, 0x87, 0xae, 0x21, 0x0c, 0x97, 0x59, 0xc0, 0x60, 0x7c, 0x6f, 0x0c,
-	0x07, 0xef, 0x6c, 0x83, 0xf4, 0xee, 0x46, 0xdd, 0xb1, 0xa5, 0xe7, 0xd1, 0x6b, 0xb8, 0xca, 0x62,
-	0xac, 0xa9, 0x6d, 0x0c, 0x49, 0xd7, 0x78, 0xf7, 0xde, 0xee, 0x4e, 0x07, 0xa6, 0x65, 0xea, 0x47,
-	0x07, 0x41, 0x83, 0xb1, 0xd5, 0x25, 0x63, 0x63, 0x68, 0x77, 0x09, 0x99, 0x10, 0xfd, 0xf8, 0xcd,
-	0x22, 0xe9, 0xc5, 0x3e, 0xe9, 0xfb, 0x2e, 0x19, 0xfc, 0xf6, 0x3e, 0x93, 0x74, 0x03, 0xbe, 0xcc,
-	0x6a, 0xcd, 0x41, 0x6f, 0x6c, 0x8f, 0x27, 0x96, 0x3d, 0x32, 0xac, 0x4e, 0x5f, 0xd7, 0xd0, 0x25,
-	0xd4, 0xb3, 0x88, 0x24, 0xeb, 0xbe, 0x61, 0xf6, 0xf5, 0x7c, 0xfb, 0xdf, 0x22, 0x54, 0x0d, 0x1a,
-	0x2d, 0x03, 0x72, 0xdb, 0x31, 0x69, 0xf4, 0xe0, 0xb9, 0x14, 0xbd, 0x85, 0xb3, 0x71, 0x30, 0xa7,
-	0x71, 0x64, 0x8a, 0x92, 0x55, 0x48, 0x6d, 0x69, 0xfd, 0x80, 0x0e, 0xe7, 0xd0, 0x5b, 0x80, 0xfd,
-	0xf5, 0x43, 0x09, 0xe9, 0xc4, 0x9a, 0xd7, 0x3f, 0x4b, 0x53, 0x30, 0x75, 0x1e, 0x71, 0x0e, 0xfd,
-	0x0a, 0x7a, 0x4c, 0x96, 0x14, 0x89, 0x19, 0xfa, 0x44, 0xc1, 0xf7, 0x1b, 0x55, 0xbf, 0x48, 0x7b,
-	0xd8, 0x93, 0x1d, 0xe7, 0x50, 0x0b, 0x4e, 0x7b, 0x54, 0xda, 0x1f, 0xcc, 0x36, 0x43, 0x7f, 0x9c,
-	0x8b, 0x77, 0xbd, 0x47, 0xb9, 0x35, 0x3d, 0x08, 0xde, 0xf3, 0x0c, 0xe7, 0xd0, 0xcf, 0x00, 0x89,
-	0xe7, 0x17, 0xe0, 0xfa, 0x0e, 0x3e, 0xf0, 0x13, 0xff, 0x6d, 0x61, 0x45, 0xa8, 0x4b, 0xbd, 0x90,
-	0x1f, 0xb4, 0x4a, 0x4e, 0x92, 0xc2, 0x88, 0x48, 0xa7, 0x92, 0xa3, 0xd6, 0x14, 0x55, 0x76, 0x3e,
-	0xe3, 0x02, 0x77, 0xad, 0x7b, 0xbe, 0x5d, 0xbb, 0xca, 0x5f, 0x9e, 0x53, 0x52, 0xb9, 0x40, 0x88,
-	0xcc, 0x2a, 0x9d, 0x88, 0x3a, 0x9c, 0xaa, 0x5b, 0x88, 0xaa, 0xbb, 0x3b, 0x27, 0xaf, 0x6b, 0xfd,
-	0xd9, 0xb1, 0xc4, 0x39, 0xf4, 0x23, 0x94, 0x7a, 0x94, 0x2b, 0x99, 0x3d, 0x1b, 0x2b, 0xca, 0xc2,
-	0x55, 0x5a, 0x3f, 0x40, 0x69, 0x18, 0xb8, 0x1f, 0x3e, 0x22, 0x48, 0x1b, 0x2a, 0x77, 0xfe, 0xfa,
-	0xe3, 0x6c, 0x1a, 0x50, 0x34, 0xbd, 0xa5, 0x6f, 0x4d, 0xd1, 0x7e, 0x66, 0xd9, 0xf1, 0x7d, 0x07,
-	0xa7, 0x72, 0x87, 0xb2, 0x98, 0xec, 0xb1, 0x90, 0x0d, 0xc5, 0x39, 0xf4, 0xad, 0x68, 0xe6, 0xad,
-	0x38, 0xf2, 0xd9, 0x2a, 0xab, 0xa9, 0xff, 0x06, 0xb2, 0xc4, 0x9b, 0xc6, 0x9f, 0x97, 0x4b, 0x8f,
-	0xaf, 0xb6, 0xb3, 0x96, 0x1b, 0x6c, 0xae, 0x9d, 0x78, 0x81, 0xbc, 0x40, 0xfe, 0xbd, 0x16, 0xe0,
-	0x59, 0x51, 0xfc, 0x24, 0xf8, 0xe9, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x63, 0x95, 0x17, 0x87,
-	0x74, 0x08, 0x00, 0x00,
+func init() { proto.RegisterFile("rpc.proto", fileDescriptor_rpc_6c671147b7647294) }
+
+var fileDescriptor_rpc_6c671147b7647294 = []byte{
+	// 1205 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdf, 0x72, 0xda, 0x46,
+	0x17, 0x47, 0xd8, 0x60, 0x7c, 0x80, 0x58, 0xdf, 0x7e, 0x9e, 0xd4, 0xa5, 0x1d, 0x9b, 0x51, 0x32,
+	0x19, 0x9a, 0x36, 0x76, 0x4a, 0x9a, 0xe9, 0x5d, 0x3b, 0x32, 0x91, 0x31, 0x53, 0x0c, 0x74, 0x91,
+	0x53, 0xa7, 0x37, 0x1a, 0x59, 0x2c, 0xa0, 0x09, 0x48, 0xea, 0xee, 0xca, 0x63, 0x7a, 0xdd, 0x07,
+	0xe9, 0x9b, 0xf4, 0xd5, 0x3a, 0xda, 0x5d, 0x81, 0xe4, 0x90, 0x4e, 0xd3, 0x2b, 0xf6, 0x1c, 0xfd,
+	0xf6, 0x9c, 0xdf, 0xf9, 0xbb, 0xc0, 0x3e, 0x8d, 0xbc, 0xd3, 0x88, 0x86, 0x3c, 0x44, 0x25, 0xbe,
+	0x8a, 0x08, 0x6b, 0x9c, 0xcc, 0xc2, 0x70, 0xb6, 0x20, 0x67, 0x42, 0x79, 0x1b, 0x4f, 0xcf, 0xb8,
+	0xbf, 0x24, 0x8c, 0xbb, 0xcb, 0x48, 0xe2, 0x1a, 0xfa, 0xed, 0x22, 0xf4, 0xde, 0x7b, 0x73, 0xd7,
+	0x0f, 0x94, 0xa6, 0xee, 0x7a, 0x5e, 0x18, 0x07, 0x5c, 0x89, 0x10, 0x84, 0x13, 0x22, 0xcf, 0xc6,
+	0x5f, 0x1a, 0xe8, 0xe7, 0x6b, 0xfc, 0x98, 0xbb, 0x3c, 0x66, 0xe8, 0x19, 0x1c, 0xdc, 0x12, 0xc6,
+	0x1d, 0x61, 0xc8, 0x99, 0xbb, 0x6c, 0x7e, 0xa4, 0x35, 0xb5, 0x56, 0x0d, 0xd7, 0x13, 0xb5, 0x80,
+	0x5f, 0xba, 0x6c, 0x8e, 0x4e, 0xa0, 0x2a, 0x70, 0x73, 0xe2, 0xcf, 0xe6, 0xfc, 0xa8, 0xd8, 0xd4,
+	0x5a, 0xbb, 0x18, 0x12, 0xd5, 0xa5, 0xd0, 0xa0, 0x23, 0xd8, 0x63, 0xab, 0xc0, 0xf3, 0x83, 0xd9,
+	0xd1, 0x4e, 0x53, 0x6b, 0x55, 0x70, 0x2a, 0xa2, 0x27, 0x50, 0x4f, 0x8e, 0x4e, 0x44, 0xc3, 0x19,
+	0x25, 0x8c, 0x1d, 0xed, 0x36, 0xb5, 0x96, 0x86, 0x6b, 0x89, 0x72, 0xa4, 0x74, 0x09, 0x88, 0xbb,
+	0x74, 0x46, 0xd6, 0x1e, 0x4a, 0xc2, 0x43, 0x4d, 0x2a, 0xa5, 0x0f, 0xc3, 0x83, 0x52, 0x2f, 0x88,
+	0x62, 0x8e, 0x10, 0xec, 0x66, 0xa8, 0x8a, 0x73, 0x42, 0xc0, 0x9d, 0x4c, 0x84, 0x83, 0x62, 0x73,
+	0xa7, 0x55, 0xc3, 0xa9, 0x88, 0x0e, 0xa1, 0x74, 0xe7, 0x2e, 0x62, 0x22, 0x88, 0xd5, 0xb0, 0x14,
+	0xd0, 0x63, 0x28, 0x33, 0x8f, 0xfa, 0x11, 0x17, 0x7c, 0x6a, 0x58, 0x49, 0xc6, 0x14, 0xca, 0xc3,
+	0x98, 0x27, 0x5e, 0x0e, 0xa1, 0xe4, 0x07, 0x13, 0x72, 0x2f, 0xdc, 0xd4, 0xb1, 0x14, 0xf2, 0x7e,
+	0xb4, 0xff, 0xee, 0x67, 0x0f, 0x4a, 0xd6, 0x32, 0xe2, 0x2b, 0xe3, 0x09, 0x54, 0xc7, 0x7e, 0x30,
+	0x5b, 0x90, 0xf3, 0x15, 0x27, 0x19, 0x2b, 0x5a, 0xc6, 0x8a, 0x11, 0x41, 0x65, 0x44, 0x28, 0x0b,
+	0x03, 0x77, 0x81, 0x8e, 0x01, 0x22, 0x97, 0xb1, 0x68, 0x4e, 0x5d, 0x26, 0x61, 0xfb, 0x38, 0xa3,
+	0x41, 0x2d, 0xd8, 0x53, 0x5d, 0x20, 0x18, 0x56, 0xdb, 0x8f, 0x4e, 0x45, 0x3f, 0x9d, 0x9a, 0x52,
+	0x8b, 0xd3, 0xcf, 0xa8, 0x01, 0x95, 0x49, 0x4c, 0x5d, 0xee, 0x87, 0x81, 0x20, 0xbd, 0x8b, 0xd7,
+	0xb2, 0xd1, 0x4f, 0x3c, 0x12, 0xda, 0xf7, 0x19, 0x47, 0x2d, 0x28, 0x45, 0x84, 0x50, 0x76, 0xa4,
+	0x35, 0x77, 0x5a, 0xd5, 0x36, 0x52, 0xf6, 0x92, 0xef, 0xa6, 0x0c, 0x1e, 0x4b, 0x80, 0x88, 0x96,
+	0xbb, 0x9c, 0xc8, 0x22, 0x94, 0xb0, 0x92, 0x8c, 0x3b, 0x80, 0xc4, 0xd2, 0xc8, 0xa5, 0xee, 0x92,
+	0x6d, 0xad, 0xdf, 0x63, 0x28, 0xe7, 0x9a, 0x4b, 0x49, 0x09, 0x96, 0xf9, 0xbf, 0xcb, 0xa4, 0xd6,
+	0xb1, 0x38, 0x27, 0xd8, 0x70, 0x3a, 0x65, 0x44, 0xe6, 0xb4, 0x8e, 0x95, 0x84, 0x74, 0xd8, 0x71,
+	0x99, 0x27, 0x7a, 0xa7, 0x82, 0x93, 0xa3, 0xf1, 0x3d, 0x1c, 0xc8, 0x26, 0x26, 0xee, 0x44, 0x05,
+	0xf3, 0x14, 0xca, 0xa2, 0xdb, 0xd3, 0x68, 0x6a, 0x2a, 0x1a, 0x81, 0xc3, 0xea, 0x9b, 0x41, 0xa0,
+	0xd6, 0x09, 0x97, 0x4b, 0x9f, 0x63, 0xc2, 0xe2, 0xc5, 0xf6, 0x96, 0xfb, 0x0a, 0x4a, 0x84, 0xd2,
+	0x90, 0x0a, 0xc6, 0x8f, 0xda, 0xff, 0x57, 0x86, 0xe4, 0x3d, 0x39, 0x60, 0x58, 0x22, 0x12, 0xc6,
+	0x13, 0xc2, 0x5d, 0x7f, 0x21, 0xe2, 0xd8, 0xc7, 0x4a, 0x32, 0x4c, 0xd0, 0xb3, 0x6e, 0x04, 0xc1,
+	0x17, 0xb0, 0x47, 0x85, 0x94, 0x32, 0xcc, 0x1b, 0x96, 0x48, 0x9c, 0x62, 0x0c, 0x1b, 0x6a, 0x6f,
+	0x09, 0xf5, 0xa7, 0x2b, 0xc5, 0xf4, 0x73, 0x28, 0x72, 0xd9, 0xb3, 0xd5, 0xf6, 0xbe, 0xba, 0x69,
+	0xdf, 0xe3, 0x22, 0xbf, 0xff, 0x18, 0x61, 0x79, 0x3d, 0x47, 0xd8, 0xe8, 0x03, 0x24, 0x0a, 0xf2,
+	0x73, 0x4c, 0xe8, 0x4a, 0x34, 0xbd, 0x6a, 0x29, 0x4d, 0x35, 0xbd, 0x14, 0xd1, 0x53, 0xa8, 0x7b,
+	0x61, 0x30, 0xf5, 0xe9, 0x52, 0xb4, 0x0d, 0x53, 0xd5, 0xcb, 0x2b, 0x8d, 0x1f, 0xa0, 0x3e, 0xf6,
+	0x67, 0x81, 0x7d, 0x8f, 0xc9, 0x6f, 0x31, 0x61, 0xff, 0x48, 0x12, 0xc1, 0xee, 0x94, 0x86, 0x4b,
+	0x35, 0x5d, 0xe2, 0xfc, 0xfc, 0xcf, 0x62, 0x5a, 0x0e, 0xb5, 0xb7, 0x0e, 0x41, 0xef, 0x0c, 0xaf,
+	0xae, 0x7a, 0xb6, 0x33, 0xb6, 0x4d, 0xfb, 0x7a, 0xec, 0x0c, 0x7f, 0xd2, 0x0b, 0xe8, 0x04, 0xbe,
+	0xc8, 0x6b, 0x07, 0xc3, 0x41, 0xc7, 0x72, 0xec, 0xe1, 0xd0, 0xe9, 0x0f, 0x7f, 0xd1, 0x35, 0x64,
+	0xc0, 0x71, 0x1e, 0xd0, 0x1b, 0xbc, 0x35, 0xfb, 0xbd, 0x37, 0x8e, 0x89, 0xbb, 0xd7, 0x57, 0xd6,
+	0xc0, 0xd6, 0x8b, 0xe8, 0x09, 0x9c, 0xe4, 0x31, 0xf6, 0x8d, 0x63, 0xf6, 0xb1, 0x65, 0xbe, 0x79,
+	0xe7, 0x58, 0x37, 0xbd, 0xb1, 0x3d, 0xd6, 0x77, 0xb6, 0x82, 0x7a, 0x03, 0xdb, 0xc2, 0x03, 0xb3,
+	0xef, 0x58, 0x18, 0x0f, 0xb1, 0xbe, 0xfb, 0x21, 0x28, 0xf5, 0x36, 0xee, 0x75, 0x07, 0xa6, 0x7d,
+	0x8d, 0x2d, 0xbd, 0x84, 0x9e, 0x81, 0xf1, 0x10, 0x34, 0xbe, 0xbe, 0xb8, 0xe8, 0x75, 0x7a, 0xd6,
+	0xc0, 0x76, 0xce, 0xcd, 0xbe, 0x39, 0xe8, 0x58, 0x7a, 0x19, 0x1d, 0x43, 0xe3, 0x03, 0x8f, 0x22,
+	0x30, 0x13, 0x77, 0x2d, 0x7d, 0xef, 0xf9, 0x34, 0x6d, 0x83, 0x4d, 0x86, 0xde, 0x5a, 0xb8, 0x77,
+	0xf1, 0x2e, 0x97, 0xa1, 0x26, 0x7c, 0x99, 0xd7, 0x26, 0x54, 0x9c, 0xc1, 0xd0, 0x76, 0xae, 0x4c,
+	0xbb, 0x73, 0xa9, 0x6b, 0x89, 0x9f, 0x3c, 0x22, 0x25, 0x7d, 0x69, 0x8e, 0x2f, 0xf5, 0x62, 0xfb,
+	0x8f, 0x0a, 0x1c, 0x98, 0x84, 0xce, 0x42, 0x3c, 0xea, 0x8c, 0x09, 0xbd, 0xf3, 0x3d, 0x82, 0x5e,
+	0xc3, 0xfe, 0x20, 0x9c, 0x10, 0xd1, 0x30, 0x28, 0xdd, 0x0e, 0x99, 0xa5, 0xd6, 0xd8, 0xa2, 0x33,
+	0x0a, 0xe8, 0x35, 0xc0, 0xe6, 0x41, 0x42, 0xe9, 0x1c, 0x8a, 0xad, 0xd8, 0xf8, 0x2c, 0x3b, 0x95,
+	0x99, 0x17, 0xcb, 0x28, 0xa0, 0x1f, 0x41, 0x4f, 0xe6, 0x24, 0x33, 0xd7, 0x0c, 0xfd, 0x4f, 0xc1,
+	0x37, 0x4b, 0xa6, 0xf1, 0x38, 0x6b, 0x61, 0x33, 0xff, 0x46, 0x01, 0x9d, 0x42, 0xa5, 0x4b, 0xe4,
+	0xfd, 0xad, 0x6c, 0x73, 0x1b, 0xc1, 0x28, 0x24, 0xeb, 0xaf, 0x4b, 0xb8, 0x7d, 0xb3, 0x15, 0xbc,
+	0xe9, 0x5e, 0xa3, 0x80, 0xbe, 0x03, 0x48, 0x2d, 0x7f, 0x04, 0xae, 0xaf, 0xe1, 0xbd, 0x20, 0xb5,
+	0xdf, 0x16, 0xb7, 0x30, 0xf1, 0x88, 0x1f, 0xf1, 0xad, 0xb7, 0xd2, 0x0d, 0xae, 0x30, 0xc2, 0x53,
+	0x45, 0x0e, 0x84, 0x7d, 0x83, 0xea, 0x6b, 0x9b, 0x49, 0x80, 0xeb, 0xd4, 0x3d, 0x5c, 0x2c, 0x46,
+	0x01, 0xbd, 0x10, 0x91, 0xcb, 0x3a, 0xa5, 0x29, 0xdb, 0x8c, 0x79, 0xa3, 0x96, 0x55, 0x09, 0x62,
+	0xf5, 0x0e, 0x25, 0x2e, 0x27, 0xea, 0xe5, 0x40, 0x07, 0xeb, 0xcd, 0x2f, 0xdf, 0xa2, 0xc6, 0x83,
+	0xa7, 0xc5, 0x28, 0xa0, 0x6f, 0xa1, 0xda, 0x25, 0x5c, 0xc9, 0xec, 0x41, 0x55, 0x51, 0x1e, 0xae,
+	0x58, 0xbd, 0x84, 0x6a, 0x3f, 0xf4, 0xde, 0x7f, 0x82, 0x93, 0x36, 0xd4, 0xaf, 0x83, 0xc5, 0xa7,
+	0xdd, 0x69, 0x42, 0x59, 0xec, 0xa0, 0x1b, 0xb4, 0x29, 0xd9, 0xc3, 0xea, 0x1d, 0x08, 0x04, 0x75,
+	0x03, 0xe6, 0x7a, 0xc9, 0xe6, 0x42, 0x87, 0xeb, 0x62, 0x64, 0xb6, 0x57, 0xfe, 0xd6, 0x37, 0x50,
+	0x91, 0x83, 0x97, 0xb7, 0x9c, 0x5f, 0xae, 0xb2, 0x0a, 0x46, 0x01, 0x7d, 0x2d, 0x2a, 0x30, 0x12,
+	0x8f, 0x65, 0x3e, 0x37, 0x07, 0x99, 0x57, 0x55, 0x25, 0xa6, 0x0d, 0xb5, 0x37, 0xf1, 0x32, 0x1a,
+	0xb5, 0x47, 0xb2, 0x64, 0xdb, 0x93, 0x99, 0x1f, 0xaa, 0x57, 0x50, 0xef, 0x92, 0x80, 0x50, 0x97,
+	0x13, 0xd9, 0xe1, 0xff, 0xe6, 0xd2, 0x4b, 0xd8, 0xeb, 0x12, 0xde, 0x0f, 0x67, 0x9b, 0x49, 0xba,
+	0xf0, 0x17, 0x9c, 0xd0, 0x5e, 0x30, 0x0d, 0xd7, 0x3d, 0x6b, 0xdd, 0x91, 0xb4, 0x66, 0xe7, 0xcd,
+	0x5f, 0x8f, 0x67, 0x3e, 0x9f, 0xc7, 0xb7, 0xa7, 0x5e, 0xb8, 0x3c, 0x73, 0x93, 0x85, 0xe0, 0x87,
+	0xf2, 0xf7, 0x4c, 0xa0, 0x6f, 0xcb, 0xe2, 0x6f, 0xe7, 0xab, 0xbf, 0x07, 0x00, 0xf0, 0x14, 0xa1,
+	0xa7, 0xd8, 0x0a, 0x00, 0x00,
 }
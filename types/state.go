@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/binary"
@@ -89,15 +90,36 @@ func NewState() *State {
 	}
 }
 
+// IsEmpty reports whether the state is same as the one of an account which doesn't exist.
 func (st *State) IsEmpty() bool {
-	return st.Nonce == 0 && st.Balance == 0
+	return st.Nonce == 0 && st.Balance == 0 && len(st.CodeHash) == 0 && len(st.StorageRoot) == 0
+}
+
+// stateEncodingVersion is the first byte of the canonical encoding of State. It must be increased
+// whenever the encoding is changed, so that the encodings of different versions never collide.
+const stateEncodingVersion byte = 1
+
+// Serialize returns the canonical encoding of State, which is used for its hash. Unlike protobuf
+// marshaling, it doesn't depend on the implementation: version byte first, and then nonce and
+// balance as little endian uint64, code hash and storage root as uint32 length-prefixed bytes.
+func (st *State) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(stateEncodingVersion)
+	binary.Write(&buf, binary.LittleEndian, st.Nonce)
+	binary.Write(&buf, binary.LittleEndian, st.Balance)
+	writeLengthPrefixed(&buf, st.CodeHash)
+	writeLengthPrefixed(&buf, st.StorageRoot)
+	return buf.Bytes()
+}
+
+func writeLengthPrefixed(buf *bytes.Buffer, data []byte) {
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
 }
 
 func (st *State) GetHash() []byte {
-	digest := sha256.New()
-	binary.Write(digest, binary.LittleEndian, st.Nonce)
-	binary.Write(digest, binary.LittleEndian, st.Balance)
-	return digest.Sum(nil)
+	digest := sha256.Sum256(st.Serialize())
+	return digest[:]
 }
 
 func (st *State) Clone() *State {
@@ -105,8 +127,10 @@ func (st *State) Clone() *State {
 		return nil
 	}
 	return &State{
-		Nonce:   st.Nonce,
-		Balance: st.Balance,
+		Nonce:       st.Nonce,
+		Balance:     st.Balance,
+		CodeHash:    append([]byte(nil), st.CodeHash...),
+		StorageRoot: append([]byte(nil), st.StorageRoot...),
 	}
 }
 
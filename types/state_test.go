@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_GetHash(t *testing.T) {
+	base := &State{Nonce: 1, Balance: 1000, CodeHash: []byte("code"), StorageRoot: []byte("root")}
+
+	equal := &State{Nonce: 1, Balance: 1000, CodeHash: []byte("code"), StorageRoot: []byte("root")}
+	assert.Equal(t, base.GetHash(), equal.GetHash())
+	assert.Equal(t, base.GetHash(), base.Clone().GetHash())
+	// protobuf internal fields must not affect the hash
+	equal.XXX_sizecache = 100
+	assert.Equal(t, base.GetHash(), equal.GetHash())
+
+	differs := []*State{
+		{Nonce: 2, Balance: 1000, CodeHash: []byte("code"), StorageRoot: []byte("root")},
+		{Nonce: 1, Balance: 1001, CodeHash: []byte("code"), StorageRoot: []byte("root")},
+		{Nonce: 1, Balance: 1000, CodeHash: []byte("Code"), StorageRoot: []byte("root")},
+		{Nonce: 1, Balance: 1000, CodeHash: []byte("code"), StorageRoot: []byte("Root")},
+		// moving bytes between fields must change the encoding
+		{Nonce: 1, Balance: 1000, CodeHash: []byte("coder"), StorageRoot: []byte("oot")},
+		{Nonce: 1, Balance: 1000, CodeHash: nil, StorageRoot: []byte("root")},
+	}
+	for _, st := range differs {
+		assert.NotEqual(t, base.GetHash(), st.GetHash(), st.String())
+	}
+}
+
+func TestState_Serialize(t *testing.T) {
+	st := &State{Nonce: 1, Balance: 2, CodeHash: []byte{0xaa}, StorageRoot: nil}
+	expected := []byte{stateEncodingVersion,
+		1, 0, 0, 0, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+		1, 0, 0, 0, 0xaa,
+		0, 0, 0, 0}
+	assert.Equal(t, expected, st.Serialize())
+}
@@ -0,0 +1,28 @@
+package types
+
+// SyncState describes whether the local chain is catching up with the chains of peers.
+type SyncState struct {
+	// Syncing is true while the best block is behind the highest block reported by peers.
+	Syncing bool
+	// Progress is the ratio of the best height to the target height. It is 1 when synced.
+	Progress     float64
+	BestHeight   BlockNo
+	TargetHeight BlockNo
+}
+
+// NewSyncState compares the local best height with the best heights reported by peers.
+// The highest one of peers is the target to sync.
+func NewSyncState(bestHeight BlockNo, peerHeights []BlockNo) SyncState {
+	target := bestHeight
+	for _, height := range peerHeights {
+		if height > target {
+			target = height
+		}
+	}
+	state := SyncState{BestHeight: bestHeight, TargetHeight: target, Progress: 1}
+	if target > bestHeight {
+		state.Syncing = true
+		state.Progress = float64(bestHeight) / float64(target)
+	}
+	return state
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSyncState(t *testing.T) {
+	tests := []struct {
+		name         string
+		bestHeight   BlockNo
+		peerHeights  []BlockNo
+		wantSyncing  bool
+		wantProgress float64
+		wantTarget   BlockNo
+	}{
+		{"TNoPeer", 10, nil, false, 1, 10},
+		{"TBehind", 25, []BlockNo{50, 100, 80}, true, 0.25, 100},
+		{"TCaughtUp", 100, []BlockNo{50, 100, 80}, false, 1, 100},
+		{"TAhead", 120, []BlockNo{50, 100}, false, 1, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewSyncState(tt.bestHeight, tt.peerHeights)
+			assert.Equal(t, tt.wantSyncing, state.Syncing)
+			assert.Equal(t, tt.wantProgress, state.Progress)
+			assert.Equal(t, tt.bestHeight, state.BestHeight)
+			assert.Equal(t, tt.wantTarget, state.TargetHeight)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/**
+ *  @file
+ *  @copyright defined in aergo/LICENSE.txt
+ */
+
+package types
+
+import (
+	"time"
+
+	"github.com/aergoio/aergo/internal/enc"
+)
+
+// TestGenesisOptions is the options of genesis made by NewTestGenesis.
+type TestGenesisOptions struct {
+	// Timestamp is the timestamp of genesis block. Current time is used if it is zero.
+	Timestamp int64
+	// Balances is the initial balances of accounts, keyed by raw address.
+	Balances map[string]uint64
+}
+
+// NewTestGenesis makes a trivial genesis with optional pre-funded accounts.
+// It is for tests only, and must not be used for a real chain.
+func NewTestGenesis(opts TestGenesisOptions) *Genesis {
+	timestamp := opts.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().UnixNano()
+	}
+	genesis := &Genesis{
+		Balance:   make(map[string]*State, len(opts.Balances)),
+		Timestamp: timestamp,
+	}
+	for addr, balance := range opts.Balances {
+		genesis.Balance[enc.ToString([]byte(addr))] = &State{Balance: balance}
+	}
+	return genesis
+}